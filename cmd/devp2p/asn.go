@@ -0,0 +1,109 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// asnTable maps IP address ranges to autonomous systems.
+// The ranges are sorted by start address and do not overlap.
+type asnTable []asnRange
+
+type asnRange struct {
+	start, end netip.Addr
+	asn        uint32
+	name       string
+}
+
+// loadASNTable reads an IP-to-ASN database file.
+func loadASNTable(file string) (asnTable, error) {
+	fd, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+	t, err := parseASNTable(fd)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	return t, nil
+}
+
+// parseASNTable parses the tab-separated format of the iptoasn.com database.
+// Each line has the fields: range_start, range_end, AS_number, country_code,
+// AS_description. Ranges with AS number zero are not routed and skipped.
+func parseASNTable(r io.Reader) (asnTable, error) {
+	var (
+		t    asnTable
+		scan = bufio.NewScanner(r)
+		line int
+	)
+	for scan.Scan() {
+		line++
+		if len(strings.TrimSpace(scan.Text())) == 0 {
+			continue
+		}
+		fields := strings.Split(scan.Text(), "\t")
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("line %d: too few fields", line)
+		}
+		start, err := netip.ParseAddr(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		end, err := netip.ParseAddr(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		asn, err := strconv.ParseUint(fields[2], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid AS number %q", line, fields[2])
+		}
+		if asn == 0 {
+			continue
+		}
+		var name string
+		if len(fields) > 4 {
+			name = fields[4]
+		}
+		t = append(t, asnRange{start.Unmap(), end.Unmap(), uint32(asn), name})
+	}
+	if err := scan.Err(); err != nil {
+		return nil, err
+	}
+	slices.SortFunc(t, func(a, b asnRange) int { return a.start.Compare(b.start) })
+	return t, nil
+}
+
+// lookup returns the autonomous system of an IP address.
+func (t asnTable) lookup(ip netip.Addr) (uint32, string) {
+	ip = ip.Unmap()
+	i := sort.Search(len(t), func(i int) bool { return t[i].start.Compare(ip) > 0 })
+	if i == 0 || t[i-1].end.Compare(ip) < 0 {
+		return 0, ""
+	}
+	return t[i-1].asn, t[i-1].name
+}
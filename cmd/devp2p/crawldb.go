@@ -0,0 +1,279 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/forkid"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// Keys in the crawl database.
+const (
+	crawlDBNodePrefix  = "n:" // n:<ID> -> node history
+	crawlDBCrawlPrefix = "c:" // c:<time> -> crawl record
+)
+
+// crawlDB stores the history of crawled nodes across many crawls.
+type crawlDB struct {
+	db *leveldb.DB
+}
+
+// nodeHistory is everything known about a node over all crawls.
+type nodeHistory struct {
+	ID        enode.ID    `json:"id"`
+	N         *enode.Node `json:"record"`
+	FirstSeen time.Time   `json:"firstSeen"`
+	LastSeen  time.Time   `json:"lastSeen"`
+	Crawls    int         `json:"crawls"` // number of crawls the node was alive in
+
+	// Client is the client identifier, taken from the RLPx handshake or the
+	// "client" ENR entry.
+	Client string `json:"client,omitempty"`
+	// The network ID is only known if the eth status handshake was performed.
+	NetworkID uint64 `json:"networkID,omitempty"`
+	// The fork ID is taken from the eth status or the "eth" ENR entry.
+	ForkHash string `json:"forkHash,omitempty"`
+	ForkNext uint64 `json:"forkNext,omitempty"`
+
+	IP     netip.Addr `json:"ip"`
+	ASN    uint32     `json:"asn,omitempty"`
+	ASName string     `json:"asName,omitempty"`
+}
+
+// crawlRecord is the set of nodes alive during a single crawl.
+type crawlRecord struct {
+	Time  time.Time  `json:"time"`
+	Nodes []enode.ID `json:"nodes"`
+}
+
+func openCrawlDB(path string) (*crawlDB, error) {
+	db, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &crawlDB{db: db}, nil
+}
+
+func (db *crawlDB) close() error {
+	return db.db.Close()
+}
+
+func crawlDBNodeKey(id enode.ID) []byte {
+	return append([]byte(crawlDBNodePrefix), id[:]...)
+}
+
+func crawlDBCrawlKey(t time.Time) []byte {
+	return binary.BigEndian.AppendUint64([]byte(crawlDBCrawlPrefix), uint64(t.UnixNano()))
+}
+
+// node returns the history of a node, or nil if the node is unknown.
+func (db *crawlDB) node(id enode.ID) (*nodeHistory, error) {
+	blob, err := db.db.Get(crawlDBNodeKey(id), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var h nodeHistory
+	if err := json.Unmarshal(blob, &h); err != nil {
+		return nil, fmt.Errorf("invalid node history %v: %v", id, err)
+	}
+	return &h, nil
+}
+
+// nodes returns the histories of all known nodes, ordered by ID.
+func (db *crawlDB) nodes() ([]*nodeHistory, error) {
+	it := db.db.NewIterator(util.BytesPrefix([]byte(crawlDBNodePrefix)), nil)
+	defer it.Release()
+
+	var result []*nodeHistory
+	for it.Next() {
+		h := new(nodeHistory)
+		if err := json.Unmarshal(it.Value(), h); err != nil {
+			return nil, fmt.Errorf("invalid node history %x: %v", it.Key(), err)
+		}
+		result = append(result, h)
+	}
+	return result, it.Error()
+}
+
+// crawls returns all crawl records, ordered by time.
+func (db *crawlDB) crawls() ([]crawlRecord, error) {
+	it := db.db.NewIterator(util.BytesPrefix([]byte(crawlDBCrawlPrefix)), nil)
+	defer it.Release()
+
+	var result []crawlRecord
+	for it.Next() {
+		var c crawlRecord
+		if err := json.Unmarshal(it.Value(), &c); err != nil {
+			return nil, fmt.Errorf("invalid crawl record %x: %v", it.Key(), err)
+		}
+		result = append(result, c)
+	}
+	return result, it.Error()
+}
+
+// recordCrawl adds the result of a crawl performed at time t to the database.
+// Nodes of the set count as alive if they responded to their most recent
+// liveness check. Probe results and the ASN table are optional.
+// It returns the number of live nodes.
+func (db *crawlDB) recordCrawl(t time.Time, ns nodeSet, probes map[enode.ID]*nodeProbe, asns asnTable) (int, error) {
+	var (
+		batch  = new(leveldb.Batch)
+		record = crawlRecord{Time: t.UTC()}
+	)
+	for id, n := range ns {
+		if !isAlive(n) {
+			continue
+		}
+		h, err := db.node(id)
+		if err != nil {
+			return 0, err
+		}
+		if h == nil {
+			h = &nodeHistory{ID: id}
+		}
+		h.update(n, probes[id], asns)
+		blob, err := json.Marshal(h)
+		if err != nil {
+			return 0, err
+		}
+		batch.Put(crawlDBNodeKey(id), blob)
+		record.Nodes = append(record.Nodes, id)
+	}
+	slices.SortFunc(record.Nodes, func(a, b enode.ID) int { return bytes.Compare(a[:], b[:]) })
+	blob, err := json.Marshal(&record)
+	if err != nil {
+		return 0, err
+	}
+	batch.Put(crawlDBCrawlKey(record.Time), blob)
+	return len(record.Nodes), db.db.Write(batch, nil)
+}
+
+// isAlive reports whether the node responded to its most recent check.
+func isAlive(n nodeJSON) bool {
+	return n.N != nil && !n.LastCheck.IsZero() && !n.LastResponse.Before(n.LastCheck)
+}
+
+// update merges the result of a crawl into the history.
+func (h *nodeHistory) update(n nodeJSON, probe *nodeProbe, asns asnTable) {
+	h.N = n.N
+	h.Crawls++
+	if h.FirstSeen.IsZero() || n.FirstResponse.Before(h.FirstSeen) {
+		h.FirstSeen = n.FirstResponse
+	}
+	if n.LastResponse.After(h.LastSeen) {
+		h.LastSeen = n.LastResponse
+	}
+
+	// Take the ENR entries first, the handshake overrides them.
+	if client := enrClient(n.N); client != "" {
+		h.Client = client
+	}
+	if id, ok := enrForkID(n.N); ok {
+		h.setForkID(id)
+	}
+	if probe != nil {
+		if probe.Client != "" {
+			h.Client = probe.Client
+		}
+		if probe.Status {
+			h.NetworkID = probe.NetworkID
+			h.setForkID(probe.ForkID)
+		}
+	}
+
+	ip := n.N.IPAddr()
+	if ip != h.IP {
+		h.IP, h.ASN, h.ASName = ip, 0, ""
+	}
+	if asns != nil && ip.IsValid() {
+		h.ASN, h.ASName = asns.lookup(ip)
+	}
+}
+
+func (h *nodeHistory) setForkID(id forkid.ID) {
+	h.ForkHash = hex.EncodeToString(id.Hash[:])
+	h.ForkNext = id.Next
+}
+
+// forkID returns the fork ID of the node, if known.
+func (h *nodeHistory) forkID() (forkid.ID, bool) {
+	var id forkid.ID
+	hash, err := hex.DecodeString(h.ForkHash)
+	if err != nil || len(hash) != len(id.Hash) {
+		return id, false
+	}
+	copy(id.Hash[:], hash)
+	id.Next = h.ForkNext
+	return id, true
+}
+
+// nodeJSON converts the history to the node set format, so node set filters
+// can be applied. The number of crawls is used as the score.
+func (h *nodeHistory) nodeJSON() nodeJSON {
+	return nodeJSON{
+		Seq:           h.N.Seq(),
+		N:             h.N,
+		Score:         h.Crawls,
+		FirstResponse: h.FirstSeen,
+		LastResponse:  h.LastSeen,
+		LastCheck:     h.LastSeen,
+	}
+}
+
+// enrClient returns the content of the "client" ENR entry (EIP-7636) in the
+// same format as the RLPx client identifier.
+func enrClient(n *enode.Node) string {
+	var client struct {
+		Name    string
+		Version string
+		Tail    []rlp.RawValue `rlp:"tail"`
+	}
+	if n.Load(enr.WithEntry("client", &client)) != nil || client.Name == "" {
+		return ""
+	}
+	if client.Version == "" {
+		return client.Name
+	}
+	return client.Name + "/" + client.Version
+}
+
+// enrForkID returns the fork ID in the "eth" ENR entry.
+func enrForkID(n *enode.Node) (forkid.ID, bool) {
+	var eth struct {
+		ForkID forkid.ID
+		Tail   []rlp.RawValue `rlp:"tail"`
+	}
+	if n.Load(enr.WithEntry("eth", &eth)) != nil {
+		return forkid.ID{}, false
+	}
+	return eth.ForkID, true
+}
@@ -0,0 +1,258 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"net"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/forkid"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+)
+
+// testCrawlNode creates a node record with optional client and eth entries.
+func testCrawlNode(t *testing.T, ip string, client []string, fork *forkid.ID) *enode.Node {
+	t.Helper()
+	key, _ := crypto.GenerateKey()
+	var r enr.Record
+	r.Set(enr.IP(net.ParseIP(ip)))
+	r.Set(enr.TCP(30303))
+	r.Set(enr.UDP(30303))
+	if client != nil {
+		r.Set(enr.WithEntry("client", client))
+	}
+	if fork != nil {
+		r.Set(enr.WithEntry("eth", []any{*fork}))
+	}
+	if err := enode.SignV4(&r, key); err != nil {
+		t.Fatal(err)
+	}
+	n, err := enode.New(enode.ValidSchemes, &r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func aliveAt(n *enode.Node, first, last time.Time) nodeJSON {
+	return nodeJSON{Seq: n.Seq(), N: n, Score: 1, FirstResponse: first, LastResponse: last, LastCheck: last}
+}
+
+func TestCrawlDBRecord(t *testing.T) {
+	db, err := openCrawlDB(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.close()
+
+	var (
+		t0   = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		t1   = t0.Add(time.Hour)
+		fork = forkid.ID{Hash: [4]byte{1, 2, 3, 4}, Next: 100}
+		n1   = testCrawlNode(t, "10.0.0.1", []string{"Geth", "v1.15.0"}, &fork)
+		n2   = testCrawlNode(t, "10.0.1.1", nil, nil)
+		n3   = testCrawlNode(t, "192.168.0.1", nil, nil)
+		asns = asnTable{
+			{netip.MustParseAddr("10.0.0.0"), netip.MustParseAddr("10.0.0.255"), 64512, "Example"},
+		}
+	)
+	// n3 did not respond to its last check.
+	dead := aliveAt(n3, t0, t0)
+	dead.LastCheck = t1
+	crawl1 := nodeSet{n1.ID(): aliveAt(n1, t0, t0), n2.ID(): aliveAt(n2, t0, t0), n3.ID(): dead}
+	if count, err := db.recordCrawl(t0, crawl1, nil, asns); err != nil {
+		t.Fatal(err)
+	} else if count != 2 {
+		t.Fatalf("recorded %d live nodes, want 2", count)
+	}
+	probes := map[enode.ID]*nodeProbe{
+		n2.ID(): {Client: "Nethermind/v1.30.0/linux-x64", Status: true, NetworkID: 1, ForkID: fork},
+	}
+	crawl2 := nodeSet{n1.ID(): aliveAt(n1, t0, t1), n2.ID(): aliveAt(n2, t0, t1)}
+	if _, err := db.recordCrawl(t1, crawl2, probes, asns); err != nil {
+		t.Fatal(err)
+	}
+
+	h1, _ := db.node(n1.ID())
+	want1 := &nodeHistory{
+		ID: n1.ID(), N: n1, FirstSeen: t0, LastSeen: t1, Crawls: 2,
+		Client: "Geth/v1.15.0", ForkHash: "01020304", ForkNext: 100,
+		IP: netip.MustParseAddr("10.0.0.1"), ASN: 64512, ASName: "Example",
+	}
+	if !reflect.DeepEqual(h1, want1) {
+		t.Errorf("wrong history of node 1:\nhave %+v\nwant %+v", h1, want1)
+	}
+	h2, _ := db.node(n2.ID())
+	if h2.Client != "Nethermind/v1.30.0/linux-x64" || h2.NetworkID != 1 || h2.ForkHash != "01020304" || h2.ASN != 0 {
+		t.Errorf("probe not recorded in history of node 2: %+v", h2)
+	}
+	if h3, _ := db.node(n3.ID()); h3 != nil {
+		t.Errorf("dead node recorded: %+v", h3)
+	}
+
+	crawls, err := db.crawls()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(crawls) != 2 || !crawls[0].Time.Equal(t0) || !crawls[1].Time.Equal(t1) {
+		t.Fatalf("wrong crawls: %+v", crawls)
+	}
+	if nodes, _ := db.nodes(); len(nodes) != 2 {
+		t.Fatalf("wrong node count %d", len(nodes))
+	}
+}
+
+func TestCrawlReport(t *testing.T) {
+	config, genesis, err := networkGenesis("sepolia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		now    = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		want   = forkid.NewID(config, genesis, math.MaxUint64, uint64(now.Unix()))
+		stale  = forkid.NewID(config, genesis, 0, 0)
+		other  = forkid.ID{Hash: [4]byte{0xff, 0xff, 0xff, 0xff}}
+		behind = forkid.ID{Hash: want.Hash}
+	)
+	fc, err := newForkChecker("sepolia", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	histories := []*nodeHistory{
+		{ID: enode.ID{1}, Client: "Geth/v1.15.0-stable/linux-amd64/go1.24"},
+		{ID: enode.ID{2}, Client: "Geth/v1.14.0-stable", NetworkID: 11155111},
+		{ID: enode.ID{3}, Client: "reth/v1.1.0", ASN: 64512, ASName: "Example"},
+		{ID: enode.ID{4}},
+		{ID: enode.ID{5}, Client: "Geth/v1.15.0"}, // not in latest crawl
+	}
+	for i, id := range []forkid.ID{want, behind, stale, other} {
+		histories[i].setForkID(id)
+	}
+	crawls := []crawlRecord{
+		{Time: now.Add(-2 * time.Hour), Nodes: []enode.ID{{1}, {5}}},
+		{Time: now.Add(-time.Hour), Nodes: []enode.ID{{1}, {2}, {5}}},
+		{Time: now, Nodes: []enode.ID{{1}, {2}, {3}, {4}}},
+	}
+	r := newCrawlReport(histories, crawls, fc)
+
+	if r.Nodes != 4 || !r.Time.Equal(now) {
+		t.Fatalf("wrong report summary: %d nodes at %v", r.Nodes, r.Time)
+	}
+	wantClients := []reportEntry{{"geth", 2, 0.5}, {"reth", 1, 0.25}, {"unknown", 1, 0.25}}
+	if !reflect.DeepEqual(r.Clients, wantClients) {
+		t.Errorf("wrong clients: %+v", r.Clients)
+	}
+	wantVersions := []reportEntry{{"geth/v1.14.0-stable", 1, 0.25}, {"geth/v1.15.0-stable", 1, 0.25}, {"reth/v1.1.0", 1, 0.25}, {"unknown", 1, 0.25}}
+	if !reflect.DeepEqual(r.Versions, wantVersions) {
+		t.Errorf("wrong client versions: %+v", r.Versions)
+	}
+	wantNetworks := []reportEntry{{"unknown", 3, 0.75}, {"11155111", 1, 0.25}}
+	if !reflect.DeepEqual(r.Networks, wantNetworks) {
+		t.Errorf("wrong networks: %+v", r.Networks)
+	}
+	wantASNs := []reportEntry{{"unknown", 3, 0.75}, {"AS64512 Example", 1, 0.25}}
+	if !reflect.DeepEqual(r.ASNs, wantASNs) {
+		t.Errorf("wrong ASNs: %+v", r.ASNs)
+	}
+	wantForks := forkReport{Network: "sepolia", ForkID: r.Forks.ForkID, Ready: 1, NotReady: 1, Stale: 1, Incompatible: 1}
+	if *r.Forks != wantForks {
+		t.Errorf("wrong fork readiness: %+v", *r.Forks)
+	}
+	wantChurn := []churnEntry{
+		{Time: crawls[1].Time, Nodes: 3, Joined: 1, Left: 0},
+		{Time: crawls[2].Time, Nodes: 4, Joined: 2, Left: 1},
+	}
+	if !reflect.DeepEqual(r.Churn, wantChurn) {
+		t.Errorf("wrong churn: %+v", r.Churn)
+	}
+
+	// The CSV output must be well-formed.
+	var buf bytes.Buffer
+	if err := r.writeCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 1 + 1 + 3 + 4 + 2 + 2 + 5 + 4; len(rows) != want {
+		t.Errorf("CSV report has %d rows, want %d", len(rows), want)
+	}
+}
+
+func TestASNTable(t *testing.T) {
+	const tsv = "1.0.0.0\t1.0.0.255\t13335\tUS\tCLOUDFLARENET\n" +
+		"1.0.1.0\t1.0.3.255\t0\tNone\tNot routed\n" +
+		"1.0.4.0\t1.0.7.255\t38803\tAU\tGTELECOM-AUSTRALIA\n" +
+		"2001:200::\t2001:200:ffff:ffff:ffff:ffff:ffff:ffff\t2500\tJP\tWIDE-BB\n"
+	table, err := parseASNTable(strings.NewReader(tsv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		ip   string
+		asn  uint32
+		name string
+	}{
+		{"0.255.255.255", 0, ""},
+		{"1.0.0.0", 13335, "CLOUDFLARENET"},
+		{"1.0.0.255", 13335, "CLOUDFLARENET"},
+		{"1.0.2.1", 0, ""},
+		{"1.0.5.1", 38803, "GTELECOM-AUSTRALIA"},
+		{"::ffff:1.0.5.1", 38803, "GTELECOM-AUSTRALIA"},
+		{"1.0.8.0", 0, ""},
+		{"2001:200::1", 2500, "WIDE-BB"},
+		{"2001:201::1", 0, ""},
+	}
+	for _, test := range tests {
+		asn, name := table.lookup(netip.MustParseAddr(test.ip))
+		if asn != test.asn || name != test.name {
+			t.Errorf("lookup(%s) = %d %q, want %d %q", test.ip, asn, name, test.asn, test.name)
+		}
+	}
+	if _, err := parseASNTable(strings.NewReader("1.0.0.0\tx\t1\n")); err == nil {
+		t.Error("no error for invalid line")
+	}
+}
+
+// The enrClient and enrForkID helpers must read the entries set by clients.
+func TestENREntries(t *testing.T) {
+	fork := forkid.ID{Hash: [4]byte{1, 2, 3, 4}}
+	n := testCrawlNode(t, "10.0.0.1", []string{"reth", "v1.1.0", "abcdef"}, &fork)
+	if client := enrClient(n); client != "reth/v1.1.0" {
+		t.Errorf("wrong client %q", client)
+	}
+	if id, ok := enrForkID(n); !ok || id != fork {
+		t.Errorf("wrong fork ID %v", id)
+	}
+	empty := testCrawlNode(t, "10.0.0.1", nil, nil)
+	if client := enrClient(empty); client != "" {
+		t.Errorf("client %q for node without entry", client)
+	}
+	if _, ok := enrForkID(empty); ok {
+		t.Error("fork ID for node without entry")
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	crawldbCommand = &cli.Command{
+		Name:  "crawldb",
+		Usage: "Crawl history database tools",
+		Subcommands: []*cli.Command{
+			crawldbImportCommand,
+			crawldbNodesCommand,
+			crawldbReportCommand,
+		},
+	}
+	crawldbImportCommand = &cli.Command{
+		Name:      "import",
+		Usage:     "Records a node set as a crawl in the database",
+		ArgsUsage: "<database> <nodes.json>",
+		Action:    crawldbImport,
+		Flags:     []cli.Flag{asnDBFlag, crawlProbeFlag, crawlParallelismFlag},
+	}
+	crawldbNodesCommand = &cli.Command{
+		Name:      "nodes",
+		Usage:     "Exports node histories matching the node set filters",
+		ArgsUsage: "<database> filters..",
+		Action:    crawldbNodes,
+		Flags:     []cli.Flag{nodesFormatFlag},
+	}
+	crawldbReportCommand = &cli.Command{
+		Name:      "report",
+		Usage:     "Shows client diversity, fork readiness and churn of the crawled network",
+		ArgsUsage: "<database>",
+		Action:    crawldbReport,
+		Flags:     []cli.Flag{reportFormatFlag, reportNetworkFlag, reportTopFlag},
+	}
+)
+
+var (
+	crawlDBFlag = &cli.StringFlag{
+		Name:  "crawldb",
+		Usage: "Records the crawl result in the given crawl history database",
+	}
+	crawlProbeFlag = &cli.BoolFlag{
+		Name:  "probe",
+		Usage: "Connects to live nodes to get their client version and eth status",
+	}
+	asnDBFlag = &cli.StringFlag{
+		Name:  "asndb",
+		Usage: "IP-to-ASN database file (iptoasn.com TSV format)",
+	}
+	nodesFormatFlag = &cli.StringFlag{
+		Name:  "format",
+		Usage: "Output format (json, csv)",
+		Value: "json",
+	}
+	reportFormatFlag = &cli.StringFlag{
+		Name:  "format",
+		Usage: "Output format (text, json, csv)",
+		Value: "text",
+	}
+	reportNetworkFlag = &cli.StringFlag{
+		Name:  "network",
+		Usage: "Network for the fork readiness report (mainnet, sepolia, holesky, hoodi), empty to disable",
+		Value: "mainnet",
+	}
+	reportTopFlag = &cli.IntFlag{
+		Name:  "top",
+		Usage: "Number of entries shown per distribution in text format",
+		Value: 10,
+	}
+)
+
+// writeCrawlDB records the result of a crawl if the crawl database is enabled.
+func writeCrawlDB(ctx *cli.Context, ns nodeSet) error {
+	path := ctx.String(crawlDBFlag.Name)
+	if path == "" {
+		return nil
+	}
+	return recordCrawl(ctx, path, truncNow(), ns)
+}
+
+func recordCrawl(ctx *cli.Context, path string, t time.Time, ns nodeSet) error {
+	var asns asnTable
+	if file := ctx.String(asnDBFlag.Name); file != "" {
+		var err error
+		if asns, err = loadASNTable(file); err != nil {
+			return err
+		}
+	}
+	var probes map[enode.ID]*nodeProbe
+	if ctx.Bool(crawlProbeFlag.Name) {
+		var nodes []*enode.Node
+		for _, n := range ns {
+			if isAlive(n) {
+				nodes = append(nodes, n.N)
+			}
+		}
+		log.Info("Probing live nodes", "count", len(nodes))
+		probes = probeNodes(nodes, ctx.Int(crawlParallelismFlag.Name))
+		log.Info("Probing done", "responses", len(probes))
+	}
+
+	db, err := openCrawlDB(path)
+	if err != nil {
+		return err
+	}
+	defer db.close()
+	count, err := db.recordCrawl(t, ns, probes, asns)
+	if err != nil {
+		return err
+	}
+	log.Info("Recorded crawl", "time", t, "nodes", count)
+	return nil
+}
+
+func crawldbImport(ctx *cli.Context) error {
+	if ctx.NArg() < 2 {
+		return errors.New("need database and nodes file as arguments")
+	}
+	ns := loadNodesJSON(ctx.Args().Get(1))
+
+	// The crawl time is the time of the latest check in the set.
+	var t time.Time
+	for _, n := range ns {
+		if n.LastCheck.After(t) {
+			t = n.LastCheck
+		}
+	}
+	if t.IsZero() {
+		return errors.New("node set has no liveness checks")
+	}
+	return recordCrawl(ctx, ctx.Args().First(), t, ns)
+}
+
+func crawldbNodes(ctx *cli.Context) error {
+	if ctx.NArg() < 1 {
+		return errors.New("need database as argument")
+	}
+	limit, err := parseFilterLimit(ctx.Args().Tail())
+	if err != nil {
+		return err
+	}
+	filter, err := andFilter(ctx.Args().Tail())
+	if err != nil {
+		return err
+	}
+
+	db, err := openCrawlDB(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	defer db.close()
+	nodes, err := db.nodes()
+	if err != nil {
+		return err
+	}
+	var (
+		result = make([]*nodeHistory, 0, len(nodes))
+		ns     = make(nodeSet)
+	)
+	for _, h := range nodes {
+		if n := h.nodeJSON(); filter(n) {
+			ns[h.ID] = n
+		}
+	}
+	if limit >= 0 {
+		ns = ns.topN(limit)
+	}
+	for _, h := range nodes {
+		if _, ok := ns[h.ID]; ok {
+			result = append(result, h)
+		}
+	}
+
+	switch format := ctx.String(nodesFormatFlag.Name); format {
+	case "json":
+		return writeJSON(os.Stdout, result)
+	case "csv":
+		return writeNodesCSV(os.Stdout, result)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+func crawldbReport(ctx *cli.Context) error {
+	if ctx.NArg() < 1 {
+		return errors.New("need database as argument")
+	}
+	db, err := openCrawlDB(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	defer db.close()
+	nodes, err := db.nodes()
+	if err != nil {
+		return err
+	}
+	crawls, err := db.crawls()
+	if err != nil {
+		return err
+	}
+	if len(crawls) == 0 {
+		return errors.New("database contains no crawls")
+	}
+
+	// Fork readiness is checked as of the latest crawl.
+	var fc *forkChecker
+	if network := ctx.String(reportNetworkFlag.Name); network != "" {
+		if fc, err = newForkChecker(network, crawls[len(crawls)-1].Time); err != nil {
+			return err
+		}
+	}
+	report := newCrawlReport(nodes, crawls, fc)
+
+	switch format := ctx.String(reportFormatFlag.Name); format {
+	case "text":
+		report.writeText(os.Stdout, ctx.Int(reportTopFlag.Name))
+		return nil
+	case "json":
+		return writeJSON(os.Stdout, report)
+	case "csv":
+		return report.writeCSV(os.Stdout)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", jsonIndent)
+	return enc.Encode(v)
+}
+
+func writeNodesCSV(w io.Writer, nodes []*nodeHistory) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"id", "ip", "tcp", "udp", "asn", "asname", "client", "networkid", "forkhash", "forknext", "firstseen", "lastseen", "crawls", "record"})
+	for _, h := range nodes {
+		var ip, network string
+		if h.IP.IsValid() {
+			ip = h.IP.String()
+		}
+		if h.NetworkID != 0 {
+			network = strconv.FormatUint(h.NetworkID, 10)
+		}
+		cw.Write([]string{
+			h.ID.String(),
+			ip,
+			strconv.Itoa(h.N.TCP()),
+			strconv.Itoa(h.N.UDP()),
+			strconv.FormatUint(uint64(h.ASN), 10),
+			h.ASName,
+			h.Client,
+			network,
+			h.ForkHash,
+			strconv.FormatUint(h.ForkNext, 10),
+			h.FirstSeen.Format(time.RFC3339),
+			h.LastSeen.Format(time.RFC3339),
+			strconv.Itoa(h.Crawls),
+			h.N.String(),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,214 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/devp2p/internal/ethtest"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/forkid"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/protocols/eth"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/rlpx"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	probeTimeout = 10 * time.Second
+	probeMaxMsgs = 8 // messages read while waiting for the eth status
+
+	// RLPx message codes.
+	probeHelloMsg      = 0x00
+	probeDisconnectMsg = 0x01
+	probePingMsg       = 0x02
+	probePongMsg       = 0x03
+	probeBaseProtoLen  = 16
+)
+
+// nodeProbe is the information obtained by connecting to a node.
+type nodeProbe struct {
+	Client string // client identifier of the RLPx handshake
+
+	// These are set if the eth status handshake was performed.
+	Status    bool
+	NetworkID uint64
+	ForkID    forkid.ID
+}
+
+// probeNodes connects to the given nodes in parallel.
+func probeNodes(nodes []*enode.Node, nthreads int) map[enode.ID]*nodeProbe {
+	if nthreads < 1 {
+		nthreads = 1
+	}
+	key, _ := crypto.GenerateKey()
+	var (
+		result = make(map[enode.ID]*nodeProbe, len(nodes))
+		ch     = make(chan *enode.Node)
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+	)
+	wg.Add(nthreads)
+	for i := 0; i < nthreads; i++ {
+		go func() {
+			defer wg.Done()
+			for n := range ch {
+				probe, err := probeNode(n, key)
+				if err != nil {
+					log.Debug("Node probe failed", "id", n.ID(), "err", err)
+					continue
+				}
+				mu.Lock()
+				result[n.ID()] = probe
+				mu.Unlock()
+			}
+		}()
+	}
+	for i, n := range nodes {
+		ch <- n
+		if i > 0 && i%1000 == 0 {
+			log.Info("Probing nodes", "done", i, "total", len(nodes))
+		}
+	}
+	close(ch)
+	wg.Wait()
+	return result
+}
+
+// probeNode performs the RLPx handshake with a node and waits for its eth status.
+func probeNode(n *enode.Node, key *ecdsa.PrivateKey) (*nodeProbe, error) {
+	addr, ok := n.TCPEndpoint()
+	if !ok {
+		return nil, errors.New("node has no TCP endpoint")
+	}
+	fd, err := net.DialTimeout("tcp", addr.String(), probeTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+	fd.SetDeadline(time.Now().Add(probeTimeout))
+
+	conn := rlpx.NewConn(fd, n.Pubkey())
+	if _, err := conn.Handshake(key); err != nil {
+		return nil, err
+	}
+	hello, err := probeHello(conn, key)
+	if err != nil {
+		return nil, err
+	}
+	probe := &nodeProbe{Client: hello.Name}
+	if !hasEthCap(hello.Caps) {
+		return probe, nil
+	}
+
+	// The remote node sends its status right after the handshake. Reading it
+	// is best-effort, the client name is recorded even if it fails.
+	for i := 0; i < probeMaxMsgs; i++ {
+		code, data, _, err := conn.Read()
+		if err != nil {
+			return probe, nil
+		}
+		switch code {
+		case probeDisconnectMsg:
+			return probe, nil
+		case probePingMsg:
+			conn.Write(probePongMsg, []byte{0xC0})
+		case probeBaseProtoLen + eth.StatusMsg:
+			var status struct {
+				ProtocolVersion uint32
+				NetworkID       uint64
+				Genesis         common.Hash
+				ForkID          forkid.ID
+				Rest            []rlp.RawValue `rlp:"tail"`
+			}
+			if err := rlp.DecodeBytes(data, &status); err != nil {
+				return probe, nil
+			}
+			probe.Status = true
+			probe.NetworkID = status.NetworkID
+			probe.ForkID = status.ForkID
+
+			reason, _ := rlp.EncodeToBytes([]p2p.DiscReason{p2p.DiscQuitting})
+			conn.Write(probeDisconnectMsg, reason)
+			return probe, nil
+		}
+	}
+	return probe, nil
+}
+
+// probeHello exchanges the RLPx protocol handshake, offering all eth versions.
+func probeHello(conn *rlpx.Conn, key *ecdsa.PrivateKey) (*ethtest.Hello, error) {
+	ours := &ethtest.Hello{
+		Version: 5,
+		Name:    "devp2p-crawler",
+		ID:      crypto.FromECDSAPub(&key.PublicKey)[1:],
+	}
+	for _, v := range eth.ProtocolVersions {
+		ours.Caps = append(ours.Caps, p2p.Cap{Name: eth.ProtocolName, Version: v})
+	}
+	enc, err := rlp.EncodeToBytes(ours)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.Write(probeHelloMsg, enc); err != nil {
+		return nil, err
+	}
+	code, data, _, err := conn.Read()
+	if err != nil {
+		return nil, err
+	}
+	switch code {
+	case probeHelloMsg:
+		var h ethtest.Hello
+		if err := rlp.DecodeBytes(data, &h); err != nil {
+			return nil, fmt.Errorf("invalid handshake: %v", err)
+		}
+		if h.Version >= 5 {
+			conn.SetSnappy(true)
+		}
+		return &h, nil
+	case probeDisconnectMsg:
+		reason, err := decodeRLPxDisconnect(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid disconnect message: %v", err)
+		}
+		return nil, fmt.Errorf("received disconnect message: %v", reason)
+	default:
+		return nil, fmt.Errorf("invalid message code %d", code)
+	}
+}
+
+func hasEthCap(caps []p2p.Cap) bool {
+	for _, c := range caps {
+		if c.Name == eth.ProtocolName {
+			for _, v := range eth.ProtocolVersions {
+				if c.Version == v {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
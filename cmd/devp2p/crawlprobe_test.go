@@ -0,0 +1,94 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/forkid"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/protocols/eth"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// startProbeServer runs a p2p server with the given protocols on localhost.
+func startProbeServer(t *testing.T, protocols []p2p.Protocol) *enode.Node {
+	t.Helper()
+	key, _ := crypto.GenerateKey()
+	srv := &p2p.Server{Config: p2p.Config{
+		PrivateKey:  key,
+		Name:        "test/v1.0.0",
+		MaxPeers:    10,
+		ListenAddr:  "127.0.0.1:0",
+		NoDiscovery: true,
+		Protocols:   protocols,
+	}}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Stop)
+	return srv.Self()
+}
+
+func TestProbeNode(t *testing.T) {
+	status := &eth.StatusPacket{
+		ProtocolVersion: eth.ETH69,
+		NetworkID:       1337,
+		ForkID:          forkid.ID{Hash: [4]byte{1, 2, 3, 4}, Next: 99},
+		LatestBlockHash: common.Hash{1},
+	}
+	node := startProbeServer(t, []p2p.Protocol{{
+		Name:    eth.ProtocolName,
+		Version: eth.ETH69,
+		Length:  18,
+		Run: func(p *p2p.Peer, rw p2p.MsgReadWriter) error {
+			if err := p2p.Send(rw, eth.StatusMsg, status); err != nil {
+				return err
+			}
+			_, err := rw.ReadMsg()
+			return err
+		},
+	}})
+	key, _ := crypto.GenerateKey()
+	probe, err := probeNode(node, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &nodeProbe{Client: "test/v1.0.0", Status: true, NetworkID: 1337, ForkID: status.ForkID}
+	if *probe != *want {
+		t.Fatalf("wrong probe result %+v, want %+v", probe, want)
+	}
+}
+
+// Nodes without the eth protocol are probed for their client only.
+func TestProbeNodeNoEth(t *testing.T) {
+	node := startProbeServer(t, []p2p.Protocol{{
+		Name:    "other",
+		Version: 1,
+		Length:  1,
+		Run: func(p *p2p.Peer, rw p2p.MsgReadWriter) error {
+			_, err := rw.ReadMsg()
+			return err
+		},
+	}})
+	probes := probeNodes([]*enode.Node{node}, 2)
+	if probe := probes[node.ID()]; probe == nil || probe.Client != "test/v1.0.0" || probe.Status {
+		t.Fatalf("wrong probe result %+v", probe)
+	}
+}
@@ -0,0 +1,296 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"cmp"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/forkid"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// crawlReport summarizes the state of the network in the crawl history.
+// All counts except churn refer to the nodes alive in the latest crawl.
+type crawlReport struct {
+	Time     time.Time     `json:"time"`
+	Nodes    int           `json:"nodes"`
+	Clients  []reportEntry `json:"clients"`
+	Versions []reportEntry `json:"clientVersions"`
+	Networks []reportEntry `json:"networks"`
+	ASNs     []reportEntry `json:"asns"`
+	Forks    *forkReport   `json:"forks,omitempty"`
+	Churn    []churnEntry  `json:"churn"`
+}
+
+type reportEntry struct {
+	Key   string  `json:"key"`
+	Count int     `json:"count"`
+	Share float64 `json:"share"`
+}
+
+// forkReport is the readiness of nodes for the next fork of a network.
+type forkReport struct {
+	Network      string `json:"network"`
+	ForkID       string `json:"forkID"`       // fork ID nodes should announce
+	Ready        int    `json:"ready"`        // announcing the next fork, or passed all forks
+	NotReady     int    `json:"notReady"`     // on the current fork, but not scheduling the next one
+	Stale        int    `json:"stale"`        // on an earlier fork of the network
+	Incompatible int    `json:"incompatible"` // on other networks, or announcing a conflicting fork
+	Unknown      int    `json:"unknown"`      // fork ID not known
+}
+
+// churnEntry compares a crawl with the previous one.
+type churnEntry struct {
+	Time   time.Time `json:"time"`
+	Nodes  int       `json:"nodes"`
+	Joined int       `json:"joined"`
+	Left   int       `json:"left"`
+}
+
+// forkChecker classifies fork IDs of nodes for the next fork of a network, using
+// the fork schedule of the forkcheck command.
+type forkChecker struct {
+	network  string
+	want     forkid.ID
+	schedule *forkSchedule
+	next     int // step of the next fork in schedule
+}
+
+// newForkChecker creates a checker for the given network at time now.
+func newForkChecker(network string, now time.Time) (*forkChecker, error) {
+	config, genesis, err := networkGenesis(network)
+	if err != nil {
+		return nil, err
+	}
+	schedule := newForkSchedule(config, genesis)
+	return &forkChecker{
+		network:  network,
+		want:     forkid.NewID(config, genesis, math.MaxUint64, uint64(now.Unix())),
+		schedule: schedule,
+		next:     schedule.next(now),
+	}, nil
+}
+
+func (fc *forkChecker) count(r *forkReport, h *nodeHistory) {
+	id, ok := h.forkID()
+	if !ok {
+		r.Unknown++
+		return
+	}
+	switch fc.schedule.classify(id, fc.next) {
+	case forkReady:
+		r.Ready++
+	case forkNotReady:
+		r.NotReady++
+	case forkStale:
+		r.Stale++
+	case forkIncompatible:
+		r.Incompatible++
+	}
+}
+
+// newCrawlReport computes a report from the node histories and crawl records.
+// The fork checker is optional.
+func newCrawlReport(nodes []*nodeHistory, crawls []crawlRecord, fc *forkChecker) *crawlReport {
+	report := new(crawlReport)
+	for i := 1; i < len(crawls); i++ {
+		report.Churn = append(report.Churn, newChurnEntry(crawls[i-1], crawls[i]))
+	}
+	if len(crawls) == 0 {
+		return report
+	}
+	latest := crawls[len(crawls)-1]
+	report.Time = latest.Time
+	report.Nodes = len(latest.Nodes)
+
+	alive := make(map[enode.ID]bool, len(latest.Nodes))
+	for _, id := range latest.Nodes {
+		alive[id] = true
+	}
+	var (
+		clients  = make(map[string]int)
+		versions = make(map[string]int)
+		networks = make(map[string]int)
+		asns     = make(map[string]int)
+	)
+	if fc != nil {
+		report.Forks = &forkReport{
+			Network: fc.network,
+			ForkID:  fmt.Sprintf("%x/%d", fc.want.Hash, fc.want.Next),
+		}
+	}
+	for _, h := range nodes {
+		if !alive[h.ID] {
+			continue
+		}
+		name, version := parseClient(h.Client)
+		clients[name]++
+		versions[version]++
+		if h.NetworkID != 0 {
+			networks[strconv.FormatUint(h.NetworkID, 10)]++
+		} else {
+			networks["unknown"]++
+		}
+		if h.ASN != 0 {
+			asns[fmt.Sprintf("AS%d %s", h.ASN, h.ASName)]++
+		} else {
+			asns["unknown"]++
+		}
+		if fc != nil {
+			fc.count(report.Forks, h)
+		}
+	}
+	report.Clients = reportEntries(clients, report.Nodes)
+	report.Versions = reportEntries(versions, report.Nodes)
+	report.Networks = reportEntries(networks, report.Nodes)
+	report.ASNs = reportEntries(asns, report.Nodes)
+	return report
+}
+
+// parseClient returns the lowercase client name and name/version of a
+// client identifier like "Geth/v1.14.0-stable-1234abcd/linux-amd64/go1.22.0".
+func parseClient(client string) (name, version string) {
+	parts := strings.Split(client, "/")
+	name = strings.ToLower(parts[0])
+	if name == "" {
+		return "unknown", "unknown"
+	}
+	if len(parts) < 2 {
+		return name, name
+	}
+	return name, name + "/" + parts[1]
+}
+
+func reportEntries(counts map[string]int, total int) []reportEntry {
+	entries := make([]reportEntry, 0, len(counts))
+	for key, n := range counts {
+		entries = append(entries, reportEntry{Key: key, Count: n, Share: float64(n) / float64(total)})
+	}
+	slices.SortFunc(entries, func(a, b reportEntry) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Key, b.Key)
+	})
+	return entries
+}
+
+func newChurnEntry(prev, cur crawlRecord) churnEntry {
+	before := make(map[enode.ID]bool, len(prev.Nodes))
+	for _, id := range prev.Nodes {
+		before[id] = true
+	}
+	e := churnEntry{Time: cur.Time, Nodes: len(cur.Nodes)}
+	for _, id := range cur.Nodes {
+		if before[id] {
+			delete(before, id)
+		} else {
+			e.Joined++
+		}
+	}
+	e.Left = len(before)
+	return e
+}
+
+// writeText writes the report in human-readable form. Distributions are limited
+// to the top entries.
+func (r *crawlReport) writeText(w io.Writer, top int) {
+	fmt.Fprintf(w, "Latest crawl: %v, %d nodes\n", r.Time.Format(time.RFC3339), r.Nodes)
+	section := func(title string, entries []reportEntry) {
+		fmt.Fprintf(w, "\n%s:\n", title)
+		for i, e := range entries {
+			if i == top {
+				fmt.Fprintf(w, "  ... %d more\n", len(entries)-top)
+				break
+			}
+			fmt.Fprintf(w, "  %-40s %6d %6.2f%%\n", e.Key, e.Count, e.Share*100)
+		}
+	}
+	section("Clients", r.Clients)
+	section("Client versions", r.Versions)
+	section("Networks", r.Networks)
+	section("Autonomous systems", r.ASNs)
+	if f := r.Forks; f != nil {
+		fmt.Fprintf(w, "\nFork readiness (%s, expected fork ID %s):\n", f.Network, f.ForkID)
+		fmt.Fprintf(w, "  ready:        %d\n", f.Ready)
+		fmt.Fprintf(w, "  not ready:    %d\n", f.NotReady)
+		fmt.Fprintf(w, "  stale:        %d\n", f.Stale)
+		fmt.Fprintf(w, "  incompatible: %d\n", f.Incompatible)
+		fmt.Fprintf(w, "  unknown:      %d\n", f.Unknown)
+	}
+	if len(r.Churn) > 0 {
+		fmt.Fprintf(w, "\nChurn:\n")
+		churn := r.Churn
+		if len(churn) > top {
+			churn = churn[len(churn)-top:]
+		}
+		for _, c := range churn {
+			fmt.Fprintf(w, "  %s  nodes %6d  joined %6d  left %6d\n", c.Time.Format(time.RFC3339), c.Nodes, c.Joined, c.Left)
+		}
+	}
+}
+
+// writeCSV writes the report as CSV with one metric per row.
+func (r *crawlReport) writeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"section", "key", "count", "share"})
+	row := func(section, key string, count int, share float64) {
+		cw.Write([]string{section, key, strconv.Itoa(count), strconv.FormatFloat(share, 'f', 4, 64)})
+	}
+	share := func(n int) float64 {
+		if r.Nodes == 0 {
+			return 0
+		}
+		return float64(n) / float64(r.Nodes)
+	}
+	row("nodes", r.Time.Format(time.RFC3339), r.Nodes, 1)
+	for _, s := range []struct {
+		name    string
+		entries []reportEntry
+	}{
+		{"client", r.Clients},
+		{"version", r.Versions},
+		{"network", r.Networks},
+		{"asn", r.ASNs},
+	} {
+		for _, e := range s.entries {
+			row(s.name, e.Key, e.Count, e.Share)
+		}
+	}
+	if f := r.Forks; f != nil {
+		row("fork", "ready", f.Ready, share(f.Ready))
+		row("fork", "notready", f.NotReady, share(f.NotReady))
+		row("fork", "stale", f.Stale, share(f.Stale))
+		row("fork", "incompatible", f.Incompatible, share(f.Incompatible))
+		row("fork", "unknown", f.Unknown, share(f.Unknown))
+	}
+	for _, c := range r.Churn {
+		t := c.Time.Format(time.RFC3339)
+		row("joined", t, c.Joined, float64(c.Joined)/float64(max(c.Nodes, 1)))
+		row("left", t, c.Left, float64(c.Left)/float64(max(c.Nodes, 1)))
+	}
+	cw.Flush()
+	return cw.Error()
+}
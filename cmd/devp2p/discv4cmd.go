@@ -91,7 +91,13 @@ var (
 		Name:   "crawl",
 		Usage:  "Updates a nodes.json file with random nodes found in the DHT",
 		Action: discv4Crawl,
-		Flags:  slices.Concat(discoveryNodeFlags, []cli.Flag{crawlTimeoutFlag, crawlParallelismFlag}),
+		Flags: slices.Concat(discoveryNodeFlags, []cli.Flag{
+			crawlTimeoutFlag,
+			crawlParallelismFlag,
+			crawlDBFlag,
+			crawlProbeFlag,
+			asnDBFlag,
+		}),
 	}
 	discv4TestCommand = &cli.Command{
 		Name:   "test",
@@ -266,7 +272,7 @@ func discv4Crawl(ctx *cli.Context) error {
 	c.revalidateInterval = 10 * time.Minute
 	output := c.run(ctx.Duration(crawlTimeoutFlag.Name), ctx.Int(crawlParallelismFlag.Name))
 	writeNodesJSON(nodesFile, output)
-	return nil
+	return writeCrawlDB(ctx, output)
 }
 
 // discv4Test runs the protocol test suite.
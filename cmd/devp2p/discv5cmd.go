@@ -58,6 +58,9 @@ var (
 		Action: discv5Crawl,
 		Flags: slices.Concat(discoveryNodeFlags, []cli.Flag{
 			crawlTimeoutFlag,
+			crawlDBFlag,
+			crawlProbeFlag,
+			asnDBFlag,
 		}),
 	}
 	discv5TestCommand = &cli.Command{
@@ -118,7 +121,7 @@ func discv5Crawl(ctx *cli.Context) error {
 	c.revalidateInterval = 10 * time.Minute
 	output := c.run(ctx.Duration(crawlTimeoutFlag.Name), ctx.Int(crawlParallelismFlag.Name))
 	writeNodesJSON(nodesFile, output)
-	return nil
+	return writeCrawlDB(ctx, output)
 }
 
 // discv5Test runs the protocol test suite.
@@ -0,0 +1,338 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"math/big"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/forkid"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	forkcheckCommand = &cli.Command{
+		Name:      "forkcheck",
+		Usage:     "Checks which nodes of a node set are ready for the forks of a network",
+		ArgsUsage: "<nodes.json>",
+		Action:    forkcheck,
+		Flags: slices.Concat(discoveryNodeFlags, []cli.Flag{
+			forkcheckNetworkFlag,
+			forkcheckGenesisFlag,
+			forkcheckCrawlFlag,
+			forkcheckFormatFlag,
+			crawlTimeoutFlag,
+			crawlParallelismFlag,
+		}),
+	}
+	forkcheckNetworkFlag = &cli.StringFlag{
+		Name:  "network",
+		Usage: "Network to check (mainnet, sepolia, holesky, hoodi)",
+		Value: "mainnet",
+	}
+	forkcheckGenesisFlag = &cli.StringFlag{
+		Name:  "genesis",
+		Usage: "Genesis JSON file of the network to check, overrides -network",
+	}
+	forkcheckCrawlFlag = &cli.BoolFlag{
+		Name:  "crawl",
+		Usage: "Updates the node set with a discovery v4 crawl before checking",
+	}
+	forkcheckFormatFlag = &cli.StringFlag{
+		Name:  "format",
+		Usage: "Output format (text, json, csv)",
+		Value: "text",
+	}
+)
+
+// forkcheckResult is the fork readiness of a node set.
+type forkcheckResult struct {
+	Network  string          `json:"network"`
+	Nodes    int             `json:"nodes"`    // nodes with fork ID
+	NoForkID int             `json:"noForkID"` // nodes without "eth" ENR entry
+	Forks    []forkReadiness `json:"forks"`
+}
+
+// forkReadiness counts the nodes of each forkStatus for a fork.
+type forkReadiness struct {
+	Name         string `json:"name"`
+	Block        uint64 `json:"block,omitempty"`
+	Time         uint64 `json:"time,omitempty"`
+	Upcoming     bool   `json:"upcoming"`
+	Ready        int    `json:"ready"`
+	NotReady     int    `json:"notReady"`
+	Stale        int    `json:"stale"`
+	Incompatible int    `json:"incompatible"`
+}
+
+// forkStatus is the state of a node with respect to a fork of the schedule.
+type forkStatus int
+
+const (
+	forkReady        forkStatus = iota // passed the fork, or announcing it as the next fork
+	forkNotReady                       // on the fork before, but not announcing the next one
+	forkStale                          // on an earlier fork of the network
+	forkIncompatible                   // on another network, or announcing a conflicting next fork
+)
+
+// forkSchedule is the list of fork transitions changing the fork ID of a chain.
+type forkSchedule struct {
+	steps []forkStep
+	state map[[4]byte]int // fork hash -> number of passed steps
+}
+
+type forkStep struct {
+	names []string // forks activating in this step
+	block uint64   // activation block, for block-based forks
+	time  uint64   // activation timestamp, for time-based forks
+}
+
+func (s forkStep) activation() uint64 {
+	if s.time != 0 {
+		return s.time
+	}
+	return s.block
+}
+
+// newForkSchedule gathers the forks of a chain config. Like core/forkid, it takes
+// all fields ending in Block or Time as forks and skips forks at genesis.
+func newForkSchedule(config *params.ChainConfig, genesis *types.Block) *forkSchedule {
+	var (
+		byBlock = make(map[uint64][]string)
+		byTime  = make(map[uint64][]string)
+		kind    = reflect.TypeFor[params.ChainConfig]()
+		conf    = reflect.ValueOf(config).Elem()
+	)
+	for i := 0; i < kind.NumField(); i++ {
+		field := kind.Field(i)
+		name, isTime := strings.CutSuffix(field.Name, "Time")
+		if !isTime {
+			var isBlock bool
+			if name, isBlock = strings.CutSuffix(field.Name, "Block"); !isBlock {
+				continue
+			}
+		}
+		switch rule := conf.Field(i).Interface().(type) {
+		case *uint64:
+			if rule != nil && *rule > genesis.Time() {
+				byTime[*rule] = append(byTime[*rule], name)
+			}
+		case *big.Int:
+			if rule != nil && rule.Sign() > 0 {
+				byBlock[rule.Uint64()] = append(byBlock[rule.Uint64()], name)
+			}
+		}
+	}
+
+	s := &forkSchedule{state: make(map[[4]byte]int)}
+	s.state[forkid.NewID(config, genesis, 0, 0).Hash] = 0
+	for _, block := range slices.Sorted(maps.Keys(byBlock)) {
+		s.steps = append(s.steps, forkStep{names: byBlock[block], block: block})
+		s.state[forkid.NewID(config, genesis, block, 0).Hash] = len(s.steps)
+	}
+	for _, time := range slices.Sorted(maps.Keys(byTime)) {
+		s.steps = append(s.steps, forkStep{names: byTime[time], time: time})
+		s.state[forkid.NewID(config, genesis, math.MaxUint64, time).Hash] = len(s.steps)
+	}
+	return s
+}
+
+// next returns the index of the first fork activating after now. Block-based forks
+// are assumed to have passed. It returns len(s.steps) if all forks have passed.
+func (s *forkSchedule) next(now time.Time) int {
+	i := slices.IndexFunc(s.steps, func(step forkStep) bool { return step.time > uint64(now.Unix()) })
+	if i < 0 {
+		return len(s.steps)
+	}
+	return i
+}
+
+// classify returns the status of a node with the given fork ID for step i of the
+// schedule. Step len(s.steps) stands for the latest fork, which nodes are ready
+// for once they have passed all steps.
+func (s *forkSchedule) classify(id forkid.ID, i int) forkStatus {
+	passed, known := s.state[id.Hash]
+	switch {
+	case !known:
+		return forkIncompatible
+	case passed > i || passed == len(s.steps):
+		return forkReady
+	case id.Next != 0 && id.Next != s.steps[passed].activation():
+		return forkIncompatible
+	case passed == i && id.Next != 0:
+		return forkReady
+	case passed == i:
+		return forkNotReady
+	default:
+		return forkStale
+	}
+}
+
+// check counts the status of a node for all steps of the schedule.
+func (s *forkSchedule) check(id forkid.ID, result []forkReadiness) {
+	for i := range s.steps {
+		result[i].count(s.classify(id, i))
+	}
+}
+
+func (f *forkReadiness) count(status forkStatus) {
+	switch status {
+	case forkReady:
+		f.Ready++
+	case forkNotReady:
+		f.NotReady++
+	case forkStale:
+		f.Stale++
+	case forkIncompatible:
+		f.Incompatible++
+	}
+}
+
+// checkForks computes the fork readiness of the nodes.
+func checkForks(s *forkSchedule, ns nodeSet, now time.Time) *forkcheckResult {
+	result := &forkcheckResult{Forks: make([]forkReadiness, len(s.steps))}
+	for i, step := range s.steps {
+		result.Forks[i] = forkReadiness{
+			Name:     strings.Join(step.names, "+"),
+			Block:    step.block,
+			Time:     step.time,
+			Upcoming: step.time > uint64(now.Unix()),
+		}
+	}
+	for _, n := range ns {
+		id, ok := enrForkID(n.N)
+		if !ok {
+			result.NoForkID++
+			continue
+		}
+		result.Nodes++
+		s.check(id, result.Forks)
+	}
+	return result
+}
+
+func forkcheck(ctx *cli.Context) error {
+	if ctx.NArg() < 1 {
+		return errors.New("need nodes file as argument")
+	}
+	config, genesis, err := forkcheckGenesis(ctx)
+	if err != nil {
+		return err
+	}
+	network := ctx.String(forkcheckNetworkFlag.Name)
+	if ctx.IsSet(forkcheckGenesisFlag.Name) {
+		network = ctx.String(forkcheckGenesisFlag.Name)
+	}
+	if ctx.Bool(forkcheckCrawlFlag.Name) {
+		if err := discv4Crawl(ctx); err != nil {
+			return err
+		}
+	}
+	ns := loadNodesJSON(ctx.Args().First())
+
+	result := checkForks(newForkSchedule(config, genesis), ns, time.Now())
+	result.Network = network
+	switch format := ctx.String(forkcheckFormatFlag.Name); format {
+	case "text":
+		result.writeText(os.Stdout)
+		return nil
+	case "json":
+		return writeJSON(os.Stdout, result)
+	case "csv":
+		return result.writeCSV(os.Stdout)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// forkcheckGenesis returns the chain config and genesis block selected by flags.
+func forkcheckGenesis(ctx *cli.Context) (*params.ChainConfig, *types.Block, error) {
+	file := ctx.String(forkcheckGenesisFlag.Name)
+	if file == "" {
+		return networkGenesis(ctx.String(forkcheckNetworkFlag.Name))
+	}
+	var genesis core.Genesis
+	if err := common.LoadJSON(file, &genesis); err != nil {
+		return nil, nil, err
+	}
+	if genesis.Config == nil {
+		return nil, nil, fmt.Errorf("%s: genesis has no chain config", file)
+	}
+	return genesis.Config, genesis.ToBlock(), nil
+}
+
+func (r *forkcheckResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "Fork readiness of %s: %d nodes with fork ID, %d without\n\n", r.Network, r.Nodes, r.NoForkID)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FORK\tACTIVATION\tREADY\tNOT READY\tSTALE\tINCOMPATIBLE\tREADY%")
+	for _, f := range r.Forks {
+		activation := fmt.Sprintf("block %d", f.Block)
+		if f.Time != 0 {
+			activation = time.Unix(int64(f.Time), 0).UTC().Format(time.DateTime)
+		}
+		name := f.Name
+		if f.Upcoming {
+			name += " (upcoming)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%.2f\n", name, activation, f.Ready, f.NotReady, f.Stale, f.Incompatible, f.readyShare()*100)
+	}
+	tw.Flush()
+}
+
+func (r *forkcheckResult) writeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"fork", "block", "time", "upcoming", "ready", "notready", "stale", "incompatible"})
+	for _, f := range r.Forks {
+		cw.Write([]string{
+			f.Name,
+			strconv.FormatUint(f.Block, 10),
+			strconv.FormatUint(f.Time, 10),
+			strconv.FormatBool(f.Upcoming),
+			strconv.Itoa(f.Ready),
+			strconv.Itoa(f.NotReady),
+			strconv.Itoa(f.Stale),
+			strconv.Itoa(f.Incompatible),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// readyShare returns the fraction of ready nodes among the nodes on the network.
+func (f *forkReadiness) readyShare() float64 {
+	total := f.Ready + f.NotReady + f.Stale
+	if total == 0 {
+		return 0
+	}
+	return float64(f.Ready) / float64(total)
+}
@@ -0,0 +1,127 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/forkid"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestForkCheck(t *testing.T) {
+	u64 := func(v uint64) *uint64 { return &v }
+	config := &params.ChainConfig{
+		ChainID:        big.NewInt(1337),
+		HomesteadBlock: big.NewInt(0),
+		EIP150Block:    big.NewInt(0),
+		EIP155Block:    big.NewInt(0),
+		EIP158Block:    big.NewInt(0),
+		ByzantiumBlock: big.NewInt(10),
+		LondonBlock:    big.NewInt(20),
+		BerlinBlock:    big.NewInt(20),
+		ShanghaiTime:   u64(1000),
+		CancunTime:     u64(2000),
+	}
+	genesis := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(0), Time: 1})
+	s := newForkSchedule(config, genesis)
+
+	wantSteps := []forkStep{
+		{names: []string{"Byzantium"}, block: 10},
+		{names: []string{"Berlin", "London"}, block: 20},
+		{names: []string{"Shanghai"}, time: 1000},
+		{names: []string{"Cancun"}, time: 2000},
+	}
+	if !reflect.DeepEqual(s.steps, wantSteps) {
+		t.Fatalf("wrong fork schedule:\nhave %+v\nwant %+v", s.steps, wantSteps)
+	}
+
+	var (
+		london   = forkid.NewID(config, genesis, 20, 0)                // passed London, announcing Shanghai
+		shanghai = forkid.NewID(config, genesis, math.MaxUint64, 1000) // passed Shanghai, announcing Cancun
+		cancun   = forkid.NewID(config, genesis, math.MaxUint64, 2000) // passed Cancun
+	)
+	tests := []struct {
+		id   forkid.ID
+		want []string // readiness for Byzantium, London, Shanghai, Cancun
+	}{
+		{cancun, []string{"ready", "ready", "ready", "ready"}},
+		{shanghai, []string{"ready", "ready", "ready", "ready"}},
+		{forkid.ID{Hash: shanghai.Hash}, []string{"ready", "ready", "ready", "not ready"}},
+		{forkid.ID{Hash: shanghai.Hash, Next: 3000}, []string{"ready", "ready", "ready", "incompatible"}},
+		{london, []string{"ready", "ready", "ready", "stale"}},
+		{forkid.ID{Hash: london.Hash}, []string{"ready", "ready", "not ready", "stale"}},
+		{forkid.NewID(config, genesis, 0, 0), []string{"ready", "stale", "stale", "stale"}},
+		{forkid.ID{Hash: [4]byte{1, 2, 3, 4}}, []string{"incompatible", "incompatible", "incompatible", "incompatible"}},
+	}
+	for i, test := range tests {
+		result := make([]forkReadiness, len(s.steps))
+		s.check(test.id, result)
+		for j, r := range result {
+			var have string
+			switch {
+			case r.Ready == 1:
+				have = "ready"
+			case r.NotReady == 1:
+				have = "not ready"
+			case r.Stale == 1:
+				have = "stale"
+			case r.Incompatible == 1:
+				have = "incompatible"
+			}
+			if have != test.want[j] {
+				t.Errorf("test %d (%v): fork %d is %s, want %s", i, test.id, j, have, test.want[j])
+			}
+		}
+	}
+
+	// Check the summary of a node set.
+	ns := nodeSet{}
+	for _, fork := range []*forkid.ID{&cancun, &shanghai, &london, nil} {
+		n := testCrawlNode(t, "10.0.0.1", nil, fork)
+		ns[n.ID()] = nodeJSON{N: n}
+	}
+	result := checkForks(s, ns, time.Unix(1500, 0))
+	if result.Nodes != 3 || result.NoForkID != 1 {
+		t.Fatalf("wrong node counts: %d with fork ID, %d without", result.Nodes, result.NoForkID)
+	}
+	want := forkReadiness{Name: "Cancun", Time: 2000, Upcoming: true, Ready: 2, Stale: 1}
+	if result.Forks[3] != want {
+		t.Errorf("wrong readiness for Cancun: %+v", result.Forks[3])
+	}
+	if result.Forks[2].Upcoming {
+		t.Error("Shanghai reported as upcoming")
+	}
+	if next := s.next(time.Unix(1500, 0)); next != 3 {
+		t.Errorf("wrong next fork %d, want 3", next)
+	}
+	if next := s.next(time.Unix(2000, 0)); next != len(s.steps) {
+		t.Errorf("wrong next fork %d after all forks, want %d", next, len(s.steps))
+	}
+	// After all forks, nodes on the latest fork are ready.
+	if status := s.classify(cancun, len(s.steps)); status != forkReady {
+		t.Errorf("wrong status %d for latest fork", status)
+	}
+	if status := s.classify(shanghai, len(s.steps)); status != forkStale {
+		t.Errorf("wrong status %d for earlier fork", status)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/internal/utesting"
+	"github.com/ethereum/go-ethereum/p2p/discover"
 	"github.com/ethereum/go-ethereum/p2p/discover/v5wire"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/p2p/netutil"
@@ -56,6 +57,8 @@ func (s *Suite) AllTests() []utesting.Test {
 		{Name: "TalkRequest", Fn: s.TestTalkRequest},
 		{Name: "FindnodeZeroDistance", Fn: s.TestFindnodeZeroDistance},
 		{Name: "FindnodeResults", Fn: s.TestFindnodeResults},
+		{Name: "TopicRegistration", Fn: s.TestTopicRegistration},
+		{Name: "TopicInvalidTicket", Fn: s.TestTopicInvalidTicket},
 	}
 }
 
@@ -340,6 +343,107 @@ that they are returned by FINDNODE.`)
 	t.Logf("this can happen if the node has a non-empty table from previous runs")
 }
 
+func (s *Suite) TestTopicRegistration(t *utesting.T) {
+	t.Log(`This test requests a ticket for a topic and uses it to register with REGTOPIC.
+It then checks that the registered node is returned by TOPICQUERY from another node.`)
+
+	topic := discover.NewTopic("v5test-" + time.Now().String())
+	conn, l1 := s.listen1(t)
+	defer conn.close()
+	conn.setEndpoint(l1)
+
+	ticket := requestTicket(t, conn, l1, topic)
+	if ticket.WaitTime > 0 {
+		t.Logf("waiting %ds before registration", ticket.WaitTime)
+		time.Sleep(time.Duration(ticket.WaitTime) * time.Second)
+	}
+	reg := &v5wire.Regtopic{
+		ReqID:  conn.nextReqID(),
+		Topic:  topic,
+		ENR:    conn.localNode.Node().Record(),
+		Ticket: ticket.Ticket,
+	}
+	switch resp := conn.reqresp(l1, reg).(type) {
+	case *v5wire.Regconfirmation:
+		if !bytes.Equal(resp.ReqID, reg.ReqID) {
+			t.Fatalf("wrong request ID %x in REGCONFIRMATION, want %x", resp.ReqID, reg.ReqID)
+		}
+		if !resp.Registered {
+			t.Fatal("registration with valid ticket rejected")
+		}
+	default:
+		t.Fatal("expected REGCONFIRMATION, got", resp.Name())
+	}
+
+	// Query the topic from another node.
+	query, l2 := s.listen1(t)
+	defer query.close()
+	nodes, err := query.topicQuery(l2, topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range nodes {
+		if n.ID() == conn.localNode.ID() {
+			return
+		}
+	}
+	t.Fatalf("registered node not returned by TOPICQUERY (got %d nodes)", len(nodes))
+}
+
+func (s *Suite) TestTopicInvalidTicket(t *utesting.T) {
+	t.Log(`This test sends REGTOPIC with a forged ticket and a ticket issued for another topic.
+The remote node should reject both registrations.`)
+
+	topic := discover.NewTopic("v5test-" + time.Now().String())
+	conn, l1 := s.listen1(t)
+	defer conn.close()
+	conn.setEndpoint(l1)
+
+	ticket := requestTicket(t, conn, l1, discover.NewTopic("v5test-other"))
+	tests := []struct {
+		name   string
+		ticket []byte
+	}{
+		{"forged ticket", []byte("invalid ticket")},
+		{"ticket for other topic", ticket.Ticket},
+	}
+	for _, test := range tests {
+		t.Log("sending REGTOPIC with", test.name)
+		reg := &v5wire.Regtopic{
+			ReqID:  conn.nextReqID(),
+			Topic:  topic,
+			ENR:    conn.localNode.Node().Record(),
+			Ticket: test.ticket,
+		}
+		switch resp := conn.reqresp(l1, reg).(type) {
+		case *v5wire.Regconfirmation:
+			if resp.Registered {
+				t.Fatalf("registration with %s accepted", test.name)
+			}
+		default:
+			t.Fatal("expected REGCONFIRMATION, got", resp.Name())
+		}
+	}
+}
+
+// requestTicket sends REQUESTTICKET for the topic and returns the TICKET response.
+func requestTicket(t *utesting.T, conn *conn, l net.PacketConn, topic discover.Topic) *v5wire.Ticket {
+	req := &v5wire.RequestTicket{ReqID: conn.nextReqID(), Topic: topic}
+	switch resp := conn.reqresp(l, req).(type) {
+	case *v5wire.Ticket:
+		if !bytes.Equal(resp.ReqID, req.ReqID) {
+			t.Fatalf("wrong request ID %x in TICKET, want %x", resp.ReqID, req.ReqID)
+		}
+		if len(resp.Ticket) == 0 {
+			t.Fatal("empty ticket in TICKET")
+		}
+		return resp
+	default:
+		t.Fatal("expected TICKET, got", resp.Name())
+		return nil
+	}
+}
+
 // A bystander is a node whose only purpose is filling a spot in the remote table.
 type bystander struct {
 	dest  *enode.Node
@@ -0,0 +1,67 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package v5test
+
+import (
+	"net"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/internal/utesting"
+	"github.com/ethereum/go-ethereum/p2p/discover"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+)
+
+// startNode runs a discv5 node on localhost to test against.
+func startNode(t *testing.T) *discover.UDPv5 {
+	key, _ := crypto.GenerateKey()
+	db, _ := enode.OpenDB("")
+	t.Cleanup(db.Close)
+	ln := enode.NewLocalNode(db, key)
+
+	socket, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IP{127, 0, 0, 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := socket.LocalAddr().(*net.UDPAddr)
+	ln.SetStaticIP(addr.IP)
+	ln.Set(enr.UDP(addr.Port))
+	disc, err := discover.ListenV5(socket, ln, discover.Config{PrivateKey: key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(disc.Close)
+	return disc
+}
+
+func TestDiscv5Suite(t *testing.T) {
+	disc := startNode(t)
+	suite := &Suite{Dest: disc.Self(), Listen1: "127.0.0.1"}
+
+	// Tests requiring a second listening IP or remote revalidation are skipped.
+	tests := utesting.MatchTests(suite.AllTests(), "^(Ping|PingLargeRequestID|TalkRequest|FindnodeZeroDistance|TopicRegistration|TopicInvalidTicket)$")
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			result := utesting.RunTests([]utesting.Test{test}, os.Stdout)
+			if result[0].Failed {
+				t.Fatal()
+			}
+		})
+	}
+}
@@ -144,9 +144,18 @@ func (tc *conn) reqresp(c net.PacketConn, req v5wire.Packet) v5wire.Packet {
 
 // findnode sends a FINDNODE request and waits for its responses.
 func (tc *conn) findnode(c net.PacketConn, dists []uint) ([]*enode.Node, error) {
+	return tc.requestNodes(c, &v5wire.Findnode{ReqID: tc.nextReqID(), Distances: dists})
+}
+
+// topicQuery sends a TOPICQUERY request and waits for its responses.
+func (tc *conn) topicQuery(c net.PacketConn, topic [32]byte) ([]*enode.Node, error) {
+	return tc.requestNodes(c, &v5wire.TopicQuery{ReqID: tc.nextReqID(), Topic: topic})
+}
+
+// requestNodes sends a request answered by NODES and waits for its responses.
+func (tc *conn) requestNodes(c net.PacketConn, req v5wire.Packet) ([]*enode.Node, error) {
 	var (
-		findnode = &v5wire.Findnode{ReqID: tc.nextReqID(), Distances: dists}
-		reqnonce = tc.write(c, findnode, nil)
+		reqnonce = tc.write(c, req, nil)
 		first    = true
 		total    uint8
 		results  []*enode.Node
@@ -158,7 +167,7 @@ func (tc *conn) findnode(c net.PacketConn, dists []uint) ([]*enode.Node, error)
 			// Handle handshake.
 			if resp.Nonce == reqnonce {
 				resp.Node = tc.remote
-				tc.writeTo(c, findnode, resp, from)
+				tc.writeTo(c, req, resp, from)
 			} else {
 				return nil, fmt.Errorf("unexpected WHOAREYOU (nonce %x), waiting for NODES", resp.Nonce[:])
 			}
@@ -172,7 +181,7 @@ func (tc *conn) findnode(c net.PacketConn, dists []uint) ([]*enode.Node, error)
 			}, nil, from)
 		case *v5wire.Nodes:
 			// Got NODES! Check request ID.
-			if !bytes.Equal(resp.ReqID, findnode.ReqID) {
+			if !bytes.Equal(resp.ReqID, req.RequestID()) {
 				return nil, fmt.Errorf("NODES response has wrong request id %x", resp.ReqID)
 			}
 			// Check total count. It should be greater than one
@@ -51,6 +51,8 @@ func init() {
 		discv5Command,
 		dnsCommand,
 		nodesetCommand,
+		crawldbCommand,
+		forkcheckCommand,
 		rlpxCommand,
 	}
 }
@@ -266,6 +266,11 @@ func makeFullNode(ctx *cli.Context) *node.Node {
 		})
 	}
 
+	// Serve the chain database to other processes if requested.
+	if ctx.IsSet(utils.RemoteDBListenFlag.Name) && eth != nil {
+		utils.RegisterRemoteDBServer(stack, eth.ChainDb(), ctx.String(utils.RemoteDBListenFlag.Name), ctx.Bool(utils.RemoteDBWritableFlag.Name))
+	}
+
 	// Configure log filter RPC API.
 	filterSystem := utils.RegisterFilterAPI(stack, backend, &cfg.Eth)
 
@@ -17,8 +17,13 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"math"
 	"os"
 	"os/signal"
@@ -102,15 +107,31 @@ Remove blockchain and state databases`,
 			dbMetadataCmd,
 			dbCheckStateContentCmd,
 			dbInspectHistoryCmd,
+			dbExportStateHistoryCmd,
+			dbImportStateHistoryCmd,
+			dbCheckpointCmd,
 		},
 	}
 	dbInspectCmd = &cli.Command{
-		Action:      inspect,
-		Name:        "inspect",
-		ArgsUsage:   "<prefix> <start>",
-		Flags:       slices.Concat(utils.NetworkFlags, utils.DatabaseFlags),
-		Usage:       "Inspect the storage size for each type of data in the database",
-		Description: `This commands iterates the entire database. If the optional 'prefix' and 'start' arguments are provided, then the iteration is limited to the given subset of data.`,
+		Action:    inspect,
+		Name:      "inspect",
+		ArgsUsage: "<prefix> <start>",
+		Flags: slices.Concat([]cli.Flag{
+			&cli.StringFlag{
+				Name:  "output",
+				Usage: "file to save the report into in JSON format",
+			},
+			&cli.BoolFlag{
+				Name:  "diff",
+				Usage: "compare saved reports instead of inspecting the database",
+			},
+		}, utils.NetworkFlags, utils.DatabaseFlags),
+		Usage: "Inspect the storage size for each type of data in the database",
+		Description: `This commands iterates the entire database. If the optional 'prefix' and 'start' arguments are provided, then the iteration is limited to the given subset of data.
+
+The report can be saved with --output, and the saved reports (or the output of
+debug_dbStats) compared with --diff <old report> [new report]. If only one report
+is given, it's compared with the current state of the database.`,
 	}
 	dbInspectTrieCmd = &cli.Command{
 		Action:    inspectTrie,
@@ -221,6 +242,17 @@ WARNING: This is a low-level operation which may cause database corruption!`,
 		Flags:       slices.Concat(utils.NetworkFlags, utils.DatabaseFlags),
 		Description: "Shows metadata about the chain status.",
 	}
+	dbCheckpointCmd = &cli.Command{
+		Action:    dbCheckpoint,
+		Name:      "checkpoint",
+		Usage:     "Create a consistent copy of the chain database",
+		ArgsUsage: "<dir>",
+		Flags:     slices.Concat(utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command creates a copy of the chain database, including the ancient
+store and the state journal, into the given directory laid out as a data directory.
+The copy shares the immutable files with the original database by hard links
+where supported. Use admin.dbCheckpoint to checkpoint the database of a running node.`,
+	}
 	dbInspectHistoryCmd = &cli.Command{
 		Action:    inspectHistory,
 		Name:      "inspect-history",
@@ -242,6 +274,32 @@ WARNING: This is a low-level operation which may cause database corruption!`,
 		}, utils.NetworkFlags, utils.DatabaseFlags),
 		Description: "This command queries the history of the account or storage slot within the specified block range",
 	}
+	dbExportStateHistoryCmd = &cli.Command{
+		Action:    exportStateHistory,
+		Name:      "export-state-history",
+		Usage:     "Exports the state history within block range. If the <dumpfile> has .gz suffix, gzip compression will be used.",
+		ArgsUsage: "<dumpfile> <first> <last>",
+		Flags:     slices.Concat(utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command exports the state histories of the blocks within the specified range (both included)
+into a checksummed stream, along with the trienode histories if they cover the whole range. It's
+only supported in path-based scheme.`,
+	}
+	dbImportStateHistoryCmd = &cli.Command{
+		Action:    importStateHistory,
+		Name:      "import-state-history",
+		Usage:     "Imports the state history from an exported dump",
+		ArgsUsage: "<dumpfile>",
+		Flags:     slices.Concat(utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command imports the state histories exported by another node, extending the local
+historical state window backwards. The dump must end with the block right before the earliest
+local history, or with the head state if there is none, e.g. right after a snap sync.
+
+All the local histories are renumbered and the history indexes are rebuilt at the next startup.
+Note the histories beyond the configured limit (--history.state) will be pruned once the node
+is started.
+WARNING: This operation may corrupt the state history if it is aborted after the histories
+are staged!`,
+	}
 )
 
 func removeDB(ctx *cli.Context) error {
@@ -340,22 +398,42 @@ func inspect(ctx *cli.Context) error {
 	var (
 		prefix []byte
 		start  []byte
+		old    *rawdb.DatabaseReport
 	)
-	if ctx.NArg() > 2 {
-		return fmt.Errorf("max 2 arguments: %v", ctx.Command.ArgsUsage)
-	}
-	if ctx.NArg() >= 1 {
-		if d, err := hexutil.Decode(ctx.Args().Get(0)); err != nil {
-			return fmt.Errorf("failed to hex-decode 'prefix': %v", err)
-		} else {
-			prefix = d
+	if ctx.Bool("diff") {
+		if ctx.NArg() < 1 || ctx.NArg() > 2 {
+			return errors.New("required arguments: <old report> [new report]")
 		}
-	}
-	if ctx.NArg() >= 2 {
-		if d, err := hexutil.Decode(ctx.Args().Get(1)); err != nil {
-			return fmt.Errorf("failed to hex-decode 'start': %v", err)
-		} else {
-			start = d
+		report, err := loadDatabaseReport(ctx.Args().Get(0))
+		if err != nil {
+			return err
+		}
+		if ctx.NArg() == 2 {
+			new, err := loadDatabaseReport(ctx.Args().Get(1))
+			if err != nil {
+				return err
+			}
+			rawdb.RenderReportDiff(os.Stdout, report, new)
+			return nil
+		}
+		old = report
+	} else {
+		if ctx.NArg() > 2 {
+			return fmt.Errorf("max 2 arguments: %v", ctx.Command.ArgsUsage)
+		}
+		if ctx.NArg() >= 1 {
+			if d, err := hexutil.Decode(ctx.Args().Get(0)); err != nil {
+				return fmt.Errorf("failed to hex-decode 'prefix': %v", err)
+			} else {
+				prefix = d
+			}
+		}
+		if ctx.NArg() >= 2 {
+			if d, err := hexutil.Decode(ctx.Args().Get(1)); err != nil {
+				return fmt.Errorf("failed to hex-decode 'start': %v", err)
+			} else {
+				start = d
+			}
 		}
 	}
 	stack, _ := makeConfigNode(ctx)
@@ -364,7 +442,39 @@ func inspect(ctx *cli.Context) error {
 	db := utils.MakeChainDatabase(ctx, stack, true)
 	defer db.Close()
 
-	return rawdb.InspectDatabase(db, prefix, start)
+	report, err := rawdb.InspectDatabase(db, prefix, start)
+	if err != nil {
+		return err
+	}
+	if old != nil {
+		rawdb.RenderReportDiff(os.Stdout, old, report)
+	} else {
+		report.Render(os.Stdout)
+	}
+	if file := ctx.String("output"); file != "" {
+		blob, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(file, blob, 0644); err != nil {
+			return err
+		}
+		log.Info("Saved database report", "file", file)
+	}
+	return nil
+}
+
+// loadDatabaseReport reads a database report saved in JSON format.
+func loadDatabaseReport(file string) (*rawdb.DatabaseReport, error) {
+	blob, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var report rawdb.DatabaseReport
+	if err := json.Unmarshal(blob, &report); err != nil {
+		return nil, fmt.Errorf("invalid database report %s: %v", file, err)
+	}
+	return &report, nil
 }
 
 func checkStateContent(ctx *cli.Context) error {
@@ -1046,3 +1156,142 @@ func inspectHistory(ctx *cli.Context) error {
 	}
 	return inspectStorage(triedb, start, end, address, slot, ctx.Bool("raw"))
 }
+
+func exportStateHistory(ctx *cli.Context) error {
+	if ctx.NArg() != 3 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	first, err := strconv.ParseUint(ctx.Args().Get(1), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid first block: %v", err)
+	}
+	last, err := strconv.ParseUint(ctx.Args().Get(2), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid last block: %v", err)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, true)
+	defer db.Close()
+
+	triedb := utils.MakeTrieDatabase(ctx, stack, db, false, true, false)
+	defer triedb.Close()
+
+	// Open the file handle and potentially wrap with a gzip stream
+	fn := ctx.Args().Get(0)
+	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	var (
+		buffered           = bufio.NewWriter(fh)
+		writer   io.Writer = buffered
+		gz       *gzip.Writer
+	)
+	if strings.HasSuffix(fn, ".gz") {
+		gz = gzip.NewWriter(buffered)
+		writer = gz
+	}
+	n, err := triedb.ExportStateHistory(writer, first, last)
+	if err != nil {
+		return err
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return err
+		}
+	}
+	if err := buffered.Flush(); err != nil {
+		return err
+	}
+	log.Info("Exported state history", "file", fn, "count", n)
+	return nil
+}
+
+func importStateHistory(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, false)
+	defer db.Close()
+
+	triedb := utils.MakeTrieDatabase(ctx, stack, db, false, false, false)
+	defer triedb.Close()
+
+	// Open the file handle and potentially unwrap the gzip stream
+	fn := ctx.Args().Get(0)
+	fh, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	var reader io.Reader = bufio.NewReader(fh)
+	if strings.HasSuffix(fn, ".gz") {
+		if reader, err = gzip.NewReader(reader); err != nil {
+			return err
+		}
+	}
+	n, err := triedb.ImportStateHistory(reader)
+	if err != nil {
+		return err
+	}
+	log.Info("Imported state history", "file", fn, "count", n)
+	return nil
+}
+
+func dbCheckpoint(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	dir := ctx.Args().Get(0)
+	if _, err := os.Stat(dir); err == nil {
+		return fmt.Errorf("checkpoint directory %s already exists", dir)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	// The key-value store can't be checkpointed in read-only mode, while the
+	// state is only read.
+	db := utils.MakeChainDatabase(ctx, stack, false)
+	defer db.Close()
+
+	cp, ok := db.(ethdb.Checkpointer)
+	if !ok {
+		return ethdb.ErrCheckpointUnsupported
+	}
+	triedb := utils.MakeTrieDatabase(ctx, stack, db, false, true, false)
+	defer triedb.Close()
+
+	// Mirror the layout of the data directory in the checkpoint
+	var paths []string
+	for _, name := range []string{"chaindata", "triedb"} {
+		path, err := filepath.Rel(stack.DataDir(), stack.ResolvePath(name))
+		if err != nil {
+			return err
+		}
+		paths = append(paths, filepath.Join(dir, path))
+	}
+	if err := os.MkdirAll(filepath.Dir(paths[0]), 0755); err != nil {
+		return err
+	}
+	var root common.Hash
+	if head := rawdb.ReadHeadBlock(db); head != nil {
+		root = head.Root()
+	}
+	start := time.Now()
+	err := triedb.Checkpoint(root, rawdb.CheckpointAncientDir(paths[0]), paths[1], func() error {
+		return cp.Checkpoint(paths[0])
+	})
+	if err != nil {
+		return err
+	}
+	log.Info("Created database checkpoint", "dir", dir, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
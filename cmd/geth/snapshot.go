@@ -206,6 +206,37 @@ block is used.
 				Description: `
 The export-preimages command exports hash preimages to a flat file, in exactly
 the expected order for the overlay tree migration.
+`,
+			},
+			{
+				Action:    snapshotExportState,
+				Name:      "export-state",
+				Usage:     "Export the entire state at a given root to a portable file",
+				ArgsUsage: "<dumpfile> [<root>]",
+				Flags:     slices.Concat(utils.NetworkFlags, utils.DatabaseFlags),
+				Description: `
+geth snapshot export-state <dumpfile> [<root>]
+exports all the accounts, storage slots and contract codes of the state with
+the given root, or the state of the head block if no root is given, into a flat
+chunked file. The exported state is verified against the root. If the file
+name ends with .gz, the output is gzipped.
+`,
+			},
+			{
+				Action:    snapshotImportState,
+				Name:      "import-state",
+				Usage:     "Import the state from a file produced by export-state",
+				ArgsUsage: "<dumpfile>",
+				Flags:     slices.Concat(utils.NetworkFlags, utils.DatabaseFlags),
+				Description: `
+geth snapshot import-state <dumpfile>
+imports the state exported by 'geth snapshot export-state', replacing the entire
+state held by the database. The whole file is verified against the exported root
+before the existing state is deleted, so a truncated or corrupted file is rejected
+without modifying the database. The exported root must be the state root of the
+head block or another canonical block of the local chain, otherwise the file is
+rejected as well. The trie is regenerated from the imported state and verified
+again. It allows restoring the state of a node offline.
 `,
 			},
 			{
@@ -975,6 +1006,59 @@ func snapshotExportPreimages(ctx *cli.Context) error {
 	return utils.ExportSnapshotPreimages(chaindb, stateIt, ctx.Args().First(), root)
 }
 
+// snapshotExportState dumps the entire state at a given root to a flat file.
+func snapshotExportState(ctx *cli.Context) error {
+	if ctx.NArg() < 1 || ctx.NArg() > 2 {
+		return errors.New("invalid arguments: <dumpfile> [<root>]")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chaindb := utils.MakeChainDatabase(ctx, stack, true)
+	defer chaindb.Close()
+
+	triedb := utils.MakeTrieDatabase(ctx, stack, chaindb, false, true, false)
+	defer triedb.Close()
+
+	var root common.Hash
+	if ctx.NArg() > 1 {
+		var err error
+		if root, err = parseRoot(ctx.Args().Get(1)); err != nil {
+			return fmt.Errorf("invalid root: %v", err)
+		}
+	} else {
+		headBlock := rawdb.ReadHeadBlock(chaindb)
+		if headBlock == nil {
+			log.Error("Failed to load head block")
+			return errors.New("no head block")
+		}
+		root = headBlock.Root()
+	}
+	stateIt, err := utils.NewStateIterator(triedb, chaindb, root)
+	if err != nil {
+		return err
+	}
+	return utils.ExportState(chaindb, stateIt, ctx.Args().First(), root)
+}
+
+// snapshotImportState imports the state from a file produced by export-state.
+func snapshotImportState(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return errors.New("invalid arguments: <dumpfile>")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chaindb := utils.MakeChainDatabase(ctx, stack, false)
+	defer chaindb.Close()
+
+	triedb := utils.MakeTrieDatabase(ctx, stack, chaindb, false, false, false)
+	defer triedb.Close()
+
+	_, err := utils.ImportState(chaindb, triedb, ctx.Args().First())
+	return err
+}
+
 // checkAccount iterates the snap data layers, and looks up the given account
 // across all layers.
 func checkAccount(ctx *cli.Context) error {
@@ -0,0 +1,459 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/stateless"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/internal/tablewriter"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	witnessRPCFlag = &cli.StringFlag{
+		Name:  "rpc",
+		Usage: "RPC endpoint of a node to fetch the blocks and witnesses from, instead of producing them locally",
+	}
+
+	statelessCommand = &cli.Command{
+		Name:  "stateless",
+		Usage: "A set of commands for stateless execution witnesses",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "export",
+				Usage:     "Export blocks and their execution witnesses",
+				ArgsUsage: "<dir> <first> <last>",
+				Action:    exportWitnesses,
+				Flags:     slices.Concat([]cli.Flag{witnessRPCFlag}, utils.NetworkFlags, utils.DatabaseFlags),
+				Description: `
+geth stateless export [--rpc <endpoint>] <dir> <first> <last>
+
+Produces the execution witnesses of the given block range and writes each block
+together with its witness into the given directory. The witnesses are produced
+by executing the blocks on top of the local state, which must be available for
+the parent of each block. Alternatively, the blocks and witnesses are fetched
+from the node behind the --rpc endpoint via debug_executionWitness.
+
+The files use the payload format consumed by cmd/keeper.`,
+			},
+			{
+				Name:      "verify",
+				Usage:     "Re-execute blocks statelessly and verify the resulting roots",
+				ArgsUsage: "<file|dir>...",
+				Action:    verifyWitnesses,
+				Flags:     slices.Concat(utils.NetworkFlags, utils.DatabaseFlags),
+				Description: `
+geth stateless verify <file|dir>...
+
+Executes the exported blocks using only their witnesses, the same way cmd/keeper
+does, and reports the blocks whose computed state or receipt root mismatches the
+header. The chain configuration is resolved from the chain ID of the payloads,
+falling back to the one stored in the local database for unknown networks.`,
+			},
+			{
+				Name:      "stats",
+				Usage:     "Report the size statistics of exported witnesses",
+				ArgsUsage: "<file|dir>...",
+				Action:    witnessStats,
+				Description: `
+geth stateless stats <file|dir>...
+
+Reports the size of the exported witnesses, broken down by headers, bytecodes and
+trie nodes.`,
+			},
+		},
+	}
+)
+
+// statelessPayload is a block bundled with its execution witness, in the input
+// format of cmd/keeper.
+type statelessPayload struct {
+	ChainID uint64
+	Block   *types.Block
+	Witness *stateless.Witness
+}
+
+// witnessSource retrieves the blocks and their witnesses.
+type witnessSource interface {
+	chainID() uint64
+	witness(number uint64) (*types.Block, *stateless.Witness, error)
+	close()
+}
+
+// localWitnessSource produces the witnesses by executing the blocks on top of
+// the local state.
+type localWitnessSource struct {
+	chain *core.BlockChain
+}
+
+func (s *localWitnessSource) chainID() uint64 {
+	return s.chain.Config().ChainID.Uint64()
+}
+
+func (s *localWitnessSource) witness(number uint64) (*types.Block, *stateless.Witness, error) {
+	block := s.chain.GetBlockByNumber(number)
+	if block == nil {
+		return nil, nil, fmt.Errorf("block #%d not found", number)
+	}
+	parent := s.chain.GetHeader(block.ParentHash(), number-1)
+	if parent == nil {
+		return nil, nil, fmt.Errorf("parent of block #%d not found", number)
+	}
+	result, err := s.chain.ProcessBlock(context.Background(), parent.Root, block, core.ExecuteConfig{MakeWitness: true})
+	if err != nil {
+		return nil, nil, err
+	}
+	return block, result.Witness(), nil
+}
+
+func (s *localWitnessSource) close() {
+	s.chain.Stop()
+}
+
+// remoteWitnessSource fetches the blocks and their witnesses from a node.
+type remoteWitnessSource struct {
+	client *rpc.Client
+	id     uint64
+}
+
+func newRemoteWitnessSource(endpoint string) (*remoteWitnessSource, error) {
+	client, err := rpc.Dial(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	var id hexutil.Uint64
+	if err := client.Call(&id, "eth_chainId"); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return &remoteWitnessSource{client: client, id: uint64(id)}, nil
+}
+
+func (s *remoteWitnessSource) chainID() uint64 {
+	return s.id
+}
+
+func (s *remoteWitnessSource) witness(number uint64) (*types.Block, *stateless.Witness, error) {
+	var blob hexutil.Bytes
+	if err := s.client.Call(&blob, "debug_getRawBlock", hexutil.Uint64(number)); err != nil {
+		return nil, nil, err
+	}
+	block := new(types.Block)
+	if err := rlp.DecodeBytes(blob, block); err != nil {
+		return nil, nil, fmt.Errorf("invalid block #%d: %v", number, err)
+	}
+	var ext stateless.ExtWitness
+	if err := s.client.Call(&ext, "debug_executionWitness", hexutil.Uint64(number)); err != nil {
+		return nil, nil, err
+	}
+	witness := new(stateless.Witness)
+	if err := witness.FromExtWitness(&ext); err != nil {
+		return nil, nil, fmt.Errorf("invalid witness of block #%d: %v", number, err)
+	}
+	return block, witness, nil
+}
+
+func (s *remoteWitnessSource) close() {
+	s.client.Close()
+}
+
+func exportWitnesses(ctx *cli.Context) error {
+	if ctx.NArg() != 3 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	dir := ctx.Args().Get(0)
+	first, err := strconv.ParseUint(ctx.Args().Get(1), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid first block: %v", err)
+	}
+	last, err := strconv.ParseUint(ctx.Args().Get(2), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid last block: %v", err)
+	}
+	if first == 0 || first > last {
+		return fmt.Errorf("invalid block range %d-%d", first, last)
+	}
+	var source witnessSource
+	if endpoint := ctx.String(witnessRPCFlag.Name); endpoint != "" {
+		if source, err = newRemoteWitnessSource(endpoint); err != nil {
+			return err
+		}
+	} else {
+		stack, _ := makeConfigNode(ctx)
+		defer stack.Close()
+
+		chain, db := utils.MakeChain(ctx, stack, true)
+		defer db.Close()
+		source = &localWitnessSource{chain: chain}
+	}
+	defer source.close()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	var (
+		start    = time.Now()
+		reported = time.Now()
+		total    stateless.WitnessSize
+	)
+	for number := first; number <= last; number++ {
+		block, witness, err := source.witness(number)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve witness of block #%d: %v", number, err)
+		}
+		blob, err := rlp.EncodeToBytes(&statelessPayload{ChainID: source.chainID(), Block: block, Witness: witness})
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("block-%d.rlp", number)), blob, 0644); err != nil {
+			return err
+		}
+		size := witness.Size()
+		total.Add(size)
+		log.Debug("Exported block witness", "number", number, "hash", block.Hash(), "size", common.StorageSize(size.Total()))
+
+		if time.Since(reported) >= 8*time.Second {
+			log.Info("Exporting block witnesses", "number", number, "elapsed", common.PrettyDuration(time.Since(start)))
+			reported = time.Now()
+		}
+	}
+	log.Info("Exported block witnesses", "dir", dir, "count", last-first+1, "size", common.StorageSize(total.Total()), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// payloadFiles expands the given files and directories into the list of the
+// payload files to process.
+func payloadFiles(args []string) ([]string, error) {
+	if len(args) == 0 {
+		return nil, errors.New("no payload files specified")
+	}
+	var files []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(arg, "*.rlp"))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// readPayload reads a block and its witness from the given file.
+func readPayload(file string) (*statelessPayload, error) {
+	blob, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var payload statelessPayload
+	if err := rlp.DecodeBytes(blob, &payload); err != nil {
+		return nil, fmt.Errorf("invalid payload %s: %v", file, err)
+	}
+	return &payload, nil
+}
+
+// statelessChainConfig resolves the chain configuration of the given chain ID.
+// Unknown networks are resolved from the local database.
+func statelessChainConfig(ctx *cli.Context, chainID uint64) (*params.ChainConfig, error) {
+	if config := params.ChainConfigByID(chainID); config != nil {
+		return config, nil
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, true)
+	defer db.Close()
+
+	config, _, err := core.LoadChainConfig(db, utils.MakeGenesis(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if config.ChainID == nil || config.ChainID.Uint64() != chainID {
+		return nil, fmt.Errorf("unknown chain ID %d, local database is of chain %v", chainID, config.ChainID)
+	}
+	return config, nil
+}
+
+// executePayload executes the block statelessly, mirroring cmd/keeper, and
+// checks the computed roots against the header.
+func executePayload(config *params.ChainConfig, payload *statelessPayload) error {
+	// Remove critical computed fields from the block to force true recalculation
+	block := payload.Block
+	header := block.Header()
+	header.Root = common.Hash{}
+	header.ReceiptHash = common.Hash{}
+	task := types.NewBlockWithHeader(header).WithBody(*block.Body())
+
+	stateRoot, receiptRoot, err := core.ExecuteStateless(context.Background(), config, vm.Config{}, task, payload.Witness)
+	if err != nil {
+		return fmt.Errorf("stateless execution failed: %v", err)
+	}
+	if stateRoot != block.Root() {
+		return fmt.Errorf("state root mismatch (stateless: %x header: %x)", stateRoot, block.Root())
+	}
+	if receiptRoot != block.ReceiptHash() {
+		return fmt.Errorf("receipt root mismatch (stateless: %x header: %x)", receiptRoot, block.ReceiptHash())
+	}
+	return nil
+}
+
+func verifyWitnesses(ctx *cli.Context) error {
+	files, err := payloadFiles(ctx.Args().Slice())
+	if err != nil {
+		return err
+	}
+	var (
+		configs = make(map[uint64]*params.ChainConfig)
+		rows    [][]string
+		failed  int
+	)
+	for _, file := range files {
+		payload, err := readPayload(file)
+		if err != nil {
+			return err
+		}
+		config, ok := configs[payload.ChainID]
+		if !ok {
+			if config, err = statelessChainConfig(ctx, payload.ChainID); err != nil {
+				return err
+			}
+			configs[payload.ChainID] = config
+		}
+		var (
+			start  = time.Now()
+			result = "ok"
+		)
+		if err := executePayload(config, payload); err != nil {
+			log.Error("Stateless verification failed", "number", payload.Block.Number(), "hash", payload.Block.Hash(), "err", err)
+			result = err.Error()
+			failed++
+		}
+		rows = append(rows, []string{
+			payload.Block.Number().String(),
+			payload.Block.Hash().TerminalString(),
+			common.StorageSize(payload.Witness.Size().Total()).String(),
+			common.PrettyDuration(time.Since(start)).String(),
+			result,
+		})
+	}
+	sortRowsByNumber(rows)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Block", "Hash", "Witness", "Elapsed", "Result"})
+	table.AppendBulk(rows)
+	table.Render()
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d blocks failed stateless verification", failed, len(rows))
+	}
+	return nil
+}
+
+func witnessStats(ctx *cli.Context) error {
+	files, err := payloadFiles(ctx.Args().Slice())
+	if err != nil {
+		return err
+	}
+	var (
+		rows    [][]string
+		total   stateless.WitnessSize
+		largest stateless.WitnessSize
+		gasUsed uint64
+	)
+	for _, file := range files {
+		payload, err := readPayload(file)
+		if err != nil {
+			return err
+		}
+		size := payload.Witness.Size()
+		total.Add(size)
+		if size.Total() > largest.Total() {
+			largest = size
+		}
+		gasUsed += payload.Block.GasUsed()
+
+		rows = append(rows, []string{
+			payload.Block.Number().String(),
+			fmt.Sprintf("%d", payload.Block.GasUsed()),
+			fmt.Sprintf("%d", size.Headers),
+			fmt.Sprintf("%d (%v)", size.Codes, common.StorageSize(size.CodeBytes)),
+			fmt.Sprintf("%d (%v)", size.States, common.StorageSize(size.StateBytes)),
+			common.StorageSize(size.Total()).String(),
+		})
+	}
+	if len(rows) == 0 {
+		return errors.New("no payload files found")
+	}
+	sortRowsByNumber(rows)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Block", "Gas used", "Headers", "Codes", "Trie nodes", "Total"})
+	table.SetFooter([]string{
+		"Total", fmt.Sprintf("%d", gasUsed), fmt.Sprintf("%d", total.Headers),
+		fmt.Sprintf("%d (%v)", total.Codes, common.StorageSize(total.CodeBytes)),
+		fmt.Sprintf("%d (%v)", total.States, common.StorageSize(total.StateBytes)),
+		common.StorageSize(total.Total()).String(),
+	})
+	table.AppendBulk(rows)
+	table.Render()
+
+	fmt.Printf("Average witness size: %v, largest: %v\n", common.StorageSize(total.Total()/len(rows)), common.StorageSize(largest.Total()))
+	if gasUsed > 0 {
+		fmt.Printf("Witness bytes per million gas: %v\n", common.StorageSize(float64(total.Total())*1e6/float64(gasUsed)))
+	}
+	return nil
+}
+
+// sortRowsByNumber sorts the table rows by the block number in the first column.
+func sortRowsByNumber(rows [][]string) {
+	slices.SortFunc(rows, func(a, b []string) int {
+		x, _ := strconv.ParseUint(a[0], 10, 64)
+		y, _ := strconv.ParseUint(b[0], 10, 64)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	})
+}
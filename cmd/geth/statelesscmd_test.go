@@ -0,0 +1,124 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/stateless"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// writeKeeperPayload writes the Hoodi block and witness embedded in cmd/keeper
+// as a payload file into dir. If tamper is set, the state root of the block is
+// altered, so that stateless verification fails.
+func writeKeeperPayload(t *testing.T, dir string, tamper bool) {
+	t.Helper()
+
+	blob, err := os.ReadFile("../keeper/1192c3_block.rlp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var block types.Block
+	if err := rlp.DecodeBytes(blob, &block); err != nil {
+		t.Fatal(err)
+	}
+	if blob, err = os.ReadFile("../keeper/1192c3_witness.rlp"); err != nil {
+		t.Fatal(err)
+	}
+	var ext struct {
+		Headers []*types.Header
+		Codes   []hexutil.Bytes
+		State   []hexutil.Bytes
+		Keys    []hexutil.Bytes
+	}
+	if err := rlp.DecodeBytes(blob, &ext); err != nil {
+		t.Fatal(err)
+	}
+	witness := &stateless.Witness{
+		Headers: ext.Headers,
+		Codes:   make(map[string]struct{}),
+		State:   make(map[string]struct{}),
+	}
+	for _, code := range ext.Codes {
+		witness.Codes[string(code)] = struct{}{}
+	}
+	for _, node := range ext.State {
+		witness.State[string(node)] = struct{}{}
+	}
+	if tamper {
+		header := block.Header()
+		header.Root = common.Hash{0x01}
+		block = *block.WithSeal(header)
+	}
+	payload := &statelessPayload{
+		ChainID: params.HoodiChainConfig.ChainID.Uint64(),
+		Block:   &block,
+		Witness: witness,
+	}
+	if blob, err = rlp.EncodeToBytes(payload); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "block-1151683.rlp"), blob, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestStatelessVerify runs "geth stateless verify" on a valid and a tampered
+// payload of a Hoodi block.
+func TestStatelessVerify(t *testing.T) {
+	t.Parallel()
+
+	valid := t.TempDir()
+	writeKeeperPayload(t, valid, false)
+	geth := runGeth(t, "stateless", "verify", valid)
+	geth.WaitExit()
+	if have, want := geth.ExitStatus(), 0; have != want {
+		t.Fatalf("valid payload: exit status mismatch, have %d want %d: %s", have, want, geth.StderrText())
+	}
+
+	tampered := t.TempDir()
+	writeKeeperPayload(t, tampered, true)
+	geth = runGeth(t, "stateless", "verify", tampered)
+	geth.WaitExit()
+	if have, want := geth.ExitStatus(), 1; have != want {
+		t.Fatalf("tampered payload: exit status mismatch, have %d want %d", have, want)
+	}
+	if stderr := geth.StderrText(); !strings.Contains(stderr, "1 of 1 blocks failed stateless verification") {
+		t.Fatalf("tampered payload: unexpected error output: %s", stderr)
+	}
+}
+
+// TestStatelessStats runs "geth stateless stats" on an exported payload.
+func TestStatelessStats(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeKeeperPayload(t, dir, false)
+	geth := runGeth(t, "stateless", "stats", filepath.Join(dir, "block-1151683.rlp"))
+	geth.WaitExit()
+	if have, want := geth.ExitStatus(), 0; have != want {
+		t.Fatalf("exit status mismatch, have %d want %d: %s", have, want, geth.StderrText())
+	}
+}
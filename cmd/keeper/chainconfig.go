@@ -25,14 +25,11 @@ import (
 // getChainConfig returns the appropriate chain configuration based on the chainID.
 // Returns an error for unsupported chain IDs.
 func getChainConfig(chainID uint64) (*params.ChainConfig, error) {
-	switch chainID {
-	case 0, params.MainnetChainConfig.ChainID.Uint64():
+	if chainID == 0 {
 		return params.MainnetChainConfig, nil
-	case params.SepoliaChainConfig.ChainID.Uint64():
-		return params.SepoliaChainConfig, nil
-	case params.HoodiChainConfig.ChainID.Uint64():
-		return params.HoodiChainConfig, nil
-	default:
-		return nil, fmt.Errorf("unsupported chain ID: %d", chainID)
 	}
+	if config := params.ChainConfigByID(chainID); config != nil {
+		return config, nil
+	}
+	return nil, fmt.Errorf("unsupported chain ID: %d", chainID)
 }
@@ -49,6 +49,7 @@ import (
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
 	"github.com/ethereum/go-ethereum/triedb"
 	"github.com/urfave/cli/v2"
 )
@@ -784,6 +785,484 @@ func ExportSnapshotPreimages(chaindb ethdb.Database, stateIt *StateIterator, fn
 	return nil
 }
 
+// stateExportHeader is the first element of a state export, identifying the
+// state root the export belongs to. Whenever a backwards-incompatible change
+// is made, the Version should be bumped.
+type stateExportHeader struct {
+	Magic    string // Always set to 'gethstate' for disambiguation
+	Version  uint64
+	Root     common.Hash
+	UnixTime uint64
+}
+
+// stateExportAccount is an account in a state export, along with its storage
+// and code. The storage of large accounts is split across multiple chunks; the
+// continued entries carry the same hash but no account data. The code is only
+// included in the first account using it.
+type stateExportAccount struct {
+	Hash    common.Hash
+	Account []byte // Account in the slim format, empty for continued entries
+	Code    []byte
+	Storage []stateExportSlot
+}
+
+// stateExportSlot is a storage slot in a state export.
+type stateExportSlot struct {
+	Hash  common.Hash
+	Value []byte
+}
+
+const (
+	stateExportMagic     = "gethstate"
+	stateExportVersion   = 0
+	stateExportChunkSize = 4096 // Number of accounts and slots per chunk
+)
+
+// stateVerifier verifies the streamed flat state against the state root by
+// feeding it into stack tries, the states must be supplied in order.
+type stateVerifier struct {
+	accountTrie *trie.StackTrie
+	storageTrie *trie.StackTrie
+	account     common.Hash              // Hash of the account being verified
+	storageRoot common.Hash              // Storage root of the account being verified
+	codes       map[common.Hash]struct{} // Codes known to be present
+	accounts    uint64
+	slots       uint64
+}
+
+func newStateVerifier() *stateVerifier {
+	return &stateVerifier{
+		accountTrie: trie.NewStackTrie(nil),
+		codes:       make(map[common.Hash]struct{}),
+	}
+}
+
+// addAccount finalizes the previous account and feeds the given one into the
+// account trie, returning the decoded account.
+func (v *stateVerifier) addAccount(hash common.Hash, blob []byte) (*types.StateAccount, error) {
+	if err := v.finishAccount(); err != nil {
+		return nil, err
+	}
+	account, err := types.FullAccount(blob)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account %x: %w", hash, err)
+	}
+	full, err := types.FullAccountRLP(blob)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.accountTrie.Update(hash.Bytes(), full); err != nil {
+		return nil, fmt.Errorf("invalid account %x: %w", hash, err)
+	}
+	v.account, v.storageRoot = hash, account.Root
+	v.storageTrie = trie.NewStackTrie(nil)
+	v.accounts++
+	return account, nil
+}
+
+// addSlot feeds the storage slot of the current account into its storage trie.
+func (v *stateVerifier) addSlot(hash common.Hash, value []byte) error {
+	if err := v.storageTrie.Update(hash.Bytes(), value); err != nil {
+		return fmt.Errorf("invalid slot %x of account %x: %w", hash, v.account, err)
+	}
+	v.slots++
+	return nil
+}
+
+// finishAccount verifies the storage root of the current account.
+func (v *stateVerifier) finishAccount() error {
+	if v.storageTrie == nil {
+		return nil
+	}
+	if root := v.storageTrie.Hash(); root != v.storageRoot {
+		return fmt.Errorf("storage root mismatch of account %x: have %x, want %x", v.account, root, v.storageRoot)
+	}
+	v.storageTrie = nil
+	return nil
+}
+
+// finish verifies the state root of all supplied accounts.
+func (v *stateVerifier) finish(root common.Hash) error {
+	if err := v.finishAccount(); err != nil {
+		return err
+	}
+	if have := v.accountTrie.Hash(); have != root {
+		return fmt.Errorf("state root mismatch: have %x, want %x", have, root)
+	}
+	return nil
+}
+
+// ExportState exports the entire state at the given root, including all the
+// accounts, storage slots and contract codes, into the specified file. The
+// exported state is verified against the root.
+func ExportState(chaindb ethdb.Database, stateIt *StateIterator, fn string, root common.Hash) error {
+	log.Info("Exporting state", "root", root, "file", fn)
+
+	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	// Enable gzip compressing if file name has gz suffix.
+	var writer io.Writer = fh
+	if strings.HasSuffix(fn, ".gz") {
+		gz := gzip.NewWriter(writer)
+		defer gz.Close()
+		writer = gz
+	}
+	buf := bufio.NewWriter(writer)
+	defer buf.Flush()
+	writer = buf
+
+	header := stateExportHeader{
+		Magic:    stateExportMagic,
+		Version:  stateExportVersion,
+		Root:     root,
+		UnixTime: uint64(time.Now().Unix()),
+	}
+	if err := rlp.Encode(writer, header); err != nil {
+		return err
+	}
+	accIt, err := stateIt.AccountIterator(root, common.Hash{})
+	if err != nil {
+		return err
+	}
+	defer accIt.Release()
+
+	var (
+		start    = time.Now()
+		logged   = time.Now()
+		verifier = newStateVerifier()
+		chunk    []stateExportAccount
+		size     int
+	)
+	flush := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+		if err := rlp.Encode(writer, chunk); err != nil {
+			return err
+		}
+		chunk, size = chunk[:0], 0
+		return nil
+	}
+	for accIt.Next() {
+		account, err := verifier.addAccount(accIt.Hash(), accIt.Account())
+		if err != nil {
+			return err
+		}
+		entry := stateExportAccount{Hash: accIt.Hash(), Account: common.CopyBytes(accIt.Account())}
+		if codeHash := common.BytesToHash(account.CodeHash); codeHash != types.EmptyCodeHash {
+			if _, ok := verifier.codes[codeHash]; !ok {
+				entry.Code = rawdb.ReadCode(chaindb, codeHash)
+				if len(entry.Code) == 0 {
+					return fmt.Errorf("missing code %x of account %x", codeHash, accIt.Hash())
+				}
+				verifier.codes[codeHash] = struct{}{}
+			}
+		}
+		size++
+		if account.Root != types.EmptyRootHash {
+			stIt, err := stateIt.StorageIterator(root, accIt.Hash(), common.Hash{})
+			if err != nil {
+				return err
+			}
+			for stIt.Next() {
+				if err := verifier.addSlot(stIt.Hash(), stIt.Slot()); err != nil {
+					stIt.Release()
+					return err
+				}
+				// Split the storage into a continued entry if the chunk is full
+				if size >= stateExportChunkSize {
+					chunk = append(chunk, entry)
+					if err := flush(); err != nil {
+						stIt.Release()
+						return err
+					}
+					entry = stateExportAccount{Hash: accIt.Hash()}
+				}
+				entry.Storage = append(entry.Storage, stateExportSlot{Hash: stIt.Hash(), Value: common.CopyBytes(stIt.Slot())})
+				size++
+			}
+			err = stIt.Error()
+			stIt.Release()
+			if err != nil {
+				return err
+			}
+		}
+		chunk = append(chunk, entry)
+		if size >= stateExportChunkSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Exporting state", "at", accIt.Hash(), "accounts", verifier.accounts, "slots", verifier.slots, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := accIt.Error(); err != nil {
+		return err
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	// Ensure the flat state is complete and consistent with the root.
+	if err := verifier.finish(root); err != nil {
+		return err
+	}
+	log.Info("Exported state", "root", root, "accounts", verifier.accounts, "slots", verifier.slots, "codes", len(verifier.codes), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// ImportState imports the state exported by ExportState into the database,
+// replacing the entire state held by it. The exported root must be the state
+// root of a canonical block of the local chain, and the whole export is verified
+// against it before the existing state is touched, so that an unrelated,
+// truncated or corrupted export leaves the database intact. The trie is
+// regenerated from the imported flat state and verified again, the root is
+// returned.
+func ImportState(chaindb ethdb.Database, tdb *triedb.Database, fn string) (common.Hash, error) {
+	log.Info("Importing state", "file", fn)
+
+	fh, err := os.Open(fn)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	defer fh.Close()
+
+	// Ensure the state belongs to the local chain before reading it entirely.
+	_, header, err := openStateExport(fh, fn)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	block := findStateBlock(chaindb, header.Root)
+	if block == nil {
+		return common.Hash{}, fmt.Errorf("state root %x does not belong to a canonical block", header.Root)
+	}
+	log.Info("Found block of imported state", "number", block.Number, "hash", block.Hash(), "root", header.Root)
+
+	// Verify the export in a dry run, nothing is written yet.
+	if _, err := fh.Seek(0, io.SeekStart); err != nil {
+		return common.Hash{}, err
+	}
+	start := time.Now()
+	header, verifier, err := readStateExport(fh, fn, "Verifying state", nil)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	log.Info("Verified state export", "root", header.Root, "exported", time.Unix(int64(header.UnixTime), 0), "accounts", verifier.accounts, "slots", verifier.slots, "elapsed", common.PrettyDuration(time.Since(start)))
+
+	// Wipe the existing flat state along with the trie nodes of path scheme,
+	// the trie is regenerated from the imported flat state. The path database
+	// is deactivated beforehand, terminating the background state generation.
+	if tdb.Scheme() == rawdb.PathScheme {
+		if err := tdb.Disable(); err != nil {
+			return common.Hash{}, err
+		}
+	}
+	batch := chaindb.NewBatch()
+	for _, prefix := range [][]byte{rawdb.SnapshotAccountPrefix, rawdb.SnapshotStoragePrefix} {
+		if err := deleteRange(batch, prefix); err != nil {
+			return common.Hash{}, err
+		}
+	}
+	if tdb.Scheme() == rawdb.PathScheme {
+		for _, prefix := range [][]byte{rawdb.TrieNodeAccountPrefix, rawdb.TrieNodeStoragePrefix} {
+			if err := deleteRange(batch, prefix); err != nil {
+				return common.Hash{}, err
+			}
+		}
+	}
+	rawdb.DeleteSnapshotRoot(batch)
+	if err := batch.Write(); err != nil {
+		return common.Hash{}, err
+	}
+	batch.Reset()
+
+	// Import the verified export from the same file handle.
+	if _, err := fh.Seek(0, io.SeekStart); err != nil {
+		return common.Hash{}, err
+	}
+	start = time.Now()
+	_, verifier, err = readStateExport(fh, fn, "Importing state", func(entry *stateExportAccount, codeHash common.Hash) error {
+		if len(entry.Account) != 0 {
+			rawdb.WriteAccountSnapshot(batch, entry.Hash, entry.Account)
+			if len(entry.Code) != 0 {
+				rawdb.WriteCode(batch, codeHash, entry.Code)
+			}
+		}
+		for _, slot := range entry.Storage {
+			rawdb.WriteStorageSnapshot(batch, entry.Hash, slot.Hash, slot.Value)
+		}
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		return nil
+	})
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := batch.Write(); err != nil {
+		return common.Hash{}, err
+	}
+	log.Info("Imported flat state", "accounts", verifier.accounts, "slots", verifier.slots, "codes", len(verifier.codes), "elapsed", common.PrettyDuration(time.Since(start)))
+
+	// Regenerate the trie from the flat state, the root is verified again.
+	if _, err := triedb.GenerateTrie(chaindb, tdb.Scheme(), header.Root, nil); err != nil {
+		return common.Hash{}, err
+	}
+	if tdb.Scheme() == rawdb.PathScheme {
+		if err := tdb.AdoptSyncedState(header.Root); err != nil {
+			return common.Hash{}, err
+		}
+	}
+	log.Info("Imported state", "root", header.Root, "elapsed", common.PrettyDuration(time.Since(start)))
+	return header.Root, nil
+}
+
+// openStateExport decodes the header of a state export, returning the stream
+// positioned at the first chunk.
+func openStateExport(fh *os.File, fn string) (*rlp.Stream, *stateExportHeader, error) {
+	// Potentially unwrap the gzip stream
+	var (
+		reader io.Reader = bufio.NewReader(fh)
+		err    error
+	)
+	if strings.HasSuffix(fn, ".gz") {
+		if reader, err = gzip.NewReader(reader); err != nil {
+			return nil, nil, err
+		}
+	}
+	stream := rlp.NewStream(reader, 0)
+
+	var header stateExportHeader
+	if err := stream.Decode(&header); err != nil {
+		return nil, nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	if header.Magic != stateExportMagic {
+		return nil, nil, errors.New("incompatible specification")
+	}
+	if header.Version != stateExportVersion {
+		return nil, nil, fmt.Errorf("incompatible version %d, (support only %d)", header.Version, stateExportVersion)
+	}
+	return stream, &header, nil
+}
+
+// findStateBlock returns the canonical header with the given state root, starting
+// the search at the chain head. It returns nil if no such header exists.
+func findStateBlock(db ethdb.Reader, root common.Hash) *types.Header {
+	headHash := rawdb.ReadHeadHeaderHash(db)
+	number, ok := rawdb.ReadHeaderNumber(db, headHash)
+	if !ok {
+		return nil
+	}
+	logged := time.Now()
+	for n := int64(number); n >= 0; n-- {
+		header := rawdb.ReadHeader(db, rawdb.ReadCanonicalHash(db, uint64(n)), uint64(n))
+		if header == nil {
+			return nil
+		}
+		if header.Root == root {
+			return header
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Searching block of imported state", "number", n, "head", number)
+			logged = time.Now()
+		}
+	}
+	return nil
+}
+
+// readStateExport decodes a state export and verifies it against the exported
+// root, passing every entry along with the code hash of its account to the
+// optional callback. The entries are only known to be valid once the function
+// returns without error.
+func readStateExport(fh *os.File, fn string, msg string, onEntry func(entry *stateExportAccount, codeHash common.Hash) error) (*stateExportHeader, *stateVerifier, error) {
+	stream, header, err := openStateExport(fh, fn)
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		start    = time.Now()
+		logged   = time.Now()
+		verifier = newStateVerifier()
+		codeHash common.Hash
+	)
+	for {
+		var chunk []stateExportAccount
+		if err := stream.Decode(&chunk); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, nil, err
+		}
+		for i := range chunk {
+			entry := &chunk[i]
+			if len(entry.Account) != 0 {
+				account, err := verifier.addAccount(entry.Hash, entry.Account)
+				if err != nil {
+					return nil, nil, err
+				}
+				codeHash = common.BytesToHash(account.CodeHash)
+				if len(entry.Code) != 0 {
+					if crypto.Keccak256Hash(entry.Code) != codeHash {
+						return nil, nil, fmt.Errorf("code mismatch of account %x", entry.Hash)
+					}
+					verifier.codes[codeHash] = struct{}{}
+				}
+				if _, ok := verifier.codes[codeHash]; !ok && codeHash != types.EmptyCodeHash {
+					return nil, nil, fmt.Errorf("missing code %x of account %x", codeHash, entry.Hash)
+				}
+			} else if entry.Hash != verifier.account || verifier.storageTrie == nil {
+				return nil, nil, fmt.Errorf("unexpected continued account %x", entry.Hash)
+			}
+			for _, slot := range entry.Storage {
+				if err := verifier.addSlot(slot.Hash, slot.Value); err != nil {
+					return nil, nil, err
+				}
+			}
+			if onEntry != nil {
+				if err := onEntry(entry, codeHash); err != nil {
+					return nil, nil, err
+				}
+			}
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info(msg, "at", verifier.account, "accounts", verifier.accounts, "slots", verifier.slots, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := verifier.finish(header.Root); err != nil {
+		return nil, nil, err
+	}
+	return header, verifier, nil
+}
+
+// deleteRange deletes all the entries with the given prefix.
+func deleteRange(batch ethdb.Batch, prefix []byte) error {
+	limit := common.CopyBytes(prefix)
+	limit[len(limit)-1]++
+	for {
+		err := batch.DeleteRange(prefix, limit)
+		if err == nil {
+			return nil
+		}
+		// Flush the batch and continue if too many entries are deleted at once.
+		if !errors.Is(err, ethdb.ErrTooManyKeys) {
+			return err
+		}
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+	}
+}
+
 // exportHeader is used in the export/import flow. When we do an export,
 // the first element we output is the exportHeader.
 // Whenever a backwards-incompatible change is made, the Version header
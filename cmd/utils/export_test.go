@@ -17,14 +17,25 @@
 package utils
 
 import (
+	"bytes"
 	"fmt"
+	"math/big"
 	"os"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/ethereum/go-ethereum/triedb/pathdb"
 )
 
 // TestExport does basic sanity checks on the export/import functionality
@@ -182,3 +193,206 @@ func TestImportFutureFormat(t *testing.T) {
 		t.Fatalf("wrong error: %v", err)
 	}
 }
+
+// TestStateExport does basic sanity checks on the state export/import.
+func TestStateExport(t *testing.T) {
+	// The state is always exported from a path database, as the hash database
+	// can't iterate the state without the snapshot, and imported into both.
+	for _, scheme := range []string{rawdb.HashScheme, rawdb.PathScheme} {
+		f := fmt.Sprintf("%v/statedump", t.TempDir())
+		testStateExport(t, scheme, f)
+	}
+}
+
+func TestStateExportGzip(t *testing.T) {
+	f := fmt.Sprintf("%v/statedump.gz", t.TempDir())
+	testStateExport(t, rawdb.PathScheme, f)
+}
+
+func testStateExport(t *testing.T, scheme string, f string) {
+	// Create a state with enough storage slots to be split across chunks.
+	alloc := types.GenesisAlloc{
+		common.Address{0x1}: {Balance: big.NewInt(1)},
+		common.Address{0x2}: {Balance: big.NewInt(2), Nonce: 1, Code: []byte{0x1}},
+		common.Address{0x3}: {Balance: big.NewInt(3), Code: []byte{0x1}, Storage: map[common.Hash]common.Hash{{0x1}: {0x1}}},
+		common.Address{0x4}: {Balance: big.NewInt(4), Code: []byte{0x2}, Storage: make(map[common.Hash]common.Hash)},
+	}
+	for i := 0; i < stateExportChunkSize+100; i++ {
+		alloc[common.Address{0x4}].Storage[common.BigToHash(big.NewInt(int64(i)))] = common.BigToHash(big.NewInt(int64(i + 1)))
+	}
+	var (
+		srcdb  = rawdb.NewMemoryDatabase()
+		srctdb = triedb.NewDatabase(srcdb, newTrieConfig(rawdb.PathScheme))
+	)
+	block, err := (&core.Genesis{Config: params.TestChainConfig, Alloc: alloc}).Commit(srcdb, srctdb, nil)
+	if err != nil {
+		t.Fatalf("Failed to commit genesis: %v", err)
+	}
+	root := block.Root()
+
+	stateIt, err := NewStateIterator(srctdb, srcdb, root)
+	if err != nil {
+		t.Fatalf("Failed to create state iterator: %v", err)
+	}
+	if err := ExportState(srcdb, stateIt, f, root); err != nil {
+		t.Fatalf("Failed to export state: %v", err)
+	}
+	// Import the state into a database holding a different state.
+	var (
+		dstdb  = rawdb.NewMemoryDatabase()
+		dsttdb = triedb.NewDatabase(dstdb, newTrieConfig(scheme))
+	)
+	if _, err := (&core.Genesis{Config: params.TestChainConfig, Alloc: types.GenesisAlloc{common.Address{0x5}: {Balance: big.NewInt(5)}}}).Commit(dstdb, dsttdb, nil); err != nil {
+		t.Fatalf("Failed to commit genesis: %v", err)
+	}
+	writeStateHead(dstdb, root)
+	imported, err := ImportState(dstdb, dsttdb, f)
+	if err != nil {
+		t.Fatalf("Failed to import state: %v", err)
+	}
+	if imported != root {
+		t.Fatalf("Imported root mismatch: have %x, want %x", imported, root)
+	}
+	statedb, err := state.New(root, state.NewDatabase(dsttdb, nil))
+	if err != nil {
+		t.Fatalf("Failed to open imported state: %v", err)
+	}
+	for addr, account := range alloc {
+		if have := statedb.GetBalance(addr).ToBig(); have.Cmp(account.Balance) != 0 {
+			t.Fatalf("Balance mismatch of %x: have %v, want %v", addr, have, account.Balance)
+		}
+		if have := statedb.GetNonce(addr); have != account.Nonce {
+			t.Fatalf("Nonce mismatch of %x: have %d, want %d", addr, have, account.Nonce)
+		}
+		if have := statedb.GetCode(addr); !bytes.Equal(have, account.Code) {
+			t.Fatalf("Code mismatch of %x: have %x, want %x", addr, have, account.Code)
+		}
+		for key, value := range account.Storage {
+			if have := statedb.GetState(addr, key); have != value {
+				t.Fatalf("Slot mismatch of %x:%x: have %x, want %x", addr, key, have, value)
+			}
+		}
+	}
+	if statedb.Exist(common.Address{0x5}) {
+		t.Fatal("Previous state is not replaced")
+	}
+	if scheme == rawdb.PathScheme {
+		if err := dsttdb.VerifyState(root); err != nil {
+			t.Fatalf("Failed to verify imported state: %v", err)
+		}
+	}
+}
+
+// TestStateImportCorrupted checks that a corrupted state export is rejected
+// without touching the existing state.
+func TestStateImportCorrupted(t *testing.T) {
+	var (
+		f     = fmt.Sprintf("%v/statedump", t.TempDir())
+		db    = rawdb.NewMemoryDatabase()
+		tdb   = triedb.NewDatabase(db, newTrieConfig(rawdb.PathScheme))
+		alloc = types.GenesisAlloc{}
+	)
+	for i := 0; i < 16; i++ {
+		alloc[common.Address{byte(i)}] = types.Account{Balance: big.NewInt(int64(i + 1))}
+	}
+	block, err := (&core.Genesis{Config: params.TestChainConfig, Alloc: alloc}).Commit(db, tdb, nil)
+	if err != nil {
+		t.Fatalf("Failed to commit genesis: %v", err)
+	}
+	stateIt, err := NewStateIterator(tdb, db, block.Root())
+	if err != nil {
+		t.Fatalf("Failed to create state iterator: %v", err)
+	}
+	if err := ExportState(db, stateIt, f, block.Root()); err != nil {
+		t.Fatalf("Failed to export state: %v", err)
+	}
+	blob, err := os.ReadFile(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Flip a byte of the last account balance.
+	blob[len(blob)-1]++
+	if err := os.WriteFile(f, blob, 0600); err != nil {
+		t.Fatal(err)
+	}
+	var (
+		dstdb  = rawdb.NewMemoryDatabase()
+		dsttdb = triedb.NewDatabase(dstdb, newTrieConfig(rawdb.PathScheme))
+		addr   = common.Address{0x5}
+	)
+	dstblock, err := (&core.Genesis{Config: params.TestChainConfig, Alloc: types.GenesisAlloc{addr: {Balance: big.NewInt(5)}}}).Commit(dstdb, dsttdb, nil)
+	if err != nil {
+		t.Fatalf("Failed to commit genesis: %v", err)
+	}
+	writeStateHead(dstdb, block.Root())
+	if _, err := ImportState(dstdb, dsttdb, f); err == nil {
+		t.Fatal("Corrupted state export is accepted")
+	}
+	if err := dsttdb.VerifyState(dstblock.Root()); err != nil {
+		t.Fatalf("Existing state is damaged: %v", err)
+	}
+	if rawdb.ReadAccountSnapshot(dstdb, crypto.Keccak256Hash(addr.Bytes())) == nil {
+		t.Fatal("Existing flat state is deleted")
+	}
+}
+
+// TestStateImportUnknownRoot checks that the state of a block which is not part of
+// the local chain is rejected without touching the existing state.
+func TestStateImportUnknownRoot(t *testing.T) {
+	var (
+		f   = fmt.Sprintf("%v/statedump", t.TempDir())
+		db  = rawdb.NewMemoryDatabase()
+		tdb = triedb.NewDatabase(db, newTrieConfig(rawdb.PathScheme))
+	)
+	block, err := (&core.Genesis{Config: params.TestChainConfig, Alloc: types.GenesisAlloc{common.Address{0x1}: {Balance: big.NewInt(1)}}}).Commit(db, tdb, nil)
+	if err != nil {
+		t.Fatalf("Failed to commit genesis: %v", err)
+	}
+	stateIt, err := NewStateIterator(tdb, db, block.Root())
+	if err != nil {
+		t.Fatalf("Failed to create state iterator: %v", err)
+	}
+	if err := ExportState(db, stateIt, f, block.Root()); err != nil {
+		t.Fatalf("Failed to export state: %v", err)
+	}
+	var (
+		dstdb  = rawdb.NewMemoryDatabase()
+		dsttdb = triedb.NewDatabase(dstdb, newTrieConfig(rawdb.PathScheme))
+		addr   = common.Address{0x5}
+	)
+	dstblock, err := (&core.Genesis{Config: params.TestChainConfig, Alloc: types.GenesisAlloc{addr: {Balance: big.NewInt(5)}}}).Commit(dstdb, dsttdb, nil)
+	if err != nil {
+		t.Fatalf("Failed to commit genesis: %v", err)
+	}
+	if _, err := ImportState(dstdb, dsttdb, f); err == nil {
+		t.Fatal("State of unknown block is accepted")
+	}
+	if err := dsttdb.VerifyState(dstblock.Root()); err != nil {
+		t.Fatalf("Existing state is damaged: %v", err)
+	}
+	if rawdb.ReadAccountSnapshot(dstdb, crypto.Keccak256Hash(addr.Bytes())) == nil {
+		t.Fatal("Existing flat state is deleted")
+	}
+}
+
+// writeStateHead extends the chain in db by a header with the given state root,
+// making it the head header.
+func writeStateHead(db ethdb.Database, root common.Hash) {
+	parent := rawdb.ReadHeadHeader(db)
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		Root:       root,
+		Difficulty: common.Big0,
+	}
+	rawdb.WriteHeader(db, header)
+	rawdb.WriteCanonicalHash(db, header.Hash(), header.Number.Uint64())
+	rawdb.WriteHeadHeaderHash(db, header.Hash())
+}
+
+func newTrieConfig(scheme string) *triedb.Config {
+	if scheme == rawdb.PathScheme {
+		return &triedb.Config{PathDB: pathdb.Defaults}
+	}
+	return triedb.HashDefaults
+}
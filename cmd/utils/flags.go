@@ -95,7 +95,17 @@ var (
 	}
 	RemoteDBFlag = &cli.StringFlag{
 		Name:     "remotedb",
-		Usage:    "URL for remote database",
+		Usage:    "URL for remote database (RPC endpoint, or tcp://host:port and unix://path for a node serving --remotedb.listen)",
+		Category: flags.LoggingCategory,
+	}
+	RemoteDBListenFlag = &cli.StringFlag{
+		Name:     "remotedb.listen",
+		Usage:    "Serve the chain database to other processes on the given endpoint (tcp://host:port or unix://path)",
+		Category: flags.LoggingCategory,
+	}
+	RemoteDBWritableFlag = &cli.BoolFlag{
+		Name:     "remotedb.writable",
+		Usage:    "Allow the clients of --remotedb.listen to modify the database (unix sockets only)",
 		Category: flags.LoggingCategory,
 	}
 	DBEngineFlag = &cli.StringFlag{
@@ -104,6 +114,17 @@ var (
 		Value:    node.DefaultConfig.DBEngine,
 		Category: flags.EthCategory,
 	}
+	DBStatsFlag = &cli.BoolFlag{
+		Name:     "db.stats",
+		Usage:    "Collect the storage usage of the database in the background (exposed via debug_dbStats and metrics)",
+		Category: flags.EthCategory,
+	}
+	DBStatsIntervalFlag = &cli.DurationFlag{
+		Name:     "db.stats.interval",
+		Usage:    "Time to wait between two full scans of the database collecting the storage usage",
+		Value:    ethconfig.Defaults.DatabaseStatsInterval,
+		Category: flags.EthCategory,
+	}
 	AncientFlag = &flags.DirectoryFlag{
 		Name:     "datadir.ancient",
 		Usage:    "Root directory for ancient data (default = inside chaindata)",
@@ -656,6 +677,11 @@ var (
 		Value:    ethconfig.Defaults.LogQueryLimit,
 		Category: flags.APICategory,
 	}
+	RPCPersistentFiltersFlag = &cli.BoolFlag{
+		Name:     "rpc.persistfilters",
+		Usage:    "Persist log filters created by eth_newFilter across restarts",
+		Category: flags.APICategory,
+	}
 	RPCTxSyncDefaultTimeoutFlag = &cli.DurationFlag{
 		Name:     "rpc.txsync.defaulttimeout",
 		Usage:    "Default timeout for eth_sendRawTransactionSync (e.g. 2s, 500ms)",
@@ -933,6 +959,16 @@ var (
 		Value:    30303,
 		Category: flags.NetworkingCategory,
 	}
+	QUICPortFlag = &cli.IntFlag{
+		Name:     "quic.port",
+		Usage:    "UDP port for P2P connections over QUIC (disabled if not set)",
+		Category: flags.NetworkingCategory,
+	}
+	HolePunchingFlag = &cli.BoolFlag{
+		Name:     "nat.holepunch",
+		Usage:    "Enables NAT traversal for QUIC connections through hole punching coordinated over discovery v5",
+		Category: flags.NetworkingCategory,
+	}
 
 	// Console
 	JSpathFlag = &flags.DirectoryFlag{
@@ -1277,6 +1313,9 @@ func setListenAddress(ctx *cli.Context, cfg *p2p.Config) {
 	if ctx.IsSet(DiscoveryPortFlag.Name) {
 		cfg.DiscAddr = fmt.Sprintf(":%d", ctx.Int(DiscoveryPortFlag.Name))
 	}
+	if ctx.IsSet(QUICPortFlag.Name) {
+		cfg.QUICListenAddr = fmt.Sprintf(":%d", ctx.Int(QUICPortFlag.Name))
+	}
 }
 
 // setNAT creates a port mapper from command line flags.
@@ -1476,6 +1515,10 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 	if ctx.IsSet(DiscoveryV5Flag.Name) {
 		cfg.DiscoveryV5 = ctx.Bool(DiscoveryV5Flag.Name)
 	}
+	flags.CheckExclusive(ctx, HolePunchingFlag, NoDiscoverFlag)
+	if ctx.IsSet(HolePunchingFlag.Name) {
+		cfg.HolePunching = ctx.Bool(HolePunchingFlag.Name)
+	}
 
 	if netrestrict := ctx.String(NetrestrictFlag.Name); netrestrict != "" {
 		list, err := netutil.ParseNetlist(netrestrict)
@@ -1774,6 +1817,12 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(EraFlag.Name) {
 		cfg.DatabaseEra = ctx.String(EraFlag.Name)
 	}
+	if ctx.IsSet(DBStatsFlag.Name) {
+		cfg.DatabaseStats = ctx.Bool(DBStatsFlag.Name)
+	}
+	if ctx.IsSet(DBStatsIntervalFlag.Name) {
+		cfg.DatabaseStatsInterval = ctx.Duration(DBStatsIntervalFlag.Name)
+	}
 
 	if gcmode := ctx.String(GCModeFlag.Name); gcmode != "full" && gcmode != "archive" {
 		Fatalf("--%s must be either 'full' or 'archive'", GCModeFlag.Name)
@@ -1848,6 +1897,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(RPCGlobalLogQueryLimit.Name) {
 		cfg.LogQueryLimit = ctx.Int(RPCGlobalLogQueryLimit.Name)
 	}
+	if ctx.IsSet(RPCPersistentFiltersFlag.Name) {
+		cfg.PersistentFilters = ctx.Bool(RPCPersistentFiltersFlag.Name)
+	}
 	if ctx.IsSet(RPCTxSyncDefaultTimeoutFlag.Name) {
 		cfg.TxSyncDefaultTimeout = ctx.Duration(RPCTxSyncDefaultTimeoutFlag.Name)
 	}
@@ -2200,11 +2252,12 @@ func RegisterGraphQLService(stack *node.Node, backend ethapi.Backend, filterSyst
 }
 
 // RegisterFilterAPI adds the eth log filtering RPC API to the node.
-func RegisterFilterAPI(stack *node.Node, backend ethapi.Backend, ethcfg *ethconfig.Config) *filters.FilterSystem {
+func RegisterFilterAPI(stack *node.Node, backend filters.Backend, ethcfg *ethconfig.Config) *filters.FilterSystem {
 	filterSystem := filters.NewFilterSystem(backend, filters.Config{
-		LogCacheSize:  ethcfg.FilterLogCacheSize,
-		LogQueryLimit: ethcfg.LogQueryLimit,
-		RangeLimit:    ethcfg.RangeLimit,
+		LogCacheSize:   ethcfg.FilterLogCacheSize,
+		LogQueryLimit:  ethcfg.LogQueryLimit,
+		RangeLimit:     ethcfg.RangeLimit,
+		PersistFilters: ethcfg.PersistentFilters,
 	})
 	stack.RegisterAPIs([]rpc.API{{
 		Namespace: "eth",
@@ -2213,6 +2266,50 @@ func RegisterFilterAPI(stack *node.Node, backend ethapi.Backend, ethcfg *ethconf
 	return filterSystem
 }
 
+// RegisterRemoteDBServer serves the chain database to other processes on the
+// given endpoint, see the remotedb package.
+func RegisterRemoteDBServer(stack *node.Node, db ethdb.Database, endpoint string, writable bool) {
+	network, _, _ := strings.Cut(endpoint, "://")
+	if writable && network != "unix" {
+		Fatalf("Flag --%s is only supported for unix socket endpoints", RemoteDBWritableFlag.Name)
+	}
+	if network == "tcp" {
+		log.Warn("Serving the chain database over TCP without authentication", "endpoint", endpoint)
+	}
+	stack.RegisterLifecycle(&remoteDBService{
+		server:   remotedb.NewServer(db, !writable),
+		endpoint: endpoint,
+	})
+}
+
+// remoteDBService runs the remote database server along with the node.
+type remoteDBService struct {
+	server   *remotedb.Server
+	endpoint string
+}
+
+// Start implements node.Lifecycle, starting the remote database server.
+func (s *remoteDBService) Start() error {
+	l, err := remotedb.Listen(s.endpoint)
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := s.server.Serve(l); err != nil {
+			log.Error("Remote database server failed", "endpoint", s.endpoint, "err", err)
+		}
+	}()
+	log.Info("Remote database server started", "endpoint", s.endpoint)
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the remote database server.
+func (s *remoteDBService) Stop() error {
+	s.server.Close()
+	log.Info("Remote database server stopped")
+	return nil
+}
+
 // RegisterSyncOverrideService adds the synchronization override service into node.
 func RegisterSyncOverrideService(stack *node.Node, eth *eth.Ethereum, config syncer.Config) {
 	if config.TargetBlock != (common.Hash{}) {
@@ -2299,6 +2396,9 @@ func MakeChainDatabase(ctx *cli.Context, stack *node.Node, readonly bool) ethdb.
 		chainDb ethdb.Database
 	)
 	switch {
+	case ctx.IsSet(RemoteDBFlag.Name) && remotedb.IsEndpoint(ctx.String(RemoteDBFlag.Name)):
+		log.Info("Using remote db", "endpoint", ctx.String(RemoteDBFlag.Name))
+		chainDb, err = remotedb.Dial(ctx.String(RemoteDBFlag.Name))
 	case ctx.IsSet(RemoteDBFlag.Name):
 		log.Info("Using remote db", "url", ctx.String(RemoteDBFlag.Name), "headers", len(ctx.StringSlice(HttpHeaderFlag.Name)))
 		client, err := DialRPCWithHeaders(ctx.String(RemoteDBFlag.Name), ctx.StringSlice(HttpHeaderFlag.Name))
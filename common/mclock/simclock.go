@@ -80,6 +80,18 @@ func (s *Simulated) ActiveTimers() int {
 	return len(s.scheduled)
 }
 
+// NextTimer returns the time at which the earliest scheduled timer fires. The
+// boolean is false if no timers are scheduled.
+func (s *Simulated) NextTimer() (AbsTime, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.scheduled) == 0 {
+		return 0, false
+	}
+	return s.scheduled[0].at, true
+}
+
 // WaitForTimers waits until the clock has at least n scheduled timers.
 func (s *Simulated) WaitForTimers(n int) {
 	s.mu.Lock()
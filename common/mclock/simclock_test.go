@@ -160,3 +160,20 @@ func TestSimulatedTimerStop(t *testing.T) {
 		t.Fatal("timer didn't fire")
 	}
 }
+
+func TestSimulatedNextTimer(t *testing.T) {
+	var c Simulated
+	if _, ok := c.NextTimer(); ok {
+		t.Fatal("NextTimer returned a timer on empty clock")
+	}
+	c.Run(time.Minute)
+	t1 := c.AfterFunc(2*time.Second, func() {})
+	c.AfterFunc(5*time.Second, func() {})
+	if next, ok := c.NextTimer(); !ok || next != AbsTime(0).Add(time.Minute+2*time.Second) {
+		t.Fatalf("wrong next timer %v", next)
+	}
+	t1.Stop()
+	if next, ok := c.NextTimer(); !ok || next != AbsTime(0).Add(time.Minute+5*time.Second) {
+		t.Fatalf("wrong next timer after stop %v", next)
+	}
+}
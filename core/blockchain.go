@@ -340,6 +340,8 @@ type BlockChain struct {
 	logsFeed         event.Feed
 	blockProcFeed    event.Feed
 	newPayloadFeed   event.Feed // Feed for engine API newPayload events
+	finalizedFeed    event.Feed // Feed for finalized block changes
+	safeFeed         event.Feed // Feed for safe block changes
 	blockProcCounter int32
 	scope            event.SubscriptionScope
 	genesisBlock     *types.Block
@@ -805,10 +807,14 @@ func (bc *BlockChain) sendChainHeadEvent() error {
 
 // SetFinalized sets the finalized block.
 func (bc *BlockChain) SetFinalized(header *types.Header) {
-	bc.currentFinalBlock.Store(header)
+	prev := bc.currentFinalBlock.Swap(header)
 	if header != nil {
 		rawdb.WriteFinalizedBlockHash(bc.db, header.Hash())
 		headFinalizedBlockGauge.Update(int64(header.Number.Uint64()))
+
+		if prev == nil || prev.Hash() != header.Hash() {
+			bc.finalizedFeed.Send(FinalizedHeadEvent{Header: header})
+		}
 	} else {
 		rawdb.WriteFinalizedBlockHash(bc.db, common.Hash{})
 		headFinalizedBlockGauge.Update(0)
@@ -817,9 +823,13 @@ func (bc *BlockChain) SetFinalized(header *types.Header) {
 
 // SetSafe sets the safe block.
 func (bc *BlockChain) SetSafe(header *types.Header) {
-	bc.currentSafeBlock.Store(header)
+	prev := bc.currentSafeBlock.Swap(header)
 	if header != nil {
 		headSafeBlockGauge.Update(int64(header.Number.Uint64()))
+
+		if prev == nil || prev.Hash() != header.Hash() {
+			bc.safeFeed.Send(SafeHeadEvent{Header: header})
+		}
 	} else {
 		headSafeBlockGauge.Update(0)
 	}
@@ -1287,6 +1297,34 @@ func (bc *BlockChain) ExportN(w io.Writer, first uint64, last uint64) error {
 	return nil
 }
 
+// Checkpoint creates a consistent copy of the chain database into the given
+// directory while the chain keeps running. The chain mutations are blocked
+// until the key-value store and the ancient stores are checkpointed. In path
+// scheme the in-memory state layers are journaled into journalDir as well.
+func (bc *BlockChain) Checkpoint(dir string, journalDir string) error {
+	cp, ok := bc.db.(ethdb.Checkpointer)
+	if !ok {
+		return ethdb.ErrCheckpointUnsupported
+	}
+	if !bc.chainmu.TryLock() {
+		return errChainStopped
+	}
+	defer bc.chainmu.Unlock()
+
+	var (
+		start = time.Now()
+		head  = bc.CurrentBlock()
+	)
+	err := bc.triedb.Checkpoint(head.Root, rawdb.CheckpointAncientDir(dir), journalDir, func() error {
+		return cp.Checkpoint(dir)
+	})
+	if err != nil {
+		return err
+	}
+	log.Info("Checkpointed chain database", "number", head.Number, "hash", head.Hash(), "dir", dir, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
 // writeHeadBlock injects a new head block into the current block chain. This method
 // assumes that the block is indeed a true head. It will also reset the head
 // header and the head snap sync block to this very same block if they are older
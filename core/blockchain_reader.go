@@ -544,6 +544,16 @@ func (bc *BlockChain) SubscribeChainHeadEvent(ch chan<- ChainHeadEvent) event.Su
 	return bc.scope.Track(bc.chainHeadFeed.Subscribe(ch))
 }
 
+// SubscribeFinalizedHeadEvent registers a subscription of FinalizedHeadEvent.
+func (bc *BlockChain) SubscribeFinalizedHeadEvent(ch chan<- FinalizedHeadEvent) event.Subscription {
+	return bc.scope.Track(bc.finalizedFeed.Subscribe(ch))
+}
+
+// SubscribeSafeHeadEvent registers a subscription of SafeHeadEvent.
+func (bc *BlockChain) SubscribeSafeHeadEvent(ch chan<- SafeHeadEvent) event.Subscription {
+	return bc.scope.Track(bc.safeFeed.Subscribe(ch))
+}
+
 // SubscribeLogsEvent registers a subscription of []*types.Log.
 func (bc *BlockChain) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription {
 	return bc.scope.Track(bc.logsFeed.Subscribe(ch))
@@ -26,6 +26,7 @@ import (
 	"math/rand"
 	"os"
 	"path"
+	"path/filepath"
 	"reflect"
 	"sync"
 	"testing"
@@ -4544,3 +4545,138 @@ func TestSetHeadBeyondRootFinalizedBug(t *testing.T) {
 			currentFinal.Number.Uint64())
 	}
 }
+
+// Tests that finalized and safe block changes are announced exactly once.
+func TestFinalityEvents(t *testing.T) {
+	_, _, blockchain, err := newCanonical(ethash.NewFaker(), 10, true, rawdb.HashScheme)
+	if err != nil {
+		t.Fatalf("failed to create pristine chain: %v", err)
+	}
+	defer blockchain.Stop()
+
+	var (
+		finalCh = make(chan FinalizedHeadEvent, 10)
+		safeCh  = make(chan SafeHeadEvent, 10)
+	)
+	finalSub := blockchain.SubscribeFinalizedHeadEvent(finalCh)
+	defer finalSub.Unsubscribe()
+	safeSub := blockchain.SubscribeSafeHeadEvent(safeCh)
+	defer safeSub.Unsubscribe()
+
+	for i := 0; i < 2; i++ {
+		blockchain.SetFinalized(blockchain.GetHeaderByNumber(5))
+		blockchain.SetSafe(blockchain.GetHeaderByNumber(8))
+	}
+	blockchain.SetFinalized(nil)
+	blockchain.SetFinalized(blockchain.GetHeaderByNumber(6))
+
+	for _, want := range []uint64{5, 6} {
+		select {
+		case ev := <-finalCh:
+			if ev.Header.Number.Uint64() != want {
+				t.Errorf("wrong finalized block: have %d, want %d", ev.Header.Number, want)
+			}
+		default:
+			t.Fatalf("missing finalized event for block %d", want)
+		}
+	}
+	if len(finalCh) != 0 {
+		t.Errorf("unexpected finalized events: %d", len(finalCh))
+	}
+	select {
+	case ev := <-safeCh:
+		if ev.Header.Number.Uint64() != 8 {
+			t.Errorf("wrong safe block: have %d, want 8", ev.Header.Number)
+		}
+	default:
+		t.Fatal("missing safe event")
+	}
+	if len(safeCh) != 0 {
+		t.Errorf("unexpected safe events: %d", len(safeCh))
+	}
+}
+
+func TestBlockChainCheckpoint(t *testing.T) {
+	testBlockChainCheckpoint(t, rawdb.HashScheme)
+	testBlockChainCheckpoint(t, rawdb.PathScheme)
+}
+
+func testBlockChainCheckpoint(t *testing.T, scheme string) {
+	openDatabase := func(datadir string) ethdb.Database {
+		pdb, err := pebble.New(datadir, 0, 0, "", false)
+		if err != nil {
+			t.Fatalf("Failed to create persistent key-value database: %v", err)
+		}
+		db, err := rawdb.Open(pdb, rawdb.OpenOptions{Ancient: rawdb.CheckpointAncientDir(datadir)})
+		if err != nil {
+			t.Fatalf("Failed to create persistent freezer database: %v", err)
+		}
+		return db
+	}
+	var (
+		datadir = t.TempDir()
+		db      = openDatabase(datadir)
+		gspec   = &Genesis{
+			Config:  params.TestChainConfig,
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		engine  = ethash.NewFaker()
+		options = DefaultConfig().WithStateScheme(scheme)
+	)
+	defer db.Close()
+
+	options.TrieJournalDirectory = filepath.Join(datadir, "triedb")
+	chain, err := NewBlockChain(db, gspec, engine, options)
+	if err != nil {
+		t.Fatalf("Failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 8, func(i int, b *BlockGen) {
+		b.SetCoinbase(common.Address{0x01})
+	})
+	if _, err := chain.InsertChain(blocks[:4]); err != nil {
+		t.Fatalf("Failed to import chain: %v", err)
+	}
+	var (
+		dir        = filepath.Join(t.TempDir(), "checkpoint")
+		journalDir = filepath.Join(dir, "triedb")
+	)
+	if err := chain.Checkpoint(dir, journalDir); err != nil {
+		t.Fatalf("Failed to checkpoint chain: %v", err)
+	}
+	// The chain must keep progressing after the checkpoint
+	if _, err := chain.InsertChain(blocks[4:]); err != nil {
+		t.Fatalf("Failed to import chain after checkpoint: %v", err)
+	}
+	if err := chain.Checkpoint(dir, journalDir); err == nil {
+		t.Fatal("Expected error for existing checkpoint directory")
+	}
+	// Open the checkpoint and ensure it's at the checkpointed head
+	cdb := openDatabase(dir)
+	defer cdb.Close()
+
+	options = DefaultConfig().WithStateScheme(scheme)
+	options.TrieJournalDirectory = journalDir
+	copied, err := NewBlockChain(cdb, gspec, engine, options)
+	if err != nil {
+		t.Fatalf("Failed to open checkpointed chain: %v", err)
+	}
+	defer copied.Stop()
+
+	if head := copied.CurrentHeader(); head.Hash() != blocks[3].Hash() {
+		t.Fatalf("Head header mismatch: have %d, want %d", head.Number, blocks[3].Number())
+	}
+	// In hash scheme the dirty trie nodes aren't persisted, the chain is
+	// rewound to the genesis.
+	want := blocks[3].Hash()
+	if scheme == rawdb.HashScheme {
+		want = chain.Genesis().Hash()
+	}
+	if head := copied.CurrentBlock(); head.Hash() != want {
+		t.Fatalf("Head block mismatch: have %d, want %x", head.Number, want)
+	}
+	if !copied.HasState(copied.CurrentBlock().Root) {
+		t.Fatal("State of the head block is not available")
+	}
+}
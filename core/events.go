@@ -39,6 +39,12 @@ type ChainHeadEvent struct {
 	Header *types.Header
 }
 
+// FinalizedHeadEvent is posted when the finalized block of the chain changes.
+type FinalizedHeadEvent struct{ Header *types.Header }
+
+// SafeHeadEvent is posted when the safe block of the chain changes.
+type SafeHeadEvent struct{ Header *types.Header }
+
 // NewPayloadEvent is posted when engine_newPayloadVX processes a block.
 type NewPayloadEvent struct {
 	Hash           common.Hash
@@ -65,21 +65,47 @@ type SyncRange struct {
 	IndexedBlocks common.Range[uint64]
 }
 
+// LogPosition identifies the position of a log in the chain by block number and
+// index of the log within the block.
+type LogPosition struct {
+	BlockNumber uint64
+	LogIndex    uint
+}
+
+// skipped returns true if the given log is located in the first block of a
+// search starting at the position, but before the position itself.
+func (p LogPosition) skipped(log *types.Log) bool {
+	return p.LogIndex > 0 && log.BlockNumber == p.BlockNumber && log.Index < p.LogIndex
+}
+
 // GetPotentialMatches returns a list of logs that are potential matches for the
 // given filter criteria. If parts of the log index in the searched range are
 // missing or changed during the search process then the resulting logs belonging
 // to that block range might be missing or incorrect.
 // Also note that the returned list may contain false positives.
 func GetPotentialMatches(ctx context.Context, backend MatcherBackend, firstBlock, lastBlock uint64, addresses []common.Address, topics [][]common.Hash) ([]*types.Log, error) {
+	logs, _, err := GetPotentialMatchesFrom(ctx, backend, LogPosition{BlockNumber: firstBlock}, lastBlock, 0, addresses, topics)
+	return logs, err
+}
+
+// GetPotentialMatchesFrom is a variant of GetPotentialMatches that allows paging
+// through the results. The search starts at the given log position, skipping
+// any earlier logs of the first block, and stops at the end of the first epoch
+// in which the number of potential matches reaches limit (zero means no limit).
+// The returned flag reports whether the entire range has been searched. If it
+// has not, the search can be resumed right after the last returned log without
+// processing the already searched epochs again.
+func GetPotentialMatchesFrom(ctx context.Context, backend MatcherBackend, from LogPosition, lastBlock uint64, limit int, addresses []common.Address, topics [][]common.Hash) ([]*types.Log, bool, error) {
+	firstBlock := from.BlockNumber
 	params := backend.GetParams()
 	// find the log value index range to search
 	firstIndex, err := backend.GetBlockLvPointer(ctx, firstBlock)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve log value pointer for first block %d: %v", firstBlock, err)
+		return nil, false, fmt.Errorf("failed to retrieve log value pointer for first block %d: %v", firstBlock, err)
 	}
 	lastIndex, err := backend.GetBlockLvPointer(ctx, lastBlock+1)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve log value pointer after last block %d: %v", lastBlock, err)
+		return nil, false, fmt.Errorf("failed to retrieve log value pointer after last block %d: %v", lastBlock, err)
 	}
 	if lastIndex > 0 {
 		lastIndex--
@@ -120,10 +146,12 @@ func GetPotentialMatches(ctx context.Context, backend MatcherBackend, firstBlock
 		lastIndex:  lastIndex,
 		firstMap:   uint32(firstIndex >> params.logValuesPerMap),
 		lastMap:    uint32(lastIndex >> params.logValuesPerMap),
+		from:       from,
+		limit:      limit,
 	}
 
 	start := time.Now()
-	res, err := m.process()
+	res, complete, err := m.process()
 	matchRequestTimer.Update(time.Since(start))
 
 	if doRuntimeStats {
@@ -137,7 +165,7 @@ func GetPotentialMatches(ctx context.Context, backend MatcherBackend, firstBlock
 		log.Info("Get log stats")
 		m.getLogStats.print()
 	}
-	return res, err
+	return res, complete, err
 }
 
 type matcherEnv struct {
@@ -148,9 +176,14 @@ type matcherEnv struct {
 	matcher               matcher
 	firstIndex, lastIndex uint64
 	firstMap, lastMap     uint32
+	from                  LogPosition // logs before this position are skipped
+	limit                 int         // stop after the epoch where this many matches were found
 }
 
-func (m *matcherEnv) process() ([]*types.Log, error) {
+// process searches the epochs of the requested range in order and returns the
+// potential matches, along with a flag reporting whether all of them have been
+// searched.
+func (m *matcherEnv) process() ([]*types.Log, bool, error) {
 	type task struct {
 		epochIndex uint32
 		logs       []*types.Log
@@ -203,11 +236,14 @@ func (m *matcherEnv) process() ([]*types.Log, error) {
 			if err := tasks[waitEpoch].err; err != nil {
 				if err == ErrMatchAll {
 					matchAllMeter.Mark(1)
-					return logs, err
+					return logs, false, err
 				}
-				return logs, fmt.Errorf("failed to process log index epoch %d: %v", waitEpoch, err)
+				return logs, false, fmt.Errorf("failed to process log index epoch %d: %v", waitEpoch, err)
 			}
 			delete(tasks, waitEpoch)
+			if m.limit > 0 && len(logs) >= m.limit && waitEpoch < lastEpoch {
+				return logs, false, nil
+			}
 			waitEpoch++
 			if waitEpoch <= lastEpoch {
 				if tasks[waitEpoch] == nil {
@@ -216,7 +252,7 @@ func (m *matcherEnv) process() ([]*types.Log, error) {
 			}
 		}
 	}
-	return logs, nil
+	return logs, true, nil
 }
 
 // processEpoch returns the potentially matching logs from the given epoch.
@@ -262,7 +298,7 @@ func (m *matcherEnv) processEpoch(epochIndex uint32) ([]*types.Log, error) {
 
 // getLogsFromMatches returns the list of potentially matching logs located at
 // the given list of matching log indices. Matches outside the firstIndex to
-// lastIndex range are not returned.
+// lastIndex range and logs before the starting position are not returned.
 func (m *matcherEnv) getLogsFromMatches(matches potentialMatches) ([]*types.Log, error) {
 	var logs []*types.Log
 	for _, match := range matches {
@@ -273,7 +309,7 @@ func (m *matcherEnv) getLogsFromMatches(matches potentialMatches) ([]*types.Log,
 		if err != nil {
 			return logs, fmt.Errorf("failed to retrieve log at index %d: %v", match, err)
 		}
-		if log != nil {
+		if log != nil && !m.from.skipped(log) {
 			logs = append(logs, log)
 		}
 		matchLogLookup.Mark(1)
@@ -23,6 +23,7 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 )
 
 func TestMatcher(t *testing.T) {
@@ -85,3 +86,64 @@ func TestMatcher(t *testing.T) {
 		}
 	}
 }
+
+func TestMatcherPaging(t *testing.T) {
+	ts := newTestSetup(t)
+	defer ts.close()
+
+	ts.chain.addBlocks(100, 10, 10, 4, true)
+	ts.setHistory(0, false)
+	ts.fm.WaitIdle()
+
+	// Search for the addresses of all logs in a range of blocks.
+	var addresses []common.Address
+	for _, bhash := range ts.chain.canonical[20:60] {
+		for _, receipt := range ts.chain.receipts[bhash] {
+			for _, log := range receipt.Logs {
+				addresses = append(addresses, log.Address)
+			}
+		}
+	}
+	mb := ts.fm.NewMatcherBackend()
+	defer mb.Close()
+
+	all, err := GetPotentialMatches(context.Background(), mb, 10, 80, addresses, nil)
+	if err != nil {
+		t.Fatalf("Log search error: %v", err)
+	}
+	if len(all) < len(addresses) {
+		t.Fatalf("Log search returned too few matches: have %d, want at least %d", len(all), len(addresses))
+	}
+	var (
+		paged []*types.Log
+		from  = LogPosition{BlockNumber: 10}
+		pages int
+	)
+	for {
+		logs, complete, err := GetPotentialMatchesFrom(context.Background(), mb, from, 80, 5, addresses, nil)
+		if err != nil {
+			t.Fatalf("Paged log search error: %v", err)
+		}
+		pages++
+		paged = append(paged, logs...)
+		if complete {
+			break
+		}
+		if len(logs) < 5 {
+			t.Fatalf("Incomplete page with %d matches", len(logs))
+		}
+		last := logs[len(logs)-1]
+		from = LogPosition{BlockNumber: last.BlockNumber, LogIndex: last.Index + 1}
+	}
+	if pages < 2 {
+		t.Fatalf("Paged log search finished in a single page")
+	}
+	if len(paged) != len(all) {
+		t.Fatalf("Paged log search returned wrong number of matches: have %d, want %d", len(paged), len(all))
+	}
+	for i := range all {
+		if paged[i] != all[i] {
+			t.Fatalf("Paged log search match %d mismatch", i)
+		}
+	}
+}
@@ -611,6 +611,29 @@ func DeleteFilterMapsRange(db ethdb.KeyValueWriter) {
 	}
 }
 
+// ReadPersistentFilters retrieves all persisted log filters, keyed by filter id.
+func ReadPersistentFilters(db ethdb.Iteratee) map[string][]byte {
+	it := db.NewIterator(persistentFilterPrefix, nil)
+	defer it.Release()
+
+	filters := make(map[string][]byte)
+	for it.Next() {
+		id := string(it.Key()[len(persistentFilterPrefix):])
+		filters[id] = common.CopyBytes(it.Value())
+	}
+	return filters
+}
+
+// WritePersistentFilter stores a persisted log filter.
+func WritePersistentFilter(db ethdb.KeyValueWriter, id string, blob []byte) error {
+	return db.Put(persistentFilterKey(id), blob)
+}
+
+// DeletePersistentFilter removes a persisted log filter.
+func DeletePersistentFilter(db ethdb.KeyValueWriter, id string) error {
+	return db.Delete(persistentFilterKey(id))
+}
+
 // deletePrefixRange deletes everything with the given prefix from the database.
 func deletePrefixRange(db ethdb.KeyValueStore, prefix []byte, hashScheme bool, stopCallback func(bool) bool) error {
 	end := bytes.Clone(prefix)
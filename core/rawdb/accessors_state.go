@@ -150,6 +150,37 @@ func WriteTrieJournal(db ethdb.KeyValueWriter, journal []byte) {
 	}
 }
 
+// DeleteTrieJournal deletes the serialized in-memory trie nodes of layers saved at
+// the last shutdown.
+func DeleteTrieJournal(db ethdb.KeyValueWriter) {
+	if err := db.Delete(trieJournalKey); err != nil {
+		log.Crit("Failed to remove tries journal", "err", err)
+	}
+}
+
+// ReadStateHistoryImport retrieves the serialized marker of the state history
+// import in progress.
+func ReadStateHistoryImport(db ethdb.KeyValueReader) []byte {
+	data, _ := db.Get(stateHistoryImportKey)
+	return data
+}
+
+// WriteStateHistoryImport stores the serialized marker of the state history
+// import in progress.
+func WriteStateHistoryImport(db ethdb.KeyValueWriter, marker []byte) {
+	if err := db.Put(stateHistoryImportKey, marker); err != nil {
+		log.Crit("Failed to store state history import marker", "err", err)
+	}
+}
+
+// DeleteStateHistoryImport deletes the marker of the state history import once
+// it's finished.
+func DeleteStateHistoryImport(db ethdb.KeyValueWriter) {
+	if err := db.Delete(stateHistoryImportKey); err != nil {
+		log.Crit("Failed to remove state history import marker", "err", err)
+	}
+}
+
 // ReadStateHistoryMeta retrieves the metadata corresponding to the specified
 // state history. Compute the position of state history in freezer by minus
 // one since the id of first state history starts from one(zero for initial
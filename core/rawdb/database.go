@@ -35,7 +35,6 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/ethdb/memorydb"
-	"github.com/ethereum/go-ethereum/internal/tablewriter"
 	"github.com/ethereum/go-ethereum/log"
 	"golang.org/x/sync/errgroup"
 )
@@ -56,6 +55,32 @@ func (frdb *freezerdb) AncientDatadir() (string, error) {
 	return frdb.ancientRoot, nil
 }
 
+// CheckpointAncientDir returns the root ancient directory of a database
+// checkpoint created in the given directory, which is the default location
+// within the key-value store directory.
+func CheckpointAncientDir(dir string) string {
+	return filepath.Join(dir, "ancient")
+}
+
+// Checkpoint implements ethdb.Checkpointer, creating a copy of the key-value
+// store in the given directory, along with the chain ancients placed in the
+// default ancient directory. The ancients are copied last, as the chain segments
+// are only deleted from the key-value store once frozen.
+func (frdb *freezerdb) Checkpoint(dir string) error {
+	kvdb, ok := frdb.KeyValueStore.(ethdb.Checkpointer)
+	if !ok {
+		return ethdb.ErrCheckpointUnsupported
+	}
+	ancients, ok := frdb.chainFreezer.ancients.(ethdb.Checkpointer)
+	if !ok {
+		return ethdb.ErrCheckpointUnsupported
+	}
+	if err := kvdb.Checkpoint(dir); err != nil {
+		return err
+	}
+	return ancients.Checkpoint(filepath.Join(CheckpointAncientDir(dir), ChainFreezerName))
+}
+
 // Close implements io.Closer, closing both the fast key-value store as well as
 // the slow ancient tables.
 func (frdb *freezerdb) Close() error {
@@ -398,49 +423,192 @@ func (s *stat) countString() string {
 	return counter(atomic.LoadUint64(&s.count)).String()
 }
 
+// dbCategory is a category of the data stored in the key-value store.
+type dbCategory int
+
+const (
+	catHeaders dbCategory = iota
+	catBodies
+	catReceipts
+	catTds
+	catNumHashPairings
+	catHashNumPairings
+	catBlockAccessList
+	catTxLookups
+	catFilterMapRows
+	catFilterMapLastBlock
+	catFilterMapBlockLV
+	catLogFilters
+	catBloomBits
+	catCodes
+	catLegacyTries
+	catStateLookups
+	catAccountTries
+	catStorageTries
+	catVerkleTries
+	catVerkleStateLookups
+	catPreimages
+	catAccountSnaps
+	catStorageSnaps
+	catStateIndex
+	catTrienodeIndex
+	catBeaconHeaders
+	catCliqueSnaps
+	catMetadata
+	catUnaccounted
+	numCategories
+)
+
+// dbCategories contains the display name and the metric name of the data
+// categories, in the order of the reports.
+var dbCategories = [numCategories]struct {
+	name   string
+	metric string
+}{
+	catHeaders:            {"Headers", "headers"},
+	catBodies:             {"Bodies", "bodies"},
+	catReceipts:           {"Receipt lists", "receipts"},
+	catTds:                {"Difficulties (deprecated)", "tds"},
+	catNumHashPairings:    {"Block number->hash", "numhash"},
+	catHashNumPairings:    {"Block hash->number", "hashnum"},
+	catBlockAccessList:    {"Block accessList", "accesslists"},
+	catTxLookups:          {"Transaction index", "txlookups"},
+	catFilterMapRows:      {"Log index filter-map rows", "filtermaprows"},
+	catFilterMapLastBlock: {"Log index last-block-of-map", "filtermaplastblock"},
+	catFilterMapBlockLV:   {"Log index block-lv", "filtermapblocklv"},
+	catLogFilters:         {"Persistent log filters", "logfilters"},
+	catBloomBits:          {"Log bloombits (deprecated)", "bloombits"},
+	catCodes:              {"Contract codes", "codes"},
+	catLegacyTries:        {"Hash trie nodes", "hashtries"},
+	catStateLookups:       {"Path trie state lookups", "statelookups"},
+	catAccountTries:       {"Path trie account nodes", "accounttries"},
+	catStorageTries:       {"Path trie storage nodes", "storagetries"},
+	catVerkleTries:        {"Verkle trie nodes", "verkletries"},
+	catVerkleStateLookups: {"Verkle trie state lookups", "verklestatelookups"},
+	catPreimages:          {"Trie preimages", "preimages"},
+	catAccountSnaps:       {"Account snapshot", "accountsnaps"},
+	catStorageSnaps:       {"Storage snapshot", "storagesnaps"},
+	catStateIndex:         {"Historical state index", "stateindex"},
+	catTrienodeIndex:      {"Historical trie index", "trienodeindex"},
+	catBeaconHeaders:      {"Beacon sync headers", "beaconheaders"},
+	catCliqueSnaps:        {"Clique snapshots", "cliquesnaps"},
+	catMetadata:           {"Singleton metadata", "metadata"},
+	catUnaccounted:        {"Unaccounted data", "unaccounted"},
+}
+
+// classifyKey determines the category of the given database entry.
+func classifyKey(key []byte, value []byte) dbCategory {
+	switch {
+	case bytes.HasPrefix(key, headerPrefix) && len(key) == (len(headerPrefix)+8+common.HashLength):
+		return catHeaders
+	case bytes.HasPrefix(key, blockBodyPrefix) && len(key) == (len(blockBodyPrefix)+8+common.HashLength):
+		return catBodies
+	case bytes.HasPrefix(key, blockReceiptsPrefix) && len(key) == (len(blockReceiptsPrefix)+8+common.HashLength):
+		return catReceipts
+	case bytes.HasPrefix(key, headerPrefix) && bytes.HasSuffix(key, headerTDSuffix) && len(key) == (len(headerPrefix)+8+common.HashLength+len(headerTDSuffix)):
+		return catTds
+	case bytes.HasPrefix(key, headerPrefix) && bytes.HasSuffix(key, headerHashSuffix) && len(key) == (len(headerPrefix)+8+len(headerHashSuffix)):
+		return catNumHashPairings
+	case bytes.HasPrefix(key, headerNumberPrefix) && len(key) == (len(headerNumberPrefix)+common.HashLength):
+		return catHashNumPairings
+	case bytes.HasPrefix(key, accessListPrefix) && len(key) == len(accessListPrefix)+8+common.HashLength:
+		return catBlockAccessList
+
+	case IsLegacyTrieNode(key, value):
+		return catLegacyTries
+	case bytes.HasPrefix(key, stateIDPrefix) && len(key) == len(stateIDPrefix)+common.HashLength:
+		return catStateLookups
+	case IsAccountTrieNode(key):
+		return catAccountTries
+	case IsStorageTrieNode(key):
+		return catStorageTries
+	case bytes.HasPrefix(key, CodePrefix) && len(key) == len(CodePrefix)+common.HashLength:
+		return catCodes
+	case bytes.HasPrefix(key, txLookupPrefix) && len(key) == (len(txLookupPrefix)+common.HashLength):
+		return catTxLookups
+	case bytes.HasPrefix(key, SnapshotAccountPrefix) && len(key) == (len(SnapshotAccountPrefix)+common.HashLength):
+		return catAccountSnaps
+	case bytes.HasPrefix(key, SnapshotStoragePrefix) && len(key) == (len(SnapshotStoragePrefix)+2*common.HashLength):
+		return catStorageSnaps
+	case bytes.HasPrefix(key, PreimagePrefix) && len(key) == (len(PreimagePrefix)+common.HashLength):
+		return catPreimages
+	case bytes.HasPrefix(key, configPrefix) && len(key) == (len(configPrefix)+common.HashLength):
+		return catMetadata
+	case bytes.HasPrefix(key, genesisPrefix) && len(key) == (len(genesisPrefix)+common.HashLength):
+		return catMetadata
+	case bytes.HasPrefix(key, skeletonHeaderPrefix) && len(key) == (len(skeletonHeaderPrefix)+8):
+		return catBeaconHeaders
+	case bytes.HasPrefix(key, CliqueSnapshotPrefix) && len(key) == 7+common.HashLength:
+		return catCliqueSnaps
+
+	// new log index
+	case bytes.HasPrefix(key, filterMapRowPrefix) && len(key) <= len(filterMapRowPrefix)+9:
+		return catFilterMapRows
+	case bytes.HasPrefix(key, filterMapLastBlockPrefix) && len(key) == len(filterMapLastBlockPrefix)+4:
+		return catFilterMapLastBlock
+	case bytes.HasPrefix(key, filterMapBlockLVPrefix) && len(key) == len(filterMapBlockLVPrefix)+8:
+		return catFilterMapBlockLV
+	case bytes.HasPrefix(key, persistentFilterPrefix):
+		return catLogFilters
+
+	// old log index (deprecated)
+	case bytes.HasPrefix(key, bloomBitsPrefix) && len(key) == (len(bloomBitsPrefix)+10+common.HashLength):
+		return catBloomBits
+	case bytes.HasPrefix(key, bloomBitsMetaPrefix) && len(key) < len(bloomBitsMetaPrefix)+8:
+		return catBloomBits
+
+	// Path-based historic state indexes
+	case bytes.HasPrefix(key, StateHistoryAccountMetadataPrefix) && len(key) == len(StateHistoryAccountMetadataPrefix)+common.HashLength:
+		return catStateIndex
+	case bytes.HasPrefix(key, StateHistoryStorageMetadataPrefix) && len(key) == len(StateHistoryStorageMetadataPrefix)+2*common.HashLength:
+		return catStateIndex
+	case bytes.HasPrefix(key, StateHistoryAccountBlockPrefix) && len(key) == len(StateHistoryAccountBlockPrefix)+common.HashLength+4:
+		return catStateIndex
+	case bytes.HasPrefix(key, StateHistoryStorageBlockPrefix) && len(key) == len(StateHistoryStorageBlockPrefix)+2*common.HashLength+4:
+		return catStateIndex
+
+	case bytes.HasPrefix(key, TrienodeHistoryMetadataPrefix) && len(key) >= len(TrienodeHistoryMetadataPrefix)+common.HashLength:
+		return catTrienodeIndex
+	case bytes.HasPrefix(key, TrienodeHistoryBlockPrefix) && len(key) >= len(TrienodeHistoryBlockPrefix)+common.HashLength+4:
+		return catTrienodeIndex
+
+	// Verkle trie data is detected, determine the sub-category
+	case bytes.HasPrefix(key, VerklePrefix):
+		remain := key[len(VerklePrefix):]
+		switch {
+		case IsAccountTrieNode(remain):
+			return catVerkleTries
+		case bytes.HasPrefix(remain, stateIDPrefix) && len(remain) == len(stateIDPrefix)+common.HashLength:
+			return catVerkleStateLookups
+		case bytes.Equal(remain, persistentStateIDKey):
+			return catMetadata
+		case bytes.Equal(remain, trieJournalKey):
+			return catMetadata
+		case bytes.Equal(remain, snapSyncStatusFlagKey):
+			return catMetadata
+		default:
+			return catUnaccounted
+		}
+
+	// Metadata keys
+	case slices.ContainsFunc(knownMetadataKeys, func(x []byte) bool { return bytes.Equal(x, key) }):
+		return catMetadata
+
+	default:
+		return catUnaccounted
+	}
+}
+
 // InspectDatabase traverses the entire database and checks the size
 // of all different categories of data.
-func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
+func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) (*DatabaseReport, error) {
 	var (
 		start = time.Now()
 		count atomic.Int64
 		total atomic.Uint64
 
 		// Key-value store statistics
-		headers            stat
-		bodies             stat
-		receipts           stat
-		tds                stat
-		numHashPairings    stat
-		hashNumPairings    stat
-		blockAccessList    stat
-		legacyTries        stat
-		stateLookups       stat
-		accountTries       stat
-		storageTries       stat
-		codes              stat
-		txLookups          stat
-		accountSnaps       stat
-		storageSnaps       stat
-		preimages          stat
-		beaconHeaders      stat
-		cliqueSnaps        stat
-		bloomBits          stat
-		filterMapRows      stat
-		filterMapLastBlock stat
-		filterMapBlockLV   stat
-
-		// Path-mode archive data
-		stateIndex    stat
-		trienodeIndex stat
-
-		// Verkle statistics
-		verkleTries        stat
-		verkleStateLookups stat
-
-		// Meta- and unaccounted data
-		metadata    stat
-		unaccounted stat
+		stats [numCategories]stat
 
 		// This map tracks example keys for unaccounted data.
 		// For each unique two-byte prefix, the first unaccounted key encountered
@@ -472,110 +640,16 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			total.Add(uint64(size))
 			count.Add(1)
 
-			switch {
-			case bytes.HasPrefix(key, headerPrefix) && len(key) == (len(headerPrefix)+8+common.HashLength):
-				headers.add(size)
-			case bytes.HasPrefix(key, blockBodyPrefix) && len(key) == (len(blockBodyPrefix)+8+common.HashLength):
-				bodies.add(size)
-			case bytes.HasPrefix(key, blockReceiptsPrefix) && len(key) == (len(blockReceiptsPrefix)+8+common.HashLength):
-				receipts.add(size)
-			case bytes.HasPrefix(key, headerPrefix) && bytes.HasSuffix(key, headerTDSuffix) && len(key) == (len(headerPrefix)+8+common.HashLength+len(headerTDSuffix)):
-				tds.add(size)
-			case bytes.HasPrefix(key, headerPrefix) && bytes.HasSuffix(key, headerHashSuffix) && len(key) == (len(headerPrefix)+8+len(headerHashSuffix)):
-				numHashPairings.add(size)
-			case bytes.HasPrefix(key, headerNumberPrefix) && len(key) == (len(headerNumberPrefix)+common.HashLength):
-				hashNumPairings.add(size)
-			case bytes.HasPrefix(key, accessListPrefix) && len(key) == len(accessListPrefix)+8+common.HashLength:
-				blockAccessList.add(size)
-
-			case IsLegacyTrieNode(key, it.Value()):
-				legacyTries.add(size)
-			case bytes.HasPrefix(key, stateIDPrefix) && len(key) == len(stateIDPrefix)+common.HashLength:
-				stateLookups.add(size)
-			case IsAccountTrieNode(key):
-				accountTries.add(size)
-			case IsStorageTrieNode(key):
-				storageTries.add(size)
-			case bytes.HasPrefix(key, CodePrefix) && len(key) == len(CodePrefix)+common.HashLength:
-				codes.add(size)
-			case bytes.HasPrefix(key, txLookupPrefix) && len(key) == (len(txLookupPrefix)+common.HashLength):
-				txLookups.add(size)
-			case bytes.HasPrefix(key, SnapshotAccountPrefix) && len(key) == (len(SnapshotAccountPrefix)+common.HashLength):
-				accountSnaps.add(size)
-			case bytes.HasPrefix(key, SnapshotStoragePrefix) && len(key) == (len(SnapshotStoragePrefix)+2*common.HashLength):
-				storageSnaps.add(size)
-			case bytes.HasPrefix(key, PreimagePrefix) && len(key) == (len(PreimagePrefix)+common.HashLength):
-				preimages.add(size)
-			case bytes.HasPrefix(key, configPrefix) && len(key) == (len(configPrefix)+common.HashLength):
-				metadata.add(size)
-			case bytes.HasPrefix(key, genesisPrefix) && len(key) == (len(genesisPrefix)+common.HashLength):
-				metadata.add(size)
-			case bytes.HasPrefix(key, skeletonHeaderPrefix) && len(key) == (len(skeletonHeaderPrefix)+8):
-				beaconHeaders.add(size)
-			case bytes.HasPrefix(key, CliqueSnapshotPrefix) && len(key) == 7+common.HashLength:
-				cliqueSnaps.add(size)
-
-			// new log index
-			case bytes.HasPrefix(key, filterMapRowPrefix) && len(key) <= len(filterMapRowPrefix)+9:
-				filterMapRows.add(size)
-			case bytes.HasPrefix(key, filterMapLastBlockPrefix) && len(key) == len(filterMapLastBlockPrefix)+4:
-				filterMapLastBlock.add(size)
-			case bytes.HasPrefix(key, filterMapBlockLVPrefix) && len(key) == len(filterMapBlockLVPrefix)+8:
-				filterMapBlockLV.add(size)
-
-			// old log index (deprecated)
-			case bytes.HasPrefix(key, bloomBitsPrefix) && len(key) == (len(bloomBitsPrefix)+10+common.HashLength):
-				bloomBits.add(size)
-			case bytes.HasPrefix(key, bloomBitsMetaPrefix) && len(key) < len(bloomBitsMetaPrefix)+8:
-				bloomBits.add(size)
-
-			// Path-based historic state indexes
-			case bytes.HasPrefix(key, StateHistoryAccountMetadataPrefix) && len(key) == len(StateHistoryAccountMetadataPrefix)+common.HashLength:
-				stateIndex.add(size)
-			case bytes.HasPrefix(key, StateHistoryStorageMetadataPrefix) && len(key) == len(StateHistoryStorageMetadataPrefix)+2*common.HashLength:
-				stateIndex.add(size)
-			case bytes.HasPrefix(key, StateHistoryAccountBlockPrefix) && len(key) == len(StateHistoryAccountBlockPrefix)+common.HashLength+4:
-				stateIndex.add(size)
-			case bytes.HasPrefix(key, StateHistoryStorageBlockPrefix) && len(key) == len(StateHistoryStorageBlockPrefix)+2*common.HashLength+4:
-				stateIndex.add(size)
-
-			case bytes.HasPrefix(key, TrienodeHistoryMetadataPrefix) && len(key) >= len(TrienodeHistoryMetadataPrefix)+common.HashLength:
-				trienodeIndex.add(size)
-			case bytes.HasPrefix(key, TrienodeHistoryBlockPrefix) && len(key) >= len(TrienodeHistoryBlockPrefix)+common.HashLength+4:
-				trienodeIndex.add(size)
-
-			// Verkle trie data is detected, determine the sub-category
-			case bytes.HasPrefix(key, VerklePrefix):
-				remain := key[len(VerklePrefix):]
-				switch {
-				case IsAccountTrieNode(remain):
-					verkleTries.add(size)
-				case bytes.HasPrefix(remain, stateIDPrefix) && len(remain) == len(stateIDPrefix)+common.HashLength:
-					verkleStateLookups.add(size)
-				case bytes.Equal(remain, persistentStateIDKey):
-					metadata.add(size)
-				case bytes.Equal(remain, trieJournalKey):
-					metadata.add(size)
-				case bytes.Equal(remain, snapSyncStatusFlagKey):
-					metadata.add(size)
-				default:
-					unaccounted.add(size)
-				}
+			category := classifyKey(key, it.Value())
+			stats[category].add(size)
 
-			// Metadata keys
-			case slices.ContainsFunc(knownMetadataKeys, func(x []byte) bool { return bytes.Equal(x, key) }):
-				metadata.add(size)
-
-			default:
-				unaccounted.add(size)
-				if len(key) >= 2 {
-					prefix := [2]byte(key[:2])
-					unaccountedMu.Lock()
-					if _, ok := unaccountedKeys[prefix]; !ok {
-						unaccountedKeys[prefix] = bytes.Clone(key)
-					}
-					unaccountedMu.Unlock()
+			if category == catUnaccounted && len(key) >= 2 {
+				prefix := [2]byte(key[:2])
+				unaccountedMu.Lock()
+				if _, ok := unaccountedKeys[prefix]; !ok {
+					unaccountedKeys[prefix] = bytes.Clone(key)
 				}
+				unaccountedMu.Unlock()
 			}
 
 			select {
@@ -617,71 +691,39 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 
 	if err := eg.Wait(); err != nil {
 		close(done)
-		return err
+		return nil, err
 	}
 	close(done)
 
-	// Display the database statistic of key-value store.
-	stats := [][]string{
-		{"Key-Value store", "Headers", headers.sizeString(), headers.countString()},
-		{"Key-Value store", "Bodies", bodies.sizeString(), bodies.countString()},
-		{"Key-Value store", "Receipt lists", receipts.sizeString(), receipts.countString()},
-		{"Key-Value store", "Difficulties (deprecated)", tds.sizeString(), tds.countString()},
-		{"Key-Value store", "Block number->hash", numHashPairings.sizeString(), numHashPairings.countString()},
-		{"Key-Value store", "Block hash->number", hashNumPairings.sizeString(), hashNumPairings.countString()},
-		{"Key-Value store", "Block accessList", blockAccessList.sizeString(), blockAccessList.countString()},
-		{"Key-Value store", "Transaction index", txLookups.sizeString(), txLookups.countString()},
-		{"Key-Value store", "Log index filter-map rows", filterMapRows.sizeString(), filterMapRows.countString()},
-		{"Key-Value store", "Log index last-block-of-map", filterMapLastBlock.sizeString(), filterMapLastBlock.countString()},
-		{"Key-Value store", "Log index block-lv", filterMapBlockLV.sizeString(), filterMapBlockLV.countString()},
-		{"Key-Value store", "Log bloombits (deprecated)", bloomBits.sizeString(), bloomBits.countString()},
-		{"Key-Value store", "Contract codes", codes.sizeString(), codes.countString()},
-		{"Key-Value store", "Hash trie nodes", legacyTries.sizeString(), legacyTries.countString()},
-		{"Key-Value store", "Path trie state lookups", stateLookups.sizeString(), stateLookups.countString()},
-		{"Key-Value store", "Path trie account nodes", accountTries.sizeString(), accountTries.countString()},
-		{"Key-Value store", "Path trie storage nodes", storageTries.sizeString(), storageTries.countString()},
-		{"Key-Value store", "Verkle trie nodes", verkleTries.sizeString(), verkleTries.countString()},
-		{"Key-Value store", "Verkle trie state lookups", verkleStateLookups.sizeString(), verkleStateLookups.countString()},
-		{"Key-Value store", "Trie preimages", preimages.sizeString(), preimages.countString()},
-		{"Key-Value store", "Account snapshot", accountSnaps.sizeString(), accountSnaps.countString()},
-		{"Key-Value store", "Storage snapshot", storageSnaps.sizeString(), storageSnaps.countString()},
-		{"Key-Value store", "Historical state index", stateIndex.sizeString(), stateIndex.countString()},
-		{"Key-Value store", "Historical trie index", trienodeIndex.sizeString(), trienodeIndex.countString()},
-		{"Key-Value store", "Beacon sync headers", beaconHeaders.sizeString(), beaconHeaders.countString()},
-		{"Key-Value store", "Clique snapshots", cliqueSnaps.sizeString(), cliqueSnaps.countString()},
-		{"Key-Value store", "Singleton metadata", metadata.sizeString(), metadata.countString()},
+	// Assemble the database statistic of key-value store.
+	report := &DatabaseReport{Time: time.Now(), Complete: true}
+	for i := range stats {
+		category := dbCategory(i)
+		if category == catUnaccounted && stats[i].empty() {
+			continue
+		}
+		report.Stats = append(report.Stats, DatabaseStat{
+			Database: kvStoreName,
+			Category: dbCategories[category].name,
+			Size:     atomic.LoadUint64(&stats[i].size),
+			Count:    atomic.LoadUint64(&stats[i].count),
+		})
 	}
 
 	// Inspect all registered append-only file store then.
 	ancients, err := inspectFreezers(db)
 	if err != nil {
-		return err
-	}
-	for _, ancient := range ancients {
-		for _, table := range ancient.tables {
-			stats = append(stats, []string{
-				fmt.Sprintf("Ancient store (%s)", strings.Title(ancient.name)),
-				strings.Title(table.name),
-				table.size.String(),
-				fmt.Sprintf("%d", table.count),
-			})
-		}
-		total.Add(uint64(ancient.size()))
+		return nil, err
 	}
+	report.Stats = append(report.Stats, ancientStats(ancients)...)
 
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Database", "Category", "Size", "Items"})
-	table.SetFooter([]string{"", "Total", common.StorageSize(total.Load()).String(), fmt.Sprintf("%d", count.Load())})
-	table.AppendBulk(stats)
-	table.Render()
-
-	if !unaccounted.empty() {
+	if unaccounted := &stats[catUnaccounted]; !unaccounted.empty() {
 		log.Error("Database contains unaccounted data", "size", unaccounted.sizeString(), "count", unaccounted.countString())
 		for _, e := range slices.SortedFunc(maps.Values(unaccountedKeys), bytes.Compare) {
 			log.Error(fmt.Sprintf("   example key: %x", e))
 		}
 	}
-	return nil
+	return report, nil
 }
 
 // This is the list of known 'metadata' keys stored in the databasse.
@@ -690,7 +732,7 @@ var knownMetadataKeys = [][]byte{
 	lastPivotKey, fastTrieProgressKey, snapshotDisabledKey, SnapshotRootKey, snapshotJournalKey,
 	snapshotGeneratorKey, snapshotRecoveryKey, txIndexTailKey, fastTxLookupLimitKey,
 	uncleanShutdownKey, badBlockKey, transitionStatusKey, skeletonSyncStatusKey,
-	persistentStateIDKey, trieJournalKey, snapshotSyncStatusKey, snapSyncStatusFlagKey,
+	persistentStateIDKey, trieJournalKey, stateHistoryImportKey, snapshotSyncStatusKey, snapSyncStatusFlagKey,
 	filterMapsRangeKey, headStateHistoryIndexKey, headTrienodeHistoryIndexKey, VerkleTransitionStatePrefix,
 }
 
@@ -0,0 +1,395 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/internal/tablewriter"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// kvStoreName is the database name of the key-value store statistics.
+const kvStoreName = "Key-Value store"
+
+// errCollectorStopped is returned if the statistics collector is stopped
+// during a scan.
+var errCollectorStopped = errors.New("statistics collector stopped")
+
+// statsChunkSize is the number of entries the statistics collector reads with
+// a single iterator. Iterators pin the database version they were opened on,
+// so long scans are split into chunks to let compactions reclaim space.
+var statsChunkSize = 100_000
+
+// DatabaseStat is the storage usage of a single category of data.
+type DatabaseStat struct {
+	Database string `json:"database"`
+	Category string `json:"category"`
+	Size     uint64 `json:"size"`
+	Count    uint64 `json:"count"`
+}
+
+// DatabaseReport is the storage usage of the database at a point in time,
+// broken down by categories of data.
+type DatabaseReport struct {
+	Time     time.Time      `json:"time"`
+	Complete bool           `json:"complete"` // Whether the entire database is accounted for
+	Stats    []DatabaseStat `json:"stats"`
+}
+
+// Total returns the total storage size of the database and the number of
+// entries in the key-value store.
+func (r *DatabaseReport) Total() (uint64, uint64) {
+	var size, count uint64
+	for _, stat := range r.Stats {
+		size += stat.Size
+		if stat.Database == kvStoreName {
+			count += stat.Count
+		}
+	}
+	return size, count
+}
+
+// Render writes the report as a table into the given writer.
+func (r *DatabaseReport) Render(w io.Writer) {
+	var rows [][]string
+	for _, stat := range r.Stats {
+		rows = append(rows, []string{stat.Database, stat.Category, common.StorageSize(stat.Size).String(), counter(stat.Count).String()})
+	}
+	size, count := r.Total()
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Database", "Category", "Size", "Items"})
+	table.SetFooter([]string{"", "Total", common.StorageSize(size).String(), counter(count).String()})
+	table.AppendBulk(rows)
+	table.Render()
+}
+
+// RenderReportDiff writes the changes of the storage usage between the two
+// reports as a table into the given writer.
+func RenderReportDiff(w io.Writer, old, new *DatabaseReport) {
+	type statKey struct{ database, category string }
+
+	var (
+		keys []statKey
+		olds = make(map[statKey]DatabaseStat)
+		news = make(map[statKey]DatabaseStat)
+	)
+	for _, stat := range new.Stats {
+		key := statKey{stat.Database, stat.Category}
+		news[key] = stat
+		keys = append(keys, key)
+	}
+	for _, stat := range old.Stats {
+		key := statKey{stat.Database, stat.Category}
+		olds[key] = stat
+		if _, ok := news[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	var rows [][]string
+	for _, key := range keys {
+		o, n := olds[key], news[key]
+		rows = append(rows, []string{
+			key.database, key.category,
+			common.StorageSize(o.Size).String(), common.StorageSize(n.Size).String(), sizeChange(o.Size, n.Size),
+			counter(o.Count).String(), counter(n.Count).String(), countChange(o.Count, n.Count),
+		})
+	}
+	oldSize, oldCount := old.Total()
+	newSize, newCount := new.Total()
+
+	fmt.Fprintf(w, "Comparing reports of %v and %v (%v)\n", old.Time.Format(time.RFC3339), new.Time.Format(time.RFC3339), common.PrettyDuration(new.Time.Sub(old.Time)))
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Database", "Category", "Old size", "New size", "Change", "Old items", "New items", "Change"})
+	table.SetFooter([]string{
+		"", "Total",
+		common.StorageSize(oldSize).String(), common.StorageSize(newSize).String(), sizeChange(oldSize, newSize),
+		counter(oldCount).String(), counter(newCount).String(), countChange(oldCount, newCount),
+	})
+	table.AppendBulk(rows)
+	table.Render()
+}
+
+// sizeChange formats the change between the two storage sizes.
+func sizeChange(old, new uint64) string {
+	if new >= old {
+		return "+" + common.StorageSize(new-old).String()
+	}
+	return "-" + common.StorageSize(old-new).String()
+}
+
+// countChange formats the change between the two item counts.
+func countChange(old, new uint64) string {
+	if new >= old {
+		return fmt.Sprintf("+%d", new-old)
+	}
+	return fmt.Sprintf("-%d", old-new)
+}
+
+// ancientStats converts the freezer information into database statistics.
+func ancientStats(infos []freezerInfo) []DatabaseStat {
+	var stats []DatabaseStat
+	for _, info := range infos {
+		tables := slices.Clone(info.tables)
+		slices.SortFunc(tables, func(a, b tableInfo) int { return strings.Compare(a.name, b.name) })
+
+		for _, table := range tables {
+			stats = append(stats, DatabaseStat{
+				Database: fmt.Sprintf("Ancient store (%s)", strings.Title(info.name)),
+				Category: strings.Title(table.name),
+				Size:     uint64(table.size),
+				Count:    table.count,
+			})
+		}
+	}
+	return stats
+}
+
+// StatsCollector maintains the storage usage of the database in the background.
+// Instead of walking the entire database at once, the key space is rescanned
+// one range of the leading key byte at a time, with pauses in between to limit
+// the resource usage. The statistics of each range are replaced once its scan
+// is finished, keeping the aggregated counters up to date.
+//
+// The counters are not updated on writes: tracking overwrites and deletions
+// would require reading the previous value of every written key, which is too
+// costly for the write path. The statistics of a key range therefore reflect
+// the time of its last scan and lag behind the database by up to a full scan
+// cycle plus the configured interval between cycles. This suits tracking
+// growth over days and weeks, but not short term changes.
+//
+// Ranges are read in chunks of statsChunkSize entries, reopening the iterator
+// between chunks, so the scan never holds on to an old database version. The
+// statistics of a range are thus not taken from a single snapshot.
+type StatsCollector struct {
+	db        ethdb.Database
+	namespace string
+	interval  time.Duration // Time to wait between two full scans
+
+	lock     sync.RWMutex
+	ranges   [256]*[numCategories]stat // Statistics of the key ranges, nil if not scanned yet
+	ancients []freezerInfo             // Statistics of the ancient stores
+	updated  time.Time                 // Time of the last statistics update
+	complete bool                      // Whether all the key ranges have been scanned
+
+	closeCh chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewStatsCollector creates a statistics collector for the given database,
+// publishing the counters as metrics under the given namespace. The interval
+// is the pause between two full scans of the database.
+func NewStatsCollector(db ethdb.Database, namespace string, interval time.Duration) *StatsCollector {
+	return &StatsCollector{
+		db:        db,
+		namespace: namespace,
+		interval:  interval,
+		closeCh:   make(chan struct{}),
+	}
+}
+
+// Start launches the background collection.
+func (c *StatsCollector) Start() {
+	c.wg.Add(1)
+	go c.loop()
+}
+
+// Stop terminates the background collection.
+func (c *StatsCollector) Stop() {
+	close(c.closeCh)
+	c.wg.Wait()
+}
+
+// Report returns the current storage usage of the database. The report is
+// incomplete until the first scan of the entire database is finished.
+func (c *StatsCollector) Report() *DatabaseReport {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	stats := c.aggregate()
+	report := &DatabaseReport{Time: c.updated, Complete: c.complete}
+	for i := range stats {
+		category := dbCategory(i)
+		if category == catUnaccounted && stats[i].count == 0 {
+			continue
+		}
+		report.Stats = append(report.Stats, DatabaseStat{
+			Database: kvStoreName,
+			Category: dbCategories[category].name,
+			Size:     stats[i].size,
+			Count:    stats[i].count,
+		})
+	}
+	report.Stats = append(report.Stats, ancientStats(c.ancients)...)
+	return report
+}
+
+// aggregate sums up the statistics of the scanned key ranges. It assumes
+// the lock is held.
+func (c *StatsCollector) aggregate() [numCategories]stat {
+	var stats [numCategories]stat
+	for _, r := range c.ranges {
+		if r == nil {
+			continue
+		}
+		for i := range r {
+			stats[i].size += r[i].size
+			stats[i].count += r[i].count
+		}
+	}
+	return stats
+}
+
+// loop scans the database periodically until the collector is stopped.
+func (c *StatsCollector) loop() {
+	defer c.wg.Done()
+
+	for {
+		start := time.Now()
+		if !c.scan(true) {
+			return
+		}
+		log.Debug("Refreshed database statistics", "elapsed", common.PrettyDuration(time.Since(start)))
+
+		select {
+		case <-time.After(c.interval):
+		case <-c.closeCh:
+			return
+		}
+	}
+}
+
+// scan walks the entire database range by range, updating the statistics.
+// If throttle is set, the collector pauses after each range as long as the
+// range took to scan. False is returned if the collector was stopped meanwhile.
+func (c *StatsCollector) scan(throttle bool) bool {
+	for i := 0; i < 256; i++ {
+		start := time.Now()
+		stats, err := c.scanRange(byte(i))
+		if errors.Is(err, errCollectorStopped) {
+			return false
+		}
+		if err != nil {
+			log.Warn("Failed to collect database statistics", "err", err)
+		} else {
+			c.lock.Lock()
+			c.ranges[i] = stats
+			c.updated = time.Now()
+			c.lock.Unlock()
+		}
+		if !throttle {
+			continue
+		}
+		select {
+		case <-time.After(time.Since(start)):
+		case <-c.closeCh:
+			return false
+		}
+	}
+	// The ancient stores only have to report their table sizes
+	infos, err := inspectFreezers(c.db)
+	if err != nil {
+		log.Warn("Failed to collect ancient store statistics", "err", err)
+	}
+	c.lock.Lock()
+	if err == nil {
+		c.ancients = infos
+	}
+	c.updated = time.Now()
+	c.complete = true
+	c.lock.Unlock()
+
+	c.publish()
+	return true
+}
+
+// scanRange collects the statistics of the entries with the given leading
+// key byte, one chunk at a time.
+func (c *StatsCollector) scanRange(r byte) (*[numCategories]stat, error) {
+	var (
+		stats [numCategories]stat
+		start []byte
+	)
+	for {
+		select {
+		case <-c.closeCh:
+			return nil, errCollectorStopped
+		default:
+		}
+		next, err := c.scanChunk(r, start, &stats)
+		if err != nil {
+			return nil, err
+		}
+		if next == nil {
+			return &stats, nil
+		}
+		start = next
+	}
+}
+
+// scanChunk adds the statistics of up to statsChunkSize entries of the key range,
+// starting at the given key suffix. It returns the suffix to continue from, or
+// nil if the end of the range is reached.
+func (c *StatsCollector) scanChunk(r byte, start []byte, stats *[numCategories]stat) ([]byte, error) {
+	it := c.db.NewIterator([]byte{r}, start)
+	defer it.Release()
+
+	for n := 0; it.Next(); n++ {
+		key, value := it.Key(), it.Value()
+		if n == statsChunkSize {
+			// Continue at this key with a fresh iterator.
+			return common.CopyBytes(key[1:]), nil
+		}
+		category := classifyKey(key, value)
+		stats[category].size += uint64(len(key) + len(value))
+		stats[category].count++
+	}
+	return nil, it.Error()
+}
+
+// publish updates the metrics with the current statistics.
+func (c *StatsCollector) publish() {
+	if !metrics.Enabled() {
+		return
+	}
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	stats := c.aggregate()
+	for i, category := range dbCategories {
+		name := c.namespace + "stats/" + category.metric
+		metrics.GetOrRegisterGauge(name+"/size", nil).Update(int64(stats[i].size))
+		metrics.GetOrRegisterGauge(name+"/count", nil).Update(int64(stats[i].count))
+	}
+	for _, info := range c.ancients {
+		for _, table := range info.tables {
+			name := fmt.Sprintf("%sstats/ancient/%s/%s", c.namespace, info.name, table.name)
+			metrics.GetOrRegisterGauge(name+"/size", nil).Update(int64(table.size))
+			metrics.GetOrRegisterGauge(name+"/count", nil).Update(int64(table.count))
+		}
+	}
+}
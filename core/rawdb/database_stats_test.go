@@ -0,0 +1,123 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+)
+
+// findStat returns the statistic of the given key-value store category.
+func findStat(report *DatabaseReport, category dbCategory) DatabaseStat {
+	for _, stat := range report.Stats {
+		if stat.Database == kvStoreName && stat.Category == dbCategories[category].name {
+			return stat
+		}
+	}
+	return DatabaseStat{}
+}
+
+func TestStatsCollector(t *testing.T) {
+	db, err := NewDatabaseWithFreezer(memorydb.New(), "", "", false)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	for i := 0; i < 10; i++ {
+		header := &types.Header{Number: common.Big1, Extra: []byte{byte(i)}}
+		WriteHeader(db, header)
+		WriteCode(db, common.Hash{byte(i)}, bytes.Repeat([]byte{byte(i)}, 100))
+	}
+	WriteDatabaseVersion(db, 9)
+	db.Put([]byte("unknown-key"), []byte("value"))
+
+	// Scan in small chunks, so the ranges are read with several iterators
+	defer func(size int) { statsChunkSize = size }(statsChunkSize)
+	statsChunkSize = 3
+
+	collector := NewStatsCollector(db, "test/", time.Hour)
+	if report := collector.Report(); report.Complete || findStat(report, catCodes).Count != 0 {
+		t.Fatal("Unexpected statistics before the scan")
+	}
+	if !collector.scan(false) {
+		t.Fatal("Scan was interrupted")
+	}
+	report := collector.Report()
+	if !report.Complete {
+		t.Fatal("Report is not complete after the scan")
+	}
+	for category, want := range map[dbCategory]uint64{
+		catHeaders:         10,
+		catHashNumPairings: 10,
+		catCodes:           10,
+		catMetadata:        1,
+		catUnaccounted:     1,
+	} {
+		if have := findStat(report, category).Count; have != want {
+			t.Errorf("Unexpected item count of %s, have %d, want %d", dbCategories[category].name, have, want)
+		}
+	}
+	// The incremental statistics must match the ones of a full inspection
+	inspected, err := InspectDatabase(db, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to inspect database: %v", err)
+	}
+	if !reflect.DeepEqual(report.Stats, inspected.Stats) {
+		t.Fatalf("Statistics mismatch\nhave: %v\nwant: %v", report.Stats, inspected.Stats)
+	}
+	// Deleted entries must be reflected after the next scan
+	DeleteCode(db, common.Hash{0})
+	collector.scan(false)
+	if have := findStat(collector.Report(), catCodes).Count; have != 9 {
+		t.Fatalf("Unexpected item count after deletion, have %d, want 9", have)
+	}
+}
+
+func TestRenderReportDiff(t *testing.T) {
+	var (
+		old = &DatabaseReport{
+			Time: time.Unix(0, 0),
+			Stats: []DatabaseStat{
+				{Database: kvStoreName, Category: "Headers", Size: 2048, Count: 2},
+				{Database: kvStoreName, Category: "Bodies", Size: 1024, Count: 1},
+			},
+		}
+		new = &DatabaseReport{
+			Time: time.Unix(86400, 0),
+			Stats: []DatabaseStat{
+				{Database: kvStoreName, Category: "Headers", Size: 3072, Count: 3},
+				{Database: kvStoreName, Category: "Codes", Size: 512, Count: 4},
+			},
+		}
+		out strings.Builder
+	)
+	RenderReportDiff(&out, old, new)
+
+	for _, want := range []string{"+1.00 KiB", "-1.00 KiB", "+512.00 B", "+1", "-1", "+4", "+4"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Missing %q in the diff:\n%s", want, out.String())
+		}
+	}
+}
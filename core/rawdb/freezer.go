@@ -363,6 +363,33 @@ func (f *Freezer) SyncAncient() error {
 	return nil
 }
 
+// Checkpoint creates a consistent copy of the freezer in the given directory,
+// which must not exist yet. Writes are blocked while the copy is created. The
+// sealed data files are hard-linked rather than copied. Both freezers stay
+// independent regardless: a data file is replaced by a truncated copy before
+// the head is truncated into it (in truncateHead and repair), and truncating
+// the tail only removes links, so neither side modifies a shared file.
+func (f *Freezer) Checkpoint(dir string) error {
+	f.writeLock.Lock()
+	defer f.writeLock.Unlock()
+
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		if err == nil {
+			return fmt.Errorf("checkpoint directory %s already exists", dir)
+		}
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, table := range f.tables {
+		if err := table.checkpoint(dir); err != nil {
+			return err
+		}
+	}
+	return syncDir(dir)
+}
+
 // validate checks that every table has the same head and that tables sharing
 // a tail group also share a tail. Used instead of `repair` in readonly mode.
 func (f *Freezer) validate() error {
@@ -411,6 +438,14 @@ func (f *Freezer) validate() error {
 	}
 	f.head.Store(head)
 
+	// Groups consisting of empty tables only have no tail yet
+	for _, table := range f.tables {
+		if group := table.config.tailGroup; group != "" {
+			if _, ok := tails[group]; !ok {
+				tails[group] = 0
+			}
+		}
+	}
 	for group, tail := range tails {
 		counter := new(atomic.Uint64)
 		counter.Store(tail)
@@ -210,6 +210,14 @@ func (f *resettableFreezer) AncientDatadir() (string, error) {
 	return f.freezer.AncientDatadir()
 }
 
+// Checkpoint creates a consistent copy of the freezer in the given directory.
+func (f *resettableFreezer) Checkpoint(dir string) error {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return f.freezer.Checkpoint(dir)
+}
+
 // cleanup removes the directory located in the specified path
 // has the name with deletion marker suffix.
 func cleanup(path string) error {
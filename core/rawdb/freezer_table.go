@@ -341,8 +341,12 @@ func (t *freezerTable) repair() error {
 			}
 			// We might have slipped back into an earlier head-file here
 			if newLastIndex.filenum != lastIndex.filenum {
-				// Release earlier opened file
+				// Release earlier opened file. The earlier file may be hard-linked
+				// into a checkpoint, break the link before it's truncated.
 				t.releaseFile(lastIndex.filenum)
+				if err := copyFrom(filepath.Join(t.path, t.fileName(newLastIndex.filenum)), filepath.Join(t.path, t.fileName(newLastIndex.filenum)), 0, nil); err != nil {
+					return err
+				}
 				if t.head, err = t.openFile(newLastIndex.filenum, openFreezerFileForAppend); err != nil {
 					return err
 				}
@@ -659,8 +663,13 @@ func (t *freezerTable) truncateHead(items uint64) error {
 	}
 	// We might need to truncate back to older files
 	if expected.filenum != t.headId {
-		// If already open for reading, force-reopen for writing
+		// If already open for reading, force-reopen for writing. The older file
+		// may be hard-linked into a checkpoint, so it's replaced by a truncated
+		// copy instead of being truncated in place.
 		t.releaseFile(expected.filenum)
+		if err := truncateCopy(filepath.Join(t.path, t.fileName(expected.filenum)), int64(expected.offset)); err != nil {
+			return err
+		}
 		newHead, err := t.openFile(expected.filenum, openFreezerFileForAppend)
 		if err != nil {
 			return err
@@ -928,13 +937,7 @@ func (t *freezerTable) Close() error {
 func (t *freezerTable) openFile(num uint32, opener func(string) (*os.File, error)) (f *os.File, err error) {
 	var exist bool
 	if f, exist = t.files[num]; !exist {
-		var name string
-		if t.config.noSnappy {
-			name = fmt.Sprintf("%s.%04d.rdat", t.name, num)
-		} else {
-			name = fmt.Sprintf("%s.%04d.cdat", t.name, num)
-		}
-		f, err = opener(filepath.Join(t.path, name))
+		f, err = opener(filepath.Join(t.path, t.fileName(num)))
 		if err != nil {
 			return nil, err
 		}
@@ -943,6 +946,53 @@ func (t *freezerTable) openFile(num uint32, opener func(string) (*os.File, error
 	return f, err
 }
 
+// fileName returns the name of the data file with the given number.
+func (t *freezerTable) fileName(num uint32) string {
+	if t.config.noSnappy {
+		return fmt.Sprintf("%s.%04d.rdat", t.name, num)
+	}
+	return fmt.Sprintf("%s.%04d.cdat", t.name, num)
+}
+
+// checkpoint creates a copy of the table in the given directory. The data files
+// before the head one are never modified in place again, so they are hard-linked,
+// while the head data file, the index and the metadata are copied. Truncating
+// the head below them replaces the file by a truncated copy, which leaves the
+// linked file of the checkpoint intact.
+func (t *freezerTable) checkpoint(dir string) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil || t.head == nil || t.metadata.file == nil {
+		return errClosed
+	}
+	// Flush the pending writes, ensuring the copied files are consistent.
+	if err := t.doSync(); err != nil {
+		return err
+	}
+	for _, f := range []*os.File{t.index, t.metadata.file} {
+		if err := copyFrom(f.Name(), filepath.Join(dir, filepath.Base(f.Name())), 0, nil); err != nil {
+			return err
+		}
+	}
+	for num := t.tailId; num <= t.headId; num++ {
+		var (
+			src = filepath.Join(t.path, t.fileName(num))
+			dst = filepath.Join(dir, t.fileName(num))
+		)
+		if num == t.headId {
+			if err := copyFrom(src, dst, 0, nil); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := linkFile(src, dst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // releaseFile closes a file, and removes it from the open file cache.
 // Assumes that the caller holds the write lock
 func (t *freezerTable) releaseFile(num uint32) {
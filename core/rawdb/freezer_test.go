@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"math/big"
 	"math/rand"
+	"path/filepath"
 	"sync"
 	"testing"
 
@@ -282,6 +283,28 @@ func TestFreezerReadonlyValidate(t *testing.T) {
 	}
 }
 
+func TestFreezerReadonlyEmptyTail(t *testing.T) {
+	tables := map[string]freezerTableConfig{"a": {noSnappy: true, tailGroup: "test"}}
+	dir := t.TempDir()
+
+	f, err := NewFreezer(dir, "", false, 2049, tables)
+	if err != nil {
+		t.Fatal("can't open freezer", err)
+	}
+	require.NoError(t, f.Close())
+
+	// The tail of the empty tables must be available in readonly mode
+	f, err = NewFreezer(dir, "", true, 2049, tables)
+	if err != nil {
+		t.Fatal("can't open readonly freezer", err)
+	}
+	defer f.Close()
+
+	tail, err := f.Tail("test")
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), tail)
+}
+
 func TestFreezerConcurrentReadonly(t *testing.T) {
 	t.Parallel()
 
@@ -544,3 +567,88 @@ func TestFreezerSuite(t *testing.T) {
 		return f
 	})
 }
+
+func TestFreezerCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	tables := map[string]freezerTableConfig{
+		"raw":  {noSnappy: true, tailGroup: "test"},
+		"comp": {noSnappy: false, tailGroup: "test"},
+	}
+	f, _ := newFreezerForTesting(t, tables)
+	defer f.Close()
+
+	write := func(from, to int, fill int) {
+		t.Helper()
+		_, err := f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+			for i := from; i < to; i++ {
+				if err := op.AppendRaw("raw", uint64(i), getChunk(256, i+fill)); err != nil {
+					return err
+				}
+				if err := op.AppendRaw("comp", uint64(i), getChunk(256, i+fill)); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal("ModifyAncients failed:", err)
+		}
+	}
+	write(0, 100, 0)
+	if _, err := f.TruncateTail("test", 20); err != nil {
+		t.Fatal("TruncateTail failed:", err)
+	}
+	cpdir := filepath.Join(t.TempDir(), "checkpoint")
+	if err := f.Checkpoint(cpdir); err != nil {
+		t.Fatal("Checkpoint failed:", err)
+	}
+	if err := f.Checkpoint(cpdir); err == nil {
+		t.Fatal("Expected error for existing checkpoint directory")
+	}
+	// Mutate the freezer after the checkpoint, truncating the head back into the
+	// sealed files shared with the checkpoint and writing new items.
+	write(100, 120, 0)
+	if _, err := f.TruncateHead(30); err != nil {
+		t.Fatal("TruncateHead failed:", err)
+	}
+	write(30, 120, 1)
+
+	cp, err := NewFreezer(cpdir, "", false, 2049, tables)
+	if err != nil {
+		t.Fatal("Failed to open checkpoint:", err)
+	}
+	defer cp.Close()
+
+	checkAncientCount(t, cp, "raw", 100)
+	if tail, _ := cp.Tail("test"); tail != 20 {
+		t.Fatalf("Unexpected tail, have %d, want 20", tail)
+	}
+	for i := 20; i < 100; i++ {
+		for _, kind := range []string{"raw", "comp"} {
+			blob, err := cp.Ancient(kind, uint64(i))
+			if err != nil {
+				t.Fatalf("Failed to read %s item %d: %v", kind, i, err)
+			}
+			if !bytes.Equal(blob, getChunk(256, i)) {
+				t.Fatalf("Unexpected %s item %d", kind, i)
+			}
+		}
+	}
+	// Appending to the checkpoint must leave the original freezer untouched.
+	_, err = cp.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		if err := op.AppendRaw("raw", 100, getChunk(256, 0)); err != nil {
+			return err
+		}
+		return op.AppendRaw("comp", 100, getChunk(256, 0))
+	})
+	if err != nil {
+		t.Fatal("ModifyAncients on checkpoint failed:", err)
+	}
+	for i := 100; i < 120; i++ {
+		blob, err := f.Ancient("raw", uint64(i))
+		if err != nil || !bytes.Equal(blob, getChunk(256, i+1)) {
+			t.Fatalf("Original item %d corrupted, err: %v", i, err)
+		}
+	}
+}
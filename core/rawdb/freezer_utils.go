@@ -89,6 +89,53 @@ func copyFrom(srcPath, destPath string, offset uint64, before func(f *os.File) e
 	return atomicRename(fname, destPath)
 }
 
+// truncateCopy replaces the file at the given path with a copy of its first
+// size bytes. Unlike truncating the file in place, this leaves other hard links
+// of the file, i.e. the ones in checkpoints, untouched.
+func truncateCopy(path string, size int64) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "*")
+	if err != nil {
+		return err
+	}
+	fname := f.Name()
+
+	// Clean up the leftover file
+	defer func() {
+		if f != nil {
+			f.Close()
+		}
+		os.Remove(fname)
+	}()
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	_, err = io.CopyN(f, src, size)
+	src.Close()
+	if err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	f = nil
+
+	return atomicRename(fname, path)
+}
+
+// linkFile creates a hard link of the source file at the destination path,
+// falling back to copying the file if hard links are not supported, e.g. the
+// paths are on different file systems.
+func linkFile(src, dest string) error {
+	if err := os.Link(src, dest); err == nil {
+		return nil
+	}
+	return copyFrom(src, dest, 0, nil)
+}
+
 // reset atomically replaces the file at the given path with the provided content.
 func reset(path string, content []byte) error {
 	// Create a temp file in the same dir where we want it to wind up
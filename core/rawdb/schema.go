@@ -76,6 +76,10 @@ var (
 	// trieJournalKey tracks the in-memory trie node layers across restarts.
 	trieJournalKey = []byte("TrieJournal")
 
+	// stateHistoryImportKey tracks the state history import in progress, which
+	// must be finished at startup if it was interrupted.
+	stateHistoryImportKey = []byte("StateHistoryImport")
+
 	// headStateHistoryIndexKey tracks the ID of the latest state history that has
 	// been indexed.
 	headStateHistoryIndexKey = []byte("LastStateHistoryIndex")
@@ -159,6 +163,9 @@ var (
 	filterMapLastBlockPrefix = []byte(filterMapsPrefix + "b") // filterMapLastBlockPrefix + mapIndex (uint32 big endian) -> block number (uint64 big endian)
 	filterMapBlockLVPrefix   = []byte(filterMapsPrefix + "p") // filterMapBlockLVPrefix + num (uint64 big endian) -> log value pointer (uint64 big endian)
 
+	// persistent log filters
+	persistentFilterPrefix = []byte("filter-") // persistentFilterPrefix + filter id -> persisted log filter
+
 	// old log index
 	bloomBitsMetaPrefix = []byte("iB")
 
@@ -389,6 +396,11 @@ func filterMapBlockLVKey(number uint64) []byte {
 	return key
 }
 
+// persistentFilterKey = persistentFilterPrefix + filter id
+func persistentFilterKey(id string) []byte {
+	return append(bytes.Clone(persistentFilterPrefix), id...)
+}
+
 // accountHistoryIndexKey = StateHistoryAccountMetadataPrefix + addressHash
 func accountHistoryIndexKey(addressHash common.Hash) []byte {
 	return append(StateHistoryAccountMetadataPrefix, addressHash.Bytes()...)
@@ -261,11 +261,12 @@ func (db *HistoricDB) Reader(stateRoot common.Hash) (Reader, error) {
 	return newReader(db.codedb.Reader(), combined), nil
 }
 
-// OpenTrie opens the main account trie. It's not supported by historic database.
+// OpenTrie opens the main account trie of a historical state. It's only
+// available for the states covered by the trienode history.
 func (db *HistoricDB) OpenTrie(root common.Hash) (Trie, error) {
 	nr, err := db.triedb.HistoricNodeReader(root)
 	if err != nil {
-		return nil, err
+		return nil, db.trieUnavailable(root, err)
 	}
 	tr, err := trie.NewStateTrie(trie.StateTrieID(root), newHistoricTrieOpener(root, nr))
 	if err != nil {
@@ -274,12 +275,12 @@ func (db *HistoricDB) OpenTrie(root common.Hash) (Trie, error) {
 	return tr, nil
 }
 
-// OpenStorageTrie opens the storage trie of an account. It's not supported by
-// historic database.
+// OpenStorageTrie opens the storage trie of an account in a historical state.
+// It's only available for the states covered by the trienode history.
 func (db *HistoricDB) OpenStorageTrie(stateRoot common.Hash, address common.Address, root common.Hash, _ Trie) (Trie, error) {
 	nr, err := db.triedb.HistoricNodeReader(stateRoot)
 	if err != nil {
-		return nil, err
+		return nil, db.trieUnavailable(stateRoot, err)
 	}
 	id := trie.StorageTrieID(stateRoot, crypto.Keccak256Hash(address.Bytes()), root)
 	tr, err := trie.NewStateTrie(id, newHistoricTrieOpener(stateRoot, nr))
@@ -289,6 +290,21 @@ func (db *HistoricDB) OpenStorageTrie(stateRoot common.Hash, address common.Addr
 	return tr, nil
 }
 
+// trieUnavailable annotates the failure of opening a historical trie with the
+// range of states covered by the trienode history.
+func (db *HistoricDB) trieUnavailable(root common.Hash, err error) error {
+	first, last, rerr := db.triedb.TrienodeHistoryRange()
+	if rerr != nil {
+		return fmt.Errorf("historical trie %x is not available: %w (%v)", root, err, rerr)
+	}
+	// The trienode history of block N describes the transition from the state
+	// of block N-1, which is the oldest state it can reconstruct.
+	if first > 0 {
+		first--
+	}
+	return fmt.Errorf("historical trie %x is not available, trienode history covers blocks %d-%d: %w", root, first, last, err)
+}
+
 // TrieDB returns the underlying trie database for managing trie nodes.
 func (db *HistoricDB) TrieDB() *triedb.Database {
 	return db.triedb
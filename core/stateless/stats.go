@@ -27,6 +27,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie"
 )
 
@@ -114,3 +115,50 @@ func (s *WitnessStats) ReportMetrics(blockNumber uint64) {
 		storageTrieLeavesAtDepth[i].Inc(storageTrieLeaves[i])
 	}
 }
+
+// WitnessSize is the breakdown of the size of a witness by its contents.
+type WitnessSize struct {
+	Headers     int // Number of headers included
+	HeaderBytes int // Size of the RLP-encoded headers
+	Codes       int // Number of bytecodes included
+	CodeBytes   int // Size of the bytecodes
+	States      int // Number of trie nodes included
+	StateBytes  int // Size of the trie nodes
+}
+
+// Total returns the total size of the witness contents.
+func (s WitnessSize) Total() int {
+	return s.HeaderBytes + s.CodeBytes + s.StateBytes
+}
+
+// Add accumulates the sizes of another witness.
+func (s *WitnessSize) Add(other WitnessSize) {
+	s.Headers += other.Headers
+	s.HeaderBytes += other.HeaderBytes
+	s.Codes += other.Codes
+	s.CodeBytes += other.CodeBytes
+	s.States += other.States
+	s.StateBytes += other.StateBytes
+}
+
+// Size computes the size breakdown of the witness.
+func (w *Witness) Size() WitnessSize {
+	var size WitnessSize
+	for _, header := range w.Headers {
+		blob, err := rlp.EncodeToBytes(header)
+		if err != nil {
+			continue
+		}
+		size.Headers++
+		size.HeaderBytes += len(blob)
+	}
+	for code := range w.Codes {
+		size.Codes++
+		size.CodeBytes += len(code)
+	}
+	for node := range w.State {
+		size.States++
+		size.StateBytes += len(node)
+	}
+	return size
+}
@@ -21,6 +21,8 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 func expectedLeaves(counts map[int]int64) [16]int64 {
@@ -253,3 +255,28 @@ func BenchmarkWitnessStatsAdd(b *testing.B) {
 		stats.Add(nodes, common.Hash{})
 	}
 }
+
+func TestWitnessSize(t *testing.T) {
+	header := &types.Header{Number: common.Big1}
+	blob, _ := rlp.EncodeToBytes(header)
+
+	w := &Witness{
+		Headers: []*types.Header{header},
+		Codes:   map[string]struct{}{"code": {}, "longer code": {}},
+		State:   map[string]struct{}{"node": {}},
+	}
+	want := WitnessSize{
+		Headers:     1,
+		HeaderBytes: len(blob),
+		Codes:       2,
+		CodeBytes:   15,
+		States:      1,
+		StateBytes:  4,
+	}
+	if have := w.Size(); have != want {
+		t.Fatalf("Unexpected witness size, have %+v, want %+v", have, want)
+	}
+	if have := want.Total(); have != len(blob)+19 {
+		t.Fatalf("Unexpected total size, have %d, want %d", have, len(blob)+19)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/core"
@@ -141,3 +142,26 @@ func (api *AdminAPI) ImportChain(file string) (bool, error) {
 	}
 	return true, nil
 }
+
+// DbCheckpoint creates a consistent copy of the chain database, including the
+// ancient store and the in-memory state layers, while the node keeps running.
+// The given directory is laid out as a data directory, which can be used to
+// start another node from the checkpointed chain head.
+func (api *AdminAPI) DbCheckpoint(dir string) (bool, error) {
+	if _, err := os.Stat(dir); err == nil {
+		// Directory already exists. Allowing overwrite could be a DoS vector,
+		// since the 'dir' may point to arbitrary paths on the drive.
+		return false, errors.New("location would overwrite an existing directory")
+	}
+	var (
+		chainData   = filepath.Join(dir, api.eth.chainDataPath)
+		trieJournal = filepath.Join(dir, api.eth.trieJournalPath)
+	)
+	if err := os.MkdirAll(filepath.Dir(chainData), 0755); err != nil {
+		return false, err
+	}
+	if err := api.eth.BlockChain().Checkpoint(chainData, trieJournal); err != nil {
+		return false, err
+	}
+	return true, nil
+}
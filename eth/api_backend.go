@@ -281,16 +281,24 @@ func (b *EthAPIBackend) HistoryPruningCutoff() uint64 {
 }
 
 func (b *EthAPIBackend) HistoryRetention() ethapi.HistoryRetention {
-	cfg := b.eth.config
-	return ethapi.HistoryRetention{
-		TxIndexHistory:   cfg.TransactionHistory,
-		LogIndexHistory:  cfg.LogHistory,
-		LogIndexDisabled: cfg.LogNoHistory,
-		StateHistory:     cfg.StateHistory,
-		TrienodeHistory:  cfg.TrienodeHistory,
-		StateArchive:     cfg.NoPruning,
-		StateScheme:      b.eth.blockchain.TrieDB().Scheme(),
+	var (
+		cfg       = b.eth.config
+		triedb    = b.eth.blockchain.TrieDB()
+		retention = ethapi.HistoryRetention{
+			TxIndexHistory:   cfg.TransactionHistory,
+			LogIndexHistory:  cfg.LogHistory,
+			LogIndexDisabled: cfg.LogNoHistory,
+			StateHistory:     cfg.StateHistory,
+			TrienodeHistory:  cfg.TrienodeHistory,
+			StateArchive:     cfg.NoPruning,
+			StateScheme:      triedb.Scheme(),
+		}
+	)
+	// The state history limits can be adjusted at runtime.
+	if state, trienode, err := triedb.HistoryLimits(); err == nil {
+		retention.StateHistory, retention.TrienodeHistory = state, trienode
 	}
+	return retention
 }
 
 func (b *EthAPIBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
@@ -330,6 +338,14 @@ func (b *EthAPIBackend) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) e
 	return b.eth.BlockChain().SubscribeChainHeadEvent(ch)
 }
 
+func (b *EthAPIBackend) SubscribeFinalizedHeadEvent(ch chan<- core.FinalizedHeadEvent) event.Subscription {
+	return b.eth.BlockChain().SubscribeFinalizedHeadEvent(ch)
+}
+
+func (b *EthAPIBackend) SubscribeSafeHeadEvent(ch chan<- core.SafeHeadEvent) event.Subscription {
+	return b.eth.BlockChain().SubscribeSafeHeadEvent(ch)
+}
+
 // SubscribeNewPayloadEvent registers a subscription for NewPayloadEvent.
 func (b *EthAPIBackend) SubscribeNewPayloadEvent(ch chan<- core.NewPayloadEvent) event.Subscription {
 	return b.eth.BlockChain().SubscribeNewPayloadEvent(ch)
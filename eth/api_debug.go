@@ -35,6 +35,7 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/triedb/pathdb"
 )
 
 // DebugAPI is the collection of Ethereum full node APIs for debugging the
@@ -448,6 +449,72 @@ func (api *DebugAPI) GetTrieFlushInterval() (string, error) {
 	return api.eth.blockchain.GetTrieFlushInterval().String(), nil
 }
 
+// SetStateHistoryLimit configures the number of recent blocks for which the
+// state histories are retained, 0 means the entire chain. If the window is
+// narrowed, the excess histories are truncated in the background; the progress
+// can be tracked via debug_getHistoryPruneProgress.
+func (api *DebugAPI) SetStateHistoryLimit(limit hexutil.Uint64) error {
+	triedb := api.eth.blockchain.TrieDB()
+	if triedb.Scheme() != rawdb.PathScheme {
+		return errors.New("state history is only available for path-based scheme")
+	}
+	return triedb.SetStateHistoryLimit(uint64(limit))
+}
+
+// SetTrienodeHistoryLimit configures the number of recent blocks for which the
+// trienode histories are retained, 0 means the entire chain. The trienode
+// history must have been enabled at startup.
+func (api *DebugAPI) SetTrienodeHistoryLimit(limit hexutil.Uint64) error {
+	triedb := api.eth.blockchain.TrieDB()
+	if triedb.Scheme() != rawdb.PathScheme {
+		return errors.New("trienode history is only available for path-based scheme")
+	}
+	return triedb.SetTrienodeHistoryLimit(uint64(limit))
+}
+
+// HistoryPruneProgress describes the retention window of a history type along
+// with the progress of removing the histories falling out of it.
+type HistoryPruneProgress struct {
+	Limit        hexutil.Uint64 `json:"limit"`
+	Histories    hexutil.Uint64 `json:"histories"`
+	Remaining    hexutil.Uint64 `json:"remaining"`
+	Truncating   bool           `json:"truncating"`
+	IndexPruning bool           `json:"indexPruning"`
+}
+
+// HistoryPruneProgressResult is the result of debug_getHistoryPruneProgress,
+// the trienode part is omitted if the trienode history is not enabled.
+type HistoryPruneProgressResult struct {
+	State    *HistoryPruneProgress `json:"state"`
+	Trienode *HistoryPruneProgress `json:"trienode,omitempty"`
+}
+
+// GetHistoryPruneProgress returns the retention windows of the state and
+// trienode histories, along with the progress of truncating the excess ones.
+func (api *DebugAPI) GetHistoryPruneProgress() (*HistoryPruneProgressResult, error) {
+	triedb := api.eth.blockchain.TrieDB()
+	if triedb.Scheme() != rawdb.PathScheme {
+		return nil, errors.New("state history is only available for path-based scheme")
+	}
+	state, trienode, err := triedb.HistoryPruneProgress()
+	if err != nil {
+		return nil, err
+	}
+	convert := func(p *pathdb.HistoryPruneProgress) *HistoryPruneProgress {
+		if p == nil {
+			return nil
+		}
+		return &HistoryPruneProgress{
+			Limit:        hexutil.Uint64(p.Limit),
+			Histories:    hexutil.Uint64(p.Histories),
+			Remaining:    hexutil.Uint64(p.Remaining),
+			Truncating:   p.Truncating,
+			IndexPruning: p.IndexPruning,
+		}
+	}
+	return &HistoryPruneProgressResult{State: convert(state), Trienode: convert(trienode)}, nil
+}
+
 // StateSize returns the current state size statistics from the state size tracker.
 // Returns an error if the state size tracker is not initialized or if stats are not ready.
 func (api *DebugAPI) StateSize(blockHashOrNumber *rpc.BlockNumberOrHash) (interface{}, error) {
@@ -529,3 +596,15 @@ func (api *DebugAPI) ClearTxpool() error {
 	api.eth.TxPool().Clear()
 	return nil
 }
+
+// DbStats returns the storage usage of the chain database, broken down by
+// categories of data. The statistics are maintained in the background by
+// periodic rescans if the collector is enabled, so they may lag behind recent
+// writes by hours. The report is incomplete until the first scan of the entire
+// database is finished.
+func (api *DebugAPI) DbStats() (*rawdb.DatabaseReport, error) {
+	if api.eth.dbStats == nil {
+		return nil, errors.New("database statistics are not collected, enable with --db.stats")
+	}
+	return api.eth.dbStats.Report(), nil
+}
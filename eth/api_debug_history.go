@@ -0,0 +1,431 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/holiman/uint256"
+)
+
+const (
+	defaultStateHistoryResults = 100  // Number of changes returned if no limit is given
+	maxStateHistoryResults     = 1024 // Maximum number of changes returned at once
+
+	defaultStateDiffResults = 256     // Number of changed states returned if no limit is given
+	maxStateDiffResults     = 4096    // Maximum number of changed states returned at once
+	maxStateDiffBlocks      = 8192    // Maximum number of blocks a state diff can span
+	maxStateDiffSize        = 1 << 20 // Maximum number of states a state diff can touch
+)
+
+// HistoricalAccount is the account content before or after a state change.
+type HistoricalAccount struct {
+	Nonce       hexutil.Uint64 `json:"nonce"`
+	Balance     *hexutil.U256  `json:"balance"`
+	CodeHash    common.Hash    `json:"codeHash"`
+	StorageRoot common.Hash    `json:"storageRoot"`
+}
+
+// AccountChange is a mutation of an account made by a block. A nil value
+// means the account didn't exist.
+type AccountChange struct {
+	Block hexutil.Uint64     `json:"block"`
+	Prev  *HistoricalAccount `json:"prev"`
+	Post  *HistoricalAccount `json:"post"`
+}
+
+// AccountHistoryResult is the result of debug_getAccountHistory. If more
+// changes are available, Next is the block to continue the query from.
+type AccountHistoryResult struct {
+	Changes []AccountChange `json:"changes"`
+	Next    *hexutil.Uint64 `json:"next,omitempty"`
+}
+
+// StorageChange is a mutation of a storage slot made by a block.
+type StorageChange struct {
+	Block hexutil.Uint64 `json:"block"`
+	Prev  common.Hash    `json:"prev"`
+	Post  common.Hash    `json:"post"`
+}
+
+// StorageHistoryResult is the result of debug_getStorageHistory. If more
+// changes are available, Next is the block to continue the query from.
+type StorageHistoryResult struct {
+	Changes []StorageChange `json:"changes"`
+	Next    *hexutil.Uint64 `json:"next,omitempty"`
+}
+
+// GetAccountHistory returns the changes made to the given account by the
+// canonical blocks within the range [from, to]. At most limit changes are
+// returned; the query can be continued from the block reported as next.
+//
+// The changes are resolved from the state history index, which is only
+// maintained by path-based archive nodes.
+func (api *DebugAPI) GetAccountHistory(ctx context.Context, address common.Address, from, to rpc.BlockNumber, limit *hexutil.Uint64) (*AccountHistoryResult, error) {
+	root, first, last, n, err := api.stateHistoryQuery(ctx, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := api.eth.blockchain.TrieDB().AccountChanges(root, address, first, last, n+1)
+	if err != nil {
+		return nil, err
+	}
+	result := &AccountHistoryResult{Changes: []AccountChange{}}
+	for i, change := range changes {
+		if i == n {
+			next := hexutil.Uint64(change.Block)
+			result.Next = &next
+			break
+		}
+		prev, err := decodeHistoricalAccount(change.Prev)
+		if err != nil {
+			return nil, err
+		}
+		post, err := decodeHistoricalAccount(change.Post)
+		if err != nil {
+			return nil, err
+		}
+		result.Changes = append(result.Changes, AccountChange{Block: hexutil.Uint64(change.Block), Prev: prev, Post: post})
+	}
+	return result, nil
+}
+
+// GetStorageHistory returns the changes made to the given storage slot by the
+// canonical blocks within the range [from, to]. At most limit changes are
+// returned; the query can be continued from the block reported as next.
+//
+// The changes are resolved from the state history index, which is only
+// maintained by path-based archive nodes.
+func (api *DebugAPI) GetStorageHistory(ctx context.Context, address common.Address, slot common.Hash, from, to rpc.BlockNumber, limit *hexutil.Uint64) (*StorageHistoryResult, error) {
+	root, first, last, n, err := api.stateHistoryQuery(ctx, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := api.eth.blockchain.TrieDB().StorageChanges(root, address, slot, first, last, n+1)
+	if err != nil {
+		return nil, err
+	}
+	result := &StorageHistoryResult{Changes: []StorageChange{}}
+	for i, change := range changes {
+		if i == n {
+			next := hexutil.Uint64(change.Block)
+			result.Next = &next
+			break
+		}
+		prev, err := decodeHistoricalSlot(change.Prev)
+		if err != nil {
+			return nil, err
+		}
+		post, err := decodeHistoricalSlot(change.Post)
+		if err != nil {
+			return nil, err
+		}
+		result.Changes = append(result.Changes, StorageChange{Block: hexutil.Uint64(change.Block), Prev: prev, Post: post})
+	}
+	return result, nil
+}
+
+// StateDiffCursor locates the position to continue a state diff query from.
+// If the slot is specified, the storage changes of the account are continued
+// from the given slot.
+type StateDiffCursor struct {
+	Address common.Address `json:"address"`
+	Slot    *common.Hash   `json:"slot,omitempty"`
+}
+
+// StateDiffOptions are the optional parameters of debug_getStateDiff.
+type StateDiffOptions struct {
+	Start *StateDiffCursor `json:"start"`
+	Limit *hexutil.Uint64  `json:"limit"`
+}
+
+// AccountDiff is the aggregated change of an account. The code is included
+// only if it has been changed. If the storage changes of the account are
+// split across pages, the account is repeated in each of them.
+type AccountDiff struct {
+	Address  common.Address     `json:"address"`
+	Prev     *HistoricalAccount `json:"prev"`
+	Post     *HistoricalAccount `json:"post"`
+	PrevCode hexutil.Bytes      `json:"prevCode,omitempty"`
+	PostCode hexutil.Bytes      `json:"postCode,omitempty"`
+	Storage  []SlotDiff         `json:"storage,omitempty"`
+}
+
+// SlotDiff is the aggregated change of a storage slot.
+type SlotDiff struct {
+	Slot common.Hash `json:"slot"`
+	Prev common.Hash `json:"prev"`
+	Post common.Hash `json:"post"`
+}
+
+// StateDiffResult is the result of debug_getStateDiff. If more changes are
+// available, Next is the position to continue the query from.
+type StateDiffResult struct {
+	Accounts []AccountDiff    `json:"accounts"`
+	Next     *StateDiffCursor `json:"next,omitempty"`
+}
+
+// GetStateDiff returns the aggregated state diff between the canonical blocks
+// from and to, i.e. the accounts and storage slots changed by the blocks after
+// from up to and including to, with their values in both states. The changes
+// are ordered by address and slot; at most limit accounts and slots are
+// returned, the query can be continued from the position reported as next.
+//
+// The diff is assembled by merging the state histories, which are maintained
+// by path-based nodes for the recent blocks.
+func (api *DebugAPI) GetStateDiff(ctx context.Context, from, to rpc.BlockNumber, opts *StateDiffOptions) (*StateDiffResult, error) {
+	if opts == nil {
+		opts = &StateDiffOptions{}
+	}
+	n, err := pageSize(opts.Limit, defaultStateDiffResults, maxStateDiffResults)
+	if err != nil {
+		return nil, err
+	}
+	root, first, last, err := api.stateHistoryRange(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	result := &StateDiffResult{Accounts: []AccountDiff{}}
+	if first == last {
+		return result, nil
+	}
+	if last-first > maxStateDiffBlocks {
+		return nil, fmt.Errorf("block range is too large, maximum %d blocks", maxStateDiffBlocks)
+	}
+	diff, err := api.eth.blockchain.TrieDB().StateDiff(root, first+1, last, maxStateDiffSize)
+	if err != nil {
+		return nil, err
+	}
+	// The values after the range are resolved from the state of the last block.
+	statedb, _, err := api.eth.APIBackend.StateAndHeaderByNumber(ctx, rpc.BlockNumber(last))
+	if err != nil {
+		return nil, err
+	}
+	addresses := make([]common.Address, 0, len(diff.Accounts))
+	for addr := range diff.Accounts {
+		addresses = append(addresses, addr)
+	}
+	for addr := range diff.Storages {
+		if _, ok := diff.Accounts[addr]; !ok {
+			addresses = append(addresses, addr)
+		}
+	}
+	slices.SortFunc(addresses, common.Address.Cmp)
+
+	var count int
+	for _, addr := range addresses {
+		// Skip the accounts before the cursor
+		var start *common.Hash
+		if opts.Start != nil {
+			if c := addr.Cmp(opts.Start.Address); c < 0 {
+				continue
+			} else if c == 0 {
+				start = opts.Start.Slot
+			}
+		}
+		if count >= n {
+			result.Next = &StateDiffCursor{Address: addr, Slot: start}
+			break
+		}
+		entry, changed, err := api.accountDiff(statedb, addr, diff.Accounts[addr])
+		if err != nil {
+			return nil, err
+		}
+		// The account is accounted for only once, not in the continued pages.
+		if changed && start == nil {
+			count++
+		}
+		slots := slices.SortedFunc(maps.Keys(diff.Storages[addr]), common.Hash.Cmp)
+		for _, slot := range slots {
+			if start != nil && slot.Cmp(*start) < 0 {
+				continue
+			}
+			prev, err := decodeHistoricalSlot(diff.Storages[addr][slot])
+			if err != nil {
+				return nil, err
+			}
+			post := statedb.GetState(addr, slot)
+			if prev == post {
+				continue
+			}
+			if count >= n {
+				result.Next = &StateDiffCursor{Address: addr, Slot: &slot}
+				break
+			}
+			entry.Storage = append(entry.Storage, SlotDiff{Slot: slot, Prev: prev, Post: post})
+			count++
+		}
+		if changed || len(entry.Storage) > 0 {
+			result.Accounts = append(result.Accounts, *entry)
+		}
+		if result.Next != nil {
+			break
+		}
+	}
+	return result, nil
+}
+
+// accountDiff assembles the change of the account with the given value before
+// the state diff, reporting whether the account has been changed at all.
+func (api *DebugAPI) accountDiff(statedb *state.StateDB, addr common.Address, blob []byte) (*AccountDiff, bool, error) {
+	prev, err := decodeHistoricalAccount(blob)
+	if err != nil {
+		return nil, false, err
+	}
+	entry := &AccountDiff{Address: addr, Prev: prev}
+	if statedb.Exist(addr) {
+		entry.Post = &HistoricalAccount{
+			Nonce:       hexutil.Uint64(statedb.GetNonce(addr)),
+			Balance:     (*hexutil.U256)(statedb.GetBalance(addr)),
+			CodeHash:    statedb.GetCodeHash(addr),
+			StorageRoot: statedb.GetStorageRoot(addr),
+		}
+	}
+	// Include the code if it has been changed.
+	var prevCode, postCode common.Hash
+	if prev != nil {
+		prevCode = prev.CodeHash
+	}
+	if entry.Post != nil {
+		postCode = entry.Post.CodeHash
+	}
+	if prevCode != postCode {
+		if prevCode != (common.Hash{}) && prevCode != types.EmptyCodeHash {
+			entry.PrevCode = rawdb.ReadCode(api.eth.ChainDb(), prevCode)
+			if len(entry.PrevCode) == 0 {
+				return nil, false, fmt.Errorf("code %x of %x is not found", prevCode, addr)
+			}
+		}
+		entry.PostCode = statedb.GetCode(addr)
+	}
+	return entry, !equalHistoricalAccount(prev, entry.Post), nil
+}
+
+// equalHistoricalAccount reports whether the two account values are identical.
+func equalHistoricalAccount(a, b *HistoricalAccount) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Nonce == b.Nonce && (*uint256.Int)(a.Balance).Eq((*uint256.Int)(b.Balance)) &&
+		a.CodeHash == b.CodeHash && a.StorageRoot == b.StorageRoot
+}
+
+// stateHistoryQuery validates the parameters of a state history query. It
+// returns the state root of the current head along with the resolved block
+// range and page size.
+func (api *DebugAPI) stateHistoryQuery(ctx context.Context, from, to rpc.BlockNumber, limit *hexutil.Uint64) (common.Hash, uint64, uint64, int, error) {
+	n, err := pageSize(limit, defaultStateHistoryResults, maxStateHistoryResults)
+	if err != nil {
+		return common.Hash{}, 0, 0, 0, err
+	}
+	root, first, last, err := api.stateHistoryRange(ctx, from, to)
+	if err != nil {
+		return common.Hash{}, 0, 0, 0, err
+	}
+	return root, first, last, n, nil
+}
+
+// pageSize validates the optional page size of a query.
+func pageSize(limit *hexutil.Uint64, defaultSize, maxSize int) (int, error) {
+	if limit == nil {
+		return defaultSize, nil
+	}
+	if *limit == 0 || *limit > hexutil.Uint64(maxSize) {
+		return 0, fmt.Errorf("limit must be between 1 and %d", maxSize)
+	}
+	return int(*limit), nil
+}
+
+// stateHistoryRange resolves the block range of a state history query. It
+// returns the state root of the current head along with the block numbers,
+// the last one is capped at the current head.
+func (api *DebugAPI) stateHistoryRange(ctx context.Context, from, to rpc.BlockNumber) (common.Hash, uint64, uint64, error) {
+	if api.eth.blockchain.TrieDB().Scheme() != rawdb.PathScheme {
+		return common.Hash{}, 0, 0, errors.New("state history is only available in path-based scheme")
+	}
+	head := api.eth.blockchain.CurrentBlock()
+	resolve := func(number rpc.BlockNumber) (uint64, error) {
+		if number == rpc.PendingBlockNumber {
+			return head.Number.Uint64(), nil
+		}
+		header, err := api.eth.APIBackend.HeaderByNumber(ctx, number)
+		if err != nil {
+			return 0, err
+		}
+		if header == nil {
+			return 0, fmt.Errorf("block %d not found", number)
+		}
+		return header.Number.Uint64(), nil
+	}
+	first, err := resolve(from)
+	if err != nil {
+		return common.Hash{}, 0, 0, err
+	}
+	last, err := resolve(to)
+	if err != nil {
+		return common.Hash{}, 0, 0, err
+	}
+	if first > last {
+		return common.Hash{}, 0, 0, fmt.Errorf("invalid block range, from: %d, to: %d", first, last)
+	}
+	if last > head.Number.Uint64() {
+		last = head.Number.Uint64()
+	}
+	return head.Root, first, last, nil
+}
+
+// decodeHistoricalAccount decodes an account in the slim format used by the
+// state histories.
+func decodeHistoricalAccount(blob []byte) (*HistoricalAccount, error) {
+	if len(blob) == 0 {
+		return nil, nil
+	}
+	account, err := types.FullAccount(blob)
+	if err != nil {
+		return nil, err
+	}
+	return &HistoricalAccount{
+		Nonce:       hexutil.Uint64(account.Nonce),
+		Balance:     (*hexutil.U256)(account.Balance),
+		CodeHash:    common.BytesToHash(account.CodeHash),
+		StorageRoot: account.Root,
+	}, nil
+}
+
+// decodeHistoricalSlot decodes a storage value in the trimmed RLP format used
+// by the state histories.
+func decodeHistoricalSlot(blob []byte) (common.Hash, error) {
+	if len(blob) == 0 {
+		return common.Hash{}, nil
+	}
+	_, content, _, err := rlp.Split(blob)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(content), nil
+}
@@ -0,0 +1,268 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/holiman/uint256"
+)
+
+func TestGetAccountHistory(t *testing.T) {
+	t.Parallel()
+
+	var (
+		accounts = newAccounts(2)
+		genesis  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: types.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+			},
+		}
+		engine = ethash.NewFaker()
+		signer = types.HomesteadSigner{}
+	)
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, engine, 10, func(i int, b *core.BlockGen) {
+		// Fund the second account in every other block
+		if i%2 != 0 {
+			return
+		}
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(accounts[0].addr), accounts[1].addr, big.NewInt(1000), params.TxGas, b.BaseFee(), nil), signer, accounts[0].key)
+		b.AddTx(tx)
+	})
+	options := core.DefaultConfig().WithStateScheme(rawdb.PathScheme)
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), genesis, engine, options)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	eth := &Ethereum{blockchain: chain}
+	eth.APIBackend = &EthAPIBackend{eth: eth}
+	api := NewDebugAPI(eth)
+
+	// Walk through the history of the funded account page by page. All the
+	// blocks are still held by the in-memory diff layers, hence the query
+	// doesn't depend on the state history index.
+	var (
+		changes []AccountChange
+		from    = rpc.BlockNumber(1)
+		limit   = hexutil.Uint64(2)
+	)
+	for {
+		result, err := api.GetAccountHistory(context.Background(), accounts[1].addr, from, rpc.LatestBlockNumber, &limit)
+		if err != nil {
+			t.Fatalf("failed to retrieve account history: %v", err)
+		}
+		changes = append(changes, result.Changes...)
+		if result.Next == nil {
+			break
+		}
+		if len(result.Changes) != int(limit) {
+			t.Fatalf("incomplete page: have %d changes, want %d", len(result.Changes), limit)
+		}
+		from = rpc.BlockNumber(*result.Next)
+	}
+	if len(changes) != 5 {
+		t.Fatalf("wrong number of changes: have %d, want 5", len(changes))
+	}
+	for i, change := range changes {
+		if change.Block != hexutil.Uint64(2*i+1) {
+			t.Errorf("change %d: wrong block: have %d, want %d", i, change.Block, 2*i+1)
+		}
+		if i == 0 {
+			if change.Prev != nil {
+				t.Errorf("change %d: account existed before funding: %v", i, change.Prev)
+			}
+		} else if (*uint256.Int)(change.Prev.Balance).Uint64() != uint64(1000*i) {
+			t.Errorf("change %d: wrong previous balance: have %v, want %d", i, change.Prev.Balance, 1000*i)
+		}
+		if (*uint256.Int)(change.Post.Balance).Uint64() != uint64(1000*(i+1)) {
+			t.Errorf("change %d: wrong new balance: have %v, want %d", i, change.Post.Balance, 1000*(i+1))
+		}
+	}
+	// Untouched ranges have no changes.
+	result, err := api.GetAccountHistory(context.Background(), accounts[1].addr, 2, 2, nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve account history: %v", err)
+	}
+	if len(result.Changes) != 0 || result.Next != nil {
+		t.Fatalf("unexpected changes in block 2: %v", result.Changes)
+	}
+}
+
+func TestGetStateDiff(t *testing.T) {
+	t.Parallel()
+
+	var (
+		accounts = newAccounts(2)
+		genesis  = &core.Genesis{
+			Config: params.MergedTestChainConfig,
+			Alloc: types.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+			},
+			Difficulty: common.Big0,
+			BaseFee:    big.NewInt(params.InitialBaseFee),
+		}
+		// The storage changes are tracked by raw slot keys since Cancun.
+		engine = beacon.New(ethash.NewFaker())
+		signer = types.LatestSigner(params.MergedTestChainConfig)
+
+		// The contract stores the block number into the slot of the same
+		// number, creating a new slot in each block it's called.
+		runtime  = common.FromHex("43435500")
+		initcode = common.FromHex("63434355006000526004601cf3")
+		contract = crypto.CreateAddress(accounts[0].addr, 0)
+	)
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, engine, 10, func(i int, b *core.BlockGen) {
+		var tx *types.Transaction
+		switch {
+		case i == 0:
+			tx = types.NewContractCreation(b.TxNonce(accounts[0].addr), new(big.Int), 100000, b.BaseFee(), initcode)
+		case i%2 == 0:
+			tx = types.NewTransaction(b.TxNonce(accounts[0].addr), accounts[1].addr, big.NewInt(1000), params.TxGas, b.BaseFee(), nil)
+		default:
+			tx = types.NewTransaction(b.TxNonce(accounts[0].addr), contract, new(big.Int), 50000, b.BaseFee(), nil)
+		}
+		tx, _ = types.SignTx(tx, signer, accounts[0].key)
+		b.AddTx(tx)
+	})
+	options := core.DefaultConfig().WithStateScheme(rawdb.PathScheme)
+	db := rawdb.NewMemoryDatabase()
+	chain, err := core.NewBlockChain(db, genesis, engine, options)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	eth := &Ethereum{blockchain: chain, chainDb: db}
+	eth.APIBackend = &EthAPIBackend{eth: eth}
+	api := NewDebugAPI(eth)
+
+	stateAt := func(number uint64) *state.StateDB {
+		statedb, err := chain.StateAt(chain.GetHeaderByNumber(number))
+		if err != nil {
+			t.Fatalf("failed to open state %d: %v", number, err)
+		}
+		return statedb
+	}
+	checkAccount := func(statedb *state.StateDB, addr common.Address, have *HistoricalAccount) {
+		t.Helper()
+		if !statedb.Exist(addr) {
+			if have != nil {
+				t.Fatalf("account %x should not exist", addr)
+			}
+			return
+		}
+		if have == nil || uint64(have.Nonce) != statedb.GetNonce(addr) || !(*uint256.Int)(have.Balance).Eq(statedb.GetBalance(addr)) || have.CodeHash != statedb.GetCodeHash(addr) {
+			t.Fatalf("account %x mismatch: %+v", addr, have)
+		}
+	}
+	// Retrieve the diff of blocks (2, 7] at once.
+	full, err := api.GetStateDiff(context.Background(), 2, 7, nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve state diff: %v", err)
+	}
+	if full.Next != nil {
+		t.Fatalf("unexpected continuation: %v", full.Next)
+	}
+	var (
+		prev, post = stateAt(2), stateAt(7)
+		seen       = make(map[common.Address]bool)
+	)
+	for _, entry := range full.Accounts {
+		seen[entry.Address] = true
+		checkAccount(prev, entry.Address, entry.Prev)
+		checkAccount(post, entry.Address, entry.Post)
+		for _, slot := range entry.Storage {
+			if slot.Prev != prev.GetState(entry.Address, slot.Slot) || slot.Post != post.GetState(entry.Address, slot.Slot) {
+				t.Fatalf("slot %x of %x mismatch: %+v", slot.Slot, entry.Address, slot)
+			}
+		}
+		if entry.Address == contract && len(entry.Storage) != 2 {
+			t.Fatalf("wrong number of changed slots: have %d, want 2", len(entry.Storage))
+		}
+	}
+	for _, addr := range []common.Address{accounts[0].addr, accounts[1].addr, contract} {
+		if !seen[addr] {
+			t.Fatalf("account %x is missing in the diff", addr)
+		}
+	}
+	// Walk through the same diff page by page.
+	var (
+		paged []AccountDiff
+		opts  = &StateDiffOptions{Limit: new(hexutil.Uint64)}
+	)
+	*opts.Limit = 1
+	for {
+		page, err := api.GetStateDiff(context.Background(), 2, 7, opts)
+		if err != nil {
+			t.Fatalf("failed to retrieve state diff page: %v", err)
+		}
+		for _, entry := range page.Accounts {
+			// Merge the storage of the accounts split across pages.
+			if n := len(paged); n > 0 && paged[n-1].Address == entry.Address {
+				paged[n-1].Storage = append(paged[n-1].Storage, entry.Storage...)
+				continue
+			}
+			paged = append(paged, entry)
+		}
+		if page.Next == nil {
+			break
+		}
+		opts.Start = page.Next
+	}
+	if !reflect.DeepEqual(paged, full.Accounts) {
+		t.Fatalf("paged diff mismatch:\nhave %+v\nwant %+v", paged, full.Accounts)
+	}
+	// The contract creation carries the deployed code.
+	creation, err := api.GetStateDiff(context.Background(), 0, 1, nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve state diff: %v", err)
+	}
+	var found bool
+	for _, entry := range creation.Accounts {
+		if entry.Address == contract {
+			found = true
+			if entry.Prev != nil || !bytes.Equal(entry.PostCode, runtime) {
+				t.Fatalf("wrong contract creation diff: %+v", entry)
+			}
+		}
+	}
+	if !found {
+		t.Fatal("contract creation is missing in the diff")
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"math"
 	"math/big"
+	"path/filepath"
 	"runtime"
 	"sync"
 	"time"
@@ -104,7 +105,13 @@ type Ethereum struct {
 	dropper *dropper
 
 	// DB interfaces
-	chainDb ethdb.Database // Block chain database
+	chainDb ethdb.Database        // Block chain database
+	dbStats *rawdb.StatsCollector // Storage statistics collector, nil if disabled
+
+	// Locations of the chain database and the trie journal relative to the
+	// data directory, mirrored by the database checkpoints.
+	chainDataPath   string
+	trieJournalPath string
 
 	engine         consensus.Engine
 	accountManager *accounts.Manager
@@ -148,6 +155,10 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		log.Warn("Sanitizing invalid miner gas price", "provided", config.Miner.GasPrice, "updated", ethconfig.Defaults.Miner.GasPrice)
 		config.Miner.GasPrice = new(big.Int).Set(ethconfig.Defaults.Miner.GasPrice)
 	}
+	if config.DatabaseStats && config.DatabaseStatsInterval <= 0 {
+		log.Warn("Sanitizing invalid database statistics interval", "provided", config.DatabaseStatsInterval, "updated", ethconfig.Defaults.DatabaseStatsInterval)
+		config.DatabaseStatsInterval = ethconfig.Defaults.DatabaseStatsInterval
+	}
 	if config.NoPruning && config.TrieDirtyCache > 0 && config.StateScheme == rawdb.HashScheme {
 		if config.SnapshotCache > 0 {
 			config.TrieCleanCache += config.TrieDirtyCache * 3 / 5
@@ -210,6 +221,11 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		shutdownTracker: shutdowncheck.NewShutdownTracker(chainDb),
 		fmHeadEventCh:   make(chan core.ChainEvent, 10),
 		fmBlockProcCh:   make(chan bool, 10),
+		chainDataPath:   relativePath(stack, "chaindata"),
+		trieJournalPath: relativePath(stack, "triedb"),
+	}
+	if config.DatabaseStats {
+		eth.dbStats = rawdb.NewStatsCollector(chainDb, "eth/db/chaindata/", config.DatabaseStatsInterval)
 	}
 	bcVersion := rawdb.ReadDatabaseVersion(chainDb)
 	var dbVer = "<nil>"
@@ -355,6 +371,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		BloomCache:     uint64(cacheLimit),
 		RequiredBlocks: config.RequiredBlocks,
 		SnapV2:         config.SnapV2,
+		TxPropagation:  config.TxPropagation,
 	}); err != nil {
 		return nil, err
 	}
@@ -385,6 +402,19 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	return eth, nil
 }
 
+// relativePath resolves the location of the named resource relative to the
+// data directory of the node.
+func relativePath(stack *node.Node, name string) string {
+	if stack.DataDir() == "" {
+		return name
+	}
+	path, err := filepath.Rel(stack.DataDir(), stack.ResolvePath(name))
+	if err != nil {
+		return name
+	}
+	return path
+}
+
 func makeExtraData(extra []byte) []byte {
 	if len(extra) == 0 {
 		// create default extradata
@@ -480,6 +510,11 @@ func (s *Ethereum) Start() error {
 	// start log indexer
 	s.filterMaps.Start()
 	go s.updateFilterMapsHeads()
+
+	// Start the storage statistics collector if enabled
+	if s.dbStats != nil {
+		s.dbStats.Start()
+	}
 	return nil
 }
 
@@ -608,6 +643,9 @@ func (s *Ethereum) Stop() error {
 	s.txPool.Close()
 	s.blockchain.Stop()
 	s.engine.Close()
+	if s.dbStats != nil {
+		s.dbStats.Stop()
+	}
 
 	// Clean shutdown marker as the last thing before closing db
 	s.shutdownTracker.Stop()
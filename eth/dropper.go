@@ -17,6 +17,7 @@
 package eth
 
 import (
+	"math"
 	mrand "math/rand"
 	"slices"
 	"sync"
@@ -51,8 +52,9 @@ var (
 
 // dropper monitors the state of the peer pool and makes changes as follows:
 //   - during sync the Downloader handles peer connections, so dropper is disabled
-//   - if not syncing and the peer count is close to the limit, it drops peers
-//     randomly every peerDropInterval to make space for new peers
+//   - if not syncing and the peer count is close to the limit, it drops the peer
+//     with the lowest reputation every peerDropInterval to make space for new
+//     peers, choosing randomly among equally reputed ones
 //   - peers are dropped separately from the inbound pool and from the dialed pool
 type dropper struct {
 	maxDialPeers    int // maximum number of dialed peers
@@ -103,8 +105,8 @@ func (cm *dropper) Stop() {
 	cm.wg.Wait()
 }
 
-// dropRandomPeer selects one of the peers randomly and drops it from the peer pool.
-func (cm *dropper) dropRandomPeer() bool {
+// dropPeer selects the least reputed peer and drops it from the peer pool.
+func (cm *dropper) dropPeer() bool {
 	peers := cm.peersFunc()
 	var numInbound int
 	for _, p := range peers {
@@ -126,9 +128,9 @@ func (cm *dropper) dropRandomPeer() bool {
 
 	droppable := slices.DeleteFunc(peers, selectDoNotDrop)
 	if len(droppable) > 0 {
-		p := droppable[mrand.Intn(len(droppable))]
-		log.Debug("Dropping random peer", "inbound", p.Inbound(),
-			"id", p.ID(), "duration", common.PrettyDuration(p.Lifetime()), "peercountbefore", len(peers))
+		p := leastReputed(droppable)
+		log.Debug("Dropping peer", "inbound", p.Inbound(), "id", p.ID(), "reputation", p.Reputation(),
+			"duration", common.PrettyDuration(p.Lifetime()), "peercountbefore", len(peers))
 		p.Disconnect(p2p.DiscUselessPeer)
 		if p.Inbound() {
 			droppedInbound.Mark(1)
@@ -140,6 +142,24 @@ func (cm *dropper) dropRandomPeer() bool {
 	return false
 }
 
+// leastReputed returns the peer with the lowest reputation, choosing randomly
+// among peers with the same score.
+func leastReputed(peers []*p2p.Peer) *p2p.Peer {
+	var (
+		lowest     = math.Inf(1)
+		candidates []*p2p.Peer
+	)
+	for _, p := range peers {
+		switch score := p.Reputation(); {
+		case score < lowest:
+			lowest, candidates = score, append(candidates[:0], p)
+		case score == lowest:
+			candidates = append(candidates, p)
+		}
+	}
+	return candidates[mrand.Intn(len(candidates))]
+}
+
 // randomDuration generates a random duration between min and max.
 func randomDuration(min, max time.Duration) time.Duration {
 	if min > max {
@@ -158,9 +178,9 @@ func (cm *dropper) loop() {
 	for {
 		select {
 		case <-cm.peerDropTimer.C:
-			// Drop a random peer if we are not syncing and the peer count is close to the limit.
+			// Drop a peer if we are not syncing and the peer count is close to the limit.
 			if !cm.syncingFunc() {
-				cm.dropRandomPeer()
+				cm.dropPeer()
 			}
 			cm.peerDropTimer.Reset(randomDuration(peerDropIntervalMin, peerDropIntervalMax))
 		case <-cm.shutdownCh:
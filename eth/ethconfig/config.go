@@ -62,6 +62,7 @@ var Defaults = Config{
 	NodeFullValueCheckpoint: pathdb.Defaults.FullValueCheckpoint,
 	BinTrieGroupDepth:       triedb.DefaultBinTrieGroupDepth,
 	DatabaseCache:           2048,
+	DatabaseStatsInterval:   6 * time.Hour,
 	TrieCleanCache:          614,
 	TrieDirtyCache:          1024,
 	SnapshotCache:           409,
@@ -148,6 +149,11 @@ type Config struct {
 	DatabaseCache      int
 	DatabaseFreezer    string
 	DatabaseEra        string
+	DatabaseStats      bool // Whether to collect the storage statistics in the background
+
+	// DatabaseStatsInterval is the pause between two full scans of the database
+	// by the storage statistics collector.
+	DatabaseStatsInterval time.Duration `toml:",omitempty"`
 
 	TrieCleanCache int
 	TrieDirtyCache int
@@ -162,6 +168,10 @@ type Config struct {
 	// for eth_getLogs.
 	LogQueryLimit int
 
+	// PersistentFilters makes the log filters installed through eth_newFilter
+	// persistent across restarts.
+	PersistentFilters bool
+
 	// Mining options
 	Miner miner.Config
 
@@ -169,6 +179,9 @@ type Config struct {
 	TxPool   legacypool.Config
 	BlobPool blobpool.Config
 
+	// Transaction propagation options
+	TxPropagation TxPropagationConfig
+
 	// Gas Price Oracle options
 	GPO gasprice.Config
 
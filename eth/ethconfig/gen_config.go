@@ -42,6 +42,8 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		DatabaseCache           int
 		DatabaseFreezer         string
 		DatabaseEra             string
+		DatabaseStats           bool
+		DatabaseStatsInterval   time.Duration `toml:",omitempty"`
 		TrieCleanCache          int
 		TrieDirtyCache          int
 		TrieTimeout             time.Duration
@@ -49,9 +51,11 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		Preimages               bool
 		FilterLogCacheSize      int
 		LogQueryLimit           int
+		PersistentFilters       bool
 		Miner                   miner.Config
 		TxPool                  legacypool.Config
 		BlobPool                blobpool.Config
+		TxPropagation           TxPropagationConfig
 		GPO                     gasprice.Config
 		EnablePreimageRecording bool
 		EnableWitnessStats      bool
@@ -97,6 +101,8 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.DatabaseCache = c.DatabaseCache
 	enc.DatabaseFreezer = c.DatabaseFreezer
 	enc.DatabaseEra = c.DatabaseEra
+	enc.DatabaseStats = c.DatabaseStats
+	enc.DatabaseStatsInterval = c.DatabaseStatsInterval
 	enc.TrieCleanCache = c.TrieCleanCache
 	enc.TrieDirtyCache = c.TrieDirtyCache
 	enc.TrieTimeout = c.TrieTimeout
@@ -104,9 +110,11 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.Preimages = c.Preimages
 	enc.FilterLogCacheSize = c.FilterLogCacheSize
 	enc.LogQueryLimit = c.LogQueryLimit
+	enc.PersistentFilters = c.PersistentFilters
 	enc.Miner = c.Miner
 	enc.TxPool = c.TxPool
 	enc.BlobPool = c.BlobPool
+	enc.TxPropagation = c.TxPropagation
 	enc.GPO = c.GPO
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
 	enc.EnableWitnessStats = c.EnableWitnessStats
@@ -156,6 +164,8 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		DatabaseCache           *int
 		DatabaseFreezer         *string
 		DatabaseEra             *string
+		DatabaseStats           *bool
+		DatabaseStatsInterval   *time.Duration `toml:",omitempty"`
 		TrieCleanCache          *int
 		TrieDirtyCache          *int
 		TrieTimeout             *time.Duration
@@ -163,9 +173,11 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		Preimages               *bool
 		FilterLogCacheSize      *int
 		LogQueryLimit           *int
+		PersistentFilters       *bool
 		Miner                   *miner.Config
 		TxPool                  *legacypool.Config
 		BlobPool                *blobpool.Config
+		TxPropagation           *TxPropagationConfig
 		GPO                     *gasprice.Config
 		EnablePreimageRecording *bool
 		EnableWitnessStats      *bool
@@ -264,6 +276,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.DatabaseEra != nil {
 		c.DatabaseEra = *dec.DatabaseEra
 	}
+	if dec.DatabaseStats != nil {
+		c.DatabaseStats = *dec.DatabaseStats
+	}
+	if dec.DatabaseStatsInterval != nil {
+		c.DatabaseStatsInterval = *dec.DatabaseStatsInterval
+	}
 	if dec.TrieCleanCache != nil {
 		c.TrieCleanCache = *dec.TrieCleanCache
 	}
@@ -285,6 +303,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.LogQueryLimit != nil {
 		c.LogQueryLimit = *dec.LogQueryLimit
 	}
+	if dec.PersistentFilters != nil {
+		c.PersistentFilters = *dec.PersistentFilters
+	}
 	if dec.Miner != nil {
 		c.Miner = *dec.Miner
 	}
@@ -294,6 +315,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.BlobPool != nil {
 		c.BlobPool = *dec.BlobPool
 	}
+	if dec.TxPropagation != nil {
+		c.TxPropagation = *dec.TxPropagation
+	}
 	if dec.GPO != nil {
 		c.GPO = *dec.GPO
 	}
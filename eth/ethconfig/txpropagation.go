@@ -0,0 +1,54 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethconfig
+
+// Fanout policies of transaction propagation. They decide which peers receive
+// new transactions directly, all other peers receive announcements.
+const (
+	TxFanoutSqrt      = "sqrt"      // Square root of all peers (default)
+	TxFanoutBroadcast = "broadcast" // All peers
+	TxFanoutAnnounce  = "announce"  // No peers
+	TxFanoutRegion    = "region"    // All peers in the local region and square root of the others
+)
+
+// Propagation policies of blob transactions, which are never sent directly.
+const (
+	BlobAnnounceAll     = "all"     // Announce to all peers (default)
+	BlobAnnounceTrusted = "trusted" // Announce to trusted peers only
+	BlobAnnounceNone    = "none"    // Do not propagate
+)
+
+// TxPropagationConfig selects how transactions are gossiped to peers. The zero
+// value is the default policy of the public network.
+type TxPropagationConfig struct {
+	// Fanout is the fanout policy, one of the TxFanout constants.
+	Fanout string `toml:",omitempty"`
+
+	// FanoutPeers overrides the number of peers which receive transactions
+	// directly with the sqrt and region policies.
+	FanoutPeers int `toml:",omitempty"`
+
+	// Trusted makes all trusted peers receive transactions directly, regardless
+	// of the fanout policy.
+	Trusted bool `toml:",omitempty"`
+
+	// RegionNets are the CIDR ranges of peers in the local region.
+	RegionNets []string `toml:",omitempty"`
+
+	// Blobs is the blob transaction policy, one of the BlobAnnounce constants.
+	Blobs string `toml:",omitempty"`
+}
@@ -195,7 +195,15 @@ type TxFetcher struct {
 // based on hash announcements.
 // Chain can be nil to disable on-chain checks.
 func NewTxFetcher(chain *core.BlockChain, validateMeta func(common.Hash, byte) error, addTxs func([]*types.Transaction) []error, fetchTxs func(string, []common.Hash) error, dropPeer func(string)) *TxFetcher {
-	return NewTxFetcherForTests(chain, validateMeta, addTxs, fetchTxs, dropPeer, mclock.System{}, time.Now, nil)
+	return NewTxFetcherWithClock(chain, validateMeta, addTxs, fetchTxs, dropPeer, mclock.System{})
+}
+
+// NewTxFetcherWithClock creates a transaction fetcher which schedules the
+// announcement and request timeouts on the given clock. The expiry of known
+// underpriced transactions still uses the system time, since it's compared to
+// the arrival times of the transactions.
+func NewTxFetcherWithClock(chain *core.BlockChain, validateMeta func(common.Hash, byte) error, addTxs func([]*types.Transaction) []error, fetchTxs func(string, []common.Hash) error, dropPeer func(string), clock mclock.Clock) *TxFetcher {
+	return NewTxFetcherForTests(chain, validateMeta, addTxs, fetchTxs, dropPeer, clock, time.Now, nil)
 }
 
 // NewTxFetcherForTests is a testing method to mock out the realtime clock with
@@ -31,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/history"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
@@ -78,6 +79,12 @@ type filter struct {
 	crit     FilterCriteria
 	logs     []*types.Log
 	s        *Subscription // associated subscription in event system
+
+	// Persistent log filters are not backed by a subscription, they catch up
+	// from the cursor on every poll instead.
+	persistent bool
+	cursor     *Cursor    // last block delivered to the client
+	pollMu     sync.Mutex // serializes polls of persistent filters
 }
 
 // FilterAPI offers support to create and manage filters. This will allow external clients to retrieve various
@@ -102,6 +109,9 @@ func NewFilterAPI(system *FilterSystem) *FilterAPI {
 		logQueryLimit: system.cfg.LogQueryLimit,
 		rangeLimit:    system.cfg.RangeLimit,
 	}
+	if system.cfg.PersistFilters {
+		api.restoreFilters()
+	}
 	go api.timeoutLoop(system.cfg.Timeout)
 
 	return api
@@ -123,7 +133,11 @@ func (api *FilterAPI) timeoutLoop(timeout time.Duration) {
 		for id, f := range api.filters {
 			select {
 			case <-f.deadline.C:
-				toUninstall = append(toUninstall, f.s)
+				if f.persistent {
+					api.deleteFilter(id)
+				} else {
+					toUninstall = append(toUninstall, f.s)
+				}
 				delete(api.filters, id)
 			default:
 				continue
@@ -256,7 +270,10 @@ func (api *FilterAPI) NewBlockFilter() rpc.ID {
 }
 
 // NewHeads send a notification each time a new (header) block is appended to the chain.
-func (api *FilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+//
+// If a cursor is given, the canonical headers the client missed since the cursor
+// block are delivered first, before going live.
+func (api *FilterAPI) NewHeads(ctx context.Context, cursor *Cursor) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
@@ -266,6 +283,85 @@ func (api *FilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
 		rpcSub     = notifier.CreateSubscription()
 		headers    = make(chan *types.Header)
 		headersSub = api.events.SubscribeNewHeads(headers)
+		replayCh   = make(chan []*types.Header, 1)
+	)
+
+	go func() {
+		defer headersSub.Unsubscribe()
+
+		if cursor != nil {
+			// Collect the live headers while the missed ones are retrieved,
+			// the event loop must not be blocked in the meantime.
+			var pending []*types.Header
+		replay:
+			for {
+				select {
+				case h := <-headers:
+					pending = append(pending, h)
+				case replay, ok := <-replayCh:
+					if !ok {
+						return
+					}
+					seen := make(map[common.Hash]struct{}, len(replay))
+					for _, h := range replay {
+						seen[h.Hash()] = struct{}{}
+						notifier.Notify(rpcSub.ID, h)
+					}
+					for _, h := range pending {
+						if _, ok := seen[h.Hash()]; !ok {
+							notifier.Notify(rpcSub.ID, h)
+						}
+					}
+					break replay
+				case <-rpcSub.Err():
+					return
+				}
+			}
+		}
+		for {
+			select {
+			case h := <-headers:
+				notifier.Notify(rpcSub.ID, h)
+			case <-rpcSub.Err():
+				return
+			}
+		}
+	}()
+
+	if cursor != nil {
+		replay, err := api.sys.resumeHeaders(ctx, cursor)
+		if err != nil {
+			close(replayCh)
+			return nil, err
+		}
+		replayCh <- replay
+	}
+	return rpcSub, nil
+}
+
+// FinalizedHeads send a notification each time a block becomes finalized, as
+// announced by the consensus client through forkchoice updates.
+func (api *FilterAPI) FinalizedHeads(ctx context.Context) (*rpc.Subscription, error) {
+	return api.finalityHeads(ctx, api.events.SubscribeFinalizedHeads)
+}
+
+// SafeHeads send a notification each time a block becomes safe, as announced by
+// the consensus client through forkchoice updates.
+func (api *FilterAPI) SafeHeads(ctx context.Context) (*rpc.Subscription, error) {
+	return api.finalityHeads(ctx, api.events.SubscribeSafeHeads)
+}
+
+// finalityHeads creates a header subscription fed by the given event source.
+func (api *FilterAPI) finalityHeads(ctx context.Context, subscribe func(chan *types.Header) *Subscription) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	var (
+		rpcSub     = notifier.CreateSubscription()
+		headers    = make(chan *types.Header)
+		headersSub = subscribe(headers)
 	)
 
 	go func() {
@@ -285,7 +381,13 @@ func (api *FilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
 }
 
 // Logs creates a subscription that fires for all new log that match the given filter criteria.
-func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subscription, error) {
+//
+// If a cursor is given, the matching logs the client missed since the cursor are
+// delivered first, before going live. If the cursor block has been reorged out
+// in the meantime, the logs previously delivered from the dropped blocks are
+// resent with the removed flag set. Around the switch to live delivery, removed
+// logs may be delivered more than once.
+func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria, cursor *Cursor) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
@@ -294,6 +396,7 @@ func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subsc
 	var (
 		rpcSub      = notifier.CreateSubscription()
 		matchedLogs = make(chan []*types.Log)
+		replayCh    = make(chan []*types.Log, 1)
 	)
 
 	logsSub, err := api.events.SubscribeLogs(ethereum.FilterQuery(crit), matchedLogs)
@@ -303,6 +406,41 @@ func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subsc
 
 	go func() {
 		defer logsSub.Unsubscribe()
+
+		if cursor != nil {
+			// Collect the live logs while the missed ones are retrieved, the
+			// event loop must not be blocked in the meantime.
+			var pending []*types.Log
+		replay:
+			for {
+				select {
+				case logs := <-matchedLogs:
+					pending = append(pending, logs...)
+				case replay, ok := <-replayCh:
+					if !ok {
+						return
+					}
+					// Live logs of blocks covered by the replay were already
+					// delivered, removals are always forwarded.
+					seen := make(map[common.Hash]struct{})
+					for _, log := range replay {
+						if !log.Removed {
+							seen[log.BlockHash] = struct{}{}
+						}
+						notifier.Notify(rpcSub.ID, log)
+					}
+					for _, log := range pending {
+						if _, ok := seen[log.BlockHash]; ok && !log.Removed {
+							continue
+						}
+						notifier.Notify(rpcSub.ID, log)
+					}
+					break replay
+				case <-rpcSub.Err():
+					return
+				}
+			}
+		}
 		for {
 			select {
 			case logs := <-matchedLogs:
@@ -315,6 +453,95 @@ func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subsc
 		}
 	}()
 
+	if cursor != nil {
+		replay, _, err := api.sys.resumeLogs(ctx, crit, cursor, api.rangeLimit)
+		if err != nil {
+			close(replayCh)
+			return nil, err
+		}
+		replayCh <- replay
+	}
+	return rpcSub, nil
+}
+
+// ChainEvents creates a subscription that follows the canonical chain block by
+// block. For every new canonical block a block event is sent, carrying the header,
+// the logs matching the given criteria and optionally the receipts. Reorgs are
+// announced by a reorg event listing the dropped and added blocks, followed by
+// block events for the added blocks. Reorgs which can't be resolved are announced
+// by a reset event instead. Changes of the finalized and safe blocks are announced
+// by finalized and safe events.
+func (api *FilterAPI) ChainEvents(ctx context.Context, crit *ChainEventsCriteria) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	if crit == nil {
+		crit = new(ChainEventsCriteria)
+	}
+	if len(crit.Topics) > maxTopics {
+		return nil, errExceedMaxTopics
+	}
+	if api.logQueryLimit != 0 {
+		if len(crit.Addresses) > api.logQueryLimit {
+			return nil, errExceedLogQueryLimit
+		}
+		for _, topics := range crit.Topics {
+			if len(topics) > api.logQueryLimit {
+				return nil, errExceedLogQueryLimit
+			}
+		}
+	}
+	var (
+		rpcSub       = notifier.CreateSubscription()
+		headers      = make(chan *types.Header)
+		headersSub   = api.events.SubscribeNewHeads(headers)
+		finalized    = make(chan *types.Header)
+		finalizedSub = api.events.SubscribeFinalizedHeads(finalized)
+		safe         = make(chan *types.Header)
+		safeSub      = api.events.SubscribeSafeHeads(safe)
+		tracker      = newChainEventsTracker(api.sys, *crit, api.sys.backend.CurrentHeader())
+	)
+	// Announce the current finality markers upfront.
+	finalHeader, _ := api.sys.backend.HeaderByNumber(ctx, rpc.FinalizedBlockNumber)
+	if ev := tracker.finalityEvent(ChainEventFinalized, finalHeader); ev != nil {
+		notifier.Notify(rpcSub.ID, ev)
+	}
+	safeHeader, _ := api.sys.backend.HeaderByNumber(ctx, rpc.SafeBlockNumber)
+	if ev := tracker.finalityEvent(ChainEventSafe, safeHeader); ev != nil {
+		notifier.Notify(rpcSub.ID, ev)
+	}
+
+	go func() {
+		defer headersSub.Unsubscribe()
+		defer finalizedSub.Unsubscribe()
+		defer safeSub.Unsubscribe()
+
+		for {
+			select {
+			case h := <-headers:
+				events, err := tracker.newHead(context.Background(), h)
+				if err != nil {
+					log.Warn("Failed to assemble chain events", "number", h.Number, "hash", h.Hash(), "err", err)
+					continue
+				}
+				for _, ev := range events {
+					notifier.Notify(rpcSub.ID, ev)
+				}
+			case h := <-finalized:
+				if ev := tracker.finalityEvent(ChainEventFinalized, h); ev != nil {
+					notifier.Notify(rpcSub.ID, ev)
+				}
+			case h := <-safe:
+				if ev := tracker.finalityEvent(ChainEventSafe, h); ev != nil {
+					notifier.Notify(rpcSub.ID, ev)
+				}
+			case <-rpcSub.Err():
+				return
+			}
+		}
+	}()
+
 	return rpcSub, nil
 }
 
@@ -410,7 +637,12 @@ type FilterCriteria ethereum.FilterQuery
 // again but with the removed property set to true.
 //
 // In case "fromBlock" > "toBlock" an error is returned.
+//
+// If filter persistence is enabled, the filter survives node restarts.
 func (api *FilterAPI) NewFilter(crit FilterCriteria) (rpc.ID, error) {
+	if api.sys.cfg.PersistFilters {
+		return api.newPersistentFilter(crit)
+	}
 	logs := make(chan []*types.Log)
 	logsSub, err := api.events.SubscribeLogs(ethereum.FilterQuery(crit), logs)
 	if err != nil {
@@ -502,9 +734,12 @@ func (api *FilterAPI) UninstallFilter(id rpc.ID) bool {
 	f, found := api.filters[id]
 	if found {
 		delete(api.filters, id)
+		if f.persistent {
+			api.deleteFilter(id)
+		}
 	}
 	api.filtersMu.Unlock()
-	if found {
+	if found && !f.persistent {
 		f.s.Unsubscribe()
 	}
 
@@ -557,19 +792,26 @@ func (api *FilterAPI) GetFilterLogs(ctx context.Context, id rpc.ID) ([]*types.Lo
 // (pending)Log filters return []Log.
 func (api *FilterAPI) GetFilterChanges(id rpc.ID) (interface{}, error) {
 	api.filtersMu.Lock()
-	defer api.filtersMu.Unlock()
-
-	chainConfig := api.sys.backend.ChainConfig()
-	latest := api.sys.backend.CurrentHeader()
-
-	if f, found := api.filters[id]; found {
+	f, found := api.filters[id]
+	if found {
 		if !f.deadline.Stop() {
 			// timer expired but filter is not yet removed in timeout loop
 			// receive timer value and reset timer
 			<-f.deadline.C
 		}
 		f.deadline.Reset(api.timeout)
+	}
+	// Persistent filters query the log index, which is done outside the lock.
+	if found && f.persistent {
+		api.filtersMu.Unlock()
+		return api.pollPersistentFilter(id, f)
+	}
+	defer api.filtersMu.Unlock()
 
+	chainConfig := api.sys.backend.ChainConfig()
+	latest := api.sys.backend.CurrentHeader()
+
+	if found {
 		switch f.typ {
 		case BlocksSubscription:
 			hashes := f.hashes
@@ -0,0 +1,250 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxChainEventsReorg is the maximum reorg depth the chainEvents subscription
+// reports as a structured reorg. Deeper reorgs are announced by a reset event.
+const maxChainEventsReorg = 1024
+
+// Kinds of events emitted by the chainEvents subscription.
+const (
+	ChainEventReorg     = "reorg"
+	ChainEventBlock     = "block"
+	ChainEventFinalized = "finalized"
+	ChainEventSafe      = "safe"
+	ChainEventReset     = "reset"
+)
+
+// ChainEventsCriteria defines the criteria of a chainEvents subscription: the
+// log filter criteria selecting the logs attached to each block, and whether
+// the full receipts of each block should be included.
+type ChainEventsCriteria struct {
+	FilterCriteria
+	Receipts bool
+}
+
+// UnmarshalJSON sets *args fields with given data.
+func (args *ChainEventsCriteria) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Receipts bool `json:"receipts"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if err := args.FilterCriteria.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	args.Receipts = raw.Receipts
+	return nil
+}
+
+// BlockID identifies a block by number and hash.
+type BlockID struct {
+	Number hexutil.Uint64 `json:"number"`
+	Hash   common.Hash    `json:"hash"`
+}
+
+func newBlockID(header *types.Header) BlockID {
+	return BlockID{Number: hexutil.Uint64(header.Number.Uint64()), Hash: header.Hash()}
+}
+
+// ChainEventNotification is a single event emitted by the chainEvents subscription.
+// Depending on the event type, only a subset of the fields are set:
+//
+//   - reorg: the common ancestor of the old and new chain, the dropped blocks of
+//     the old chain and the added blocks of the new chain, in ascending order.
+//     A block event follows for every added block.
+//   - block: a new canonical block with its matching logs and, if requested,
+//     its receipts.
+//   - finalized, safe: the new finalized or safe block.
+//   - reset: the chain switched to a new head, but the common ancestor with the
+//     last announced head couldn't be resolved, e.g. due to a reorg deeper than
+//     the supported depth. The block is the last announced head; any of the
+//     blocks announced before may have been dropped. A block event follows for
+//     the new head.
+type ChainEventNotification struct {
+	Type           string                   `json:"type"`
+	CommonAncestor *BlockID                 `json:"commonAncestor,omitempty"`
+	Dropped        []BlockID                `json:"dropped,omitempty"`
+	Added          []BlockID                `json:"added,omitempty"`
+	Header         *types.Header            `json:"header,omitempty"`
+	Logs           []*types.Log             `json:"logs,omitempty"`
+	Receipts       []map[string]interface{} `json:"receipts,omitempty"`
+	Block          *BlockID                 `json:"block,omitempty"`
+}
+
+// chainEventsTracker follows the canonical chain on behalf of a chainEvents
+// subscription, turning head updates into structured events.
+type chainEventsTracker struct {
+	sys      *FilterSystem
+	filter   *Filter
+	crit     ChainEventsCriteria
+	signer   types.Signer
+	head     *types.Header // last head announced to the subscriber
+	finality map[string]common.Hash
+}
+
+func newChainEventsTracker(sys *FilterSystem, crit ChainEventsCriteria, head *types.Header) *chainEventsTracker {
+	return &chainEventsTracker{
+		sys:      sys,
+		filter:   newFilter(sys, crit.Addresses, crit.Topics),
+		crit:     crit,
+		signer:   types.LatestSigner(sys.backend.ChainConfig()),
+		head:     head,
+		finality: make(map[string]common.Hash),
+	}
+}
+
+// newHead processes a new chain head and returns the events to deliver.
+func (t *chainEventsTracker) newHead(ctx context.Context, head *types.Header) ([]*ChainEventNotification, error) {
+	var events []*ChainEventNotification
+	if t.head == nil || head.ParentHash == t.head.Hash() {
+		ev, err := t.blockEvent(ctx, head)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, ev)
+	} else if head.Hash() != t.head.Hash() {
+		ancestor, dropped, added, err := t.findAncestor(ctx, t.head, head)
+		if err != nil {
+			log.Debug("Failed to resolve reorg for chain events", "from", t.head.Number, "to", head.Number, "err", err)
+			oldID := newBlockID(t.head)
+			events = append(events, &ChainEventNotification{Type: ChainEventReset, Block: &oldID})
+			dropped, added = nil, []*types.Header{head}
+		}
+		if len(dropped) > 0 {
+			ancestorID := newBlockID(ancestor)
+			reorg := &ChainEventNotification{Type: ChainEventReorg, CommonAncestor: &ancestorID}
+			for _, h := range dropped {
+				reorg.Dropped = append(reorg.Dropped, newBlockID(h))
+			}
+			for _, h := range added {
+				reorg.Added = append(reorg.Added, newBlockID(h))
+			}
+			events = append(events, reorg)
+		}
+		for _, h := range added {
+			ev, err := t.blockEvent(ctx, h)
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, ev)
+		}
+	}
+	t.head = head
+	return events, nil
+}
+
+// findAncestor returns the common ancestor of the two headers along with the
+// blocks on either side of it, in ascending order.
+func (t *chainEventsTracker) findAncestor(ctx context.Context, oldHead, newHead *types.Header) (*types.Header, []*types.Header, []*types.Header, error) {
+	var dropped, added []*types.Header
+
+	parent := func(h *types.Header) (*types.Header, error) {
+		if len(dropped)+len(added) > 2*maxChainEventsReorg {
+			return nil, fmt.Errorf("reorg deeper than %d blocks", maxChainEventsReorg)
+		}
+		p, err := t.sys.backend.HeaderByHash(ctx, h.ParentHash)
+		if err != nil {
+			return nil, err
+		}
+		if p == nil {
+			return nil, fmt.Errorf("missing parent of block #%d", h.Number)
+		}
+		return p, nil
+	}
+	var err error
+	for oldHead.Number.Cmp(newHead.Number) > 0 {
+		dropped = append(dropped, oldHead)
+		if oldHead, err = parent(oldHead); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	for newHead.Number.Cmp(oldHead.Number) > 0 {
+		added = append(added, newHead)
+		if newHead, err = parent(newHead); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	for oldHead.Hash() != newHead.Hash() {
+		dropped = append(dropped, oldHead)
+		added = append(added, newHead)
+		if oldHead, err = parent(oldHead); err != nil {
+			return nil, nil, nil, err
+		}
+		if newHead, err = parent(newHead); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	slices.Reverse(dropped)
+	slices.Reverse(added)
+	return oldHead, dropped, added, nil
+}
+
+// blockEvent assembles the block event of a canonical block.
+func (t *chainEventsTracker) blockEvent(ctx context.Context, header *types.Header) (*ChainEventNotification, error) {
+	logs, err := t.filter.blockLogs(ctx, header)
+	if err != nil {
+		return nil, err
+	}
+	ev := &ChainEventNotification{Type: ChainEventBlock, Header: header, Logs: returnLogs(logs)}
+	if !t.crit.Receipts {
+		return ev, nil
+	}
+	receipts, err := t.sys.backend.GetReceipts(ctx, header.Hash())
+	if err != nil {
+		return nil, err
+	}
+	body, err := t.sys.backend.GetBody(ctx, header.Hash(), rpc.BlockNumber(header.Number.Uint64()))
+	if err != nil {
+		return nil, err
+	}
+	if len(receipts) != len(body.Transactions) {
+		return nil, errors.New("receipts and transactions mismatch")
+	}
+	ev.Receipts = make([]map[string]interface{}, len(receipts))
+	for i, receipt := range receipts {
+		ev.Receipts[i] = ethapi.MarshalReceipt(receipt, header.Hash(), header.Number.Uint64(), t.signer, body.Transactions[i], i)
+	}
+	return ev, nil
+}
+
+// finalityEvent returns the event announcing a new finalized or safe block, or
+// nil if the block has already been announced.
+func (t *chainEventsTracker) finalityEvent(typ string, header *types.Header) *ChainEventNotification {
+	if header == nil || t.finality[typ] == header.Hash() {
+		return nil
+	}
+	t.finality[typ] = header.Hash()
+	id := newBlockID(header)
+	return &ChainEventNotification{Type: typ, Block: &id}
+}
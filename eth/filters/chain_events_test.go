@@ -0,0 +1,164 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestUnmarshalChainEventsCriteria(t *testing.T) {
+	var crit ChainEventsCriteria
+	input := `{"address":"0x0000000000000000000000000000000000000001","receipts":true}`
+	if err := json.Unmarshal([]byte(input), &crit); err != nil {
+		t.Fatal(err)
+	}
+	if !crit.Receipts {
+		t.Error("receipts flag not set")
+	}
+	if len(crit.Addresses) != 1 || crit.Addresses[0] != common.BytesToAddress([]byte{1}) {
+		t.Errorf("wrong addresses: %v", crit.Addresses)
+	}
+}
+
+func TestChainEventsExtend(t *testing.T) {
+	t.Parallel()
+
+	var (
+		addr          = common.Address{0xaa}
+		sys, chain, _ = newResumeTestChain(t, addr, false)
+		crit          = ChainEventsCriteria{FilterCriteria: FilterCriteria{Addresses: []common.Address{{0xbb}}}, Receipts: true}
+		tracker       = newChainEventsTracker(sys, crit, chain[8].Header())
+		events, err   = tracker.newHead(context.Background(), chain[9].Header())
+	)
+	if err != nil {
+		t.Fatalf("failed to process head: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("wrong number of events: have %d, want 1", len(events))
+	}
+	ev := events[0]
+	if ev.Type != ChainEventBlock || ev.Header.Hash() != chain[9].Hash() {
+		t.Fatalf("wrong block event: %+v", ev)
+	}
+	if len(ev.Logs) != 0 {
+		t.Errorf("wrong number of matching logs: have %d, want 0", len(ev.Logs))
+	}
+	if len(ev.Receipts) != 2 {
+		t.Errorf("wrong number of receipts: have %d, want 2", len(ev.Receipts))
+	}
+}
+
+func TestChainEventsReorg(t *testing.T) {
+	t.Parallel()
+
+	var (
+		addr             = common.Address{0xaa}
+		sys, chain, fork = newResumeTestChain(t, addr, true)
+		crit             = ChainEventsCriteria{FilterCriteria: FilterCriteria{Addresses: []common.Address{addr}}}
+		tracker          = newChainEventsTracker(sys, crit, chain[9].Header())
+	)
+	events, err := tracker.newHead(context.Background(), fork[4].Header())
+	if err != nil {
+		t.Fatalf("failed to process head: %v", err)
+	}
+	if len(events) != 1+len(fork) {
+		t.Fatalf("wrong number of events: have %d, want %d", len(events), 1+len(fork))
+	}
+	reorg := events[0]
+	if reorg.Type != ChainEventReorg {
+		t.Fatalf("first event is not a reorg: %s", reorg.Type)
+	}
+	if reorg.CommonAncestor.Hash != chain[4].Hash() {
+		t.Errorf("wrong common ancestor: %d", reorg.CommonAncestor.Number)
+	}
+	if len(reorg.Dropped) != 5 || len(reorg.Added) != 5 {
+		t.Fatalf("wrong reorg size: dropped %d, added %d", len(reorg.Dropped), len(reorg.Added))
+	}
+	for i := range 5 {
+		if reorg.Dropped[i].Hash != chain[5+i].Hash() {
+			t.Errorf("dropped block %d: wrong hash", i)
+		}
+		if reorg.Added[i].Hash != fork[i].Hash() {
+			t.Errorf("added block %d: wrong hash", i)
+		}
+		ev := events[1+i]
+		if ev.Type != ChainEventBlock || ev.Header.Hash() != fork[i].Hash() {
+			t.Errorf("event %d: wrong block event", 1+i)
+		} else if len(ev.Logs) != 2 {
+			t.Errorf("event %d: wrong number of logs: have %d, want 2", 1+i, len(ev.Logs))
+		}
+	}
+	// The same head must not produce any events.
+	if events, err = tracker.newHead(context.Background(), fork[4].Header()); err != nil {
+		t.Fatalf("failed to process head: %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("unexpected events for known head: %d", len(events))
+	}
+}
+
+// Reorgs with an unknown common ancestor are announced by a reset event.
+func TestChainEventsReset(t *testing.T) {
+	t.Parallel()
+
+	var (
+		sys, chain, _ = newResumeTestChain(t, common.Address{0xaa}, false)
+		unknown       = &types.Header{Number: chain[9].Number(), ParentHash: common.Hash{0x01}}
+		tracker       = newChainEventsTracker(sys, ChainEventsCriteria{}, unknown)
+	)
+	events, err := tracker.newHead(context.Background(), chain[9].Header())
+	if err != nil {
+		t.Fatalf("failed to process head: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("wrong number of events: have %d, want 2", len(events))
+	}
+	if ev := events[0]; ev.Type != ChainEventReset || ev.Block.Hash != unknown.Hash() {
+		t.Fatalf("wrong reset event: %+v", ev)
+	}
+	if ev := events[1]; ev.Type != ChainEventBlock || ev.Header.Hash() != chain[9].Hash() {
+		t.Fatalf("wrong block event: %+v", ev)
+	}
+}
+
+func TestChainEventsFinality(t *testing.T) {
+	t.Parallel()
+
+	var (
+		sys, chain, _ = newResumeTestChain(t, common.Address{0xaa}, false)
+		tracker       = newChainEventsTracker(sys, ChainEventsCriteria{}, chain[9].Header())
+	)
+	ev := tracker.finalityEvent(ChainEventFinalized, chain[3].Header())
+	if ev == nil || ev.Type != ChainEventFinalized || ev.Block.Hash != chain[3].Hash() {
+		t.Fatalf("wrong finalized event: %+v", ev)
+	}
+	// Markers are only announced once, independently of each other.
+	if ev := tracker.finalityEvent(ChainEventFinalized, chain[3].Header()); ev != nil {
+		t.Errorf("finalized block announced twice")
+	}
+	if ev := tracker.finalityEvent(ChainEventSafe, chain[3].Header()); ev == nil || ev.Type != ChainEventSafe {
+		t.Errorf("wrong safe event: %+v", ev)
+	}
+	if ev := tracker.finalityEvent(ChainEventFinalized, nil); ev != nil {
+		t.Errorf("unexpected event for missing finalized block")
+	}
+}
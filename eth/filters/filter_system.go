@@ -45,6 +45,8 @@ type Config struct {
 	Timeout       time.Duration // how long filters stay active (default: 5min)
 	LogQueryLimit int           // maximum number of addresses allowed in filter criteria (default: 1000)
 	RangeLimit    uint64        // maximum block range allowed in filter criteria (default: 0)
+
+	PersistFilters bool // whether log filters are stored in the database and survive restarts
 }
 
 func (cfg Config) withDefaults() Config {
@@ -72,6 +74,8 @@ type Backend interface {
 	SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription
 	SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription
 	SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription
+	SubscribeFinalizedHeadEvent(ch chan<- core.FinalizedHeadEvent) event.Subscription
+	SubscribeSafeHeadEvent(ch chan<- core.SafeHeadEvent) event.Subscription
 
 	CurrentView() *filtermaps.ChainView
 	NewMatcherBackend() filtermaps.MatcherBackend
@@ -161,6 +165,10 @@ const (
 	BlocksSubscription
 	// TransactionReceiptsSubscription queries for transaction receipts when transactions are included in blocks
 	TransactionReceiptsSubscription
+	// FinalizedHeadsSubscription queries headers of blocks that become finalized
+	FinalizedHeadsSubscription
+	// SafeHeadsSubscription queries headers of blocks that become safe
+	SafeHeadsSubscription
 	// LastIndexSubscription keeps track of the last index
 	LastIndexSubscription
 )
@@ -175,6 +183,8 @@ const (
 	logsChanSize = 10
 	// chainEvChanSize is the size of channel listening to ChainEvent.
 	chainEvChanSize = 10
+	// finalityChanSize is the size of channel listening to FinalizedHeadEvent and SafeHeadEvent.
+	finalityChanSize = 10
 )
 
 type subscription struct {
@@ -202,14 +212,18 @@ type EventSystem struct {
 	logsSub   event.Subscription // Subscription for new log event
 	rmLogsSub event.Subscription // Subscription for removed log event
 	chainSub  event.Subscription // Subscription for new chain event
+	finalSub  event.Subscription // Subscription for finalized block changes
+	safeSub   event.Subscription // Subscription for safe block changes
 
 	// Channels
-	install   chan *subscription         // install filter for event notification
-	uninstall chan *subscription         // remove filter for event notification
-	txsCh     chan core.NewTxsEvent      // Channel to receive new transactions event
-	logsCh    chan []*types.Log          // Channel to receive new log event
-	rmLogsCh  chan core.RemovedLogsEvent // Channel to receive removed log event
-	chainCh   chan core.ChainEvent       // Channel to receive new chain event
+	install   chan *subscription           // install filter for event notification
+	uninstall chan *subscription           // remove filter for event notification
+	txsCh     chan core.NewTxsEvent        // Channel to receive new transactions event
+	logsCh    chan []*types.Log            // Channel to receive new log event
+	rmLogsCh  chan core.RemovedLogsEvent   // Channel to receive removed log event
+	chainCh   chan core.ChainEvent         // Channel to receive new chain event
+	finalCh   chan core.FinalizedHeadEvent // Channel to receive finalized block changes
+	safeCh    chan core.SafeHeadEvent      // Channel to receive safe block changes
 }
 
 // NewEventSystem creates a new manager that listens for event on the given mux,
@@ -228,6 +242,8 @@ func NewEventSystem(sys *FilterSystem) *EventSystem {
 		logsCh:    make(chan []*types.Log, logsChanSize),
 		rmLogsCh:  make(chan core.RemovedLogsEvent, rmLogsChanSize),
 		chainCh:   make(chan core.ChainEvent, chainEvChanSize),
+		finalCh:   make(chan core.FinalizedHeadEvent, finalityChanSize),
+		safeCh:    make(chan core.SafeHeadEvent, finalityChanSize),
 	}
 
 	// Subscribe events
@@ -235,9 +251,11 @@ func NewEventSystem(sys *FilterSystem) *EventSystem {
 	m.logsSub = m.backend.SubscribeLogsEvent(m.logsCh)
 	m.rmLogsSub = m.backend.SubscribeRemovedLogsEvent(m.rmLogsCh)
 	m.chainSub = m.backend.SubscribeChainEvent(m.chainCh)
+	m.finalSub = m.backend.SubscribeFinalizedHeadEvent(m.finalCh)
+	m.safeSub = m.backend.SubscribeSafeHeadEvent(m.safeCh)
 
 	// Make sure none of the subscriptions are empty
-	if m.txsSub == nil || m.logsSub == nil || m.rmLogsSub == nil || m.chainSub == nil {
+	if m.txsSub == nil || m.logsSub == nil || m.rmLogsSub == nil || m.chainSub == nil || m.finalSub == nil || m.safeSub == nil {
 		log.Crit("Subscribe for event system failed")
 	}
 
@@ -369,9 +387,26 @@ func (es *EventSystem) subscribeLogs(crit ethereum.FilterQuery, logs chan []*typ
 // SubscribeNewHeads creates a subscription that writes the header of a block that is
 // imported in the chain.
 func (es *EventSystem) SubscribeNewHeads(headers chan *types.Header) *Subscription {
+	return es.subscribeHeaders(BlocksSubscription, headers)
+}
+
+// SubscribeFinalizedHeads creates a subscription that writes the header of a block
+// that becomes finalized.
+func (es *EventSystem) SubscribeFinalizedHeads(headers chan *types.Header) *Subscription {
+	return es.subscribeHeaders(FinalizedHeadsSubscription, headers)
+}
+
+// SubscribeSafeHeads creates a subscription that writes the header of a block
+// that becomes safe.
+func (es *EventSystem) SubscribeSafeHeads(headers chan *types.Header) *Subscription {
+	return es.subscribeHeaders(SafeHeadsSubscription, headers)
+}
+
+// subscribeHeaders creates a subscription of the given type that writes headers.
+func (es *EventSystem) subscribeHeaders(typ Type, headers chan *types.Header) *Subscription {
 	sub := &subscription{
 		id:        rpc.NewID(),
-		typ:       BlocksSubscription,
+		typ:       typ,
 		created:   time.Now(),
 		logs:      make(chan []*types.Log),
 		txs:       make(chan []*types.Transaction),
@@ -457,6 +492,12 @@ func (es *EventSystem) handleChainEvent(filters filterIndex, ev core.ChainEvent)
 	}
 }
 
+func (es *EventSystem) handleFinalityEvent(filters filterIndex, typ Type, header *types.Header) {
+	for _, f := range filters[typ] {
+		f.headers <- header
+	}
+}
+
 // eventLoop (un)installs filters and processes mux events.
 func (es *EventSystem) eventLoop() {
 	// Ensure all subscriptions get cleaned up
@@ -465,6 +506,8 @@ func (es *EventSystem) eventLoop() {
 		es.logsSub.Unsubscribe()
 		es.rmLogsSub.Unsubscribe()
 		es.chainSub.Unsubscribe()
+		es.finalSub.Unsubscribe()
+		es.safeSub.Unsubscribe()
 	}()
 
 	index := make(filterIndex)
@@ -482,6 +525,10 @@ func (es *EventSystem) eventLoop() {
 			es.handleLogs(index, ev.Logs)
 		case ev := <-es.chainCh:
 			es.handleChainEvent(index, ev)
+		case ev := <-es.finalCh:
+			es.handleFinalityEvent(index, FinalizedHeadsSubscription, ev.Header)
+		case ev := <-es.safeCh:
+			es.handleFinalityEvent(index, SafeHeadsSubscription, ev.Header)
 
 		case f := <-es.install:
 			index[f.typ][f.id] = f
@@ -500,6 +547,10 @@ func (es *EventSystem) eventLoop() {
 			return
 		case <-es.chainSub.Err():
 			return
+		case <-es.finalSub.Err():
+			return
+		case <-es.safeSub.Err():
+			return
 		}
 	}
 }
@@ -47,6 +47,8 @@ type testBackend struct {
 	logsFeed        event.Feed
 	rmLogsFeed      event.Feed
 	chainFeed       event.Feed
+	finalizedFeed   event.Feed
+	safeFeed        event.Feed
 	pendingBlock    *types.Block
 	pendingReceipts types.Receipts
 }
@@ -160,6 +162,14 @@ func (b *testBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subsc
 	return b.chainFeed.Subscribe(ch)
 }
 
+func (b *testBackend) SubscribeFinalizedHeadEvent(ch chan<- core.FinalizedHeadEvent) event.Subscription {
+	return b.finalizedFeed.Subscribe(ch)
+}
+
+func (b *testBackend) SubscribeSafeHeadEvent(ch chan<- core.SafeHeadEvent) event.Subscription {
+	return b.safeFeed.Subscribe(ch)
+}
+
 func (b *testBackend) CurrentView() *filtermaps.ChainView {
 	head := b.CurrentBlock()
 	return filtermaps.NewChainView(b, head.Number.Uint64(), head.Hash())
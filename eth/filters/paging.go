@@ -0,0 +1,273 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/filtermaps"
+	"github.com/ethereum/go-ethereum/core/history"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxLogsPageSize is the maximum number of logs returned in a single page.
+const maxLogsPageSize = 10000
+
+var (
+	errInvalidPageLimit  = invalidParamsErr("page limit must be between 1 and %d", maxLogsPageSize)
+	errInvalidPageCursor = invalidParamsErr("invalid page cursor")
+	errPageCursorReorged = invalidParamsErr("chain reorganized since the page cursor was issued")
+)
+
+// LogsPage is a page of logs returned by eth_getLogsPage. If the query has
+// more results, Cursor is set and can be passed to the next call in order to
+// continue the search.
+type LogsPage struct {
+	Logs   []*types.Log  `json:"logs"`
+	Cursor hexutil.Bytes `json:"cursor,omitempty"`
+}
+
+// pageCursor is the decoded form of the opaque cursor handed out with a page.
+// It points at the first log not yet delivered, and pins the last block of the
+// searched range by hash, so the walk fails instead of returning inconsistent
+// results if that block is reorged out.
+type pageCursor struct {
+	BlockNumber uint64
+	LogIndex    uint64
+	Head        common.Hash
+}
+
+// GetLogsPage returns at most limit logs matching the given criteria. The end
+// of the range is pinned when the first page is requested, so subsequent pages
+// walk the same range even if the chain advances. The fromBlock and toBlock
+// criteria are ignored when a cursor is given.
+//
+// If a range limit is configured, a single page searches at most that many
+// blocks; a page may then be incomplete but still carry a cursor.
+func (api *FilterAPI) GetLogsPage(ctx context.Context, crit FilterCriteria, limit hexutil.Uint64, cursor *hexutil.Bytes) (*LogsPage, error) {
+	if limit == 0 || limit > maxLogsPageSize {
+		return nil, errInvalidPageLimit
+	}
+	if len(crit.Topics) > maxTopics {
+		return nil, errExceedMaxTopics
+	}
+	if api.logQueryLimit != 0 {
+		if len(crit.Addresses) > api.logQueryLimit {
+			return nil, errExceedLogQueryLimit
+		}
+		for _, topics := range crit.Topics {
+			if len(topics) > api.logQueryLimit {
+				return nil, errExceedLogQueryLimit
+			}
+		}
+	}
+	if crit.BlockHash != nil {
+		return nil, invalidParamsErr("blockHash is not supported by paginated queries")
+	}
+	var (
+		from filtermaps.LogPosition
+		head *types.Header
+		err  error
+	)
+	if cursor == nil {
+		begin := rpc.LatestBlockNumber
+		if crit.FromBlock != nil {
+			begin = rpc.BlockNumber(crit.FromBlock.Int64())
+		}
+		end := rpc.LatestBlockNumber
+		if crit.ToBlock != nil {
+			end = rpc.BlockNumber(crit.ToBlock.Int64())
+		}
+		if begin == rpc.PendingBlockNumber || end == rpc.PendingBlockNumber {
+			return nil, errPendingLogsUnsupported
+		}
+		first, err := api.sys.backend.HeaderByNumber(ctx, begin)
+		if err != nil {
+			return nil, err
+		}
+		if head, err = api.sys.backend.HeaderByNumber(ctx, end); err != nil {
+			return nil, err
+		}
+		if first == nil || head == nil {
+			return nil, errUnknownBlock
+		}
+		if first.Number.Cmp(head.Number) > 0 {
+			return nil, errInvalidBlockRange
+		}
+		from = filtermaps.LogPosition{BlockNumber: first.Number.Uint64()}
+	} else {
+		var dec pageCursor
+		if err := rlp.DecodeBytes(*cursor, &dec); err != nil {
+			return nil, errInvalidPageCursor
+		}
+		if head, err = api.canonicalHeader(ctx, dec.Head); err != nil {
+			return nil, err
+		}
+		if dec.BlockNumber > head.Number.Uint64()+1 {
+			return nil, errInvalidPageCursor
+		}
+		from = filtermaps.LogPosition{BlockNumber: dec.BlockNumber, LogIndex: uint(dec.LogIndex)}
+	}
+	if from.BlockNumber < api.sys.backend.HistoryPruningCutoff() {
+		return nil, &history.PrunedHistoryError{}
+	}
+	last := head.Number.Uint64()
+	if api.rangeLimit != 0 && last-from.BlockNumber >= api.rangeLimit {
+		last = from.BlockNumber + api.rangeLimit - 1
+	}
+	filter := newFilter(api.sys, crit.Addresses, crit.Topics)
+	logs, next, err := filter.pagedLogs(ctx, from, last, int(limit))
+	if err != nil {
+		return nil, err
+	}
+	// Make sure the range wasn't reorged while searching.
+	if _, err := api.canonicalHeader(ctx, head.Hash()); err != nil {
+		return nil, err
+	}
+	page := &LogsPage{Logs: returnLogs(logs)}
+	if next.BlockNumber <= head.Number.Uint64() {
+		page.Cursor, err = rlp.EncodeToBytes(&pageCursor{
+			BlockNumber: next.BlockNumber,
+			LogIndex:    uint64(next.LogIndex),
+			Head:        head.Hash(),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// canonicalHeader returns the header with the given hash if it is part of the
+// canonical chain.
+func (api *FilterAPI) canonicalHeader(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	header, err := api.sys.backend.HeaderByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, errPageCursorReorged
+	}
+	canon, err := api.sys.backend.HeaderByNumber(ctx, rpc.BlockNumber(header.Number.Uint64()))
+	if err != nil {
+		return nil, err
+	}
+	if canon == nil || canon.Hash() != hash {
+		return nil, errPageCursorReorged
+	}
+	return header, nil
+}
+
+// pagedLogs returns at most limit matching logs from the canonical chain,
+// starting at the given position and ending with the last block. It returns
+// the position of the first log that has not been searched yet, which is past
+// the last block if the whole range has been searched.
+//
+// The indexed part of the range is searched with the log index, resuming the
+// matcher where the previous iteration stopped. Blocks not covered by the index
+// are searched one by one.
+func (f *Filter) pagedLogs(ctx context.Context, from filtermaps.LogPosition, last uint64, limit int) ([]*types.Log, filtermaps.LogPosition, error) {
+	mb := f.sys.backend.NewMatcherBackend()
+	defer mb.Close()
+
+	syncRange, err := mb.SyncLogIndex(ctx)
+	if err != nil {
+		return nil, from, err
+	}
+	var (
+		logs      []*types.Log
+		pos       = from
+		unindexed bool // set for match-all criteria which the index can't serve
+	)
+	// add appends a log to the results and reports whether the page is full.
+	add := func(log *types.Log) bool {
+		logs = append(logs, log)
+		pos = filtermaps.LogPosition{BlockNumber: log.BlockNumber, LogIndex: log.Index + 1}
+		return len(logs) == limit
+	}
+	for pos.BlockNumber <= last {
+		if err := ctx.Err(); err != nil {
+			return nil, from, err
+		}
+		indexed := syncRange.IndexedBlocks.Intersection(common.NewRange(pos.BlockNumber, last+1-pos.BlockNumber))
+		if !unindexed && !indexed.IsEmpty() && indexed.First() == pos.BlockNumber {
+			potential, complete, err := filtermaps.GetPotentialMatchesFrom(ctx, mb, pos, indexed.Last(), limit-len(logs), f.addresses, f.topics)
+			if errors.Is(err, filtermaps.ErrMatchAll) {
+				unindexed = true
+				continue
+			}
+			if err != nil {
+				return nil, from, err
+			}
+			// Discard the results if the index changed during the search.
+			searched := indexed.Last()
+			if !complete && len(potential) > 0 {
+				searched = potential[len(potential)-1].BlockNumber
+			}
+			if syncRange, err = mb.SyncLogIndex(ctx); err != nil {
+				return nil, from, err
+			}
+			if !syncRange.ValidBlocks.Includes(pos.BlockNumber) || !syncRange.ValidBlocks.Includes(searched) {
+				continue
+			}
+			for _, log := range filterLogs(potential, nil, nil, f.addresses, f.topics) {
+				if add(log) {
+					return logs, pos, nil
+				}
+			}
+			if complete {
+				pos = filtermaps.LogPosition{BlockNumber: indexed.Last() + 1}
+			} else if len(potential) > 0 {
+				final := potential[len(potential)-1]
+				pos = filtermaps.LogPosition{BlockNumber: final.BlockNumber, LogIndex: final.Index + 1}
+			}
+			continue
+		}
+		// Search the blocks not covered by the index one by one.
+		end := last
+		if !unindexed && !indexed.IsEmpty() {
+			end = indexed.First() - 1
+		}
+		for ; pos.BlockNumber <= end; pos = (filtermaps.LogPosition{BlockNumber: pos.BlockNumber + 1}) {
+			header, err := f.sys.backend.HeaderByNumber(ctx, rpc.BlockNumber(pos.BlockNumber))
+			if err != nil {
+				return nil, from, err
+			}
+			if header == nil {
+				return nil, from, errors.New("header not found")
+			}
+			found, err := f.blockLogs(ctx, header)
+			if err != nil {
+				return nil, from, err
+			}
+			for _, log := range found {
+				if log.Index < pos.LogIndex {
+					continue
+				}
+				if add(log) {
+					return logs, pos, nil
+				}
+			}
+		}
+	}
+	return logs, pos, nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// walkLogsPages retrieves all pages of a query and returns the concatenated
+// logs along with the number of pages.
+func walkLogsPages(t *testing.T, api *FilterAPI, crit FilterCriteria, limit uint64) ([]*types.Log, int) {
+	t.Helper()
+
+	var (
+		logs   []*types.Log
+		cursor *hexutil.Bytes
+		pages  int
+	)
+	for {
+		page, err := api.GetLogsPage(context.Background(), crit, hexutil.Uint64(limit), cursor)
+		if err != nil {
+			t.Fatalf("failed to retrieve page %d: %v", pages, err)
+		}
+		if uint64(len(page.Logs)) > limit {
+			t.Fatalf("page %d exceeds limit: have %d logs", pages, len(page.Logs))
+		}
+		pages++
+		logs = append(logs, page.Logs...)
+		if page.Cursor == nil {
+			return logs, pages
+		}
+		cursor = &page.Cursor
+	}
+}
+
+func TestGetLogsPage(t *testing.T) {
+	t.Parallel()
+
+	var (
+		addr           = common.Address{0xaa}
+		sys, chain, _  = newResumeTestChain(t, addr, false)
+		api            = NewFilterAPI(sys)
+		indexedCrit    = FilterCriteria{FromBlock: big.NewInt(1), Addresses: []common.Address{addr}}
+		unindexedCrit  = FilterCriteria{FromBlock: big.NewInt(1)} // match-all criteria are not served by the index
+		expectedBlocks = len(chain)
+	)
+	for _, crit := range []FilterCriteria{indexedCrit, unindexedCrit} {
+		for _, limit := range []uint64{1, 3, 20, 100} {
+			logs, pages := walkLogsPages(t, api, crit, limit)
+			if len(logs) != 2*expectedBlocks {
+				t.Fatalf("limit %d: wrong number of logs: have %d, want %d", limit, len(logs), 2*expectedBlocks)
+			}
+			for i, log := range logs {
+				if log.BlockHash != chain[i/2].Hash() || log.Index != uint(i%2) {
+					t.Fatalf("limit %d: log %d: wrong log: block %d index %d", limit, i, log.BlockNumber, log.Index)
+				}
+			}
+			if want := (len(logs) + int(limit) - 1) / int(limit); pages < want {
+				t.Errorf("limit %d: wrong number of pages: have %d, want at least %d", limit, pages, want)
+			}
+		}
+	}
+	// With a range limit, pages cover at most that many blocks.
+	api.rangeLimit = 3
+	logs, pages := walkLogsPages(t, api, indexedCrit, 100)
+	if len(logs) != 2*expectedBlocks {
+		t.Fatalf("wrong number of logs with range limit: have %d, want %d", len(logs), 2*expectedBlocks)
+	}
+	if pages != 4 {
+		t.Errorf("wrong number of pages with range limit: have %d, want 4", pages)
+	}
+}
+
+func TestGetLogsPageReorg(t *testing.T) {
+	t.Parallel()
+
+	var (
+		addr         = common.Address{0xaa}
+		sys, _, fork = newResumeTestChain(t, addr, false)
+		api          = NewFilterAPI(sys)
+		crit         = FilterCriteria{FromBlock: big.NewInt(1), Addresses: []common.Address{addr}}
+	)
+	page, err := api.GetLogsPage(context.Background(), crit, 5, nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve first page: %v", err)
+	}
+	if len(page.Logs) != 5 || page.Cursor == nil {
+		t.Fatalf("wrong first page: %d logs, cursor %v", len(page.Logs), page.Cursor)
+	}
+	// Reorg the end of the range, the cursor should be rejected.
+	db := sys.backend.ChainDb()
+	for _, block := range fork {
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+	}
+	rawdb.WriteHeadBlockHash(db, fork[len(fork)-1].Hash())
+
+	if _, err := api.GetLogsPage(context.Background(), crit, 5, &page.Cursor); !errors.Is(err, errPageCursorReorged) {
+		t.Fatalf("wrong error for reorged cursor: %v", err)
+	}
+	// Garbage cursors are rejected.
+	garbage := hexutil.Bytes{0x01, 0x02}
+	if _, err := api.GetLogsPage(context.Background(), crit, 5, &garbage); !errors.Is(err, errInvalidPageCursor) {
+		t.Fatalf("wrong error for invalid cursor: %v", err)
+	}
+	if _, err := api.GetLogsPage(context.Background(), crit, 0, nil); !errors.Is(err, errInvalidPageLimit) {
+		t.Fatalf("wrong error for zero limit: %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/history"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxPersistentFilters is the maximum number of persistent log filters installed
+// at the same time. As they are stored in the database, they are limited to
+// keep the clients from filling it up.
+const maxPersistentFilters = 1024
+
+var errTooManyFilters = errors.New("too many persistent filters")
+
+// persistedFilter is the database representation of a persistent log filter.
+// Instead of buffering the logs emitted since the last poll, a persistent
+// filter only tracks the last block delivered to the client. Every poll
+// catches up from there using the log index, which makes the filter survive
+// node restarts.
+type persistedFilter struct {
+	Criteria ethereum.FilterQuery `json:"criteria"`
+	Cursor   Cursor               `json:"cursor"`
+	LastPoll uint64               `json:"lastPoll"` // unix time of the last poll
+}
+
+// restoreFilters loads the persistent log filters from the database, dropping
+// the ones that expired while the node was down.
+func (api *FilterAPI) restoreFilters() {
+	db := api.sys.backend.ChainDb()
+	for id, blob := range rawdb.ReadPersistentFilters(db) {
+		var stored persistedFilter
+		if err := json.Unmarshal(blob, &stored); err != nil {
+			log.Warn("Dropping corrupt persistent log filter", "id", id, "err", err)
+			api.deleteFilter(rpc.ID(id))
+			continue
+		}
+		remaining := api.timeout - time.Since(time.Unix(int64(stored.LastPoll), 0))
+		if remaining <= 0 || len(api.filters) >= maxPersistentFilters {
+			api.deleteFilter(rpc.ID(id))
+			continue
+		}
+		cursor := stored.Cursor
+		api.filters[rpc.ID(id)] = &filter{
+			typ:        LogsSubscription,
+			crit:       FilterCriteria(stored.Criteria),
+			deadline:   time.NewTimer(remaining),
+			persistent: true,
+			cursor:     &cursor,
+		}
+	}
+	if len(api.filters) > 0 {
+		log.Info("Restored persistent log filters", "count", len(api.filters))
+	}
+}
+
+// newPersistentFilter installs a persistent log filter which starts delivering
+// logs from the current head onwards.
+func (api *FilterAPI) newPersistentFilter(crit FilterCriteria) (rpc.ID, error) {
+	if len(crit.Topics) > maxTopics {
+		return "", errExceedMaxTopics
+	}
+	if api.logQueryLimit != 0 {
+		if len(crit.Addresses) > api.logQueryLimit {
+			return "", errExceedLogQueryLimit
+		}
+		for _, topics := range crit.Topics {
+			if len(topics) > api.logQueryLimit {
+				return "", errExceedLogQueryLimit
+			}
+		}
+	}
+	pending := rpc.PendingBlockNumber.Int64()
+	if (crit.FromBlock != nil && crit.FromBlock.Int64() == pending) || (crit.ToBlock != nil && crit.ToBlock.Int64() == pending) {
+		return "", errPendingLogsUnsupported
+	}
+	head := api.sys.backend.CurrentHeader()
+	if head == nil {
+		return "", errors.New("current header not found")
+	}
+	var (
+		id = rpc.NewID()
+		f  = &filter{
+			typ:        LogsSubscription,
+			crit:       crit,
+			deadline:   time.NewTimer(api.timeout),
+			persistent: true,
+			cursor:     &Cursor{BlockNumber: hexutil.Uint64(head.Number.Uint64()), BlockHash: head.Hash()},
+		}
+	)
+	api.filtersMu.Lock()
+	defer api.filtersMu.Unlock()
+
+	if api.persistentFilters() >= maxPersistentFilters {
+		return "", errTooManyFilters
+	}
+	if err := api.persistFilter(id, f); err != nil {
+		return "", err
+	}
+	api.filters[id] = f
+	return id, nil
+}
+
+// persistentFilters returns the number of installed persistent filters. This
+// function assumes the filtersMu is already held.
+func (api *FilterAPI) persistentFilters() int {
+	var n int
+	for _, f := range api.filters {
+		if f.persistent {
+			n++
+		}
+	}
+	return n
+}
+
+// persistFilter writes the filter into the database.
+func (api *FilterAPI) persistFilter(id rpc.ID, f *filter) error {
+	blob, err := json.Marshal(&persistedFilter{
+		Criteria: ethereum.FilterQuery(f.crit),
+		Cursor:   *f.cursor,
+		LastPoll: uint64(time.Now().Unix()),
+	})
+	if err != nil {
+		return err
+	}
+	return rawdb.WritePersistentFilter(api.sys.backend.ChainDb(), string(id), blob)
+}
+
+// deleteFilter removes the filter from the database. Failures are only logged,
+// the filter is dropped at the next restart after it expired anyway.
+func (api *FilterAPI) deleteFilter(id rpc.ID) {
+	if err := rawdb.DeletePersistentFilter(api.sys.backend.ChainDb(), string(id)); err != nil {
+		log.Warn("Failed to delete persistent log filter", "id", id, "err", err)
+	}
+}
+
+// pollPersistentFilter returns the logs matching a persistent filter that were
+// emitted since the last poll, and advances the filter to the current head.
+// Filters that can no longer catch up are uninstalled.
+func (api *FilterAPI) pollPersistentFilter(id rpc.ID, f *filter) ([]*types.Log, error) {
+	f.pollMu.Lock()
+	defer f.pollMu.Unlock()
+
+	logs, head, err := api.sys.resumeLogs(context.Background(), f.crit, f.cursor, api.rangeLimit)
+	if err != nil {
+		var pruned *history.PrunedHistoryError
+		if errors.Is(err, errUnknownCursor) || errors.Is(err, errCursorTooOld) || errors.Is(err, errCursorReorgDeep) || errors.As(err, &pruned) {
+			api.UninstallFilter(id)
+		}
+		return nil, err
+	}
+	api.filtersMu.Lock()
+	defer api.filtersMu.Unlock()
+
+	// Don't resurrect the filter if it was uninstalled in the meantime.
+	if api.filters[id] != f {
+		return nil, errFilterNotFound
+	}
+	cursor := f.cursor
+	f.cursor = &Cursor{BlockNumber: hexutil.Uint64(head.Number.Uint64()), BlockHash: head.Hash()}
+	if err := api.persistFilter(id, f); err != nil {
+		f.cursor = cursor
+		return nil, err
+	}
+	return returnLogs(logs), nil
+}
@@ -0,0 +1,210 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/filtermaps"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/triedb"
+)
+
+// TestPersistentFilter checks that persistent log filters survive a restart of
+// the filter API and catch up with the chain, including reorgs, on every poll.
+func TestPersistentFilter(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		addr    = common.Address{0xaa}
+		backend = &testBackend{db: db}
+		config  = Config{PersistFilters: true}
+		gspec   = &core.Genesis{
+			Config:  params.TestChainConfig,
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		generate = func(extra string) func(int, *core.BlockGen) {
+			return func(i int, gen *core.BlockGen) {
+				gen.SetExtra([]byte(extra))
+				for j := 0; j < 2; j++ {
+					gen.AddUncheckedReceipt(makeReceipt(addr))
+					gen.AddUncheckedTx(types.NewTransaction(uint64(j), common.HexToAddress("0x999"), big.NewInt(999), 999, gen.BaseFee(), nil))
+				}
+			}
+		}
+	)
+	defer db.Close()
+
+	genDb, chain, receipts := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), 10, generate("main"))
+	fork, forkReceipts := core.GenerateChain(gspec.Config, chain[4], ethash.NewFaker(), genDb, 5, generate("fork"))
+	gspec.MustCommit(db, triedb.NewDatabase(db, triedb.HashDefaults))
+
+	write := func(blocks []*types.Block, receipts []types.Receipts) {
+		for i, block := range blocks {
+			rawdb.WriteBlock(db, block)
+			rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+			rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+			rawdb.WriteHeadBlockHash(db, block.Hash())
+		}
+	}
+	poll := func(api *FilterAPI, id rpc.ID) []*types.Log {
+		t.Helper()
+		changes, err := api.GetFilterChanges(id)
+		if err != nil {
+			t.Fatalf("failed to poll filter: %v", err)
+		}
+		return changes.([]*types.Log)
+	}
+	write(chain[:5], receipts[:5])
+	backend.startFilterMaps(0, false, filtermaps.DefaultParams)
+	defer backend.stopFilterMaps()
+
+	api := NewFilterAPI(NewFilterSystem(backend, config))
+	id, err := api.NewFilter(FilterCriteria{Addresses: []common.Address{addr}})
+	if err != nil {
+		t.Fatalf("failed to install filter: %v", err)
+	}
+	if logs := poll(api, id); len(logs) != 0 {
+		t.Fatalf("wrong number of logs before new blocks: have %d, want 0", len(logs))
+	}
+	write(chain[5:], receipts[5:])
+	logs := poll(api, id)
+	if len(logs) != 10 {
+		t.Fatalf("wrong number of logs: have %d, want 10", len(logs))
+	}
+	if logs[0].BlockNumber != 6 || logs[9].BlockNumber != 10 {
+		t.Errorf("wrong log range: blocks %d-%d", logs[0].BlockNumber, logs[9].BlockNumber)
+	}
+
+	// Restart the API and reorg the chain while it's down.
+	write(fork, forkReceipts)
+	api = NewFilterAPI(NewFilterSystem(backend, config))
+
+	logs = poll(api, id)
+	if len(logs) != 20 {
+		t.Fatalf("wrong number of logs after reorg: have %d, want 20", len(logs))
+	}
+	for i, log := range logs[:10] {
+		if !log.Removed || log.BlockHash != chain[5+i/2].Hash() {
+			t.Errorf("log %d: want removed log of block %d, have removed=%v block %d", i, 6+i/2, log.Removed, log.BlockNumber)
+		}
+	}
+	for i, log := range logs[10:] {
+		if log.Removed || log.BlockHash != fork[i/2].Hash() {
+			t.Errorf("log %d: want log of side chain block %d, have removed=%v block %d", i, 6+i/2, log.Removed, log.BlockNumber)
+		}
+	}
+	if logs := poll(api, id); len(logs) != 0 {
+		t.Fatalf("wrong number of logs after catching up: have %d, want 0", len(logs))
+	}
+
+	// Uninstalling removes the filter from the database.
+	if !api.UninstallFilter(id) {
+		t.Fatal("failed to uninstall filter")
+	}
+	if stored := rawdb.ReadPersistentFilters(db); len(stored) != 0 {
+		t.Fatalf("uninstalled filter still stored: %v", stored)
+	}
+}
+
+// TestPersistentFilterExpiry checks that persistent filters which expired while
+// the node was down are dropped on startup.
+func TestPersistentFilterExpiry(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		backend = &testBackend{db: db}
+		config  = Config{PersistFilters: true, Timeout: time.Second}
+		gspec   = &core.Genesis{
+			Config:  params.TestChainConfig,
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+	)
+	defer db.Close()
+	gspec.MustCommit(db, triedb.NewDatabase(db, triedb.HashDefaults))
+
+	api := NewFilterAPI(NewFilterSystem(backend, config))
+	id, err := api.NewFilter(FilterCriteria{})
+	if err != nil {
+		t.Fatalf("failed to install filter: %v", err)
+	}
+	if _, ok := rawdb.ReadPersistentFilters(db)[string(id)]; !ok {
+		t.Fatal("filter not stored")
+	}
+	time.Sleep(config.Timeout)
+
+	api = NewFilterAPI(NewFilterSystem(backend, config))
+	if _, err := api.GetFilterChanges(id); err != errFilterNotFound {
+		t.Fatalf("expired filter still installed: %v", err)
+	}
+	if stored := rawdb.ReadPersistentFilters(db); len(stored) != 0 {
+		t.Fatalf("expired filter still stored: %v", stored)
+	}
+}
+
+// TestPersistentFilterLimit checks that the number of persistent filters is
+// limited.
+func TestPersistentFilterLimit(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		backend = &testBackend{db: db}
+		config  = Config{PersistFilters: true, Timeout: time.Minute}
+		gspec   = &core.Genesis{
+			Config:  params.TestChainConfig,
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+	)
+	defer db.Close()
+	gspec.MustCommit(db, triedb.NewDatabase(db, triedb.HashDefaults))
+
+	api := NewFilterAPI(NewFilterSystem(backend, config))
+	var first rpc.ID
+	for i := 0; i < maxPersistentFilters; i++ {
+		id, err := api.NewFilter(FilterCriteria{})
+		if err != nil {
+			t.Fatalf("failed to install filter %d: %v", i, err)
+		}
+		if i == 0 {
+			first = id
+		}
+	}
+	if _, err := api.NewFilter(FilterCriteria{}); err != errTooManyFilters {
+		t.Fatalf("wrong error: %v, want %v", err, errTooManyFilters)
+	}
+	if stored := rawdb.ReadPersistentFilters(db); len(stored) != maxPersistentFilters {
+		t.Fatalf("wrong number of stored filters: %d", len(stored))
+	}
+	// Uninstalling a filter frees its slot.
+	if !api.UninstallFilter(first) {
+		t.Fatal("failed to uninstall filter")
+	}
+	if _, err := api.NewFilter(FilterCriteria{}); err != nil {
+		t.Fatalf("failed to install filter: %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/history"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxResumeBlocks is the maximum number of blocks a resumed subscription is
+// allowed to replay (or unwind in case of a reorg) before going live.
+const maxResumeBlocks = 8192
+
+var (
+	errUnknownCursor   = invalidParamsErr("unknown cursor block")
+	errCursorTooOld    = invalidParamsErr("cursor is more than %d blocks behind the head", maxResumeBlocks)
+	errCursorReorgDeep = invalidParamsErr("cursor is on a side chain more than %d blocks deep", maxResumeBlocks)
+)
+
+// Cursor identifies the last event delivered on a subscription. Every event
+// emitted by the newHeads and logs subscriptions carries its own cursor: a
+// header is identified by its number and hash, a log additionally by its
+// index within the block. A client that lost its connection can pass the
+// cursor of the last event it received when subscribing again, and the server
+// will deliver the events it missed before going live.
+//
+// A nil LogIndex means that all events of the block have been delivered.
+type Cursor struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	LogIndex    *hexutil.Uint  `json:"logIndex,omitempty"`
+}
+
+// resumePoint is the result of locating a cursor relative to the canonical
+// chain: the cursor block is either canonical, or it sits on a side chain that
+// forked off at the returned ancestor.
+type resumePoint struct {
+	ancestor *types.Header   // last canonical block the client is known to have seen
+	dropped  []*types.Header // side chain blocks seen by the client, ascending
+	head     *types.Header   // canonical head at the time of resumption
+}
+
+// locateCursor resolves the cursor against the current canonical chain.
+func (sys *FilterSystem) locateCursor(ctx context.Context, cursor *Cursor) (*resumePoint, error) {
+	head := sys.backend.CurrentHeader()
+	if head == nil {
+		return nil, errors.New("current header not found")
+	}
+	header, err := sys.backend.HeaderByHash(ctx, cursor.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil || header.Number.Uint64() != uint64(cursor.BlockNumber) {
+		return nil, errUnknownCursor
+	}
+	if header.Number.Uint64() < sys.backend.HistoryPruningCutoff() {
+		return nil, &history.PrunedHistoryError{}
+	}
+	// Walk back along the client's chain until a canonical block is found,
+	// collecting the blocks that were dropped since the client saw them.
+	var dropped []*types.Header
+	for {
+		canon, err := sys.backend.HeaderByNumber(ctx, rpc.BlockNumber(header.Number.Uint64()))
+		if err != nil {
+			return nil, err
+		}
+		if canon != nil && canon.Hash() == header.Hash() {
+			break
+		}
+		if len(dropped) >= maxResumeBlocks || header.Number.Sign() == 0 {
+			return nil, errCursorReorgDeep
+		}
+		dropped = append(dropped, header)
+		if header, err = sys.backend.HeaderByHash(ctx, header.ParentHash); err != nil {
+			return nil, err
+		}
+		if header == nil {
+			return nil, errUnknownCursor
+		}
+	}
+	if head.Number.Uint64() > header.Number.Uint64()+maxResumeBlocks {
+		return nil, errCursorTooOld
+	}
+	slices.Reverse(dropped)
+	return &resumePoint{ancestor: header, dropped: dropped, head: head}, nil
+}
+
+// resumeHeaders returns the canonical headers the client has not yet seen,
+// starting after the given cursor up to and including the current head.
+func (sys *FilterSystem) resumeHeaders(ctx context.Context, cursor *Cursor) ([]*types.Header, error) {
+	point, err := sys.locateCursor(ctx, cursor)
+	if err != nil {
+		return nil, err
+	}
+	var headers []*types.Header
+	for number := point.ancestor.Number.Uint64() + 1; number <= point.head.Number.Uint64(); number++ {
+		header, err := sys.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+		if err != nil {
+			return nil, err
+		}
+		if header == nil {
+			return nil, errors.New("header not found")
+		}
+		headers = append(headers, header)
+	}
+	return headers, nil
+}
+
+// resumeLogs returns the logs matching the given criteria that the client has
+// missed since the given cursor. If the cursor block was reorged out, the logs
+// previously delivered from the dropped blocks are returned first, flagged as
+// removed, followed by the matching logs of the new canonical chain. The head
+// up to which logs were collected is returned along with them.
+func (sys *FilterSystem) resumeLogs(ctx context.Context, crit FilterCriteria, cursor *Cursor, rangeLimit uint64) ([]*types.Log, *types.Header, error) {
+	point, err := sys.locateCursor(ctx, cursor)
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		logs   []*types.Log
+		filter = newFilter(sys, crit.Addresses, crit.Topics)
+	)
+	for _, header := range point.dropped {
+		found, err := filter.blockLogs(ctx, header)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, log := range found {
+			if header.Hash() == cursor.BlockHash && cursor.LogIndex != nil && log.Index > uint(*cursor.LogIndex) {
+				continue // never delivered to the client
+			}
+			if crit.ToBlock != nil && crit.ToBlock.Sign() >= 0 && crit.ToBlock.Uint64() < log.BlockNumber {
+				continue
+			}
+			removed := *log
+			removed.Removed = true
+			logs = append(logs, &removed)
+		}
+	}
+	// Replay the canonical logs. If the cursor block is still canonical and
+	// the client has only seen part of its logs, start within that block.
+	begin := point.ancestor.Number.Uint64() + 1
+	if len(point.dropped) == 0 && cursor.LogIndex != nil {
+		begin = point.ancestor.Number.Uint64()
+	}
+	if crit.FromBlock != nil && crit.FromBlock.Sign() >= 0 && crit.FromBlock.Uint64() > begin {
+		begin = crit.FromBlock.Uint64()
+	}
+	end := point.head.Number.Uint64()
+	if crit.ToBlock != nil && crit.ToBlock.Sign() >= 0 && crit.ToBlock.Uint64() < end {
+		end = crit.ToBlock.Uint64()
+	}
+	if begin > end {
+		return logs, point.head, nil
+	}
+	found, err := sys.NewRangeFilter(int64(begin), int64(end), crit.Addresses, crit.Topics, rangeLimit).Logs(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, log := range found {
+		if log.BlockHash == cursor.BlockHash && cursor.LogIndex != nil && log.Index <= uint(*cursor.LogIndex) {
+			continue // already delivered to the client
+		}
+		logs = append(logs, log)
+	}
+	return logs, point.head, nil
+}
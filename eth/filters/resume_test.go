@@ -0,0 +1,190 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/filtermaps"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/triedb"
+)
+
+// newResumeTestChain creates a chain of ten blocks and a side chain forking off
+// at block five, each block containing two logs of the given address. If
+// reorged is set, the side chain is made canonical.
+func newResumeTestChain(t *testing.T, addr common.Address, reorged bool) (*FilterSystem, []*types.Block, []*types.Block) {
+	var (
+		db           = rawdb.NewMemoryDatabase()
+		backend, sys = newTestFilterSystem(db, Config{})
+		gspec        = &core.Genesis{
+			Config:  params.TestChainConfig,
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		generate = func(extra string) func(int, *core.BlockGen) {
+			return func(i int, gen *core.BlockGen) {
+				gen.SetExtra([]byte(extra))
+				for j := 0; j < 2; j++ {
+					gen.AddUncheckedReceipt(makeReceipt(addr))
+					gen.AddUncheckedTx(types.NewTransaction(uint64(j), common.HexToAddress("0x999"), big.NewInt(999), 999, gen.BaseFee(), nil))
+				}
+			}
+		}
+	)
+	t.Cleanup(func() { db.Close() })
+
+	genDb, chain, receipts := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), 10, generate("main"))
+	fork, forkReceipts := core.GenerateChain(gspec.Config, chain[4], ethash.NewFaker(), genDb, 5, generate("fork"))
+	gspec.MustCommit(db, triedb.NewDatabase(db, triedb.HashDefaults))
+
+	write := func(blocks []*types.Block, receipts []types.Receipts, canonical bool) {
+		for i, block := range blocks {
+			rawdb.WriteBlock(db, block)
+			rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+			if canonical {
+				rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+				rawdb.WriteHeadBlockHash(db, block.Hash())
+			}
+		}
+	}
+	write(chain, receipts, true)
+	if reorged {
+		write(fork, forkReceipts, true)
+	} else {
+		write(fork, forkReceipts, false)
+	}
+	backend.startFilterMaps(0, false, filtermaps.DefaultParams)
+	t.Cleanup(backend.stopFilterMaps)
+
+	return sys, chain, fork
+}
+
+func TestResumeCanonical(t *testing.T) {
+	t.Parallel()
+
+	var (
+		addr             = common.Address{0xaa}
+		sys, chain, fork = newResumeTestChain(t, addr, false)
+		crit             = FilterCriteria{Addresses: []common.Address{addr}}
+	)
+	// Resume after a fully delivered block.
+	cursor := &Cursor{BlockNumber: 6, BlockHash: chain[5].Hash()}
+	headers, err := sys.resumeHeaders(context.Background(), cursor)
+	if err != nil {
+		t.Fatalf("failed to resume headers: %v", err)
+	}
+	if len(headers) != 4 {
+		t.Fatalf("wrong number of headers: have %d, want 4", len(headers))
+	}
+	for i, header := range headers {
+		if header.Hash() != chain[6+i].Hash() {
+			t.Errorf("header %d: wrong hash", i)
+		}
+	}
+	logs, _, err := sys.resumeLogs(context.Background(), crit, cursor, 0)
+	if err != nil {
+		t.Fatalf("failed to resume logs: %v", err)
+	}
+	if len(logs) != 8 {
+		t.Fatalf("wrong number of logs: have %d, want 8", len(logs))
+	}
+	if logs[0].BlockNumber != 7 || logs[0].Index != 0 {
+		t.Errorf("wrong first log: block %d index %d", logs[0].BlockNumber, logs[0].Index)
+	}
+	// Resume within a partially delivered block.
+	index := hexutil.Uint(0)
+	cursor.LogIndex = &index
+	if logs, _, err = sys.resumeLogs(context.Background(), crit, cursor, 0); err != nil {
+		t.Fatalf("failed to resume logs: %v", err)
+	}
+	if len(logs) != 9 {
+		t.Fatalf("wrong number of logs: have %d, want 9", len(logs))
+	}
+	if logs[0].BlockNumber != 6 || logs[0].Index != 1 {
+		t.Errorf("wrong first log: block %d index %d", logs[0].BlockNumber, logs[0].Index)
+	}
+	// Resume from an unknown block.
+	cursor = &Cursor{BlockNumber: 6, BlockHash: common.Hash{0x01}}
+	if _, err := sys.resumeHeaders(context.Background(), cursor); !errors.Is(err, errUnknownCursor) {
+		t.Errorf("wrong error for unknown cursor: %v", err)
+	}
+	// Resume from a block number that doesn't match the hash.
+	cursor = &Cursor{BlockNumber: 7, BlockHash: fork[0].Hash()}
+	if _, err := sys.resumeHeaders(context.Background(), cursor); !errors.Is(err, errUnknownCursor) {
+		t.Errorf("wrong error for mismatching cursor: %v", err)
+	}
+}
+
+func TestResumeReorged(t *testing.T) {
+	t.Parallel()
+
+	var (
+		addr             = common.Address{0xaa}
+		sys, chain, fork = newResumeTestChain(t, addr, true)
+		crit             = FilterCriteria{Addresses: []common.Address{addr}}
+		index            = hexutil.Uint(0)
+	)
+	// The client has seen the first log of block 8 on the dropped chain.
+	cursor := &Cursor{BlockNumber: 8, BlockHash: chain[7].Hash(), LogIndex: &index}
+
+	headers, err := sys.resumeHeaders(context.Background(), cursor)
+	if err != nil {
+		t.Fatalf("failed to resume headers: %v", err)
+	}
+	if len(headers) != len(fork) {
+		t.Fatalf("wrong number of headers: have %d, want %d", len(headers), len(fork))
+	}
+	for i, header := range headers {
+		if header.Hash() != fork[i].Hash() {
+			t.Errorf("header %d: wrong hash", i)
+		}
+	}
+	logs, _, err := sys.resumeLogs(context.Background(), crit, cursor, 0)
+	if err != nil {
+		t.Fatalf("failed to resume logs: %v", err)
+	}
+	// Blocks 6 and 7 are fully removed, block 8 partially, then the side
+	// chain is delivered.
+	if len(logs) != 5+2*len(fork) {
+		t.Fatalf("wrong number of logs: have %d, want %d", len(logs), 5+2*len(fork))
+	}
+	for i, log := range logs[:5] {
+		if !log.Removed {
+			t.Errorf("log %d: not marked as removed", i)
+		}
+		if log.BlockHash != chain[5+i/2].Hash() || log.Index != uint(i%2) {
+			t.Errorf("log %d: wrong removed log: block %d index %d", i, log.BlockNumber, log.Index)
+		}
+	}
+	for i, log := range logs[5:] {
+		if log.Removed {
+			t.Errorf("log %d: marked as removed", i)
+		}
+		if log.BlockHash != fork[i/2].Hash() {
+			t.Errorf("log %d: wrong block %d", i, log.BlockNumber)
+		}
+	}
+}
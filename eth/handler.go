@@ -29,6 +29,7 @@ import (
 
 	"github.com/dchest/siphash"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/txpool"
@@ -100,15 +101,17 @@ type txPool interface {
 // handlerConfig is the collection of initialization parameters to create a full
 // node network handler.
 type handlerConfig struct {
-	NodeID         enode.ID               // P2P node ID used for tx propagation topology
-	Database       ethdb.Database         // Database for direct sync insertions
-	Chain          *core.BlockChain       // Blockchain to serve data from
-	TxPool         txPool                 // Transaction pool to propagate from
-	Network        uint64                 // Network identifier to advertise
-	Sync           ethconfig.SyncMode     // Whether to snap or full sync
-	BloomCache     uint64                 // Megabytes to alloc for snap sync bloom
-	RequiredBlocks map[uint64]common.Hash // Hard coded map of required block hashes for sync challenges
-	SnapV2         bool                   // Whether to advertise and sync via the snap/2 protocol
+	NodeID         enode.ID                      // P2P node ID used for tx propagation topology
+	Database       ethdb.Database                // Database for direct sync insertions
+	Chain          *core.BlockChain              // Blockchain to serve data from
+	TxPool         txPool                        // Transaction pool to propagate from
+	Network        uint64                        // Network identifier to advertise
+	Sync           ethconfig.SyncMode            // Whether to snap or full sync
+	BloomCache     uint64                        // Megabytes to alloc for snap sync bloom
+	RequiredBlocks map[uint64]common.Hash        // Hard coded map of required block hashes for sync challenges
+	SnapV2         bool                          // Whether to advertise and sync via the snap/2 protocol
+	TxPropagation  ethconfig.TxPropagationConfig // Transaction propagation policy
+	Clock          mclock.Clock                  // Clock of the transaction fetcher, the system clock if nil
 }
 
 type handler struct {
@@ -125,6 +128,7 @@ type handler struct {
 	txFetcher      *fetcher.TxFetcher
 	peers          *peerSet
 	txBroadcastKey [16]byte
+	txPropagation  *txPropagation
 
 	txsCh      chan core.NewTxsEvent
 	txsSub     event.Subscription
@@ -156,6 +160,10 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		handlerDoneCh:  make(chan struct{}),
 		handlerStartCh: make(chan struct{}),
 	}
+	var err error
+	if h.txPropagation, err = newTxPropagation(config.TxPropagation); err != nil {
+		return nil, err
+	}
 	// Construct the downloader (long sync)
 	h.downloader = downloader.New(config.Database, config.Sync, h.chain, h.removePeer, h.enableSyncedFeatures, config.SnapV2)
 
@@ -182,7 +190,11 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		}
 		return nil
 	}
-	h.txFetcher = fetcher.NewTxFetcher(h.chain, validateMeta, addTxs, fetchTx, h.removePeer)
+	clock := config.Clock
+	if clock == nil {
+		clock = mclock.System{}
+	}
+	h.txFetcher = fetcher.NewTxFetcherWithClock(h.chain, validateMeta, addTxs, fetchTx, h.removePeer, clock)
 	return h, nil
 }
 
@@ -364,10 +376,12 @@ func (h *handler) runSnapExtension(peer *snap.Peer, handler snap.Handler) error
 	return handler(peer)
 }
 
-// removePeer requests disconnection of a peer.
+// removePeer requests disconnection of a misbehaving peer, penalizing its
+// reputation.
 func (h *handler) removePeer(id string) {
 	peer := h.peers.peer(id)
 	if peer != nil {
+		peer.Peer.Report(p2p.ReputationInvalidData)
 		peer.Peer.Disconnect(p2p.DiscUselessPeer)
 	}
 }
@@ -445,7 +459,8 @@ func (h *handler) Stop() {
 	log.Info("Ethereum protocol stopped")
 }
 
-// BroadcastTransactions will propagate a batch of transactions
+// BroadcastTransactions will propagate a batch of transactions according to the
+// propagation policy. By default:
 // - To a square root of all peers for non-blob transactions
 // - And, separately, as announcements to all peers which are not known to
 // already have the given transaction.
@@ -461,37 +476,41 @@ func (h *handler) BroadcastTransactions(txs types.Transactions) {
 		annos = make(map[*ethPeer][]common.Hash) // Set peer->hash to announce
 
 		signer = types.LatestSigner(h.chain.Config())
-		choice = newBroadcastChoice(h.nodeID, h.txBroadcastKey)
 		peers  = h.peers.all()
+		round  = h.txPropagation.newRound(newBroadcastChoice(h.nodeID, h.txBroadcastKey), peers)
 	)
 
 	for _, tx := range txs {
-		var directSet map[*ethPeer]struct{}
+		var (
+			directSet map[*ethPeer]struct{}
+			blob      = tx.Type() == types.BlobTxType
+			large     = !blob && tx.Size() > txMaxBroadcastSize
+		)
 		switch {
-		case tx.Type() == types.BlobTxType:
+		case blob:
 			blobTxs++
-		case tx.Size() > txMaxBroadcastSize:
+		case large:
 			largeTxs++
 		default:
 			// Get transaction sender address. Here we can ignore any error
 			// since we're just interested in any value.
 			txSender, _ := types.Sender(signer, tx)
-			directSet = choice.choosePeers(peers, txSender)
+			directSet = round.directPeers(txSender)
 		}
 
 		for _, peer := range peers {
 			if peer.KnownTransaction(tx.Hash()) {
 				continue
 			}
-			if _, ok := directSet[peer]; ok {
-				// Send direct.
+			switch round.decide(peer, directSet, blob, large) {
+			case txBroadcast:
 				txset[peer] = append(txset[peer], tx.Hash())
-			} else {
-				// Send announcement.
+			case txAnnounce:
 				annos[peer] = append(annos[peer], tx.Hash())
 			}
 		}
 	}
+	round.finish()
 
 	for peer, hashes := range txset {
 		directCount += len(hashes)
@@ -655,6 +674,7 @@ func (st *blockRangeState) currentRange() eth.BlockRangeUpdatePacket {
 type broadcastChoice struct {
 	self   enode.ID
 	key    [16]byte
+	count  int // number of peers to choose, square root of all peers if zero
 	buffer map[*ethPeer]struct{}
 	tmp    []broadcastPeer
 }
@@ -699,8 +719,21 @@ func (bc *broadcastChoice) choosePeers(peers []*ethPeer, txSender common.Address
 	// Take top n.
 	clear(bc.buffer)
 	n := int(math.Ceil(math.Sqrt(float64(len(bc.tmp)))))
+	if bc.count > 0 {
+		n = min(bc.count, len(bc.tmp))
+	}
 	for i := range n {
 		bc.buffer[bc.tmp[i].p] = struct{}{}
 	}
 	return bc.buffer
 }
+
+// all selects all peers for a direct broadcast. Like with choosePeers, the return
+// value will only stay valid until the next call.
+func (bc *broadcastChoice) all(peers []*ethPeer) map[*ethPeer]struct{} {
+	clear(bc.buffer)
+	for _, peer := range peers {
+		bc.buffer[peer] = struct{}{}
+	}
+	return bc.buffer
+}
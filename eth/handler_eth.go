@@ -24,6 +24,7 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
+	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 )
 
@@ -69,7 +70,11 @@ func (h *ethHandler) Handle(peer *eth.Peer, packet eth.Packet) error {
 		if err := handleTransactions(peer, txs, true); err != nil {
 			return fmt.Errorf("Transactions: %v", err)
 		}
-		return h.txFetcher.Enqueue(peer.ID(), txs, false)
+		if err := h.txFetcher.Enqueue(peer.ID(), txs, false); err != nil {
+			return err
+		}
+		h.reportTransactions(peer, txs)
+		return nil
 
 	case *eth.PooledTransactionsPacket:
 		txs, err := packet.List.Items()
@@ -79,13 +84,28 @@ func (h *ethHandler) Handle(peer *eth.Peer, packet eth.Packet) error {
 		if err := handleTransactions(peer, txs, false); err != nil {
 			return fmt.Errorf("PooledTransactions: %v", err)
 		}
-		return h.txFetcher.Enqueue(peer.ID(), txs, true)
+		if err := h.txFetcher.Enqueue(peer.ID(), txs, true); err != nil {
+			return err
+		}
+		h.reportTransactions(peer, txs)
+		return nil
 
 	default:
 		return fmt.Errorf("unexpected eth packet type: %T", packet)
 	}
 }
 
+// reportTransactions credits the peer's reputation if any of the delivered
+// transactions made it into the pool.
+func (h *ethHandler) reportTransactions(peer *eth.Peer, txs []*types.Transaction) {
+	for _, tx := range txs {
+		if h.txpool.Has(tx.Hash()) {
+			peer.Report(p2p.ReputationValidAnnouncement)
+			return
+		}
+	}
+}
+
 // handleTransactions marks all given transactions as known to the peer
 // and performs basic validations.
 func handleTransactions(peer *eth.Peer, list []*types.Transaction, directBroadcast bool) error {
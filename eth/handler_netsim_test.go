@@ -0,0 +1,235 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/eth/protocols/eth"
+	"github.com/ethereum/go-ethereum/p2p/netsim"
+)
+
+// newSimulatedHandlers creates a simulated network of eth handlers.
+func newSimulatedHandlers(t *testing.T, n int, link netsim.LinkConfig, prop ethconfig.TxPropagationConfig) (*netsim.Network, []*testHandler) {
+	network := netsim.New(netsim.Config{Link: link})
+	handlers := make([]*testHandler, n)
+	t.Cleanup(func() {
+		network.Close()
+		for _, h := range handlers {
+			if h != nil {
+				h.close()
+			}
+		}
+	})
+	for i := range handlers {
+		handlers[i] = newTestHandlerWithConfig(0, ethconfig.FullSync, handlerConfig{
+			Clock:         network.Clock(),
+			TxPropagation: prop,
+		})
+		handlers[i].handler.synced.Store(true) // mark synced to accept transactions
+
+		protocols := eth.MakeProtocols((*ethHandler)(handlers[i].handler), 1, nil)
+		if _, err := network.AddNode(netsim.NodeConfig{Protocols: protocols}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return network, handlers
+}
+
+// awaitEthPeers waits until all connected nodes have completed the eth handshake.
+func awaitEthPeers(t *testing.T, network *netsim.Network, handlers []*testHandler) {
+	if err := network.AwaitConnected(10 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	nodes := network.Nodes()
+	err := network.Await(10*time.Second, func() bool {
+		for i, h := range handlers {
+			if h.handler.peers.len() != len(nodes[i].Server.Peers()) {
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		t.Fatal("eth handshakes not completed:", err)
+	}
+}
+
+// Tests that transactions propagate across multiple hops of a network with link
+// latency, both by direct broadcast and by announcement and retrieval.
+func TestSimulatedTransactionPropagation(t *testing.T) {
+	const latency = 50 * time.Millisecond
+	tests := []struct {
+		name string
+		topo netsim.Topology
+	}{
+		{"chain", netsim.Chain},
+		{"ring", netsim.Ring},
+		{"random", netsim.Random(3, 1)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			network, handlers := newSimulatedHandlers(t, 8, netsim.LinkConfig{Latency: latency}, ethconfig.TxPropagationConfig{})
+			network.ConnectTopology(test.topo)
+			awaitEthPeers(t, network, handlers)
+
+			txs := make([]*types.Transaction, 16)
+			for nonce := range txs {
+				tx := types.NewTransaction(uint64(nonce), common.Address{}, big.NewInt(0), 100000, big.NewInt(0), nil)
+				tx, _ = types.SignTx(tx, types.HomesteadSigner{}, testKey)
+				txs[nonce] = tx
+			}
+			start := network.Elapsed()
+			handlers[0].txpool.Add(txs, false)
+
+			err := network.Await(30*time.Second, func() bool {
+				for _, h := range handlers {
+					for _, tx := range txs {
+						if !h.txpool.Has(tx.Hash()) {
+							return false
+						}
+					}
+				}
+				return true
+			})
+			if err != nil {
+				t.Fatal("transactions did not propagate:", err)
+			}
+			// Transactions cannot cross the network faster than the link latency.
+			hops := maxHops(test.topo(len(handlers)), 0)
+			if elapsed := network.Elapsed() - start; elapsed < time.Duration(hops)*latency {
+				t.Fatalf("transactions propagated in %v, faster than %d hops", elapsed, hops)
+			}
+		})
+	}
+}
+
+// maxHops returns the number of hops from the source node to the most distant
+// node of a topology.
+func maxHops(links [][2]int, source int) int {
+	adj := make(map[int][]int)
+	for _, l := range links {
+		adj[l[0]] = append(adj[l[0]], l[1])
+		adj[l[1]] = append(adj[l[1]], l[0])
+	}
+	var (
+		dist  = map[int]int{source: 0}
+		queue = []int{source}
+		max   int
+	)
+	for ; len(queue) > 0; queue = queue[1:] {
+		for _, next := range adj[queue[0]] {
+			if _, ok := dist[next]; !ok {
+				dist[next] = dist[queue[0]] + 1
+				max = dist[next]
+				queue = append(queue, next)
+			}
+		}
+	}
+	return max
+}
+
+// txReasonCounts returns the current values of the propagation decision counters.
+func txReasonCounts() (counts [txReasonCount]int64) {
+	for reason, c := range txReasonCounters {
+		counts[reason] = c.Snapshot().Count()
+	}
+	return counts
+}
+
+// Tests that the transaction propagation policy decides which peers receive
+// transactions directly. The test checks the decisions of the hub instead of the
+// arrival times, which depend on goroutine scheduling. It must not run in
+// parallel with other tests broadcasting transactions.
+func TestSimulatedTransactionPolicy(t *testing.T) {
+	const (
+		leaves  = 9
+		latency = 50 * time.Millisecond
+	)
+	tests := []struct {
+		name    string
+		config  ethconfig.TxPropagationConfig
+		trusted int // number of leaves trusted by the hub
+		direct  int // number of leaves receiving transactions directly
+	}{
+		{name: "sqrt", direct: 3},
+		{name: "fanout", config: ethconfig.TxPropagationConfig{FanoutPeers: 5}, direct: 5},
+		{name: "broadcast", config: ethconfig.TxPropagationConfig{Fanout: ethconfig.TxFanoutBroadcast}, direct: leaves},
+		{name: "announce", config: ethconfig.TxPropagationConfig{Fanout: ethconfig.TxFanoutAnnounce}, direct: 0},
+		{
+			name:    "trusted",
+			config:  ethconfig.TxPropagationConfig{Fanout: ethconfig.TxFanoutAnnounce, Trusted: true},
+			trusted: 4,
+			direct:  4,
+		},
+		{
+			// Nodes have addresses 10.0.0.1 to 10.0.0.10, so the hub and leaves
+			// 1-6 are in the region. One of the remaining three leaves is chosen.
+			name:   "region",
+			config: ethconfig.TxPropagationConfig{Fanout: ethconfig.TxFanoutRegion, RegionNets: []string{"10.0.0.0/29"}, FanoutPeers: 1},
+			direct: 7,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			network, handlers := newSimulatedHandlers(t, leaves+1, netsim.LinkConfig{Latency: latency}, test.config)
+			nodes := network.Nodes()
+			for _, leaf := range nodes[1 : 1+test.trusted] {
+				nodes[0].Server.AddTrustedPeer(leaf.Node())
+			}
+			network.ConnectTopology(netsim.Star)
+			awaitEthPeers(t, network, handlers)
+
+			tx := types.NewTransaction(0, common.Address{}, big.NewInt(0), 100000, big.NewInt(0), nil)
+			tx, _ = types.SignTx(tx, types.HomesteadSigner{}, testKey)
+			// Put the transaction into the hub's pool without notifying the broadcast
+			// loop and broadcast it synchronously, so the decisions can be checked
+			// right away.
+			pool := handlers[0].txpool
+			pool.lock.Lock()
+			pool.pool[tx.Hash()] = tx
+			pool.lock.Unlock()
+
+			before := txReasonCounts()
+			handlers[0].handler.BroadcastTransactions(types.Transactions{tx})
+			var decided [txReasonCount]int64
+			for reason, n := range txReasonCounts() {
+				decided[reason] = n - before[reason]
+			}
+			direct := decided[txReasonFanout] + decided[txReasonTrusted] + decided[txReasonRegion]
+			if direct != int64(test.direct) || decided[txReasonDefault] != int64(leaves-test.direct) {
+				t.Fatalf("%d leaves chosen for direct broadcast, want %d (decisions %v)", direct, test.direct, decided)
+			}
+			err := network.Await(30*time.Second, func() bool {
+				for _, h := range handlers {
+					if !h.txpool.Has(tx.Hash()) {
+						return false
+					}
+				}
+				return true
+			})
+			if err != nil {
+				t.Fatal("transaction did not propagate:", err)
+			}
+		})
+	}
+}
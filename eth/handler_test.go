@@ -192,6 +192,13 @@ func newTestHandler(mode ethconfig.SyncMode) *testHandler {
 // newTestHandlerWithBlocks creates a new handler for testing purposes, with a
 // given number of initial blocks.
 func newTestHandlerWithBlocks(blocks int, mode ethconfig.SyncMode) *testHandler {
+	return newTestHandlerWithConfig(blocks, mode, handlerConfig{})
+}
+
+// newTestHandlerWithConfig creates a new handler for testing purposes, with a
+// given number of initial blocks. The chain and pool related fields of config
+// are filled in.
+func newTestHandlerWithConfig(blocks int, mode ethconfig.SyncMode, config handlerConfig) *testHandler {
 	// Create a database pre-initialize with a genesis block
 	db := rawdb.NewMemoryDatabase()
 	gspec := &core.Genesis{
@@ -206,14 +213,16 @@ func newTestHandlerWithBlocks(blocks int, mode ethconfig.SyncMode) *testHandler
 	}
 	txpool := newTestTxPool()
 
-	handler, _ := newHandler(&handlerConfig{
-		Database:   db,
-		Chain:      chain,
-		TxPool:     txpool,
-		Network:    1,
-		Sync:       mode,
-		BloomCache: 1,
-	})
+	config.Database = db
+	config.Chain = chain
+	config.TxPool = txpool
+	config.Network = 1
+	config.Sync = mode
+	config.BloomCache = 1
+	handler, err := newHandler(&config)
+	if err != nil {
+		panic(err)
+	}
 	handler.Start(1000)
 
 	return &testHandler{
@@ -35,6 +35,10 @@ const (
 	// softResponseLimit is the target maximum size of replies to data retrievals.
 	softResponseLimit = 2 * 1024 * 1024
 
+	// throttledResponseLimit is the target maximum size of replies to data
+	// retrievals while the outbound bandwidth budget of the peer is exhausted.
+	throttledResponseLimit = softResponseLimit / 16
+
 	// maxPacketSize is the devp2p message size limit commonly enforced by clients.
 	// Any packet exceeding this limit must be rejected.
 	maxPacketSize = 10 * 1024 * 1024
@@ -588,11 +588,11 @@ func handleGetPooledTransactions(backend Backend, msg Decoder, peer *Peer) error
 	if err := msg.Decode(&query); err != nil {
 		return err
 	}
-	hashes, txs := answerGetPooledTransactions(backend, query.GetPooledTransactionsRequest)
+	hashes, txs := answerGetPooledTransactions(backend, query.GetPooledTransactionsRequest, peer.responseLimit())
 	return peer.ReplyPooledTransactionsRLP(query.RequestId, hashes, txs)
 }
 
-func answerGetPooledTransactions(backend Backend, query GetPooledTransactionsRequest) ([]common.Hash, []rlp.RawValue) {
+func answerGetPooledTransactions(backend Backend, query GetPooledTransactionsRequest, limit int) ([]common.Hash, []rlp.RawValue) {
 	// Gather transactions until the fetch or network limits is reached
 	var (
 		bytes  int
@@ -600,7 +600,7 @@ func answerGetPooledTransactions(backend Backend, query GetPooledTransactionsReq
 		txs    []rlp.RawValue
 	)
 	for _, hash := range query {
-		if bytes >= softResponseLimit {
+		if bytes >= limit {
 			break
 		}
 		// Retrieve the requested transaction, skipping if unknown to us
@@ -106,6 +106,8 @@ func NewPeer(version uint, p *p2p.Peer, rw p2p.MsgReadWriter, txpool TxPool, cha
 		receiptBuffer: make(map[uint64]*receiptRequest),
 		term:          make(chan struct{}),
 	}
+	peer.tracker.SetReporter(p)
+
 	// Start up all the broadcasters
 	go peer.broadcastTransactions()
 	go peer.announceTransactions()
@@ -131,6 +133,15 @@ func (p *Peer) Version() uint {
 	return p.version
 }
 
+// responseLimit returns the target size of replies to the peer, backing off
+// while the outbound bandwidth budget of the peer is exhausted.
+func (p *Peer) responseLimit() int {
+	if p.Peer != nil && p.BandwidthExhausted(ProtocolName) {
+		return throttledResponseLimit
+	}
+	return softResponseLimit
+}
+
 // BlockRange returns the latest announced block range.
 // This will be nil for peers below protocol version eth/69.
 func (p *Peer) BlockRange() *BlockRangeUpdatePacket {
@@ -31,6 +31,10 @@ const (
 	// softResponseLimit is the target maximum size of replies to data retrievals.
 	softResponseLimit = 2 * 1024 * 1024
 
+	// throttledResponseLimit is the target maximum size of replies to data
+	// retrievals while the outbound bandwidth budget of the peer is exhausted.
+	throttledResponseLimit = softResponseLimit / 16
+
 	// maxCodeLookups is the maximum number of bytecodes to serve. This number is
 	// there to limit the number of disk lookups.
 	maxCodeLookups = 1024
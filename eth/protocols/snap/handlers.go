@@ -40,6 +40,8 @@ func handleGetAccountRange(backend Backend, msg Decoder, peer *Peer) error {
 	if err := msg.Decode(&req); err != nil {
 		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
 	}
+	req.Bytes = peer.responseLimit(req.Bytes)
+
 	// Service the request, potentially returning nothing in case of errors
 	accounts, proofs := ServiceGetAccountRangeQuery(backend.Chain(), &req)
 
@@ -158,6 +160,8 @@ func handleGetStorageRanges(backend Backend, msg Decoder, peer *Peer) error {
 	if err := msg.Decode(&req); err != nil {
 		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
 	}
+	req.Bytes = peer.responseLimit(req.Bytes)
+
 	// Service the request, potentially returning nothing in case of errors
 	slots, proofs := ServiceGetStorageRangesQuery(backend.Chain(), &req)
 
@@ -332,6 +336,8 @@ func handleGetByteCodes(backend Backend, msg Decoder, peer *Peer) error {
 	if err := msg.Decode(&req); err != nil {
 		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
 	}
+	req.Bytes = peer.responseLimit(req.Bytes)
+
 	// Service the request, potentially returning nothing in case of errors
 	codes := ServiceGetByteCodesQuery(backend.Chain(), &req)
 
@@ -397,6 +403,8 @@ func handleGetTrienodes(backend Backend, msg Decoder, peer *Peer) error {
 	if err := msg.Decode(&req); err != nil {
 		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
 	}
+	req.Bytes = peer.responseLimit(req.Bytes)
+
 	// Service the request, potentially returning nothing in case of errors
 	nodes, err := ServiceGetTrieNodesQuery(backend.Chain(), &req)
 	if err != nil {
@@ -558,6 +566,8 @@ func handleGetAccessLists(backend Backend, msg Decoder, peer *Peer) error {
 	if err := msg.Decode(&req); err != nil {
 		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
 	}
+	req.Bytes = peer.responseLimit(req.Bytes)
+
 	return p2p.Send(peer.rw, AccessListsMsg, &AccessListsPacket{
 		ID:          req.ID,
 		AccessLists: ServiceGetAccessListsQuery(backend.Chain(), &req),
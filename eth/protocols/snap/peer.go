@@ -80,6 +80,15 @@ func (p *Peer) Log() log.Logger {
 	return p.logger
 }
 
+// responseLimit caps the requested size of a reply, backing off while the
+// outbound bandwidth budget of the peer is exhausted.
+func (p *Peer) responseLimit(bytes uint64) uint64 {
+	if p.Peer != nil && p.BandwidthExhausted(ProtocolName) {
+		return min(bytes, throttledResponseLimit)
+	}
+	return bytes
+}
+
 // Close releases resources associated with the peer.
 func (p *Peer) Close() {
 	p.tracker.Stop()
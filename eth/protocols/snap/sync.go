@@ -38,6 +38,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/msgrate"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie"
@@ -600,6 +601,14 @@ func (s *syncer) Unregister(id string) error {
 	return nil
 }
 
+// reportPeer feeds the outcome of a request measured by the rate tracker into
+// the reputation of the remote peer, if it is backed by a p2p connection.
+func reportPeer(peer SyncPeer, ev p2p.ReputationEvent) {
+	if p, ok := peer.(*Peer); ok && p.Peer != nil {
+		p.Report(ev)
+	}
+}
+
 // Sync starts (or resumes a previous) sync cycle to iterate over a state trie
 // with the given root and reconstruct the nodes based on the snapshot leaves.
 // Previously downloaded segments will not be redownloaded of fixed, rather any
@@ -1086,6 +1095,7 @@ func (s *syncer) assignAccountTasks(success chan *accountResponse, fail chan *ac
 		req.timeout = time.AfterFunc(s.rates.TargetTimeout(), func() {
 			peer.Log().Debug("Account range request timed out", "reqid", reqid)
 			s.rates.Update(idle, AccountRangeMsg, 0, 0)
+			reportPeer(peer, p2p.ReputationTimeout)
 			s.scheduleRevertAccountRequest(req)
 		})
 		s.accountReqs[reqid] = req
@@ -1197,6 +1207,7 @@ func (s *syncer) assignBytecodeTasks(success chan *bytecodeResponse, fail chan *
 		req.timeout = time.AfterFunc(s.rates.TargetTimeout(), func() {
 			peer.Log().Debug("Bytecode request timed out", "reqid", reqid)
 			s.rates.Update(idle, ByteCodesMsg, 0, 0)
+			reportPeer(peer, p2p.ReputationTimeout)
 			s.scheduleRevertBytecodeRequest(req)
 		})
 		s.bytecodeReqs[reqid] = req
@@ -1345,6 +1356,7 @@ func (s *syncer) assignStorageTasks(success chan *storageResponse, fail chan *st
 		req.timeout = time.AfterFunc(s.rates.TargetTimeout(), func() {
 			peer.Log().Debug("Storage request timed out", "reqid", reqid)
 			s.rates.Update(idle, StorageRangesMsg, 0, 0)
+			reportPeer(peer, p2p.ReputationTimeout)
 			s.scheduleRevertStorageRequest(req)
 		})
 		s.storageReqs[reqid] = req
@@ -1482,6 +1494,7 @@ func (s *syncer) assignTrienodeHealTasks(success chan *trienodeHealResponse, fai
 		req.timeout = time.AfterFunc(s.rates.TargetTimeout(), func() {
 			peer.Log().Debug("Trienode heal request timed out", "reqid", reqid)
 			s.rates.Update(idle, TrieNodesMsg, 0, 0)
+			reportPeer(peer, p2p.ReputationTimeout)
 			s.scheduleRevertTrienodeHealRequest(req)
 		})
 		s.trienodeHealReqs[reqid] = req
@@ -1598,6 +1611,7 @@ func (s *syncer) assignBytecodeHealTasks(success chan *bytecodeHealResponse, fai
 		req.timeout = time.AfterFunc(s.rates.TargetTimeout(), func() {
 			peer.Log().Debug("Bytecode heal request timed out", "reqid", reqid)
 			s.rates.Update(idle, ByteCodesMsg, 0, 0)
+			reportPeer(peer, p2p.ReputationTimeout)
 			s.scheduleRevertBytecodeHealRequest(req)
 		})
 		s.bytecodeHealReqs[reqid] = req
@@ -2557,6 +2571,7 @@ func (s *syncer) OnAccounts(peer SyncPeer, id uint64, hashes []common.Hash, acco
 	}
 	delete(s.accountReqs, id)
 	s.rates.Update(peer.ID(), AccountRangeMsg, time.Since(req.time), int(size))
+	reportPeer(peer, p2p.ReputationResponse)
 
 	// Clean up the request timeout timer, we'll see how to proceed further based
 	// on the actual delivered content
@@ -2667,6 +2682,7 @@ func (s *syncer) onByteCodes(peer SyncPeer, id uint64, bytecodes [][]byte) error
 	}
 	delete(s.bytecodeReqs, id)
 	s.rates.Update(peer.ID(), ByteCodesMsg, time.Since(req.time), len(bytecodes))
+	reportPeer(peer, p2p.ReputationResponse)
 
 	// Clean up the request timeout timer, we'll see how to proceed further based
 	// on the actual delivered content
@@ -2780,6 +2796,7 @@ func (s *syncer) OnStorage(peer SyncPeer, id uint64, hashes [][]common.Hash, slo
 	}
 	delete(s.storageReqs, id)
 	s.rates.Update(peer.ID(), StorageRangesMsg, time.Since(req.time), int(size))
+	reportPeer(peer, p2p.ReputationResponse)
 
 	// Clean up the request timeout timer, we'll see how to proceed further based
 	// on the actual delivered content
@@ -2914,6 +2931,7 @@ func (s *syncer) OnTrieNodes(peer SyncPeer, id uint64, trienodes [][]byte) error
 	}
 	delete(s.trienodeHealReqs, id)
 	s.rates.Update(peer.ID(), TrieNodesMsg, time.Since(req.time), len(trienodes))
+	reportPeer(peer, p2p.ReputationResponse)
 
 	// Clean up the request timeout timer, we'll see how to proceed further based
 	// on the actual delivered content
@@ -3021,6 +3039,7 @@ func (s *syncer) onHealByteCodes(peer SyncPeer, id uint64, bytecodes [][]byte) e
 	}
 	delete(s.bytecodeHealReqs, id)
 	s.rates.Update(peer.ID(), ByteCodesMsg, time.Since(req.time), len(bytecodes))
+	reportPeer(peer, p2p.ReputationResponse)
 
 	// Clean up the request timeout timer, we'll see how to proceed further based
 	// on the actual delivered content
@@ -0,0 +1,204 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"fmt"
+	"net/netip"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// txDecision is the way a transaction is propagated to a single peer.
+type txDecision int
+
+const (
+	txSkip      txDecision = iota // not sent at all
+	txAnnounce                    // hash announced, peer fetches it if needed
+	txBroadcast                   // sent directly
+)
+
+// txReason is the policy rule behind a propagation decision.
+type txReason int
+
+const (
+	txReasonFanout   txReason = iota // direct broadcast chosen by fanout
+	txReasonTrusted                  // direct broadcast to trusted peer
+	txReasonRegion                   // direct broadcast to peer in local region
+	txReasonDefault                  // announcement to peer outside of the fanout
+	txReasonLarge                    // announcement of transaction too large for broadcast
+	txReasonBlob                     // announcement of blob transaction
+	txReasonBlobSkip                 // blob transaction not propagated to peer
+
+	txReasonCount
+)
+
+var txReasonCounters = [txReasonCount]*metrics.Counter{
+	txReasonFanout:   metrics.NewRegisteredCounter("eth/txpropagation/broadcast/fanout", nil),
+	txReasonTrusted:  metrics.NewRegisteredCounter("eth/txpropagation/broadcast/trusted", nil),
+	txReasonRegion:   metrics.NewRegisteredCounter("eth/txpropagation/broadcast/region", nil),
+	txReasonDefault:  metrics.NewRegisteredCounter("eth/txpropagation/announce/default", nil),
+	txReasonLarge:    metrics.NewRegisteredCounter("eth/txpropagation/announce/large", nil),
+	txReasonBlob:     metrics.NewRegisteredCounter("eth/txpropagation/announce/blob", nil),
+	txReasonBlobSkip: metrics.NewRegisteredCounter("eth/txpropagation/skip/blob", nil),
+}
+
+// txPropagation is the transaction propagation policy of the handler.
+type txPropagation struct {
+	fanout      string
+	fanoutPeers int
+	trusted     bool
+	region      []netip.Prefix
+	blobs       string
+}
+
+func newTxPropagation(cfg ethconfig.TxPropagationConfig) (*txPropagation, error) {
+	p := &txPropagation{
+		fanout:      cfg.Fanout,
+		fanoutPeers: cfg.FanoutPeers,
+		trusted:     cfg.Trusted,
+		blobs:       cfg.Blobs,
+	}
+	switch p.fanout {
+	case "":
+		p.fanout = ethconfig.TxFanoutSqrt
+	case ethconfig.TxFanoutSqrt, ethconfig.TxFanoutBroadcast, ethconfig.TxFanoutAnnounce, ethconfig.TxFanoutRegion:
+	default:
+		return nil, fmt.Errorf("unknown transaction fanout policy %q", p.fanout)
+	}
+	switch p.blobs {
+	case "":
+		p.blobs = ethconfig.BlobAnnounceAll
+	case ethconfig.BlobAnnounceAll, ethconfig.BlobAnnounceTrusted, ethconfig.BlobAnnounceNone:
+	default:
+		return nil, fmt.Errorf("unknown blob transaction policy %q", p.blobs)
+	}
+	if p.fanoutPeers < 0 {
+		return nil, fmt.Errorf("invalid transaction fanout peer count %d", p.fanoutPeers)
+	}
+	for _, s := range cfg.RegionNets {
+		net, err := netip.ParsePrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid region network: %v", err)
+		}
+		p.region = append(p.region, net.Masked())
+	}
+	if p.fanout == ethconfig.TxFanoutRegion && len(p.region) == 0 {
+		return nil, fmt.Errorf("transaction fanout policy %q requires region networks", p.fanout)
+	}
+	return p, nil
+}
+
+// txPropagationRound holds the state of a single BroadcastTransactions call.
+type txPropagationRound struct {
+	policy *txPropagation
+	choice *broadcastChoice
+	peers  []*ethPeer
+	remote []*ethPeer // peers outside of the local region
+	local  map[*ethPeer]bool
+	counts [txReasonCount]int64
+}
+
+func (p *txPropagation) newRound(choice *broadcastChoice, peers []*ethPeer) *txPropagationRound {
+	choice.count = p.fanoutPeers
+	r := &txPropagationRound{policy: p, choice: choice, peers: peers, remote: peers}
+	if p.fanout == ethconfig.TxFanoutRegion {
+		r.local = make(map[*ethPeer]bool)
+		r.remote = nil
+		for _, peer := range peers {
+			if p.inRegion(peer) {
+				r.local[peer] = true
+			} else {
+				r.remote = append(r.remote, peer)
+			}
+		}
+	}
+	return r
+}
+
+// inRegion reports whether the peer's address is in one of the region networks.
+func (p *txPropagation) inRegion(peer *ethPeer) bool {
+	addr, err := netip.ParseAddrPort(peer.RemoteAddr().String())
+	if err != nil {
+		return false
+	}
+	ip := addr.Addr().Unmap()
+	for _, net := range p.region {
+		if net.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// directPeers returns the peers chosen by the fanout policy to receive a regular
+// transaction of the given sender directly.
+func (r *txPropagationRound) directPeers(sender common.Address) map[*ethPeer]struct{} {
+	switch r.policy.fanout {
+	case ethconfig.TxFanoutAnnounce:
+		return nil
+	case ethconfig.TxFanoutBroadcast:
+		return r.choice.all(r.peers)
+	default:
+		return r.choice.choosePeers(r.remote, sender)
+	}
+}
+
+// decide returns how a transaction is propagated to a peer. The direct set is
+// the result of directPeers, or nil for blob and large transactions.
+func (r *txPropagationRound) decide(peer *ethPeer, direct map[*ethPeer]struct{}, blob, large bool) txDecision {
+	decision, reason := r.policy.decide(peer.Trusted(), r.local[peer], containsPeer(direct, peer), blob, large)
+	r.counts[reason]++
+	return decision
+}
+
+func containsPeer(set map[*ethPeer]struct{}, peer *ethPeer) bool {
+	_, ok := set[peer]
+	return ok
+}
+
+func (p *txPropagation) decide(trusted, local, direct, blob, large bool) (txDecision, txReason) {
+	switch {
+	case blob && p.blobs == ethconfig.BlobAnnounceNone:
+		return txSkip, txReasonBlobSkip
+	case blob && p.blobs == ethconfig.BlobAnnounceTrusted && !trusted:
+		return txSkip, txReasonBlobSkip
+	case blob:
+		return txAnnounce, txReasonBlob
+	case large:
+		return txAnnounce, txReasonLarge
+	case trusted && p.trusted:
+		return txBroadcast, txReasonTrusted
+	case local:
+		return txBroadcast, txReasonRegion
+	case direct:
+		return txBroadcast, txReasonFanout
+	default:
+		return txAnnounce, txReasonDefault
+	}
+}
+
+// finish updates the decision counters.
+func (r *txPropagationRound) finish() {
+	for reason, n := range r.counts {
+		if n > 0 {
+			txReasonCounters[reason].Inc(n)
+		}
+	}
+}
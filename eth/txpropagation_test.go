@@ -0,0 +1,86 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+)
+
+func TestTxPropagationConfig(t *testing.T) {
+	invalid := []ethconfig.TxPropagationConfig{
+		{Fanout: "flood"},
+		{Blobs: "some"},
+		{FanoutPeers: -1},
+		{Fanout: ethconfig.TxFanoutRegion},
+		{Fanout: ethconfig.TxFanoutRegion, RegionNets: []string{"10.0.0.0"}},
+	}
+	for _, cfg := range invalid {
+		if _, err := newTxPropagation(cfg); err == nil {
+			t.Errorf("no error for invalid config %+v", cfg)
+		}
+	}
+	p, err := newTxPropagation(ethconfig.TxPropagationConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.fanout != ethconfig.TxFanoutSqrt || p.blobs != ethconfig.BlobAnnounceAll {
+		t.Fatalf("wrong defaults: fanout %q, blobs %q", p.fanout, p.blobs)
+	}
+}
+
+func TestTxPropagationDecide(t *testing.T) {
+	type peer struct{ trusted, local, direct bool }
+	var (
+		regular = peer{}
+		trusted = peer{trusted: true}
+		local   = peer{local: true}
+		direct  = peer{direct: true}
+	)
+	tests := []struct {
+		config      ethconfig.TxPropagationConfig
+		peer        peer
+		blob, large bool
+		want        txDecision
+		reason      txReason
+	}{
+		{peer: regular, want: txAnnounce, reason: txReasonDefault},
+		{peer: direct, want: txBroadcast, reason: txReasonFanout},
+		{peer: local, want: txBroadcast, reason: txReasonRegion},
+		{peer: trusted, want: txAnnounce, reason: txReasonDefault},
+		{config: ethconfig.TxPropagationConfig{Trusted: true}, peer: trusted, want: txBroadcast, reason: txReasonTrusted},
+		{config: ethconfig.TxPropagationConfig{Trusted: true}, peer: trusted, large: true, want: txAnnounce, reason: txReasonLarge},
+		{peer: direct, large: true, want: txAnnounce, reason: txReasonLarge},
+
+		// Blob transactions are never broadcast.
+		{config: ethconfig.TxPropagationConfig{Trusted: true}, peer: trusted, blob: true, want: txAnnounce, reason: txReasonBlob},
+		{config: ethconfig.TxPropagationConfig{Blobs: ethconfig.BlobAnnounceTrusted}, peer: regular, blob: true, want: txSkip, reason: txReasonBlobSkip},
+		{config: ethconfig.TxPropagationConfig{Blobs: ethconfig.BlobAnnounceTrusted}, peer: trusted, blob: true, want: txAnnounce, reason: txReasonBlob},
+		{config: ethconfig.TxPropagationConfig{Blobs: ethconfig.BlobAnnounceNone}, peer: trusted, blob: true, want: txSkip, reason: txReasonBlobSkip},
+	}
+	for i, test := range tests {
+		p, err := newTxPropagation(test.config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decision, reason := p.decide(test.peer.trusted, test.peer.local, test.peer.direct, test.blob, test.large)
+		if decision != test.want || reason != test.reason {
+			t.Errorf("test %d: got decision %d (reason %d), want %d (reason %d)", i, decision, reason, test.want, test.reason)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	resumeBackoffMin = 100 * time.Millisecond // initial delay between resubscribe attempts
+	resumeBackoffMax = 30 * time.Second       // maximum delay between resubscribe attempts
+)
+
+// SubscribeNewHeadResumable is like SubscribeNewHead, but the subscription survives
+// connection failures. When the connection drops, the subscription is re-established
+// as soon as the server is reachable again, and the headers that were announced in
+// the meantime are delivered before the subscription goes live again. After a reorg,
+// delivery continues with the first block of the new canonical chain after the
+// common ancestor. The subscription starts after the head block at the time of
+// subscribing.
+//
+// The error channel of the subscription only receives a value if the server refuses
+// to resume, e.g. because the client has been disconnected for too long.
+func (ec *Client) SubscribeNewHeadResumable(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	cursor, err := ec.headCursor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	subscribe := func(ctx context.Context, inner chan *types.Header, cursor *filters.Cursor) (*rpc.ClientSubscription, error) {
+		return ec.c.EthSubscribe(ctx, inner, "newHeads", cursor)
+	}
+	advance := func(header *types.Header, _ *filters.Cursor) *filters.Cursor {
+		return headerCursor(header)
+	}
+	return subscribeResumable(ctx, ch, cursor, subscribe, advance)
+}
+
+// SubscribeFilterLogsResumable is like SubscribeFilterLogs, but the subscription
+// survives connection failures. When the connection drops, the subscription is
+// re-established as soon as the server is reachable again, and the matching logs
+// emitted in the meantime are delivered before the subscription goes live again.
+// If blocks were reorged out while disconnected, the logs delivered from them are
+// sent again with the Removed flag set. Removed logs may be delivered more than
+// once. The subscription starts after the head block at the time of subscribing.
+//
+// The error channel of the subscription only receives a value if the server refuses
+// to resume, e.g. because the client has been disconnected for too long.
+func (ec *Client) SubscribeFilterLogsResumable(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	arg, err := toFilterArg(q)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := ec.headCursor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	subscribe := func(ctx context.Context, inner chan types.Log, cursor *filters.Cursor) (*rpc.ClientSubscription, error) {
+		return ec.c.EthSubscribe(ctx, inner, "logs", arg, cursor)
+	}
+	advance := func(log types.Log, cursor *filters.Cursor) *filters.Cursor {
+		// Removals are delivered in ascending block order, followed by the logs
+		// of the new chain. Keep resuming from the last delivered log until the
+		// new chain arrives; the server will unwind it again if needed.
+		if log.Removed {
+			return cursor
+		}
+		index := hexutil.Uint(log.Index)
+		return &filters.Cursor{
+			BlockNumber: hexutil.Uint64(log.BlockNumber),
+			BlockHash:   log.BlockHash,
+			LogIndex:    &index,
+		}
+	}
+	return subscribeResumable(ctx, ch, cursor, subscribe, advance)
+}
+
+// headCursor returns the cursor of the current head block. Resumable subscriptions
+// start from it, so that a connection failure before the first event does not
+// lose the events emitted meanwhile.
+func (ec *Client) headCursor(ctx context.Context) (*filters.Cursor, error) {
+	head, err := ec.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return headerCursor(head), nil
+}
+
+// headerCursor returns the cursor identifying the given header.
+func headerCursor(header *types.Header) *filters.Cursor {
+	return &filters.Cursor{
+		BlockNumber: hexutil.Uint64(header.Number.Uint64()),
+		BlockHash:   header.Hash(),
+	}
+}
+
+// subscribeResumable establishes a subscription starting after the given cursor
+// and keeps it alive across connection failures, resuming it from the cursor of
+// the last delivered event.
+func subscribeResumable[T any](ctx context.Context, ch chan<- T, cursor *filters.Cursor,
+	subscribe func(context.Context, chan T, *filters.Cursor) (*rpc.ClientSubscription, error),
+	advance func(T, *filters.Cursor) *filters.Cursor) (ethereum.Subscription, error) {
+	inner := make(chan T)
+	sub, err := subscribe(ctx, inner, cursor)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		for {
+			select {
+			case ev := <-inner:
+				cursor = advance(ev, cursor)
+				select {
+				case ch <- ev:
+				case <-quit:
+					sub.Unsubscribe()
+					return nil
+				}
+
+			case err := <-sub.Err():
+				if err == nil {
+					return nil // client closed
+				}
+				log.Debug("Resumable subscription failed", "err", err)
+				if sub, err = resubscribe(quit, inner, cursor, subscribe); err != nil {
+					return err
+				}
+				if sub == nil {
+					return nil // unsubscribed or closed while reconnecting
+				}
+
+			case <-quit:
+				sub.Unsubscribe()
+				return nil
+			}
+		}
+	}), nil
+}
+
+// resubscribe attempts to re-establish a failed subscription until it succeeds,
+// the server rejects it or the subscription is cancelled.
+func resubscribe[T any](quit <-chan struct{}, inner chan T, cursor *filters.Cursor,
+	subscribe func(context.Context, chan T, *filters.Cursor) (*rpc.ClientSubscription, error)) (*rpc.ClientSubscription, error) {
+	backoff := resumeBackoffMin
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), resumeBackoffMax)
+		sub, err := subscribe(ctx, inner, cursor)
+		cancel()
+		if err == nil {
+			return sub, nil
+		}
+		if errors.Is(err, rpc.ErrClientQuit) {
+			return nil, nil
+		}
+		// Errors reported by the server are final, anything else is most likely
+		// a connectivity issue that is worth retrying.
+		var rpcErr rpc.Error
+		if errors.As(err, &rpcErr) {
+			return nil, err
+		}
+		log.Debug("Failed to resume subscription", "err", err, "retry", backoff)
+		select {
+		case <-time.After(backoff):
+		case <-quit:
+			return nil, nil
+		}
+		backoff = min(2*backoff, resumeBackoffMax)
+	}
+}
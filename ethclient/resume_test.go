@@ -0,0 +1,166 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethclient_test
+
+import (
+	"context"
+	"math/big"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type resumeTestCursor struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+}
+
+// resumeTestService serves a fake newHeads subscription delivering three
+// consecutive headers after the given cursor.
+type resumeTestService struct {
+	head    *types.Header
+	cursors chan *resumeTestCursor
+}
+
+func (s *resumeTestService) GetBlockByNumber(ctx context.Context, number string, fullTx bool) (*types.Header, error) {
+	return s.head, nil
+}
+
+func (s *resumeTestService) NewHeads(ctx context.Context, cursor *resumeTestCursor) (*rpc.Subscription, error) {
+	notifier, _ := rpc.NotifierFromContext(ctx)
+	sub := notifier.CreateSubscription()
+	s.cursors <- cursor
+
+	first := uint64(1)
+	if cursor != nil {
+		first = uint64(cursor.BlockNumber) + 1
+	}
+	for n := first; n < first+3; n++ {
+		notifier.Notify(sub.ID, &types.Header{Number: new(big.Int).SetUint64(n), Difficulty: common.Big0})
+	}
+	return sub, nil
+}
+
+// dropListener tracks the accepted connections, so they can be severed.
+type dropListener struct {
+	net.Listener
+	mu    sync.Mutex
+	conns []net.Conn
+}
+
+func (l *dropListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err == nil {
+		l.mu.Lock()
+		l.conns = append(l.conns, conn)
+		l.mu.Unlock()
+	}
+	return conn, err
+}
+
+func (l *dropListener) drop() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, conn := range l.conns {
+		conn.Close()
+	}
+	l.conns = nil
+}
+
+func TestSubscribeNewHeadResumable(t *testing.T) {
+	t.Parallel()
+
+	srv := rpc.NewServer()
+	service := &resumeTestService{
+		head:    &types.Header{Number: big.NewInt(10), Difficulty: common.Big0},
+		cursors: make(chan *resumeTestCursor, 2),
+	}
+	if err := srv.RegisterName("eth", service); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+	defer srv.Stop()
+
+	endpoint := filepath.Join(t.TempDir(), "geth.ipc")
+	listener, err := net.Listen("unix", endpoint)
+	if err != nil {
+		t.Skipf("can't listen on unix socket: %v", err)
+	}
+	dl := &dropListener{Listener: listener}
+	defer dl.Close()
+	go srv.ServeListener(dl)
+
+	client, err := rpc.Dial(endpoint)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	ec := ethclient.NewClient(client)
+	defer ec.Close()
+
+	headers := make(chan *types.Header)
+	sub, err := ec.SubscribeNewHeadResumable(context.Background(), headers)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	receive := func(want uint64) *types.Header {
+		t.Helper()
+		select {
+		case h := <-headers:
+			if h.Number.Uint64() != want {
+				t.Fatalf("wrong header number: have %d, want %d", h.Number, want)
+			}
+			return h
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for header %d", want)
+		}
+		return nil
+	}
+	// The first subscription starts from the head, so that it can be resumed
+	// even if the connection drops before the first header.
+	if cursor := <-service.cursors; cursor == nil || uint64(cursor.BlockNumber) != 10 || cursor.BlockHash != service.head.Hash() {
+		t.Fatalf("wrong cursor on first subscription: %+v", cursor)
+	}
+	var last *types.Header
+	for n := uint64(11); n <= 13; n++ {
+		last = receive(n)
+	}
+	// Sever the connection, the client must resume from the last header.
+	dl.drop()
+
+	select {
+	case cursor := <-service.cursors:
+		if cursor == nil || uint64(cursor.BlockNumber) != 13 || cursor.BlockHash != last.Hash() {
+			t.Fatalf("wrong resume cursor: %+v", cursor)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("subscription not resumed")
+	}
+	for n := uint64(14); n <= 16; n++ {
+		receive(n)
+	}
+}
@@ -94,6 +94,18 @@ type Compacter interface {
 	Compact(start []byte, limit []byte) error
 }
 
+// ErrCheckpointUnsupported is returned if the data store doesn't support
+// creating checkpoints.
+var ErrCheckpointUnsupported = errors.New("checkpoint is not supported")
+
+// Checkpointer wraps the Checkpoint method of a backing data store.
+type Checkpointer interface {
+	// Checkpoint creates a consistent, openable copy of the data store in the
+	// given directory, which must not exist yet. Immutable files are hard-linked
+	// whenever possible, making the checkpoint cheap in both time and space.
+	Checkpoint(dir string) error
+}
+
 // KeyValueStore contains all the methods required to allow handling different
 // key-value data stores backing the high level database.
 type KeyValueStore interface {
@@ -59,6 +59,7 @@ type Database struct {
 	fn        string     // filename for reporting
 	db        *pebble.DB // Underlying pebble storage engine
 	namespace string     // Namespace for metrics
+	readonly  bool       // Whether the database is opened in read-only mode
 
 	compTimeMeter          *metrics.Meter   // Meter for measuring the total time spent in database compaction
 	compReadMeter          *metrics.Meter   // Meter for measuring the data read during compaction
@@ -222,6 +223,7 @@ func New(file string, cache int, handles int, namespace string, readonly bool) (
 		log:       logger,
 		quitChan:  make(chan chan error),
 		namespace: namespace,
+		readonly:  readonly,
 
 		// Use asynchronous write mode by default. Otherwise, the overhead of frequent fsync
 		// operations can be significant, especially on platforms with slow fsync performance
@@ -526,6 +528,25 @@ func (d *Database) SyncKeyValue() error {
 	return d.db.Apply(b, pebble.Sync)
 }
 
+// Checkpoint creates a consistent, openable copy of the database in the given
+// directory, which must not exist yet. The sstables are hard-linked if possible,
+// while the manifest and the write-ahead-log are copied.
+func (d *Database) Checkpoint(dir string) error {
+	d.quitLock.RLock()
+	defer d.quitLock.RUnlock()
+	if d.closed {
+		return pebble.ErrClosed
+	}
+	// The options file required by the checkpoint is not persisted by pebble
+	// if the database is opened in read-only mode.
+	if d.readonly {
+		return errors.New("checkpoint is not supported in read-only mode")
+	}
+	// Sync the write-ahead-log before copying it, as the writes are performed
+	// in the asynchronous mode.
+	return d.db.Checkpoint(dir, pebble.WithFlushedWAL())
+}
+
 // meter periodically retrieves internal pebble counters and reports them to
 // the metrics subsystem.
 func (d *Database) meter(refresh time.Duration, namespace string) {
@@ -18,6 +18,7 @@ package pebble
 
 import (
 	"errors"
+	"path/filepath"
 	"testing"
 
 	"github.com/cockroachdb/pebble"
@@ -78,3 +79,41 @@ func TestPebbleLogData(t *testing.T) {
 		t.Fatal("Unknown database entry")
 	}
 }
+
+func TestPebbleCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	db, err := New(filepath.Join(dir, "db"), 16, 16, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	db.Put([]byte("a"), []byte("1"))
+	db.Put([]byte("b"), []byte("2"))
+
+	if err := db.Checkpoint(filepath.Join(dir, "checkpoint")); err != nil {
+		t.Fatalf("Failed to create checkpoint: %v", err)
+	}
+	// Mutations after the checkpoint must not be reflected in it
+	db.Put([]byte("a"), []byte("3"))
+	db.Delete([]byte("b"))
+
+	if err := db.Checkpoint(filepath.Join(dir, "checkpoint")); err == nil {
+		t.Fatal("Expected error for existing checkpoint directory")
+	}
+	cp, err := New(filepath.Join(dir, "checkpoint"), 16, 16, "", true)
+	if err != nil {
+		t.Fatalf("Failed to open checkpoint: %v", err)
+	}
+	defer cp.Close()
+
+	for key, want := range map[string]string{"a": "1", "b": "2"} {
+		have, err := cp.Get([]byte(key))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", key, err)
+		}
+		if string(have) != want {
+			t.Fatalf("Value mismatch for %s, have %s, want %s", key, have, want)
+		}
+	}
+}
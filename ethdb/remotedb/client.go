@@ -0,0 +1,599 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package remotedb
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// maxIdleConns is the maximum number of idle connections kept by the client.
+const maxIdleConns = 16
+
+var errNotFound = errors.New("not found")
+
+// remoteError is an error returned by the server. Unlike the transport errors,
+// it leaves the connection in a usable state.
+type remoteError string
+
+func (e remoteError) Error() string { return string(e) }
+
+// clientConn is a single connection to the server.
+type clientConn struct {
+	conn     net.Conn
+	reader   *bufio.Reader
+	writer   *bufio.Writer
+	readOnly bool // Whether the server rejects write operations
+}
+
+// call sends a request and decodes the response into resp.
+func (c *clientConn) call(op byte, req interface{}, resp interface{}) error {
+	blob, err := rlp.EncodeToBytes(req)
+	if err != nil {
+		return err
+	}
+	if err := writeFrame(c.writer, op, blob); err != nil {
+		return err
+	}
+	if err := c.writer.Flush(); err != nil {
+		return err
+	}
+	status, payload, err := readFrame(c.reader, func(byte) uint32 { return maxFrameSize })
+	if err != nil {
+		return err
+	}
+	if status != statusOK {
+		return remoteError(payload)
+	}
+	if resp == nil {
+		return nil
+	}
+	if err := rlp.DecodeBytes(payload, resp); err != nil {
+		return remoteError("invalid response: " + err.Error())
+	}
+	return nil
+}
+
+// Client is a database served by another process over the binary protocol.
+// It implements the full ethdb.Database interface, multiple requests can be
+// issued concurrently.
+type Client struct {
+	network  string
+	addr     string
+	readOnly bool
+
+	lock   sync.Mutex
+	idle   []*clientConn
+	closed bool
+}
+
+// Dial connects to the database server listening on the given endpoint, in the
+// form of tcp://host:port or unix://path.
+func Dial(endpoint string) (*Client, error) {
+	network, addr, err := parseEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{network: network, addr: addr}
+
+	// Establish the first connection to ensure the server is reachable
+	conn, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+	c.readOnly = conn.readOnly
+	c.put(conn)
+	return c, nil
+}
+
+// ReadOnly reports whether the server rejects write operations.
+func (c *Client) ReadOnly() bool {
+	return c.readOnly
+}
+
+// dial establishes a new connection and performs the handshake.
+func (c *Client) dial() (*clientConn, error) {
+	conn, err := net.Dial(c.network, c.addr)
+	if err != nil {
+		return nil, err
+	}
+	cc := &clientConn{
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+		writer: bufio.NewWriter(conn),
+	}
+	var hello helloMsg
+	if err := cc.call(opHello, &helloMsg{Version: protocolVersion}, &hello); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	cc.readOnly = hello.ReadOnly
+	return cc, nil
+}
+
+// get returns an idle connection, or establishes a new one if there is none.
+func (c *Client) get() (*clientConn, error) {
+	c.lock.Lock()
+	if c.closed {
+		c.lock.Unlock()
+		return nil, errClosed
+	}
+	if n := len(c.idle); n > 0 {
+		conn := c.idle[n-1]
+		c.idle = c.idle[:n-1]
+		c.lock.Unlock()
+		return conn, nil
+	}
+	c.lock.Unlock()
+	return c.dial()
+}
+
+// put returns the connection into the idle pool, or closes it if the pool is
+// full or the client is closed.
+func (c *Client) put(conn *clientConn) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed || len(c.idle) >= maxIdleConns {
+		conn.conn.Close()
+		return
+	}
+	c.idle = append(c.idle, conn)
+}
+
+// release hands the connection back after a call. Connections failed with a
+// transport error are discarded, as their state is unknown.
+func (c *Client) release(conn *clientConn, err error) {
+	var rerr remoteError
+	if err != nil && !errors.As(err, &rerr) {
+		conn.conn.Close()
+		return
+	}
+	c.put(conn)
+}
+
+// call issues a single request on an idle connection.
+func (c *Client) call(op byte, req interface{}, resp interface{}) error {
+	conn, err := c.get()
+	if err != nil {
+		return err
+	}
+	err = conn.call(op, req, resp)
+	c.release(conn, err)
+	return err
+}
+
+// Has retrieves if a key is present in the key-value data store.
+func (c *Client) Has(key []byte) (bool, error) {
+	var res []bool
+	if err := c.call(opHas, &keysMsg{Keys: [][]byte{key}}, &res); err != nil {
+		return false, err
+	}
+	if len(res) != 1 {
+		return false, errors.New("invalid response")
+	}
+	return res[0], nil
+}
+
+// Get retrieves the given key if it's present in the key-value data store.
+func (c *Client) Get(key []byte) ([]byte, error) {
+	values, err := c.GetBatch([][]byte{key})
+	if err != nil {
+		return nil, err
+	}
+	if values[0] == nil {
+		return nil, errNotFound
+	}
+	return values[0], nil
+}
+
+// GetBatch retrieves the given keys in a single round trip. The value of a key
+// not present in the data store is nil.
+func (c *Client) GetBatch(keys [][]byte) ([][]byte, error) {
+	var res []valueMsg
+	if err := c.call(opGet, &keysMsg{Keys: keys}, &res); err != nil {
+		return nil, err
+	}
+	if len(res) != len(keys) {
+		return nil, errors.New("invalid response")
+	}
+	values := make([][]byte, len(keys))
+	for i, r := range res {
+		if !r.Found {
+			continue
+		}
+		values[i] = r.Value
+		if values[i] == nil {
+			values[i] = []byte{}
+		}
+	}
+	return values, nil
+}
+
+// Put inserts the given value into the key-value data store.
+func (c *Client) Put(key []byte, value []byte) error {
+	return c.call(opPut, &batchOp{Kind: batchPut, Key: key, Value: value}, nil)
+}
+
+// Delete removes the key from the key-value data store.
+func (c *Client) Delete(key []byte) error {
+	return c.call(opDelete, &batchOp{Kind: batchDelete, Key: key}, nil)
+}
+
+// DeleteRange deletes all of the keys (and values) in the range [start,end).
+func (c *Client) DeleteRange(start, end []byte) error {
+	return c.call(opDeleteRange, newRangeMsg(start, end), nil)
+}
+
+// Stat returns the statistic data of the remote database.
+func (c *Client) Stat() (string, error) {
+	var stat string
+	err := c.call(opStat, []byte{}, &stat)
+	return stat, err
+}
+
+// SyncKeyValue flushes all pending writes of the remote database to disk.
+func (c *Client) SyncKeyValue() error {
+	return c.call(opSyncKeyValue, []byte{}, nil)
+}
+
+// Compact flattens the remote data store for the given key range.
+func (c *Client) Compact(start []byte, limit []byte) error {
+	return c.call(opCompact, newRangeMsg(start, limit), nil)
+}
+
+// NewBatch creates a write-only batch that is sent to the server when Write is
+// called, and applied atomically there.
+func (c *Client) NewBatch() ethdb.Batch {
+	return &batch{client: c}
+}
+
+// NewBatchWithSize creates a write-only database batch with pre-allocated buffer.
+func (c *Client) NewBatchWithSize(size int) ethdb.Batch {
+	return &batch{client: c}
+}
+
+// NewIterator creates a binary-alphabetical iterator over a subset of database
+// content with a particular key prefix, starting at a particular initial key
+// (or after, if it does not exist). The iterator holds a connection until it's
+// released.
+func (c *Client) NewIterator(prefix []byte, start []byte) ethdb.Iterator {
+	conn, err := c.get()
+	if err != nil {
+		return &iterator{pos: -1, err: err}
+	}
+	it := &iterator{client: c, conn: conn, pos: -1}
+	if err := conn.call(opIterNew, &iterNewMsg{Prefix: prefix, Start: start}, &it.id); err != nil {
+		c.release(conn, err)
+		return &iterator{pos: -1, err: err}
+	}
+	return it
+}
+
+// Ancient retrieves an ancient binary blob from the append-only immutable files.
+func (c *Client) Ancient(kind string, number uint64) ([]byte, error) {
+	var blob []byte
+	err := c.call(opAncient, &ancientMsg{Kind: kind, Start: number}, &blob)
+	return blob, err
+}
+
+// AncientRange retrieves multiple items in sequence, starting from the index 'start'.
+// The server limits the size of a single response, so the items are retrieved
+// in multiple steps if no limit is specified.
+func (c *Client) AncientRange(kind string, start, count, maxBytes uint64) ([][]byte, error) {
+	var blobs [][]byte
+	if err := c.call(opAncientRange, &ancientMsg{Kind: kind, Start: start, Count: count, MaxBytes: maxBytes}, &blobs); err != nil {
+		return nil, err
+	}
+	if maxBytes != 0 || uint64(len(blobs)) >= count {
+		return blobs, nil
+	}
+	// The response was cut short by either the size limit of the server or
+	// the end of the ancient store.
+	frozen, err := c.Ancients()
+	if err != nil {
+		return nil, err
+	}
+	if frozen > start {
+		count = min(count, frozen-start)
+	}
+	for uint64(len(blobs)) < count {
+		var more [][]byte
+		next := start + uint64(len(blobs))
+		if err := c.call(opAncientRange, &ancientMsg{Kind: kind, Start: next, Count: count - uint64(len(blobs))}, &more); err != nil {
+			return nil, err
+		}
+		if len(more) == 0 {
+			break
+		}
+		blobs = append(blobs, more...)
+	}
+	return blobs, nil
+}
+
+// AncientBytes retrieves the value segment of the element specified by the id
+// and value offsets.
+func (c *Client) AncientBytes(kind string, id, offset, length uint64) ([]byte, error) {
+	var blob []byte
+	err := c.call(opAncientBytes, &ancientBytesMsg{Kind: kind, ID: id, Offset: offset, Length: length}, &blob)
+	return blob, err
+}
+
+// Ancients returns the ancient item numbers in the ancient store.
+func (c *Client) Ancients() (uint64, error) {
+	var n uint64
+	err := c.call(opAncients, []byte{}, &n)
+	return n, err
+}
+
+// Tail returns the lowest accessible item index for the given tail group.
+func (c *Client) Tail(group string) (uint64, error) {
+	var n uint64
+	err := c.call(opTail, group, &n)
+	return n, err
+}
+
+// AncientSize returns the ancient size of the specified category.
+func (c *Client) AncientSize(kind string) (uint64, error) {
+	var n uint64
+	err := c.call(opAncientSize, kind, &n)
+	return n, err
+}
+
+// ReadAncients runs the given read operation against the remote ancient store.
+// Note, the reads are not isolated from the concurrent writes on the server.
+func (c *Client) ReadAncients(fn func(ethdb.AncientReaderOp) error) error {
+	return fn(c)
+}
+
+// ModifyAncients collects the items appended by the given function and sends
+// them to the server at once. Nothing is written if the function fails.
+func (c *Client) ModifyAncients(fn func(ethdb.AncientWriteOp) error) (int64, error) {
+	var w ancientWriter
+	if err := fn(&w); err != nil {
+		return 0, err
+	}
+	var size uint64
+	if err := c.call(opModifyAncients, &modifyAncientsMsg{Items: w.items}, &size); err != nil {
+		return 0, err
+	}
+	return int64(size), nil
+}
+
+// TruncateHead discards all but the first n ancient data from the ancient store.
+func (c *Client) TruncateHead(n uint64) (uint64, error) {
+	var old uint64
+	err := c.call(opTruncateHead, &truncateMsg{N: n}, &old)
+	return old, err
+}
+
+// TruncateTail discards the first n items from every table belonging to the
+// named tail group.
+func (c *Client) TruncateTail(group string, n uint64) (uint64, error) {
+	var old uint64
+	err := c.call(opTruncateTail, &truncateMsg{Group: group, N: n}, &old)
+	return old, err
+}
+
+// SyncAncient flushes all in-memory ancient store data of the server to disk.
+func (c *Client) SyncAncient() error {
+	return c.call(opSyncAncient, []byte{}, nil)
+}
+
+// AncientDatadir returns the path of the ancient store directory on the server.
+func (c *Client) AncientDatadir() (string, error) {
+	var dir string
+	err := c.call(opAncientDatadir, []byte{}, &dir)
+	return dir, err
+}
+
+// Close terminates the idle connections, the ones in use are closed once the
+// pending requests are finished. The remote database itself is left open.
+func (c *Client) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	for _, conn := range c.idle {
+		conn.conn.Close()
+	}
+	c.idle = nil
+	return nil
+}
+
+// batch is a write-only batch that commits changes to the remote database
+// when Write is called. A batch cannot be used concurrently.
+type batch struct {
+	client *Client
+	ops    []batchOp
+	size   int
+}
+
+// Put inserts the given value into the batch for later committing.
+func (b *batch) Put(key, value []byte) error {
+	b.ops = append(b.ops, batchOp{Kind: batchPut, Key: common.CopyBytes(key), Value: common.CopyBytes(value)})
+	b.size += len(key) + len(value)
+	return nil
+}
+
+// Delete inserts the key removal into the batch for later committing.
+func (b *batch) Delete(key []byte) error {
+	b.ops = append(b.ops, batchOp{Kind: batchDelete, Key: common.CopyBytes(key)})
+	b.size += len(key)
+	return nil
+}
+
+// DeleteRange removes all keys in the range [start, end) from the batch for
+// later committing.
+func (b *batch) DeleteRange(start, end []byte) error {
+	b.ops = append(b.ops, batchOp{Kind: batchDeleteRange, Key: common.CopyBytes(start), Value: common.CopyBytes(end), HasEnd: end != nil})
+	b.size += len(start) + len(end)
+	return nil
+}
+
+// ValueSize retrieves the amount of data queued up for writing.
+func (b *batch) ValueSize() int {
+	return b.size
+}
+
+// Write flushes any accumulated data to the remote database.
+func (b *batch) Write() error {
+	return b.client.call(opBatchWrite, &batchMsg{Ops: b.ops}, nil)
+}
+
+// Reset resets the batch for reuse.
+func (b *batch) Reset() {
+	b.ops = b.ops[:0]
+	b.size = 0
+}
+
+// Replay replays the batch contents.
+func (b *batch) Replay(w ethdb.KeyValueWriter) error {
+	for _, op := range b.ops {
+		var err error
+		switch op.Kind {
+		case batchPut:
+			err = w.Put(op.Key, op.Value)
+		case batchDelete:
+			err = w.Delete(op.Key)
+		case batchDeleteRange:
+			rangeDeleter, ok := w.(ethdb.KeyValueRangeDeleter)
+			if !ok {
+				return errors.New("ethdb.KeyValueWriter does not implement DeleteRange")
+			}
+			r := rangeMsg{Start: op.Key, End: op.Value, HasEnd: op.HasEnd}
+			err = rangeDeleter.DeleteRange(r.Start, r.end())
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the batch and releases all associated resources.
+func (b *batch) Close() {}
+
+// iterator walks over the key space of the remote database, fetching the
+// entries from the server in chunks.
+type iterator struct {
+	client *Client
+	conn   *clientConn // Connection holding the server-side iterator, nil once released
+	id     uint64
+
+	keys   [][]byte
+	values [][]byte
+	pos    int  // Position of the current entry in the fetched chunk
+	done   bool // Whether the server-side iterator is exhausted
+	err    error
+}
+
+// Next moves the iterator to the next key/value pair. It returns whether the
+// iterator is exhausted.
+func (it *iterator) Next() bool {
+	if it.err != nil || it.conn == nil {
+		return false
+	}
+	if it.pos+1 < len(it.keys) {
+		it.pos++
+		return true
+	}
+	if it.done {
+		it.pos = len(it.keys)
+		return false
+	}
+	var res iterNextMsg
+	if err := it.conn.call(opIterNext, it.id, &res); err != nil {
+		it.err = err
+		return false
+	}
+	if len(res.Keys) != len(res.Values) {
+		it.err = errors.New("invalid response")
+		return false
+	}
+	it.keys, it.values, it.pos, it.done = res.Keys, res.Values, 0, res.Done
+	if len(it.keys) == 0 {
+		return false
+	}
+	return true
+}
+
+// Error returns any accumulated error.
+func (it *iterator) Error() error {
+	return it.err
+}
+
+// Key returns the key of the current key/value pair, or nil if done.
+func (it *iterator) Key() []byte {
+	if it.pos < 0 || it.pos >= len(it.keys) {
+		return nil
+	}
+	return it.keys[it.pos]
+}
+
+// Value returns the value of the current key/value pair, or nil if done.
+func (it *iterator) Value() []byte {
+	if it.pos < 0 || it.pos >= len(it.values) {
+		return nil
+	}
+	if it.values[it.pos] == nil {
+		return []byte{}
+	}
+	return it.values[it.pos]
+}
+
+// Release releases the server-side iterator and hands the connection back.
+func (it *iterator) Release() {
+	if it.conn == nil {
+		return
+	}
+	err := it.err
+	if err == nil {
+		err = it.conn.call(opIterRelease, it.id, nil)
+	}
+	it.client.release(it.conn, err)
+	it.conn, it.keys, it.values = nil, nil, nil
+}
+
+// ancientWriter collects the items appended to the ancient store.
+type ancientWriter struct {
+	items []ancientItem
+}
+
+// Append adds an RLP-encoded item.
+func (w *ancientWriter) Append(kind string, number uint64, item interface{}) error {
+	blob, err := rlp.EncodeToBytes(item)
+	if err != nil {
+		return err
+	}
+	return w.AppendRaw(kind, number, blob)
+}
+
+// AppendRaw adds an item without RLP-encoding it.
+func (w *ancientWriter) AppendRaw(kind string, number uint64, item []byte) error {
+	w.items = append(w.items, ancientItem{Kind: kind, Number: number, Data: common.CopyBytes(item)})
+	return nil
+}
@@ -0,0 +1,304 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package remotedb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// The binary protocol is a simple request-response exchange over a stream
+// connection. Every message is framed as
+//
+//	length (uint32, big endian) || code (byte) || payload (RLP)
+//
+// where the length covers the code and the payload. In requests the code is
+// the operation, in responses it's the status of the operation. Requests on
+// a single connection are served sequentially, the client pipelines requests
+// by using multiple connections.
+const (
+	protocolVersion = 1
+
+	// maxFrameSize is the maximum size of a single message, large enough for
+	// any batch or ancient item the node writes. Servers only accept requests of
+	// this size for write operations on unix sockets.
+	maxFrameSize = 256 * 1024 * 1024
+
+	// maxRequestSize is the maximum size of all the other requests. The clients
+	// of read-only servers are not authenticated, so the limit is kept small.
+	maxRequestSize = 1024 * 1024
+
+	// maxHelloSize is the maximum size of the handshake request, which must be
+	// the first request on every connection.
+	maxHelloSize = 64
+
+	// maxConns is the maximum number of connections a server serves at the
+	// same time.
+	maxConns = 256
+
+	// iteratorBatchItems and iteratorBatchSize are the limits of the entries
+	// delivered by the server in response to a single iterator step.
+	iteratorBatchItems = 256
+	iteratorBatchSize  = 1024 * 1024
+
+	// maxIterators is the maximum number of iterators a connection can hold
+	// open at the same time.
+	maxIterators = 64
+
+	// maxAncientRangeBytes is the maximum size of the items delivered in
+	// response to a single ancient range request. The freezer allocates the
+	// whole limit upfront, keep it moderate.
+	maxAncientRangeBytes = 16 * 1024 * 1024
+)
+
+// Operation codes of the requests.
+const (
+	opHello byte = iota
+	opHas
+	opGet
+	opPut
+	opDelete
+	opDeleteRange
+	opBatchWrite
+	opIterNew
+	opIterNext
+	opIterRelease
+	opStat
+	opCompact
+	opSyncKeyValue
+	opAncient
+	opAncientRange
+	opAncientBytes
+	opAncients
+	opTail
+	opAncientSize
+	opModifyAncients
+	opTruncateHead
+	opTruncateTail
+	opSyncAncient
+	opAncientDatadir
+)
+
+// Status codes of the responses.
+const (
+	statusOK byte = iota
+	statusError
+)
+
+var (
+	errFrameTooLarge = errors.New("message too large")
+	errReadOnly      = errors.New("remote database is read-only")
+	errClosed        = errors.New("remote database is closed")
+
+	errWritableNetwork = errors.New("writable remote database requires a unix socket")
+	errTooManyIters    = errors.New("too many open iterators")
+	errTooManyConns    = errors.New("too many connections")
+)
+
+// helloMsg is exchanged in both directions when a connection is established.
+type helloMsg struct {
+	Version  uint64
+	ReadOnly bool // Whether the server rejects write operations
+}
+
+// keysMsg is the request of the batched Has and Get operations.
+type keysMsg struct {
+	Keys [][]byte
+}
+
+// valueMsg is a single result of the batched Get operation.
+type valueMsg struct {
+	Found bool
+	Value []byte
+}
+
+// rangeMsg specifies a key range. RLP doesn't distinguish a nil slice from an
+// empty one, which have different meanings for the range end, so the presence
+// of the end is encoded explicitly.
+type rangeMsg struct {
+	Start  []byte
+	End    []byte
+	HasEnd bool
+}
+
+func newRangeMsg(start, end []byte) *rangeMsg {
+	return &rangeMsg{Start: start, End: end, HasEnd: end != nil}
+}
+
+// end returns the range end, nil meaning it's unbounded.
+func (r *rangeMsg) end() []byte {
+	if !r.HasEnd {
+		return nil
+	}
+	if r.End == nil {
+		return []byte{}
+	}
+	return r.End
+}
+
+// Kinds of the batch operations.
+const (
+	batchPut byte = iota
+	batchDelete
+	batchDeleteRange
+)
+
+// batchOp is a single operation of a write batch. For range deletions the key
+// and value are the start and end of the range.
+type batchOp struct {
+	Kind   byte
+	Key    []byte
+	Value  []byte
+	HasEnd bool
+}
+
+// batchMsg is the request of the BatchWrite operation.
+type batchMsg struct {
+	Ops []batchOp
+}
+
+// iterNewMsg is the request of the IterNew operation.
+type iterNewMsg struct {
+	Prefix []byte
+	Start  []byte
+}
+
+// iterNextMsg is the response of the IterNext operation.
+type iterNextMsg struct {
+	Keys   [][]byte
+	Values [][]byte
+	Done   bool // Whether the iterator is exhausted
+}
+
+// ancientMsg is the request of the ancient read operations.
+type ancientMsg struct {
+	Kind     string
+	Start    uint64
+	Count    uint64
+	MaxBytes uint64
+}
+
+// ancientBytesMsg is the request of the AncientBytes operation.
+type ancientBytesMsg struct {
+	Kind   string
+	ID     uint64
+	Offset uint64
+	Length uint64
+}
+
+// ancientItem is a single raw item appended to the ancient store.
+type ancientItem struct {
+	Kind   string
+	Number uint64
+	Data   []byte
+}
+
+// modifyAncientsMsg is the request of the ModifyAncients operation.
+type modifyAncientsMsg struct {
+	Items []ancientItem
+}
+
+// truncateMsg is the request of the TruncateHead and TruncateTail operations.
+type truncateMsg struct {
+	Group string
+	N     uint64
+}
+
+// writeFrame writes a single framed message into the given writer.
+func writeFrame(w io.Writer, code byte, payload []byte) error {
+	if len(payload)+1 > maxFrameSize {
+		return errFrameTooLarge
+	}
+	var header [5]byte
+	binary.BigEndian.PutUint32(header[:4], uint32(len(payload)+1))
+	header[4] = code
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+// readFrame reads a single framed message from the given reader. The limit
+// function returns the maximum size of a message with the given code.
+func readFrame(r io.Reader, limit func(code byte) uint32) (byte, []byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	size := binary.BigEndian.Uint32(header[:4])
+	if size == 0 || size > limit(header[4]) {
+		return 0, nil, fmt.Errorf("invalid message size %d", size)
+	}
+	// The size is chosen by the remote end, so the buffer is grown as the data
+	// arrives instead of being allocated upfront.
+	var payload bytes.Buffer
+	if _, err := io.CopyN(&payload, r, int64(size-1)); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, nil, err
+	}
+	return header[4], payload.Bytes(), nil
+}
+
+// IsEndpoint reports whether the given string is an endpoint of the binary
+// protocol, in the form of tcp://host:port or unix://path.
+func IsEndpoint(endpoint string) bool {
+	_, _, err := parseEndpoint(endpoint)
+	return err == nil
+}
+
+// parseEndpoint splits the endpoint into the network and the address.
+func parseEndpoint(endpoint string) (string, string, error) {
+	for _, network := range []string{"tcp", "unix"} {
+		if addr, ok := strings.CutPrefix(endpoint, network+"://"); ok && addr != "" {
+			return network, addr, nil
+		}
+	}
+	return "", "", fmt.Errorf("invalid remote database endpoint %q", endpoint)
+}
+
+// Listen creates a listener on the given endpoint. Any stale unix socket left
+// behind at the same path is removed.
+func Listen(endpoint string) (net.Listener, error) {
+	network, addr, err := parseEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if network == "unix" {
+		if err := os.MkdirAll(filepath.Dir(addr), 0751); err != nil {
+			return nil, err
+		}
+		os.Remove(addr)
+	}
+	l, err := net.Listen(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	if network == "unix" {
+		os.Chmod(addr, 0600)
+	}
+	return l, nil
+}
@@ -19,6 +19,10 @@
 // read-only database.
 // There really are no guarantees in this database, since the local geth does not
 // exclusive access, but it can be used for basic diagnostics of a remote node.
+//
+// Additionally, the package provides a client and server pair exposing the full
+// database of a running node to other processes over a compact binary protocol,
+// see Server and Client.
 package remotedb
 
 import (
@@ -0,0 +1,332 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package remotedb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/dbtest"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var _ ethdb.Database = (*Client)(nil)
+
+// newTestClient starts a server for the given database and connects to it.
+func newTestClient(t *testing.T, db ethdb.Database, endpoint string, readOnly bool) *Client {
+	t.Helper()
+
+	l, err := Listen(endpoint)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	server := NewServer(db, readOnly)
+	go server.Serve(l)
+	t.Cleanup(server.Close)
+
+	if l.Addr().Network() == "tcp" {
+		endpoint = "tcp://" + l.Addr().String()
+	}
+	client, err := Dial(endpoint)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	return client
+}
+
+func TestRemoteDB(t *testing.T) {
+	t.Run("DatabaseSuiteUnix", func(t *testing.T) {
+		dir := t.TempDir()
+		dbtest.TestDatabaseSuite(t, func() ethdb.KeyValueStore {
+			return newTestClient(t, rawdb.NewMemoryDatabase(), "unix://"+filepath.Join(dir, "db.ipc"), false)
+		})
+	})
+}
+
+// Writable servers must not be reachable over the network.
+func TestRemoteDBWritableTCP(t *testing.T) {
+	l, err := Listen("tcp://127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer l.Close()
+
+	server := NewServer(rawdb.NewMemoryDatabase(), false)
+	defer server.Close()
+	if err := server.Serve(l); err != errWritableNetwork {
+		t.Fatalf("Unexpected error, have %v, want %v", err, errWritableNetwork)
+	}
+}
+
+func TestRemoteDBIteratorLimit(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	db.Put([]byte("key"), []byte("value"))
+
+	client := newTestClient(t, db, "tcp://127.0.0.1:0", true)
+	defer client.Close()
+
+	var ids []uint64
+	for i := 0; i < maxIterators; i++ {
+		var id uint64
+		if err := client.call(opIterNew, &iterNewMsg{}, &id); err != nil {
+			t.Fatalf("Failed to open iterator %d: %v", i, err)
+		}
+		ids = append(ids, id)
+	}
+	var id uint64
+	if err := client.call(opIterNew, &iterNewMsg{}, &id); err == nil || err.Error() != errTooManyIters.Error() {
+		t.Fatalf("Unexpected error, have %v, want %v", err, errTooManyIters)
+	}
+	// Released iterators make room for new ones.
+	if err := client.call(opIterRelease, ids[0], new([]byte)); err != nil {
+		t.Fatalf("Failed to release iterator: %v", err)
+	}
+	it := client.NewIterator(nil, nil)
+	defer it.Release()
+	if !it.Next() || !bytes.Equal(it.Key(), []byte("key")) {
+		t.Fatalf("Failed to iterate, err: %v", it.Error())
+	}
+}
+
+func TestRemoteDBGetBatch(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	db.Put([]byte("a"), []byte("1"))
+	db.Put([]byte("b"), []byte{})
+
+	client := newTestClient(t, db, "unix://"+filepath.Join(t.TempDir(), "db.ipc"), false)
+	defer client.Close()
+
+	values, err := client.GetBatch([][]byte{[]byte("a"), []byte("b"), []byte("c")})
+	if err != nil {
+		t.Fatalf("Failed to retrieve values: %v", err)
+	}
+	if !bytes.Equal(values[0], []byte("1")) {
+		t.Fatalf("Unexpected value, have %x, want %x", values[0], []byte("1"))
+	}
+	if values[1] == nil || len(values[1]) != 0 {
+		t.Fatalf("Unexpected empty value, have %x", values[1])
+	}
+	if values[2] != nil {
+		t.Fatalf("Unexpected value of missing key, have %x", values[2])
+	}
+	if _, err := client.Get([]byte("c")); err == nil {
+		t.Fatal("Expected error for missing key")
+	}
+}
+
+func TestRemoteDBAncients(t *testing.T) {
+	db, err := rawdb.NewDatabaseWithFreezer(memorydb.New(), "", "", false)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	client := newTestClient(t, db, "unix://"+filepath.Join(t.TempDir(), "db.ipc"), false)
+	defer client.Close()
+
+	// Write a few blocks into the ancient store through the client
+	var (
+		items  [][]byte
+		tables = []string{rawdb.ChainFreezerHeaderTable, rawdb.ChainFreezerHashTable, rawdb.ChainFreezerBodiesTable, rawdb.ChainFreezerReceiptTable, rawdb.ChainFreezerBALTable}
+	)
+	for i := 0; i < 4; i++ {
+		items = append(items, bytes.Repeat([]byte{byte(i)}, 10+i))
+	}
+	_, err = client.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for i, item := range items {
+			for _, kind := range tables {
+				if err := op.AppendRaw(kind, uint64(i), item); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to write ancients: %v", err)
+	}
+	if n, err := client.Ancients(); err != nil || n != uint64(len(items)) {
+		t.Fatalf("Unexpected ancient count, have %d, want %d, err: %v", n, len(items), err)
+	}
+	for i, item := range items {
+		blob, err := client.Ancient(rawdb.ChainFreezerHashTable, uint64(i))
+		if err != nil {
+			t.Fatalf("Failed to read ancient %d: %v", i, err)
+		}
+		if !bytes.Equal(blob, item) {
+			t.Fatalf("Ancient %d mismatch, have %x, want %x", i, blob, item)
+		}
+	}
+	blobs, err := client.AncientRange(rawdb.ChainFreezerHashTable, 1, 2, 0)
+	if err != nil {
+		t.Fatalf("Failed to read ancient range: %v", err)
+	}
+	if len(blobs) != 2 || !bytes.Equal(blobs[0], items[1]) || !bytes.Equal(blobs[1], items[2]) {
+		t.Fatalf("Ancient range mismatch, have %x", blobs)
+	}
+	// Responses are limited in size, but unlimited ranges are still
+	// retrieved completely.
+	_, err = client.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for i := len(items); i < len(items)+3; i++ {
+			for _, kind := range tables {
+				item := []byte{byte(i)}
+				if kind == rawdb.ChainFreezerBodiesTable {
+					item = bytes.Repeat(item, maxAncientRangeBytes/2)
+				}
+				if err := op.AppendRaw(kind, uint64(i), item); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to write ancients: %v", err)
+	}
+	var limited [][]byte
+	if err := client.call(opAncientRange, &ancientMsg{Kind: rawdb.ChainFreezerBodiesTable, Start: 0, Count: 7}, &limited); err != nil {
+		t.Fatalf("Failed to read ancient range: %v", err)
+	}
+	if len(limited) != 5 {
+		t.Fatalf("Unexpected number of items in limited response, have %d, want 5", len(limited))
+	}
+	if blobs, err := client.AncientRange(rawdb.ChainFreezerBodiesTable, 0, 10, 0); err != nil || len(blobs) != 7 {
+		t.Fatalf("Unexpected number of items, have %d, want 7, err: %v", len(blobs), err)
+	}
+	if _, err := client.TruncateHead(uint64(len(items))); err != nil {
+		t.Fatalf("Failed to truncate head: %v", err)
+	}
+	if blob, err := client.AncientBytes(rawdb.ChainFreezerHashTable, 3, 2, 4); err != nil || !bytes.Equal(blob, items[3][2:6]) {
+		t.Fatalf("Ancient bytes mismatch, have %x, want %x, err: %v", blob, items[3][2:6], err)
+	}
+	// Failed write operations must not be sent
+	_, err = client.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		op.AppendRaw(rawdb.ChainFreezerHashTable, 4, []byte{0x1})
+		return errors.New("aborted")
+	})
+	if err == nil {
+		t.Fatal("Expected error for aborted write")
+	}
+	if _, err := client.TruncateHead(2); err != nil {
+		t.Fatalf("Failed to truncate head: %v", err)
+	}
+	if n, err := client.Ancients(); err != nil || n != 2 {
+		t.Fatalf("Unexpected ancient count, have %d, want 2, err: %v", n, err)
+	}
+	if _, err := client.Ancient(rawdb.ChainFreezerHashTable, 3); err == nil {
+		t.Fatal("Expected error for truncated item")
+	}
+}
+
+func TestRemoteDBReadOnly(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	db.Put([]byte("key"), []byte("value"))
+
+	client := newTestClient(t, db, "tcp://127.0.0.1:0", true)
+	defer client.Close()
+
+	if !client.ReadOnly() {
+		t.Fatal("Server not reported as read-only")
+	}
+	if value, err := client.Get([]byte("key")); err != nil || !bytes.Equal(value, []byte("value")) {
+		t.Fatalf("Unexpected value, have %x, err: %v", value, err)
+	}
+	if err := client.Put([]byte("key"), []byte("other")); err == nil {
+		t.Fatal("Expected error for write on read-only database")
+	}
+	batch := client.NewBatch()
+	batch.Delete([]byte("key"))
+	if err := batch.Write(); err == nil {
+		t.Fatal("Expected error for batch write on read-only database")
+	}
+	// The rejected writes must leave the connections usable
+	if ok, err := client.Has([]byte("key")); err != nil || !ok {
+		t.Fatalf("Key not found after rejected writes, err: %v", err)
+	}
+}
+
+// This test checks that the server rejects oversized requests of clients before
+// reading them, and requests other than the handshake on new connections.
+func TestRemoteDBFrameLimits(t *testing.T) {
+	l, err := Listen("tcp://127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	server := NewServer(rawdb.NewMemoryDatabase(), true)
+	go server.Serve(l)
+	defer server.Close()
+
+	hello, _ := rlp.EncodeToBytes(&helloMsg{Version: protocolVersion})
+	tests := []struct {
+		name   string
+		frames func(w io.Writer)
+	}{
+		{"no handshake", func(w io.Writer) {
+			writeFrame(w, opGet, []byte{0xc0})
+		}},
+		{"large handshake", func(w io.Writer) {
+			writeFrameHeader(w, opHello, maxHelloSize+1)
+		}},
+		{"large request", func(w io.Writer) {
+			writeFrame(w, opHello, hello)
+			writeFrameHeader(w, opGet, maxRequestSize+1)
+		}},
+	}
+	for _, test := range tests {
+		conn, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatalf("Failed to dial: %v", err)
+		}
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		test.frames(conn)
+
+		// The server responds to the handshake and closes the connection then.
+		reader := bufio.NewReader(conn)
+		for {
+			code, _, err := readFrame(reader, func(byte) uint32 { return maxFrameSize })
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: connection not closed: %v", test.name, err)
+			}
+			if code != statusOK {
+				t.Fatalf("%s: unexpected response status %d", test.name, code)
+			}
+		}
+		conn.Close()
+	}
+}
+
+// writeFrameHeader writes the header of a frame with the given size, without
+// the payload.
+func writeFrameHeader(w io.Writer, code byte, size uint32) {
+	var header [5]byte
+	binary.BigEndian.PutUint32(header[:4], size)
+	header[4] = code
+	w.Write(header[:])
+}
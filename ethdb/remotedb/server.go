@@ -0,0 +1,460 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package remotedb
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Server exposes a database to other processes over the binary protocol.
+type Server struct {
+	db       ethdb.Database
+	readOnly bool
+
+	lock      sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// NewServer creates a server for the given database. If readOnly is set, all
+// the operations modifying the database are rejected.
+func NewServer(db ethdb.Database, readOnly bool) *Server {
+	return &Server{
+		db:        db,
+		readOnly:  readOnly,
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}
+}
+
+// Serve accepts the connections on the given listener and serves them until
+// the listener fails or the server is closed. The protocol has no means of
+// authentication, so writable servers only accept unix socket listeners, which
+// are guarded by the file permissions.
+func (s *Server) Serve(l net.Listener) error {
+	if !s.readOnly && l.Addr().Network() != "unix" {
+		return errWritableNetwork
+	}
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		return errClosed
+	}
+	s.listeners[l] = struct{}{}
+	s.lock.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.lock.Lock()
+			delete(s.listeners, l)
+			closed := s.closed
+			s.lock.Unlock()
+
+			if closed {
+				return nil
+			}
+			return err
+		}
+		s.lock.Lock()
+		if s.closed {
+			s.lock.Unlock()
+			conn.Close()
+			return nil
+		}
+		if len(s.conns) >= maxConns {
+			s.lock.Unlock()
+			log.Debug("Rejected remote database connection", "addr", conn.RemoteAddr(), "err", errTooManyConns)
+			conn.Close()
+			continue
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.lock.Unlock()
+
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops all the listeners, terminates the open connections and waits
+// until the in-flight requests are finished.
+func (s *Server) Close() {
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		return
+	}
+	s.closed = true
+	for l := range s.listeners {
+		l.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.lock.Unlock()
+
+	s.wg.Wait()
+}
+
+// serverConn is the state of a single client connection.
+type serverConn struct {
+	conn     net.Conn
+	reader   *bufio.Reader
+	writer   *bufio.Writer
+	iters    map[uint64]ethdb.Iterator
+	nextIter uint64
+	greeted  bool // Whether the handshake is done
+	unix     bool // Whether the connection is a unix socket
+}
+
+// frameLimit returns the maximum size of a request with the given operation.
+// Nothing but the handshake is accepted before the handshake is done.
+func (s *Server) frameLimit(c *serverConn, op byte) uint32 {
+	switch {
+	case !c.greeted:
+		if op != opHello {
+			return 0
+		}
+		return maxHelloSize
+	case c.unix && !s.readOnly && isWrite(op):
+		return maxFrameSize
+	default:
+		return maxRequestSize
+	}
+}
+
+// serveConn serves the requests of a single connection until it's closed.
+func (s *Server) serveConn(conn net.Conn) {
+	c := &serverConn{
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+		writer: bufio.NewWriter(conn),
+		iters:  make(map[uint64]ethdb.Iterator),
+		unix:   conn.LocalAddr().Network() == "unix",
+	}
+	defer func() {
+		// Release the iterators abandoned by the client
+		for _, it := range c.iters {
+			it.Release()
+		}
+		conn.Close()
+
+		s.lock.Lock()
+		delete(s.conns, conn)
+		s.lock.Unlock()
+		s.wg.Done()
+	}()
+	limit := func(op byte) uint32 { return s.frameLimit(c, op) }
+	for {
+		op, payload, err := readFrame(c.reader, limit)
+		if err != nil {
+			return
+		}
+		resp, err := s.handle(c, op, payload)
+		if op == opHello && err == nil {
+			c.greeted = true
+		}
+		if err != nil {
+			err = writeFrame(c.writer, statusError, []byte(err.Error()))
+		} else {
+			var blob []byte
+			if blob, err = rlp.EncodeToBytes(resp); err == nil {
+				err = writeFrame(c.writer, statusOK, blob)
+			}
+		}
+		if err == nil {
+			err = c.writer.Flush()
+		}
+		if err != nil {
+			log.Debug("Failed to respond remote database request", "op", op, "err", err)
+			return
+		}
+	}
+}
+
+// isWrite reports whether the operation modifies the database.
+func isWrite(op byte) bool {
+	switch op {
+	case opPut, opDelete, opDeleteRange, opBatchWrite, opCompact, opModifyAncients, opTruncateHead, opTruncateTail:
+		return true
+	}
+	return false
+}
+
+// handle executes a single request, returning the response to be encoded.
+func (s *Server) handle(c *serverConn, op byte, payload []byte) (interface{}, error) {
+	if s.readOnly && isWrite(op) {
+		return nil, errReadOnly
+	}
+	switch op {
+	case opHello:
+		var req helloMsg
+		if err := rlp.DecodeBytes(payload, &req); err != nil {
+			return nil, err
+		}
+		if req.Version != protocolVersion {
+			return nil, fmt.Errorf("unsupported protocol version %d, want %d", req.Version, protocolVersion)
+		}
+		return &helloMsg{Version: protocolVersion, ReadOnly: s.readOnly}, nil
+
+	case opHas:
+		var req keysMsg
+		if err := rlp.DecodeBytes(payload, &req); err != nil {
+			return nil, err
+		}
+		res := make([]bool, len(req.Keys))
+		for i, key := range req.Keys {
+			ok, err := s.db.Has(key)
+			if err != nil {
+				return nil, err
+			}
+			res[i] = ok
+		}
+		return res, nil
+
+	case opGet:
+		var req keysMsg
+		if err := rlp.DecodeBytes(payload, &req); err != nil {
+			return nil, err
+		}
+		res := make([]valueMsg, len(req.Keys))
+		for i, key := range req.Keys {
+			value, err := s.db.Get(key)
+			if err != nil {
+				// The not-found error isn't standardized across the backends,
+				// check the existence explicitly to tell it apart from failures.
+				if ok, herr := s.db.Has(key); herr == nil && !ok {
+					continue
+				}
+				return nil, err
+			}
+			res[i] = valueMsg{Found: true, Value: value}
+		}
+		return res, nil
+
+	case opPut:
+		var req batchOp
+		if err := rlp.DecodeBytes(payload, &req); err != nil {
+			return nil, err
+		}
+		return []byte{}, s.db.Put(req.Key, req.Value)
+
+	case opDelete:
+		var req batchOp
+		if err := rlp.DecodeBytes(payload, &req); err != nil {
+			return nil, err
+		}
+		return []byte{}, s.db.Delete(req.Key)
+
+	case opDeleteRange:
+		var req rangeMsg
+		if err := rlp.DecodeBytes(payload, &req); err != nil {
+			return nil, err
+		}
+		return []byte{}, s.db.DeleteRange(req.Start, req.end())
+
+	case opBatchWrite:
+		var req batchMsg
+		if err := rlp.DecodeBytes(payload, &req); err != nil {
+			return nil, err
+		}
+		return []byte{}, s.writeBatch(req.Ops)
+
+	case opIterNew:
+		var req iterNewMsg
+		if err := rlp.DecodeBytes(payload, &req); err != nil {
+			return nil, err
+		}
+		if len(c.iters) >= maxIterators {
+			return nil, errTooManyIters
+		}
+		c.nextIter++
+		c.iters[c.nextIter] = s.db.NewIterator(req.Prefix, req.Start)
+		return c.nextIter, nil
+
+	case opIterNext:
+		var id uint64
+		if err := rlp.DecodeBytes(payload, &id); err != nil {
+			return nil, err
+		}
+		it, ok := c.iters[id]
+		if !ok {
+			return nil, fmt.Errorf("unknown iterator %d", id)
+		}
+		var (
+			res  iterNextMsg
+			size int
+		)
+		for len(res.Keys) < iteratorBatchItems && size < iteratorBatchSize {
+			if !it.Next() {
+				if err := it.Error(); err != nil {
+					return nil, err
+				}
+				res.Done = true
+				break
+			}
+			key, value := it.Key(), it.Value()
+			res.Keys = append(res.Keys, key)
+			res.Values = append(res.Values, value)
+			size += len(key) + len(value)
+		}
+		return &res, nil
+
+	case opIterRelease:
+		var id uint64
+		if err := rlp.DecodeBytes(payload, &id); err != nil {
+			return nil, err
+		}
+		if it, ok := c.iters[id]; ok {
+			it.Release()
+			delete(c.iters, id)
+		}
+		return []byte{}, nil
+
+	case opStat:
+		return s.db.Stat()
+
+	case opCompact:
+		var req rangeMsg
+		if err := rlp.DecodeBytes(payload, &req); err != nil {
+			return nil, err
+		}
+		return []byte{}, s.db.Compact(req.Start, req.end())
+
+	case opSyncKeyValue:
+		return []byte{}, s.db.SyncKeyValue()
+
+	case opAncient:
+		var req ancientMsg
+		if err := rlp.DecodeBytes(payload, &req); err != nil {
+			return nil, err
+		}
+		return s.db.Ancient(req.Kind, req.Start)
+
+	case opAncientRange:
+		var req ancientMsg
+		if err := rlp.DecodeBytes(payload, &req); err != nil {
+			return nil, err
+		}
+		// Zero means no limit, but the response must fit into a frame.
+		if req.MaxBytes == 0 || req.MaxBytes > maxAncientRangeBytes {
+			req.MaxBytes = maxAncientRangeBytes
+		}
+		return s.db.AncientRange(req.Kind, req.Start, req.Count, req.MaxBytes)
+
+	case opAncientBytes:
+		var req ancientBytesMsg
+		if err := rlp.DecodeBytes(payload, &req); err != nil {
+			return nil, err
+		}
+		return s.db.AncientBytes(req.Kind, req.ID, req.Offset, req.Length)
+
+	case opAncients:
+		return s.db.Ancients()
+
+	case opTail:
+		var group string
+		if err := rlp.DecodeBytes(payload, &group); err != nil {
+			return nil, err
+		}
+		return s.db.Tail(group)
+
+	case opAncientSize:
+		var kind string
+		if err := rlp.DecodeBytes(payload, &kind); err != nil {
+			return nil, err
+		}
+		return s.db.AncientSize(kind)
+
+	case opModifyAncients:
+		var req modifyAncientsMsg
+		if err := rlp.DecodeBytes(payload, &req); err != nil {
+			return nil, err
+		}
+		size, err := s.db.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+			for _, item := range req.Items {
+				if err := op.AppendRaw(item.Kind, item.Number, item.Data); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		// The size can't be negative, but keep it RLP encodable regardless.
+		return uint64(max(size, 0)), nil
+
+	case opTruncateHead:
+		var req truncateMsg
+		if err := rlp.DecodeBytes(payload, &req); err != nil {
+			return nil, err
+		}
+		return s.db.TruncateHead(req.N)
+
+	case opTruncateTail:
+		var req truncateMsg
+		if err := rlp.DecodeBytes(payload, &req); err != nil {
+			return nil, err
+		}
+		return s.db.TruncateTail(req.Group, req.N)
+
+	case opSyncAncient:
+		return []byte{}, s.db.SyncAncient()
+
+	case opAncientDatadir:
+		return s.db.AncientDatadir()
+
+	default:
+		return nil, fmt.Errorf("unknown operation %d", op)
+	}
+}
+
+// writeBatch applies the batch operations atomically.
+func (s *Server) writeBatch(ops []batchOp) error {
+	batch := s.db.NewBatch()
+	defer batch.Close()
+
+	for _, op := range ops {
+		var err error
+		switch op.Kind {
+		case batchPut:
+			err = batch.Put(op.Key, op.Value)
+		case batchDelete:
+			err = batch.Delete(op.Key)
+		case batchDeleteRange:
+			r := rangeMsg{Start: op.Key, End: op.Value, HasEnd: op.HasEnd}
+			err = batch.DeleteRange(r.Start, r.end())
+		default:
+			err = errors.New("unknown batch operation")
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
@@ -403,6 +403,12 @@ func (api *BlockChainAPI) GetProof(ctx context.Context, address common.Address,
 	if statedb == nil || err != nil {
 		return nil, err
 	}
+	// Open the account trie first. Historical states are resolved via the
+	// trienode history in path mode, bail out early if it's not available.
+	tr, err := statedb.Database().OpenTrie(header.Root)
+	if err != nil {
+		return nil, err
+	}
 	codeHash := statedb.GetCodeHash(address)
 	storageRoot := statedb.GetStorageRoot(address)
 
@@ -443,10 +449,6 @@ func (api *BlockChainAPI) GetProof(ctx context.Context, address common.Address,
 		}
 	}
 	// Create the accountProof.
-	tr, err := statedb.Database().OpenTrie(header.Root)
-	if err != nil {
-		return nil, err
-	}
 	var accountProof proofList
 	if err := tr.Prove(crypto.Keccak256(address.Bytes()), &accountProof); err != nil {
 		return nil, err
@@ -0,0 +1,143 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// newHistoricalProofBackend creates a path-based archive chain retaining the
+// trienode histories of the given number of blocks, with most of the blocks
+// already flushed out of the in-memory layers.
+func newHistoricalProofBackend(t *testing.T, trienodeHistory int64) (*testBackend, common.Address) {
+	var (
+		key, _ = crypto.GenerateKey()
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		engine = ethash.NewFaker()
+		gspec  = &core.Genesis{
+			Config:    params.TestChainConfig,
+			Timestamp: uint64(time.Now().Unix()) - 3000, // recent enough for the history indexer to start
+			Alloc:     types.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	_, blocks, _ := core.GenerateChainWithGenesis(gspec, engine, 200, func(i int, b *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(addr), common.Address{0x01}, big.NewInt(1000), params.TxGas, b.BaseFee(), nil), signer, key)
+		b.AddTx(tx)
+	})
+	db, err := rawdb.Open(memorydb.New(), rawdb.OpenOptions{Ancient: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	options := core.DefaultConfig().WithStateScheme(rawdb.PathScheme).WithArchive(true)
+	options.TrienodeHistory = trienodeHistory
+
+	chain, err := core.NewBlockChain(db, gspec, engine, options)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	// Reopen the chain, making the history indexer pick up the recent head.
+	chain.Stop()
+	if chain, err = core.NewBlockChain(db, gspec, engine, options); err != nil {
+		t.Fatalf("failed to reopen tester chain: %v", err)
+	}
+	t.Cleanup(chain.Stop)
+	return &testBackend{db: db, chain: chain}, addr
+}
+
+func TestGetProofHistorical(t *testing.T) {
+	t.Parallel()
+
+	backend, addr := newHistoricalProofBackend(t, 0)
+	api := NewBlockChainAPI(backend)
+
+	number := rpc.BlockNumberOrHashWithNumber(10)
+	header := backend.chain.GetHeaderByNumber(10)
+	if _, err := backend.chain.StateAt(header); err == nil {
+		t.Fatal("state of block 10 is still live")
+	}
+	var (
+		result *AccountResult
+		err    error
+	)
+	for {
+		result, err = api.GetProof(context.Background(), addr, nil, &number)
+		if err == nil || !strings.Contains(err.Error(), "indexed") {
+			break
+		}
+		time.Sleep(10 * time.Millisecond) // the histories are not indexed yet
+	}
+	if err != nil {
+		t.Fatalf("failed to create historical proof: %v", err)
+	}
+	if result.Nonce != 10 {
+		t.Fatalf("wrong nonce: have %d, want 10", result.Nonce)
+	}
+	// Verify the proof against the historical state root.
+	proof := memorydb.New()
+	for _, node := range result.AccountProof {
+		blob := hexutil.MustDecode(node)
+		proof.Put(crypto.Keccak256(blob), blob)
+	}
+	value, err := trie.VerifyProof(header.Root, crypto.Keccak256(addr.Bytes()), proof)
+	if err != nil {
+		t.Fatalf("invalid proof: %v", err)
+	}
+	var account types.StateAccount
+	if err := rlp.DecodeBytes(value, &account); err != nil {
+		t.Fatalf("failed to decode account: %v", err)
+	}
+	if account.Nonce != 10 || account.Balance.ToBig().Cmp(result.Balance.ToInt()) != 0 {
+		t.Fatalf("proof doesn't match the result: nonce %d, balance %v", account.Nonce, account.Balance)
+	}
+}
+
+func TestGetProofOutsideHistory(t *testing.T) {
+	t.Parallel()
+
+	backend, addr := newHistoricalProofBackend(t, 50)
+	api := NewBlockChainAPI(backend)
+
+	number := rpc.BlockNumberOrHashWithNumber(10)
+	_, err := api.GetProof(context.Background(), addr, nil, &number)
+	if err == nil {
+		t.Fatal("proof created outside of the trienode history")
+	}
+	if !strings.Contains(err.Error(), "trienode history covers blocks") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
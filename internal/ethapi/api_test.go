@@ -574,6 +574,9 @@ func (b testBackend) StateAndHeaderByNumber(ctx context.Context, number rpc.Bloc
 		return nil, nil, errors.New("header not found")
 	}
 	stateDb, err := b.chain.StateAt(header)
+	if err != nil {
+		stateDb, err = b.chain.HistoricState(header)
+	}
 	return stateDb, header, err
 }
 func (b testBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
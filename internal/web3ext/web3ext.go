@@ -121,6 +121,11 @@ web3._extend({
 			call: 'admin_importChain',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'dbCheckpoint',
+			call: 'admin_dbCheckpoint',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'sleepBlocks',
 			call: 'admin_sleepBlocks',
@@ -432,6 +437,24 @@ web3._extend({
 			params: 2,
 			inputFormatter:[null, null],
 		}),
+		new web3._extend.Method({
+			name: 'getAccountHistory',
+			call: 'debug_getAccountHistory',
+			params: 4,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter, function (val) { return val == null ? null : web3._extend.utils.fromDecimal(val); }],
+		}),
+		new web3._extend.Method({
+			name: 'getStorageHistory',
+			call: 'debug_getStorageHistory',
+			params: 5,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter, function (val) { return val == null ? null : web3._extend.utils.fromDecimal(val); }],
+		}),
+		new web3._extend.Method({
+			name: 'getStateDiff',
+			call: 'debug_getStateDiff',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter, null],
+		}),
 		new web3._extend.Method({
 			name: 'getAccessibleState',
 			call: 'debug_getAccessibleState',
@@ -453,6 +476,11 @@ web3._extend({
 			call: 'debug_dbAncients',
 			params: 0
 		}),
+		new web3._extend.Method({
+			name: 'dbStats',
+			call: 'debug_dbStats',
+			params: 0
+		}),
 		new web3._extend.Method({
 			name: 'setTrieFlushInterval',
 			call: 'debug_setTrieFlushInterval',
@@ -463,6 +491,23 @@ web3._extend({
 			call: 'debug_getTrieFlushInterval',
 			params: 0
 		}),
+		new web3._extend.Method({
+			name: 'setStateHistoryLimit',
+			call: 'debug_setStateHistoryLimit',
+			params: 1,
+			inputFormatter: [web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'setTrienodeHistoryLimit',
+			call: 'debug_setTrienodeHistoryLimit',
+			params: 1,
+			inputFormatter: [web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'getHistoryPruneProgress',
+			call: 'debug_getHistoryPruneProgress',
+			params: 0
+		}),
 		new web3._extend.Method({
 			name: 'sync',
 			call: 'debug_sync',
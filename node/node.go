@@ -781,6 +781,16 @@ func (db *closeTrackingDB) Close() error {
 	return err
 }
 
+// Checkpoint implements ethdb.Checkpointer, forwarding the call to the wrapped
+// database if it's supported.
+func (db *closeTrackingDB) Checkpoint(dir string) error {
+	cp, ok := db.Database.(ethdb.Checkpointer)
+	if !ok {
+		return ethdb.ErrCheckpointUnsupported
+	}
+	return cp.Checkpoint(dir)
+}
+
 // wrapDatabase ensures the database will be auto-closed when Node is closed.
 func (n *Node) wrapDatabase(db ethdb.Database) ethdb.Database {
 	wrapper := &closeTrackingDB{db, n}
@@ -0,0 +1,188 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	"golang.org/x/time/rate"
+)
+
+// BandwidthLimit is a budget for message traffic in bytes per second. Zero values
+// mean unlimited. Budgets apply to message payloads, i.e. the size of messages
+// before compression.
+//
+// Incoming messages exceeding the ingress budget are handed to the protocol once
+// enough budget is available. Meanwhile, further messages of the protocol are not
+// read from the connection, which also stalls the remote end. Limits should
+// therefore allow transferring the largest expected message within a few seconds,
+// otherwise remote peers may run into write timeouts.
+type BandwidthLimit struct {
+	Ingress int `toml:",omitempty"`
+	Egress  int `toml:",omitempty"`
+}
+
+// bandwidthBudget holds the limiters of a single budget. Nil limiters are unlimited.
+type bandwidthBudget struct {
+	ingress, egress *rate.Limiter
+}
+
+func newBandwidthBudget(limit BandwidthLimit) bandwidthBudget {
+	var b bandwidthBudget
+	if limit.Ingress > 0 {
+		b.ingress = rate.NewLimiter(rate.Limit(limit.Ingress), limit.Ingress)
+	}
+	if limit.Egress > 0 {
+		b.egress = rate.NewLimiter(rate.Limit(limit.Egress), limit.Egress)
+	}
+	return b
+}
+
+func (b bandwidthBudget) limiter(egress bool) *rate.Limiter {
+	if egress {
+		return b.egress
+	}
+	return b.ingress
+}
+
+// bandwidthLimits holds the budgets shared by all peers of a server.
+type bandwidthLimits struct {
+	global    bandwidthBudget
+	protocols map[string]bandwidthBudget
+	peer      BandwidthLimit
+}
+
+// newBandwidthLimits creates the shared budgets from the configuration. It returns
+// nil if no limits are configured.
+func newBandwidthLimits(cfg *Config) *bandwidthLimits {
+	empty := cfg.BandwidthLimit == (BandwidthLimit{}) && cfg.PeerBandwidthLimit == (BandwidthLimit{})
+	for _, limit := range cfg.ProtocolBandwidthLimits {
+		empty = empty && limit == (BandwidthLimit{})
+	}
+	if empty {
+		return nil
+	}
+	l := &bandwidthLimits{
+		global:    newBandwidthBudget(cfg.BandwidthLimit),
+		protocols: make(map[string]bandwidthBudget, len(cfg.ProtocolBandwidthLimits)),
+		peer:      cfg.PeerBandwidthLimit,
+	}
+	for name, limit := range cfg.ProtocolBandwidthLimits {
+		l.protocols[name] = newBandwidthBudget(limit)
+	}
+	return l
+}
+
+// newPeer creates the budgets of a newly connected peer.
+func (l *bandwidthLimits) newPeer() *peerBandwidth {
+	return &peerBandwidth{shared: l, peer: newBandwidthBudget(l.peer)}
+}
+
+// peerBandwidth enforces the bandwidth budgets applying to a single peer.
+//
+// Every message draws from the global, peer and protocol budget at the same time.
+// The limiters serve reservations in arrival order, there is no fair queuing
+// between peers or protocols: a peer sending more messages gets a larger share of
+// an exhausted budget.
+//
+// Ingress throttling happens when a protocol handler reads a message. Messages of
+// a peer arrive on a single connection and are handed to the protocols one by one,
+// so when a throttled protocol receives further messages, the messages of its
+// other protocols wait behind them (head-of-line blocking). Per-protocol limits
+// therefore bound ingress traffic but don't isolate the protocols of a peer.
+type peerBandwidth struct {
+	shared *bandwidthLimits
+	peer   bandwidthBudget
+}
+
+// limiters returns the limiters applying to traffic of the given protocol.
+func (b *peerBandwidth) limiters(protocol string, egress bool) []*rate.Limiter {
+	var limiters []*rate.Limiter
+	for _, lim := range []*rate.Limiter{
+		b.shared.global.limiter(egress),
+		b.peer.limiter(egress),
+		b.shared.protocols[protocol].limiter(egress),
+	} {
+		if lim != nil {
+			limiters = append(limiters, lim)
+		}
+	}
+	return limiters
+}
+
+// wait blocks until the budgets allow transferring size bytes of the given
+// protocol. It returns false if the peer was closed while waiting.
+func (b *peerBandwidth) wait(protocol string, egress bool, size uint32, closed <-chan struct{}) bool {
+	var (
+		now          = time.Now()
+		delay        time.Duration
+		reservations []*rate.Reservation
+	)
+	for _, lim := range b.limiters(protocol, egress) {
+		// Messages larger than the burst size are reserved in chunks.
+		for n := int(size); n > 0; {
+			chunk := min(n, lim.Burst())
+			r := lim.ReserveN(now, chunk)
+			reservations = append(reservations, r)
+			delay = max(delay, r.DelayFrom(now))
+			n -= chunk
+		}
+	}
+	if delay <= 0 {
+		return true
+	}
+	markThrottled(protocol, egress, size, delay)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-closed:
+		for _, r := range reservations {
+			r.Cancel()
+		}
+		return false
+	}
+}
+
+// exhausted reports whether any egress budget of the protocol is used up, i.e.
+// less than a tenth of a second worth of traffic is available.
+func (b *peerBandwidth) exhausted(protocol string) bool {
+	now := time.Now()
+	for _, lim := range b.limiters(protocol, true) {
+		if lim.TokensAt(now) < float64(lim.Limit())/10 {
+			return true
+		}
+	}
+	return false
+}
+
+// markThrottled records a message delayed by the bandwidth limits.
+func markThrottled(protocol string, egress bool, size uint32, delay time.Duration) {
+	if !metrics.Enabled() {
+		return
+	}
+	name := ingressThrottledMeterName
+	if egress {
+		name = egressThrottledMeterName
+	}
+	metrics.GetOrRegisterMeter(name, nil).Mark(int64(size))
+	metrics.GetOrRegisterMeter(name+"/"+protocol, nil).Mark(int64(size))
+	metrics.GetOrRegisterTimer(name+"/delay", nil).Update(delay)
+}
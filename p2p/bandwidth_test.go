@@ -0,0 +1,153 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestBandwidthLimitsConfig(t *testing.T) {
+	if l := newBandwidthLimits(&Config{}); l != nil {
+		t.Fatal("limits created without configuration")
+	}
+	cfg := &Config{
+		PeerBandwidthLimit:      BandwidthLimit{Egress: 1000},
+		ProtocolBandwidthLimits: map[string]BandwidthLimit{"snap": {Ingress: 2000}},
+	}
+	bw := newBandwidthLimits(cfg).newPeer()
+	if n := len(bw.limiters("eth", true)); n != 1 {
+		t.Fatalf("wrong number of eth egress limiters: %d", n)
+	}
+	if n := len(bw.limiters("eth", false)); n != 0 {
+		t.Fatalf("wrong number of eth ingress limiters: %d", n)
+	}
+	if n := len(bw.limiters("snap", false)); n != 1 {
+		t.Fatalf("wrong number of snap ingress limiters: %d", n)
+	}
+}
+
+func TestBandwidthWait(t *testing.T) {
+	const limit = 1000000
+
+	var (
+		shared = newBandwidthLimits(&Config{
+			BandwidthLimit: BandwidthLimit{Egress: limit},
+		})
+		bw1    = shared.newPeer()
+		bw2    = shared.newPeer()
+		closed = make(chan struct{})
+	)
+	// The burst allowance is available immediately.
+	start := time.Now()
+	if !bw1.wait("test", true, limit, closed) {
+		t.Fatal("wait failed")
+	}
+	if d := time.Since(start); d > 50*time.Millisecond {
+		t.Fatalf("burst was delayed by %v", d)
+	}
+	if !bw1.exhausted("test") || !bw2.exhausted("test") {
+		t.Fatal("shared budget not exhausted")
+	}
+	start = time.Now()
+	if !bw1.wait("test", false, limit, closed) {
+		t.Fatal("wait failed")
+	}
+	if d := time.Since(start); d > 50*time.Millisecond {
+		t.Fatalf("ingress delayed by %v without ingress budget", d)
+	}
+	// Further traffic of any peer has to wait for the budget to refill.
+	start = time.Now()
+	if !bw2.wait("test", true, limit/5, closed) {
+		t.Fatal("wait failed")
+	}
+	if d := time.Since(start); d < 150*time.Millisecond {
+		t.Fatalf("traffic over budget delayed by %v only", d)
+	}
+	// Messages larger than the burst size are allowed, but delayed accordingly.
+	done := make(chan bool)
+	go func() {
+		done <- bw1.wait("test", true, 3*limit, closed)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	close(closed)
+	if <-done {
+		t.Fatal("wait didn't abort when closed")
+	}
+}
+
+// A throttled message of one protocol must not hold back the messages of other
+// protocols. Further messages of the throttled protocol would, see peerBandwidth.
+func TestBandwidthIngressPerProtocol(t *testing.T) {
+	const limit = 1000
+
+	var (
+		bw = newBandwidthLimits(&Config{
+			ProtocolBandwidthLimits: map[string]BandwidthLimit{"a": {Ingress: limit}},
+		}).newPeer()
+		received = make(chan string, 2)
+		// The protocols announce every message, until the peer is closed.
+		run = func(name string) func(*Peer, MsgReadWriter) error {
+			return func(peer *Peer, rw MsgReadWriter) error {
+				for {
+					msg, err := rw.ReadMsg()
+					if err != nil {
+						return err
+					}
+					msg.Discard()
+					received <- name
+				}
+			}
+		}
+		protoA = Protocol{Name: "a", Length: 1, Run: run("a")}
+		protoB = Protocol{Name: "b", Length: 1, Run: run("b")}
+	)
+	closer, rw, _, _ := testPeerWithBandwidth([]Protocol{protoA, protoB}, bw)
+	defer closer()
+
+	// The message of protocol a exceeds its burst allowance and is delayed by
+	// about half a second.
+	start := time.Now()
+	if err := Send(rw, baseProtocolLength, bytes.Repeat([]byte{1}, limit*3/2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := Send(rw, baseProtocolLength+1, []uint{1}); err != nil {
+		t.Fatal(err)
+	}
+	next := func() string {
+		select {
+		case name := <-received:
+			return name
+		case <-time.After(5 * time.Second):
+			t.Fatal("message not received")
+			return ""
+		}
+	}
+	if name := next(); name != "b" {
+		t.Fatalf("message of protocol %s received first", name)
+	}
+	if d := time.Since(start); d > 250*time.Millisecond {
+		t.Fatalf("unthrottled protocol delayed by %v", d)
+	}
+	if name := next(); name != "a" {
+		t.Fatalf("unexpected message of protocol %s", name)
+	}
+	if d := time.Since(start); d < 250*time.Millisecond {
+		t.Fatalf("throttled protocol delayed by %v only", d)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"crypto/ecdsa"
 	"encoding"
 	"fmt"
+	"sync/atomic"
 
 	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/log"
@@ -105,6 +106,17 @@ type Config struct {
 	// for TCP and DiscAddr for the UDP discovery protocol.
 	DiscAddr string
 
+	// If QUICListenAddr is set to a non-empty UDP address, the server also
+	// accepts connections over QUIC. The QUIC port is announced in the node
+	// record and used for dialing nodes which announce it, too.
+	QUICListenAddr string `toml:",omitempty"`
+
+	// HolePunching enables NAT traversal through hole punching coordinated over
+	// discovery v5. The node relays hole punching requests of other nodes and,
+	// if QUICListenAddr is set, uses hole punching when a direct dial of a node
+	// announcing it is behind a NAT times out. It requires DiscoveryV5.
+	HolePunching bool `toml:",omitempty"`
+
 	// If set to a non-nil value, the given NAT port mapper
 	// is used to make the listening port available to the
 	// Internet.
@@ -121,10 +133,29 @@ type Config struct {
 	// whenever a message is sent to or received from a peer
 	EnableMsgEvents bool
 
+	// BandwidthLimit is the message traffic budget of all peers combined.
+	BandwidthLimit BandwidthLimit `toml:",omitempty"`
+
+	// PeerBandwidthLimit is the message traffic budget of every single peer.
+	PeerBandwidthLimit BandwidthLimit `toml:",omitempty"`
+
+	// ProtocolBandwidthLimits are the message traffic budgets of subprotocols,
+	// keyed by protocol name. Every budget is shared by all peers.
+	ProtocolBandwidthLimits map[string]BandwidthLimit `toml:",omitempty"`
+
 	// Logger is a custom logger to use with the p2p.Server.
 	Logger log.Logger `toml:"-"`
 
-	clock mclock.Clock
+	// Clock is the time source of the server, e.g. a simulated clock in tests.
+	// The system clock is used if nil.
+	Clock mclock.Clock `toml:"-"`
+
+	// PendingMessages, if set, counts the subprotocol messages which have been
+	// received but not yet processed. A message is counted from the time it is
+	// handed to the protocol handler until the handler reads the next message or
+	// returns. Network simulations use this to find out when all delivered
+	// messages have been handled.
+	PendingMessages *atomic.Int64 `toml:"-"`
 }
 
 type configMarshaling struct {
@@ -4,7 +4,9 @@ package p2p
 
 import (
 	"crypto/ecdsa"
+	"sync/atomic"
 
+	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/p2p/nat"
@@ -16,28 +18,35 @@ var _ = (*configMarshaling)(nil)
 // MarshalTOML marshals as TOML.
 func (c Config) MarshalTOML() (interface{}, error) {
 	type Config struct {
-		PrivateKey       *ecdsa.PrivateKey `toml:"-"`
-		MaxPeers         int
-		MaxPendingPeers  int `toml:",omitempty"`
-		DialRatio        int `toml:",omitempty"`
-		NoDiscovery      bool
-		DiscoveryV4      bool   `toml:",omitempty"`
-		DiscoveryV5      bool   `toml:",omitempty"`
-		Name             string `toml:"-"`
-		BootstrapNodes   []*enode.Node
-		BootstrapNodesV5 []*enode.Node `toml:",omitempty"`
-		StaticNodes      []*enode.Node
-		TrustedNodes     []*enode.Node
-		NetRestrict      *netutil.Netlist `toml:",omitempty"`
-		NodeDatabase     string           `toml:",omitempty"`
-		Protocols        []Protocol       `toml:"-" json:"-"`
-		ListenAddr       string
-		DiscAddr         string
-		NAT              nat.Interface `toml:",omitempty"`
-		Dialer           NodeDialer    `toml:"-"`
-		NoDial           bool          `toml:",omitempty"`
-		EnableMsgEvents  bool
-		Logger           log.Logger `toml:"-"`
+		PrivateKey              *ecdsa.PrivateKey `toml:"-"`
+		MaxPeers                int
+		MaxPendingPeers         int `toml:",omitempty"`
+		DialRatio               int `toml:",omitempty"`
+		NoDiscovery             bool
+		DiscoveryV4             bool   `toml:",omitempty"`
+		DiscoveryV5             bool   `toml:",omitempty"`
+		Name                    string `toml:"-"`
+		BootstrapNodes          []*enode.Node
+		BootstrapNodesV5        []*enode.Node `toml:",omitempty"`
+		StaticNodes             []*enode.Node
+		TrustedNodes            []*enode.Node
+		NetRestrict             *netutil.Netlist `toml:",omitempty"`
+		NodeDatabase            string           `toml:",omitempty"`
+		Protocols               []Protocol       `toml:"-" json:"-"`
+		ListenAddr              string
+		DiscAddr                string
+		QUICListenAddr          string        `toml:",omitempty"`
+		HolePunching            bool          `toml:",omitempty"`
+		NAT                     nat.Interface `toml:",omitempty"`
+		Dialer                  NodeDialer    `toml:"-"`
+		NoDial                  bool          `toml:",omitempty"`
+		EnableMsgEvents         bool
+		BandwidthLimit          BandwidthLimit            `toml:",omitempty"`
+		PeerBandwidthLimit      BandwidthLimit            `toml:",omitempty"`
+		ProtocolBandwidthLimits map[string]BandwidthLimit `toml:",omitempty"`
+		Logger                  log.Logger                `toml:"-"`
+		Clock                   mclock.Clock              `toml:"-"`
+		PendingMessages         *atomic.Int64             `toml:"-"`
 	}
 	var enc Config
 	enc.PrivateKey = c.PrivateKey
@@ -57,39 +66,53 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.Protocols = c.Protocols
 	enc.ListenAddr = c.ListenAddr
 	enc.DiscAddr = c.DiscAddr
+	enc.QUICListenAddr = c.QUICListenAddr
+	enc.HolePunching = c.HolePunching
 	enc.NAT = c.NAT
 	enc.Dialer = c.Dialer
 	enc.NoDial = c.NoDial
 	enc.EnableMsgEvents = c.EnableMsgEvents
+	enc.BandwidthLimit = c.BandwidthLimit
+	enc.PeerBandwidthLimit = c.PeerBandwidthLimit
+	enc.ProtocolBandwidthLimits = c.ProtocolBandwidthLimits
 	enc.Logger = c.Logger
+	enc.Clock = c.Clock
+	enc.PendingMessages = c.PendingMessages
 	return &enc, nil
 }
 
 // UnmarshalTOML unmarshals from TOML.
 func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	type Config struct {
-		PrivateKey       *ecdsa.PrivateKey `toml:"-"`
-		MaxPeers         *int
-		MaxPendingPeers  *int `toml:",omitempty"`
-		DialRatio        *int `toml:",omitempty"`
-		NoDiscovery      *bool
-		DiscoveryV4      *bool   `toml:",omitempty"`
-		DiscoveryV5      *bool   `toml:",omitempty"`
-		Name             *string `toml:"-"`
-		BootstrapNodes   []*enode.Node
-		BootstrapNodesV5 []*enode.Node `toml:",omitempty"`
-		StaticNodes      []*enode.Node
-		TrustedNodes     []*enode.Node
-		NetRestrict      *netutil.Netlist `toml:",omitempty"`
-		NodeDatabase     *string          `toml:",omitempty"`
-		Protocols        []Protocol       `toml:"-" json:"-"`
-		ListenAddr       *string
-		DiscAddr         *string
-		NAT              *configNAT `toml:",omitempty"`
-		Dialer           NodeDialer `toml:"-"`
-		NoDial           *bool      `toml:",omitempty"`
-		EnableMsgEvents  *bool
-		Logger           log.Logger `toml:"-"`
+		PrivateKey              *ecdsa.PrivateKey `toml:"-"`
+		MaxPeers                *int
+		MaxPendingPeers         *int `toml:",omitempty"`
+		DialRatio               *int `toml:",omitempty"`
+		NoDiscovery             *bool
+		DiscoveryV4             *bool   `toml:",omitempty"`
+		DiscoveryV5             *bool   `toml:",omitempty"`
+		Name                    *string `toml:"-"`
+		BootstrapNodes          []*enode.Node
+		BootstrapNodesV5        []*enode.Node `toml:",omitempty"`
+		StaticNodes             []*enode.Node
+		TrustedNodes            []*enode.Node
+		NetRestrict             *netutil.Netlist `toml:",omitempty"`
+		NodeDatabase            *string          `toml:",omitempty"`
+		Protocols               []Protocol       `toml:"-" json:"-"`
+		ListenAddr              *string
+		DiscAddr                *string
+		QUICListenAddr          *string    `toml:",omitempty"`
+		HolePunching            *bool      `toml:",omitempty"`
+		NAT                     *configNAT `toml:",omitempty"`
+		Dialer                  NodeDialer `toml:"-"`
+		NoDial                  *bool      `toml:",omitempty"`
+		EnableMsgEvents         *bool
+		BandwidthLimit          *BandwidthLimit           `toml:",omitempty"`
+		PeerBandwidthLimit      *BandwidthLimit           `toml:",omitempty"`
+		ProtocolBandwidthLimits map[string]BandwidthLimit `toml:",omitempty"`
+		Logger                  log.Logger                `toml:"-"`
+		Clock                   mclock.Clock              `toml:"-"`
+		PendingMessages         *atomic.Int64             `toml:"-"`
 	}
 	var dec Config
 	if err := unmarshal(&dec); err != nil {
@@ -146,6 +169,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.DiscAddr != nil {
 		c.DiscAddr = *dec.DiscAddr
 	}
+	if dec.QUICListenAddr != nil {
+		c.QUICListenAddr = *dec.QUICListenAddr
+	}
+	if dec.HolePunching != nil {
+		c.HolePunching = *dec.HolePunching
+	}
 	if dec.NAT != nil {
 		c.NAT = dec.NAT
 	}
@@ -158,8 +187,23 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.EnableMsgEvents != nil {
 		c.EnableMsgEvents = *dec.EnableMsgEvents
 	}
+	if dec.BandwidthLimit != nil {
+		c.BandwidthLimit = *dec.BandwidthLimit
+	}
+	if dec.PeerBandwidthLimit != nil {
+		c.PeerBandwidthLimit = *dec.PeerBandwidthLimit
+	}
+	if dec.ProtocolBandwidthLimits != nil {
+		c.ProtocolBandwidthLimits = dec.ProtocolBandwidthLimits
+	}
 	if dec.Logger != nil {
 		c.Logger = dec.Logger
 	}
+	if dec.Clock != nil {
+		c.Clock = dec.Clock
+	}
+	if dec.PendingMessages != nil {
+		c.PendingMessages = dec.PendingMessages
+	}
 	return nil
 }
@@ -84,6 +84,7 @@ var (
 	errNetRestrict      = errors.New("not contained in netrestrict list")
 	errNoPort           = errors.New("node does not provide TCP port")
 	errNoResolvedIP     = errors.New("node does not provide a resolved IP")
+	errLowReputation    = errors.New("reputation too low")
 )
 
 // dialer creates outbound connections and submits them into Server.
@@ -125,6 +126,11 @@ type dialScheduler struct {
 	static     map[enode.ID]*dialTask
 	staticPool []*dialTask
 
+	// The preferred list holds the peers connected before the last shutdown,
+	// followed by previously connected nodes with a good reputation. They are
+	// dialed once, ahead of the nodes arriving through the iterator.
+	preferred []*enode.Node
+
 	// The dial history keeps recently dialed nodes. Members of history are not dialed.
 	history      expHeap
 	historyTimer *mclock.Alarm
@@ -143,9 +149,14 @@ type dialConfig struct {
 	netRestrict    *netutil.Netlist // IP netrestrict list, disabled if nil
 	resolver       nodeResolver
 	dialer         NodeDialer
+	holePunch      NodeDialer // used when dialer fails, disabled if nil
 	log            log.Logger
 	clock          mclock.Clock
 	rand           *mrand.Rand
+
+	reputation func(enode.ID) float64 // reputation score of a node, disabled if nil
+	preferred  []*enode.Node          // nodes to dial before any discovered ones
+	previous   []*enode.Node          // peers before the last shutdown, dialed before preferred nodes
 }
 
 func (cfg dialConfig) withDefaults() dialConfig {
@@ -186,6 +197,7 @@ func newDialScheduler(config dialConfig, it enode.Iterator, setupFunc dialSetupF
 		remPeerCh:      make(chan *conn),
 		addPendingCh:   make(chan enode.ID),
 		remPendingCh:   make(chan enode.ID),
+		preferred:      dialCandidates(cfg.previous, cfg.preferred),
 	}
 	d.lastStatsLog = d.clock.Now()
 	d.ctx, d.cancel = context.WithCancel(context.Background())
@@ -260,6 +272,7 @@ loop:
 		// Launch new dials if slots are available.
 		slots := d.freeDialSlots()
 		slots -= d.startStaticDials(slots)
+		slots -= d.startPreferredDials(slots)
 		if slots > 0 {
 			nodesCh = d.nodesIn
 		} else {
@@ -270,7 +283,7 @@ loop:
 
 		select {
 		case node := <-nodesCh:
-			if err := d.checkDial(node); err != nil {
+			if err := d.checkDynDial(node); err != nil {
 				d.log.Trace("Discarding dial candidate", "id", node.ID(), "ip", node.IPAddr(), "reason", err)
 			} else {
 				d.startDial(newDialTask(node, dynDialedConn))
@@ -437,6 +450,50 @@ func (d *dialScheduler) checkDial(n *enode.Node) error {
 	return nil
 }
 
+// checkDynDial returns an error if node n should not be dialed dynamically.
+// Unlike static nodes, dynamic dial candidates are also filtered by reputation.
+func (d *dialScheduler) checkDynDial(n *enode.Node) error {
+	if err := d.checkDial(n); err != nil {
+		return err
+	}
+	if d.reputation != nil && d.reputation(n.ID()) < dialReputationThreshold {
+		return errLowReputation
+	}
+	return nil
+}
+
+// dialCandidates concatenates node lists, dropping duplicate nodes.
+func dialCandidates(lists ...[]*enode.Node) []*enode.Node {
+	var (
+		seen  = make(map[enode.ID]bool)
+		nodes []*enode.Node
+	)
+	for _, list := range lists {
+		for _, n := range list {
+			if !seen[n.ID()] {
+				seen[n.ID()] = true
+				nodes = append(nodes, n)
+			}
+		}
+	}
+	return nodes
+}
+
+// startPreferredDials starts at most n dial tasks to preferred nodes.
+func (d *dialScheduler) startPreferredDials(n int) (started int) {
+	for started < n && len(d.preferred) > 0 {
+		node := d.preferred[0]
+		d.preferred = d.preferred[1:]
+		if err := d.checkDynDial(node); err != nil {
+			d.log.Trace("Discarding preferred dial candidate", "id", node.ID(), "ip", node.IPAddr(), "reason", err)
+			continue
+		}
+		d.startDial(newDialTask(node, dynDialedConn))
+		started++
+	}
+	return started
+}
+
 // startStaticDials starts n static dial tasks.
 func (d *dialScheduler) startStaticDials(n int) (started int) {
 	for started = 0; started < n && len(d.staticPool) > 0; started++ {
@@ -648,6 +705,13 @@ func (t *dialTask) resolve(d *dialScheduler) bool {
 func (t *dialTask) dial(d *dialScheduler, dest *enode.Node) error {
 	dialMeter.Mark(1)
 	fd, err := d.dialer.Dial(d.ctx, dest)
+	if err != nil && d.holePunch != nil && d.ctx.Err() == nil && needsHolePunch(dest, err) {
+		if hfd, herr := d.holePunch.Dial(d.ctx, dest); herr == nil {
+			fd, err = hfd, nil
+		} else {
+			d.log.Trace("Hole punching failed", "id", dest.ID(), "err", herr)
+		}
+	}
 	if err != nil {
 		addr, _ := dest.TCPEndpoint()
 		d.log.Trace("Dial error", "id", dest.ID(), "addr", addr, "conn", t.flags, "err", cleanupDialErr(err))
@@ -23,6 +23,7 @@ import (
 	"math/rand"
 	"net"
 	"net/netip"
+	"os"
 	"reflect"
 	"sync"
 	"testing"
@@ -32,6 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/internal/testlog"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
 	"github.com/ethereum/go-ethereum/p2p/netutil"
 )
 
@@ -153,6 +155,89 @@ func TestDialSchedNetRestrict(t *testing.T) {
 	})
 }
 
+// This test checks that preferred nodes are dialed before discovered ones and
+// that candidates with a bad reputation are not dialed.
+func TestDialSchedReputation(t *testing.T) {
+	t.Parallel()
+
+	scores := map[enode.ID]float64{
+		uintID(0x02): dialReputationThreshold - 1,
+		uintID(0x04): dialReputationThreshold - 1,
+	}
+	config := dialConfig{
+		maxActiveDials: 2,
+		maxDialPeers:   4,
+		reputation:     func(id enode.ID) float64 { return scores[id] },
+		preferred: []*enode.Node{
+			newNode(uintID(0x01), "127.0.0.1:30303"),
+			newNode(uintID(0x02), "127.0.0.2:30303"), // not dialed because of its reputation
+			newNode(uintID(0x03), "127.0.0.3:30303"),
+		},
+	}
+	runDialTest(t, config, []dialTestRound{
+		// The preferred nodes occupy all dial slots.
+		{
+			discovered: []*enode.Node{
+				newNode(uintID(0x04), "127.0.0.4:30303"), // not dialed because of its reputation
+				newNode(uintID(0x05), "127.0.0.5:30303"),
+			},
+			wantNewDials: []*enode.Node{
+				newNode(uintID(0x01), "127.0.0.1:30303"),
+				newNode(uintID(0x03), "127.0.0.3:30303"),
+			},
+		},
+		// Discovered nodes are dialed once the preferred ones are exhausted.
+		{
+			failed: []enode.ID{
+				uintID(0x01),
+				uintID(0x03),
+			},
+			wantNewDials: []*enode.Node{
+				newNode(uintID(0x05), "127.0.0.5:30303"),
+			},
+		},
+	})
+}
+
+// This test checks that peers from before the last shutdown are dialed first.
+func TestDialSchedPreviousPeers(t *testing.T) {
+	t.Parallel()
+
+	config := dialConfig{
+		maxActiveDials: 2,
+		maxDialPeers:   4,
+		previous: []*enode.Node{
+			newNode(uintID(0x01), "127.0.0.1:30303"),
+			newNode(uintID(0x02), "127.0.0.2:30303"),
+		},
+		preferred: []*enode.Node{
+			newNode(uintID(0x02), "127.0.0.2:30303"), // duplicate of previous peer
+			newNode(uintID(0x03), "127.0.0.3:30303"),
+		},
+	}
+	runDialTest(t, config, []dialTestRound{
+		{
+			discovered: []*enode.Node{
+				newNode(uintID(0x04), "127.0.0.4:30303"),
+			},
+			wantNewDials: []*enode.Node{
+				newNode(uintID(0x01), "127.0.0.1:30303"),
+				newNode(uintID(0x02), "127.0.0.2:30303"),
+			},
+		},
+		{
+			succeeded: []enode.ID{
+				uintID(0x01),
+				uintID(0x02),
+			},
+			wantNewDials: []*enode.Node{
+				newNode(uintID(0x03), "127.0.0.3:30303"),
+				newNode(uintID(0x04), "127.0.0.4:30303"),
+			},
+		},
+	})
+}
+
 // This test checks that static dials work and obey the limits.
 func TestDialSchedStaticDial(t *testing.T) {
 	t.Parallel()
@@ -499,6 +584,55 @@ func TestDialSchedPendingInbound(t *testing.T) {
 	})
 }
 
+// This test checks that hole punching is tried when the direct dial of a node
+// behind a NAT times out.
+func TestDialSchedHolePunch(t *testing.T) {
+	t.Parallel()
+
+	var (
+		punched = make(chan enode.ID, 1)
+		setupCh = make(chan enode.ID, 1)
+		r       enr.Record
+	)
+	r.Set(enr.IPv4{127, 0, 0, 1})
+	r.Set(enr.TCP(30303))
+	r.Set(holePunchEntry(true))
+	node := enode.SignNull(&r, uintID(0x01))
+
+	config := dialConfig{
+		maxActiveDials: 1,
+		maxDialPeers:   1,
+		log:            testlog.Logger(t, log.LvlTrace),
+		dialer: dialTestFunc(func(ctx context.Context, n *enode.Node) (net.Conn, error) {
+			return nil, &net.OpError{Op: "dial", Err: os.ErrDeadlineExceeded}
+		}),
+		holePunch: dialTestFunc(func(ctx context.Context, n *enode.Node) (net.Conn, error) {
+			punched <- n.ID()
+			fd, _ := net.Pipe()
+			return fd, nil
+		}),
+	}
+	setup := func(fd net.Conn, f connFlag, n *enode.Node) error {
+		setupCh <- n.ID()
+		return nil
+	}
+	dialsched := newDialScheduler(config, newDialTestIterator(), setup)
+	defer dialsched.stop()
+	dialsched.addStatic(node)
+
+	select {
+	case id := <-setupCh:
+		if id != node.ID() {
+			t.Fatalf("wrong node set up: %v", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection not set up")
+	}
+	if len(punched) != 1 {
+		t.Fatal("hole punching not used")
+	}
+}
+
 // -------
 // Code below here is the framework for the tests above.
 
@@ -669,6 +803,13 @@ func newDialTestDialer() *dialTestDialer {
 	}
 }
 
+// dialTestFunc is a NodeDialer calling a function.
+type dialTestFunc func(ctx context.Context, n *enode.Node) (net.Conn, error)
+
+func (f dialTestFunc) Dial(ctx context.Context, n *enode.Node) (net.Conn, error) {
+	return f(ctx, n)
+}
+
 // Dial implements NodeDialer.
 func (d *dialTestDialer) Dial(ctx context.Context, n *enode.Node) (net.Conn, error) {
 	req := &dialTestReq{n: n, unblock: make(chan error, 1)}
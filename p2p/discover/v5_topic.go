@@ -0,0 +1,488 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"net/netip"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p/discover/v5wire"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/netutil"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	topicAdLifetime     = 15 * time.Minute // how long a registration stays in the topic table
+	topicQueueLimit     = 100              // max registrations per topic
+	topicSubnetLimit    = 10               // max registrations per topic from a /24 network
+	topicSubnet         = 24               // prefix length of the subnets counted by topicSubnetLimit
+	topicTableLimit     = 10000            // max registrations across all topics
+	topicTicketWindow   = 10 * time.Second // time after the wait time in which a ticket is accepted
+	topicMaxWaitTime    = 5 * time.Minute  // tickets with a longer wait time are not used
+	topicRegisterTries  = 3                // max tickets requested per registration attempt
+	topicRegistrarCount = 5                // number of nodes a topic is advertised on
+	topicRefreshTime    = 5 * time.Minute  // interval between advertisement rounds
+	topicRetryTime      = 30 * time.Second // retry interval if no registration succeeded
+	topicSeenLimit      = 1024             // number of nodes deduplicated by the topic iterator
+)
+
+var (
+	errInvalidTicket     = errors.New("invalid ticket")
+	errTicketWaitTooLong = errors.New("ticket wait time too long")
+	errTopicNotAccepted  = errors.New("topic registration not accepted")
+)
+
+// Topic identifies a service advertised in the discovery DHT. Advertisements are
+// stored on the nodes closest to the topic, which is interpreted as a node ID.
+type Topic [32]byte
+
+// NewTopic creates the topic for the given service name.
+func NewTopic(name string) Topic {
+	return Topic(crypto.Keccak256Hash([]byte(name)))
+}
+
+// topicAd is a registration in the topic table.
+type topicAd struct {
+	node    *enode.Node
+	expires mclock.AbsTime
+}
+
+// topicTable stores the topic registrations of remote nodes. Every topic has a
+// queue of registrations, ordered by their registration time.
+type topicTable struct {
+	queues map[Topic][]topicAd
+	count  int
+}
+
+func newTopicTable() *topicTable {
+	return &topicTable{queues: make(map[Topic][]topicAd)}
+}
+
+// expire removes all expired registrations.
+func (tt *topicTable) expire(now mclock.AbsTime) {
+	for topic, q := range tt.queues {
+		i := 0
+		for i < len(q) && q[i].expires <= now {
+			i++
+		}
+		if i == 0 {
+			continue
+		}
+		tt.count -= i
+		if i == len(q) {
+			delete(tt.queues, topic)
+		} else {
+			tt.queues[topic] = slices.Delete(q, 0, i)
+		}
+	}
+}
+
+// waitTime returns how long a node with the given IP has to wait before its
+// registration for the topic can be accepted. It's zero if the table has space
+// or if the node is already registered.
+func (tt *topicTable) waitTime(topic Topic, id enode.ID, ip netip.Addr, now mclock.AbsTime) time.Duration {
+	tt.expire(now)
+
+	q := tt.queues[topic]
+	if slices.ContainsFunc(q, func(ad topicAd) bool { return ad.node.ID() == id }) {
+		return 0
+	}
+	var wait time.Duration
+	if len(q) >= topicQueueLimit {
+		wait = q[0].expires.Sub(now)
+	}
+	if oldest, full := subnetFull(q, ip); full {
+		// Wait for the oldest registration of the subnet to expire, so a single
+		// network cannot occupy the queue.
+		wait = max(wait, oldest.Sub(now))
+	}
+	if tt.count >= topicTableLimit {
+		// Wait for the oldest registration of any topic to expire.
+		oldest := mclock.AbsTime(0)
+		for _, q := range tt.queues {
+			if oldest == 0 || q[0].expires < oldest {
+				oldest = q[0].expires
+			}
+		}
+		wait = max(wait, oldest.Sub(now))
+	}
+	return wait
+}
+
+// register adds a registration for the topic. Existing registrations of the node
+// are refreshed. It returns false if the table has no space for the registration.
+func (tt *topicTable) register(topic Topic, n *enode.Node, now mclock.AbsTime) bool {
+	tt.expire(now)
+
+	q := tt.queues[topic]
+	if i := slices.IndexFunc(q, func(ad topicAd) bool { return ad.node.ID() == n.ID() }); i >= 0 {
+		q = slices.Delete(q, i, i+1)
+		tt.count--
+	} else if len(q) >= topicQueueLimit || tt.count >= topicTableLimit {
+		return false
+	} else if _, full := subnetFull(q, n.IPAddr()); full {
+		return false
+	}
+	tt.queues[topic] = append(q, topicAd{node: n, expires: now.Add(topicAdLifetime)})
+	tt.count++
+	return true
+}
+
+// subnetFull reports whether the queue holds topicSubnetLimit registrations from
+// the subnet of ip, and returns the expiry time of the oldest one. Like the node
+// table, LAN addresses are not limited.
+func subnetFull(q []topicAd, ip netip.Addr) (oldest mclock.AbsTime, full bool) {
+	if netutil.AddrIsLAN(ip) {
+		return 0, false
+	}
+	subnet, err := ip.Prefix(topicSubnet)
+	if err != nil {
+		return 0, false
+	}
+	count := 0
+	for _, ad := range q {
+		if subnet.Contains(ad.node.IPAddr()) {
+			if count == 0 {
+				oldest = ad.expires
+			}
+			count++
+		}
+	}
+	return oldest, count >= topicSubnetLimit
+}
+
+// nodes returns the registered nodes of the topic, most recent registrations first.
+func (tt *topicTable) nodes(topic Topic, now mclock.AbsTime) []*enode.Node {
+	tt.expire(now)
+
+	q := tt.queues[topic]
+	nodes := make([]*enode.Node, 0, len(q))
+	for i := len(q) - 1; i >= 0; i-- {
+		nodes = append(nodes, q[i].node)
+	}
+	return nodes
+}
+
+// topicTicket is the content of a ticket. Tickets are opaque to the requester and
+// authenticated with a local secret, so no state needs to be kept for them.
+type topicTicket struct {
+	Node   enode.ID
+	IP     []byte
+	Topic  Topic
+	Issued uint64 // mclock.AbsTime
+	Wait   uint64 // time.Duration
+}
+
+type topicSystem struct {
+	transport *UDPv5
+	key       [32]byte // ticket authentication key
+
+	mutex sync.Mutex
+	table *topicTable
+	ads   map[Topic]context.CancelFunc // topics advertised by the local node
+}
+
+func newTopicSystem(transport *UDPv5) *topicSystem {
+	ts := &topicSystem{
+		transport: transport,
+		table:     newTopicTable(),
+		ads:       make(map[Topic]context.CancelFunc),
+	}
+	crand.Read(ts.key[:])
+	return ts
+}
+
+// issueTicket creates an authenticated ticket.
+func (ts *topicSystem) issueTicket(tk *topicTicket) []byte {
+	enc, _ := rlp.EncodeToBytes(tk)
+	mac := hmac.New(sha256.New, ts.key[:])
+	mac.Write(enc)
+	return mac.Sum(enc)
+}
+
+// checkTicket verifies a ticket presented by the given node for the topic.
+func (ts *topicSystem) checkTicket(ticket []byte, id enode.ID, addr netip.Addr, topic Topic, now mclock.AbsTime) error {
+	if len(ticket) < sha256.Size {
+		return errInvalidTicket
+	}
+	enc, sum := ticket[:len(ticket)-sha256.Size], ticket[len(ticket)-sha256.Size:]
+	mac := hmac.New(sha256.New, ts.key[:])
+	mac.Write(enc)
+	if !hmac.Equal(mac.Sum(nil), sum) {
+		return errInvalidTicket
+	}
+	var tk topicTicket
+	if err := rlp.DecodeBytes(enc, &tk); err != nil {
+		return errInvalidTicket
+	}
+	if tk.Node != id || !bytes.Equal(tk.IP, addr.AsSlice()) || tk.Topic != topic {
+		return errors.New("ticket issued for different registration")
+	}
+	mature := mclock.AbsTime(tk.Issued).Add(time.Duration(tk.Wait))
+	if now < mature {
+		return errors.New("ticket used before wait time")
+	}
+	if now > mature.Add(topicTicketWindow) {
+		return errors.New("ticket expired")
+	}
+	return nil
+}
+
+// handleRequestTicket issues a ticket for the requested topic.
+func (ts *topicSystem) handleRequestTicket(p *v5wire.RequestTicket, fromID enode.ID, fromAddr netip.AddrPort) {
+	now := ts.transport.clock.Now()
+	ts.mutex.Lock()
+	wait := ts.table.waitTime(p.Topic, fromID, fromAddr.Addr().Unmap(), now)
+	ts.mutex.Unlock()
+
+	ticket := ts.issueTicket(&topicTicket{
+		Node:   fromID,
+		IP:     fromAddr.Addr().AsSlice(),
+		Topic:  p.Topic,
+		Issued: uint64(now),
+		Wait:   uint64(wait),
+	})
+	ts.transport.sendResponse(fromID, fromAddr, &v5wire.Ticket{
+		ReqID:    p.ReqID,
+		Ticket:   ticket,
+		WaitTime: uint((wait + time.Second - 1) / time.Second),
+	})
+}
+
+// handleRegtopic adds the sender to the topic table if its ticket is valid.
+func (ts *topicSystem) handleRegtopic(p *v5wire.Regtopic, fromID enode.ID, fromAddr netip.AddrPort) {
+	resp := &v5wire.Regconfirmation{ReqID: p.ReqID}
+	defer ts.transport.sendResponse(fromID, fromAddr, resp)
+
+	now := ts.transport.clock.Now()
+	if err := ts.checkTicket(p.Ticket, fromID, fromAddr.Addr(), p.Topic, now); err != nil {
+		ts.transport.log.Debug("Rejected topic registration", "id", fromID, "addr", fromAddr, "err", err)
+		return
+	}
+	if p.ENR == nil {
+		return
+	}
+	n, err := enode.New(ts.transport.validSchemes, p.ENR)
+	if err != nil || n.ID() != fromID {
+		ts.transport.log.Debug("Invalid record in "+p.Name(), "id", fromID, "addr", fromAddr, "err", err)
+		return
+	}
+	// The subnet limits of the table apply to the record's IP, so it must be the
+	// address the registration is sent from.
+	if n.IPAddr() != fromAddr.Addr().Unmap() {
+		ts.transport.log.Debug("Rejected topic registration", "id", fromID, "addr", fromAddr, "err", "record IP mismatch", "ip", n.IPAddr())
+		return
+	}
+	ts.mutex.Lock()
+	resp.Registered = ts.table.register(p.Topic, n, now)
+	ts.mutex.Unlock()
+}
+
+// handleTopicQuery returns the registered nodes of a topic to the requester.
+func (ts *topicSystem) handleTopicQuery(p *v5wire.TopicQuery, fromID enode.ID, fromAddr netip.AddrPort) {
+	ts.mutex.Lock()
+	registered := ts.table.nodes(p.Topic, ts.transport.clock.Now())
+	ts.mutex.Unlock()
+
+	var nodes []*enode.Node
+	for _, n := range registered {
+		if netutil.CheckRelayAddr(fromAddr.Addr(), n.IPAddr()) != nil {
+			continue
+		}
+		if nodes = append(nodes, n); len(nodes) >= findnodeResultLimit {
+			break
+		}
+	}
+	for _, resp := range packNodes(p.ReqID, nodes) {
+		ts.transport.sendResponse(fromID, fromAddr, resp)
+	}
+}
+
+// start begins advertising the topic.
+func (ts *topicSystem) start(topic Topic) {
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+
+	if _, ok := ts.ads[topic]; ok || ts.transport.closeCtx.Err() != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(ts.transport.closeCtx)
+	ts.ads[topic] = cancel
+	ts.transport.wg.Add(1)
+	go ts.advertise(ctx, topic)
+}
+
+// stop ends advertising the topic.
+func (ts *topicSystem) stop(topic Topic) {
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+
+	if cancel, ok := ts.ads[topic]; ok {
+		cancel()
+		delete(ts.ads, topic)
+	}
+}
+
+// advertise registers the local node for the topic on the nodes closest to the
+// topic, renewing the registrations before they expire.
+func (ts *topicSystem) advertise(ctx context.Context, topic Topic) {
+	defer ts.transport.wg.Done()
+
+	// Wait for the table to be initialized, otherwise the first lookup finds nothing.
+	select {
+	case <-ts.transport.tab.initDone:
+	case <-ctx.Done():
+		return
+	}
+	for {
+		registrars := ts.transport.newLookup(ctx, enode.ID(topic)).run()
+		if len(registrars) > topicRegistrarCount {
+			registrars = registrars[:topicRegistrarCount]
+		}
+		var (
+			wg         sync.WaitGroup
+			registered = make(chan struct{}, len(registrars))
+		)
+		for _, n := range registrars {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := ts.register(ctx, n, topic); err != nil {
+					ts.transport.log.Debug("Topic registration failed", "id", n.ID(), "err", err)
+					return
+				}
+				registered <- struct{}{}
+			}()
+		}
+		wg.Wait()
+
+		wait := topicRefreshTime
+		if len(registered) == 0 {
+			wait = topicRetryTime
+		}
+		timer := ts.transport.clock.NewTimer(wait)
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// register obtains a ticket from the given node and uses it to register the topic.
+func (ts *topicSystem) register(ctx context.Context, n *enode.Node, topic Topic) error {
+	for i := 0; i < topicRegisterTries; i++ {
+		ticket, err := ts.transport.requestTicket(n, topic)
+		if err != nil {
+			return err
+		}
+		wait := time.Duration(ticket.WaitTime) * time.Second
+		if wait > topicMaxWaitTime {
+			return errTicketWaitTooLong
+		}
+		if wait > 0 {
+			timer := ts.transport.clock.NewTimer(wait)
+			select {
+			case <-timer.C():
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			}
+		}
+		ok, err := ts.transport.regtopic(n, topic, ticket.Ticket)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+	}
+	return errTopicNotAccepted
+}
+
+// topicIterator yields the nodes registered for a topic. It walks the DHT towards
+// the topic and queries the registered nodes on every node it encounters.
+type topicIterator struct {
+	transport  *UDPv5
+	topic      Topic
+	registrars *lookupIterator
+	buffer     []*enode.Node
+	seen       map[enode.ID]struct{}
+}
+
+func newTopicIterator(t *UDPv5, topic Topic) *topicIterator {
+	return &topicIterator{
+		transport: t,
+		topic:     topic,
+		registrars: newLookupIterator(t.closeCtx, func(ctx context.Context) *lookup {
+			return t.newLookup(ctx, enode.ID(topic))
+		}),
+		seen: make(map[enode.ID]struct{}),
+	}
+}
+
+// Node returns the current node.
+func (it *topicIterator) Node() *enode.Node {
+	if len(it.buffer) == 0 {
+		return nil
+	}
+	return it.buffer[0]
+}
+
+// Next moves to the next node.
+func (it *topicIterator) Next() bool {
+	if len(it.buffer) > 0 {
+		it.buffer = it.buffer[1:]
+	}
+	for len(it.buffer) == 0 {
+		if !it.registrars.Next() {
+			return false
+		}
+		nodes, err := it.transport.TopicQuery(it.registrars.Node(), it.topic)
+		if err != nil {
+			continue
+		}
+		if len(it.seen) >= topicSeenLimit {
+			clear(it.seen)
+		}
+		for _, n := range nodes {
+			if _, ok := it.seen[n.ID()]; ok || n.ID() == it.transport.Self().ID() {
+				continue
+			}
+			it.seen[n.ID()] = struct{}{}
+			it.buffer = append(it.buffer, n)
+		}
+	}
+	return true
+}
+
+// Close ends the iterator.
+func (it *topicIterator) Close() {
+	it.registrars.Close()
+}
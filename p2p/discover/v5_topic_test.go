@@ -0,0 +1,270 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"bytes"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/p2p/discover/v5wire"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+func TestTopicTable(t *testing.T) {
+	var (
+		tab   = newTopicTable()
+		topic = NewTopic("test")
+		other = NewTopic("other")
+		nodes = nodesAtDistance(enode.ID{}, 256, topicQueueLimit+1)
+		now   mclock.AbsTime
+	)
+	// Fill the queue of the topic, one registration per second.
+	for _, n := range nodes[:topicQueueLimit] {
+		if wait := tab.waitTime(topic, n.ID(), n.IPAddr(), now); wait != 0 {
+			t.Fatalf("unexpected wait time %v with space in queue", wait)
+		}
+		if !tab.register(topic, n, now) {
+			t.Fatal("registration rejected with space in queue")
+		}
+		now = now.Add(time.Second)
+	}
+	// The queue is full, new nodes have to wait for the oldest registration.
+	last := nodes[topicQueueLimit]
+	if wait, want := tab.waitTime(topic, last.ID(), last.IPAddr(), now), topicAdLifetime-topicQueueLimit*time.Second; wait != want {
+		t.Fatalf("wrong wait time %v, want %v", wait, want)
+	}
+	if tab.register(topic, last, now) {
+		t.Fatal("registration accepted with full queue")
+	}
+	// Registered nodes can refresh, other topics are not affected.
+	if wait := tab.waitTime(topic, nodes[0].ID(), nodes[0].IPAddr(), now); wait != 0 {
+		t.Fatalf("unexpected wait time %v for registered node", wait)
+	}
+	if !tab.register(topic, nodes[0], now) {
+		t.Fatal("refresh rejected")
+	}
+	if !tab.register(other, last, now) {
+		t.Fatal("registration for other topic rejected")
+	}
+	if res := tab.nodes(topic, now); len(res) != topicQueueLimit || res[0].ID() != nodes[0].ID() {
+		t.Fatalf("wrong topic nodes after refresh: %d nodes, first %v", len(res), res[0].ID())
+	}
+	// Registrations expire after their lifetime.
+	now = now.Add(topicAdLifetime - time.Second)
+	if res := tab.nodes(topic, now); len(res) != 1 || res[0].ID() != nodes[0].ID() {
+		t.Fatalf("wrong topic nodes after expiry: %v", res)
+	}
+	now = now.Add(time.Second)
+	if res := tab.nodes(topic, now); len(res) != 0 {
+		t.Fatalf("wrong topic nodes after expiry: %v", res)
+	}
+	if tab.count != 0 || len(tab.queues) != 0 {
+		t.Fatalf("table not empty: count %d, %d queues", tab.count, len(tab.queues))
+	}
+}
+
+// This test checks that a single network cannot fill the queue of a topic.
+func TestTopicTableSubnetLimit(t *testing.T) {
+	var (
+		tab   = newTopicTable()
+		topic = NewTopic("test")
+		now   mclock.AbsTime
+	)
+	for i := 0; i < topicSubnetLimit; i++ {
+		n := nodeAtDistance(enode.ID{}, 256, net.IP{1, 2, 3, byte(i)})
+		if wait := tab.waitTime(topic, n.ID(), n.IPAddr(), now); wait != 0 {
+			t.Fatalf("unexpected wait time %v below subnet limit", wait)
+		}
+		if !tab.register(topic, n, now) {
+			t.Fatal("registration rejected below subnet limit")
+		}
+		now = now.Add(time.Second)
+	}
+	// Further nodes of the subnet wait for its oldest registration.
+	n := nodeAtDistance(enode.ID{}, 256, net.IP{1, 2, 3, 200})
+	if wait, want := tab.waitTime(topic, n.ID(), n.IPAddr(), now), topicAdLifetime-topicSubnetLimit*time.Second; wait != want {
+		t.Fatalf("wrong wait time %v, want %v", wait, want)
+	}
+	if tab.register(topic, n, now) {
+		t.Fatal("registration accepted above subnet limit")
+	}
+	// Other subnets and LAN addresses are not affected.
+	for _, ip := range []net.IP{{1, 2, 4, 1}, {10, 0, 0, 1}} {
+		n := nodeAtDistance(enode.ID{}, 256, ip)
+		if wait := tab.waitTime(topic, n.ID(), n.IPAddr(), now); wait != 0 {
+			t.Fatalf("unexpected wait time %v for %v", wait, ip)
+		}
+		if !tab.register(topic, n, now) {
+			t.Fatalf("registration rejected for %v", ip)
+		}
+	}
+}
+
+// This test checks that incoming topic registrations and queries are handled correctly.
+func TestUDPv5_topicHandling(t *testing.T) {
+	t.Parallel()
+	test := newUDPV5Test(t)
+	defer test.close()
+
+	var (
+		topic  = NewTopic("test")
+		remote = test.getNode(test.remotekey, test.remoteaddr).Node()
+		ticket []byte
+	)
+	test.packetIn(&v5wire.RequestTicket{ReqID: []byte{1}, Topic: topic})
+	test.waitPacketOut(func(p *v5wire.Ticket, addr netip.AddrPort, _ v5wire.Nonce) {
+		if !bytes.Equal(p.ReqID, []byte{1}) {
+			t.Error("wrong request ID in response:", p.ReqID)
+		}
+		if p.WaitTime != 0 {
+			t.Error("unexpected wait time:", p.WaitTime)
+		}
+		ticket = p.Ticket
+	})
+
+	// Tickets are bound to the topic and can't be forged.
+	test.packetIn(&v5wire.Regtopic{ReqID: []byte{2}, Topic: NewTopic("other"), ENR: remote.Record(), Ticket: ticket})
+	test.waitPacketOut(func(p *v5wire.Regconfirmation, addr netip.AddrPort, _ v5wire.Nonce) {
+		if p.Registered {
+			t.Error("registration accepted for wrong topic")
+		}
+	})
+	forged := bytes.Clone(ticket)
+	forged[0]++
+	test.packetIn(&v5wire.Regtopic{ReqID: []byte{3}, Topic: topic, ENR: remote.Record(), Ticket: forged})
+	test.waitPacketOut(func(p *v5wire.Regconfirmation, addr netip.AddrPort, _ v5wire.Nonce) {
+		if p.Registered {
+			t.Error("registration accepted with forged ticket")
+		}
+	})
+
+	// The record must contain the address of the sender.
+	key := newkey()
+	spoofed := test.getNode(key, netip.MustParseAddrPort("10.0.2.1:30303")).Node()
+	test.packetInFrom(key, test.remoteaddr, &v5wire.RequestTicket{ReqID: []byte{7}, Topic: topic})
+	var spoofedTicket []byte
+	test.waitPacketOut(func(p *v5wire.Ticket, addr netip.AddrPort, _ v5wire.Nonce) {
+		spoofedTicket = p.Ticket
+	})
+	test.packetInFrom(key, test.remoteaddr, &v5wire.Regtopic{ReqID: []byte{8}, Topic: topic, ENR: spoofed.Record(), Ticket: spoofedTicket})
+	test.waitPacketOut(func(p *v5wire.Regconfirmation, addr netip.AddrPort, _ v5wire.Nonce) {
+		if p.Registered {
+			t.Error("registration accepted with record of other address")
+		}
+	})
+
+	// Valid registration.
+	test.packetIn(&v5wire.Regtopic{ReqID: []byte{4}, Topic: topic, ENR: remote.Record(), Ticket: ticket})
+	test.waitPacketOut(func(p *v5wire.Regconfirmation, addr netip.AddrPort, _ v5wire.Nonce) {
+		if !bytes.Equal(p.ReqID, []byte{4}) {
+			t.Error("wrong request ID in response:", p.ReqID)
+		}
+		if !p.Registered {
+			t.Error("valid registration rejected")
+		}
+	})
+
+	// The registered node is returned by TOPICQUERY.
+	test.packetIn(&v5wire.TopicQuery{ReqID: []byte{5}, Topic: topic})
+	test.expectNodes([]byte{5}, 1, []*enode.Node{remote})
+	test.packetIn(&v5wire.TopicQuery{ReqID: []byte{6}, Topic: NewTopic("other")})
+	test.expectNodes([]byte{6}, 1, nil)
+}
+
+// This test checks that outgoing topic registrations work.
+func TestUDPv5_topicRegister(t *testing.T) {
+	t.Parallel()
+	test := newUDPV5Test(t)
+	defer test.close()
+
+	var (
+		topic  = NewTopic("test")
+		remote = test.getNode(test.remotekey, test.remoteaddr).Node()
+		done   = make(chan error, 1)
+	)
+	go func() {
+		done <- test.udp.topics.register(test.udp.closeCtx, remote, topic)
+	}()
+	test.waitPacketOut(func(p *v5wire.RequestTicket, addr netip.AddrPort, _ v5wire.Nonce) {
+		if p.Topic != topic {
+			t.Errorf("wrong topic in ticket request: %x", p.Topic)
+		}
+		test.packetIn(&v5wire.Ticket{ReqID: p.ReqID, Ticket: []byte("ticket")})
+	})
+	test.waitPacketOut(func(p *v5wire.Regtopic, addr netip.AddrPort, _ v5wire.Nonce) {
+		if string(p.Ticket) != "ticket" {
+			t.Errorf("wrong ticket in registration: %q", p.Ticket)
+		}
+		n, err := enode.New(enode.ValidSchemesForTesting, p.ENR)
+		if err != nil || n.ID() != test.udp.Self().ID() {
+			t.Errorf("wrong record in registration: %v", err)
+		}
+		test.packetIn(&v5wire.Regconfirmation{ReqID: p.ReqID, Registered: true})
+	})
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	// Tickets with an excessive wait time are not used.
+	go func() {
+		done <- test.udp.topics.register(test.udp.closeCtx, remote, topic)
+	}()
+	test.waitPacketOut(func(p *v5wire.RequestTicket, addr netip.AddrPort, _ v5wire.Nonce) {
+		wait := uint((topicMaxWaitTime + time.Second) / time.Second)
+		test.packetIn(&v5wire.Ticket{ReqID: p.ReqID, Ticket: []byte("ticket"), WaitTime: wait})
+	})
+	if err := <-done; err != errTicketWaitTooLong {
+		t.Fatalf("want errTicketWaitTooLong, got %v", err)
+	}
+}
+
+// Real sockets, real crypto: this test checks that topic advertisements can be found.
+func TestUDPv5_topicE2E(t *testing.T) {
+	t.Parallel()
+
+	const N = 5
+	var nodes []*UDPv5
+	for i := 0; i < N; i++ {
+		var cfg Config
+		if len(nodes) > 0 {
+			cfg.Bootnodes = []*enode.Node{nodes[0].Self()}
+		}
+		node := startLocalhostV5(t, cfg)
+		nodes = append(nodes, node)
+		defer node.Close()
+	}
+	topic := NewTopic("test")
+	nodes[1].RegisterTopic(topic)
+	defer nodes[1].UnregisterTopic(topic)
+
+	it := nodes[N-1].TopicNodes(topic)
+	defer it.Close()
+	timeout := time.AfterFunc(30*time.Second, it.Close)
+	defer timeout.Stop()
+
+	for it.Next() {
+		if it.Node().ID() == nodes[1].Self().ID() {
+			return
+		}
+		t.Fatalf("unexpected node for topic: %v", it.Node().ID())
+	}
+	t.Fatal("advertised node not found")
+}
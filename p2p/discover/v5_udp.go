@@ -89,6 +89,9 @@ type UDPv5 struct {
 	// talkreq handler registry
 	talk *talkSystem
 
+	// topic table and advertisements
+	topics *topicSystem
+
 	// channels into dispatch
 	packetInCh    chan ReadPacket
 	readNextCh    chan struct{}
@@ -188,6 +191,7 @@ func newUDPv5(conn UDPConn, ln *enode.LocalNode, cfg Config) (*UDPv5, error) {
 		cancelCloseCtx: cancelCloseCtx,
 	}
 	t.talk = newTalkSystem(t)
+	t.topics = newTopicSystem(t)
 	tab, err := newTable(t, t.db, cfg)
 	if err != nil {
 		return nil, err
@@ -326,6 +330,55 @@ func (t *UDPv5) TalkRequestToID(id enode.ID, addr netip.AddrPort, protocol strin
 	}
 }
 
+// RegisterTopic starts advertising the local node for the given topic. The node is
+// registered on the nodes closest to the topic and re-registered periodically until
+// UnregisterTopic is called or the transport is closed.
+func (t *UDPv5) RegisterTopic(topic Topic) {
+	t.topics.start(topic)
+}
+
+// UnregisterTopic stops advertising the local node for the given topic. Existing
+// registrations expire on their own.
+func (t *UDPv5) UnregisterTopic(topic Topic) {
+	t.topics.stop(topic)
+}
+
+// TopicQuery calls TOPICQUERY on a node and waits for responses.
+func (t *UDPv5) TopicQuery(n *enode.Node, topic Topic) ([]*enode.Node, error) {
+	resp := t.callToNode(n, v5wire.NodesMsg, &v5wire.TopicQuery{Topic: topic})
+	return t.waitForNodes(resp, nil)
+}
+
+// TopicNodes returns an iterator that finds nodes registered for the given topic.
+func (t *UDPv5) TopicNodes(topic Topic) enode.Iterator {
+	return newTopicIterator(t, topic)
+}
+
+// requestTicket calls REQUESTTICKET on a node and waits for a TICKET response.
+func (t *UDPv5) requestTicket(n *enode.Node, topic Topic) (*v5wire.Ticket, error) {
+	resp := t.callToNode(n, v5wire.TicketMsg, &v5wire.RequestTicket{Topic: topic})
+	defer t.callDone(resp)
+	select {
+	case respMsg := <-resp.ch:
+		return respMsg.(*v5wire.Ticket), nil
+	case err := <-resp.err:
+		return nil, err
+	}
+}
+
+// regtopic calls REGTOPIC on a node and waits for a REGCONFIRMATION response.
+func (t *UDPv5) regtopic(n *enode.Node, topic Topic, ticket []byte) (bool, error) {
+	req := &v5wire.Regtopic{Topic: topic, ENR: t.Self().Record(), Ticket: ticket}
+	resp := t.callToNode(n, v5wire.RegconfirmationMsg, req)
+	defer t.callDone(resp)
+	select {
+	case respMsg := <-resp.ch:
+		return respMsg.(*v5wire.Regconfirmation).Registered, nil
+	case err := <-resp.err:
+		return false, err
+	}
+}
+
 // RandomNodes returns an iterator that finds random nodes in the DHT.
 func (t *UDPv5) RandomNodes() enode.Iterator {
 	return newLookupIterator(t.closeCtx, t.newRandomLookup)
@@ -825,6 +878,16 @@ func (t *UDPv5) handle(p v5wire.Packet, fromID enode.ID, fromAddr netip.AddrPort
 		t.talk.handleRequest(fromID, fromAddr, p)
 	case *v5wire.TalkResponse:
 		t.handleCallResponse(fromID, fromAddr, p)
+	case *v5wire.RequestTicket:
+		t.topics.handleRequestTicket(p, fromID, fromAddr)
+	case *v5wire.Ticket:
+		t.handleCallResponse(fromID, fromAddr, p)
+	case *v5wire.Regtopic:
+		t.topics.handleRegtopic(p, fromID, fromAddr)
+	case *v5wire.Regconfirmation:
+		t.handleCallResponse(fromID, fromAddr, p)
+	case *v5wire.TopicQuery:
+		t.topics.handleTopicQuery(p, fromID, fromAddr)
 	}
 }
 
@@ -237,6 +237,16 @@ func (n *Node) QUICEndpoint() (netip.AddrPort, bool) {
 	return netip.AddrPortFrom(n.ip, quic), true
 }
 
+// DevP2PQUICEndpoint returns the announced endpoint of the devp2p QUIC transport.
+func (n *Node) DevP2PQUICEndpoint() (netip.AddrPort, bool) {
+	var port uint16
+	n.Load((*enr.DevP2PQUIC)(&port))
+	if !n.ip.IsValid() || n.ip.IsUnspecified() || port == 0 {
+		return netip.AddrPort{}, false
+	}
+	return netip.AddrPortFrom(n.ip, port), true
+}
+
 // Pubkey returns the secp256k1 public key of the node, if present.
 func (n *Node) Pubkey() *ecdsa.PublicKey {
 	var key ecdsa.PublicKey
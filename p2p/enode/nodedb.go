@@ -18,11 +18,14 @@ package enode
 
 import (
 	"bytes"
+	"cmp"
 	"crypto/rand"
 	"encoding/binary"
 	"fmt"
+	"math"
 	"net/netip"
 	"os"
+	"slices"
 	"sync"
 	"time"
 
@@ -41,6 +44,8 @@ const (
 	dbVersionKey   = "version" // Version of the database to flush if changes
 	dbNodePrefix   = "n:"      // Identifier to prefix node entries with
 	dbLocalPrefix  = "local:"
+	dbRepPrefix    = "rep:"  // Identifier to prefix peer reputation entries with
+	dbPeerPrefix   = "peer:" // Identifier to prefix peer set entries with
 	dbDiscoverRoot = "v4"
 	dbDiscv5Root   = "v5"
 
@@ -57,8 +62,9 @@ const (
 )
 
 const (
-	dbNodeExpiration = 24 * time.Hour // Time after which an unseen node should be dropped.
-	dbCleanupCycle   = time.Hour      // Time period for running the expiration task.
+	dbNodeExpiration = 24 * time.Hour      // Time after which an unseen node should be dropped.
+	dbRepExpiration  = 30 * 24 * time.Hour // Time after which an unchanged reputation should be dropped.
+	dbCleanupCycle   = time.Hour           // Time period for running the expiration task.
 	dbVersion        = 9
 )
 
@@ -315,6 +321,7 @@ func (db *DB) expirer() {
 		select {
 		case <-tick.C:
 			db.expireNodes()
+			db.expireReputations()
 		case <-db.quit:
 			return
 		}
@@ -427,6 +434,161 @@ func (db *DB) UpdateFindFailsV5(id ID, ip netip.Addr, fails int) error {
 	return db.storeInt64(v5Key(id, ip, dbNodeFindFails), int64(fails))
 }
 
+// Reputation is the persisted reputation of a remote node.
+type Reputation struct {
+	Score   float64   // Reputation score at the time of the last update
+	Updated time.Time // Time of the last update
+}
+
+// repKey returns the database key for the reputation of a node.
+func repKey(id ID) []byte {
+	return append([]byte(dbRepPrefix), id[:]...)
+}
+
+// encodeReputation encodes a reputation into its database representation.
+func encodeReputation(rep Reputation) []byte {
+	blob := binary.BigEndian.AppendUint64(nil, math.Float64bits(rep.Score))
+	return binary.AppendVarint(blob, rep.Updated.Unix())
+}
+
+// decodeReputation decodes a reputation from its database representation.
+func decodeReputation(blob []byte) (Reputation, bool) {
+	if len(blob) < 8 {
+		return Reputation{}, false
+	}
+	updated, read := binary.Varint(blob[8:])
+	if read <= 0 {
+		return Reputation{}, false
+	}
+	return Reputation{
+		Score:   math.Float64frombits(binary.BigEndian.Uint64(blob)),
+		Updated: time.Unix(updated, 0),
+	}, true
+}
+
+// Reputation retrieves the stored reputation of a node. The zero value is
+// returned if nothing is stored.
+func (db *DB) Reputation(id ID) Reputation {
+	blob, err := db.lvl.Get(repKey(id), nil)
+	if err != nil {
+		return Reputation{}
+	}
+	rep, _ := decodeReputation(blob)
+	return rep
+}
+
+// UpdateReputation stores the reputation of a node.
+func (db *DB) UpdateReputation(id ID, rep Reputation) error {
+	return db.lvl.Put(repKey(id), encodeReputation(rep), nil)
+}
+
+// Reputations retrieves the stored reputations of all nodes.
+func (db *DB) Reputations() map[ID]Reputation {
+	it := db.lvl.NewIterator(util.BytesPrefix([]byte(dbRepPrefix)), nil)
+	defer it.Release()
+
+	reps := make(map[ID]Reputation)
+	for it.Next() {
+		key := it.Key()[len(dbRepPrefix):]
+		if len(key) != len(ID{}) {
+			continue
+		}
+		if rep, ok := decodeReputation(it.Value()); ok {
+			reps[ID(key)] = rep
+		}
+	}
+	return reps
+}
+
+// expireReputations deletes all reputations that have not been updated for
+// some time.
+func (db *DB) expireReputations() {
+	it := db.lvl.NewIterator(util.BytesPrefix([]byte(dbRepPrefix)), nil)
+	defer it.Release()
+
+	threshold := time.Now().Add(-dbRepExpiration)
+	for it.Next() {
+		rep, ok := decodeReputation(it.Value())
+		if !ok || rep.Updated.Before(threshold) {
+			db.lvl.Delete(it.Key(), nil)
+		}
+	}
+}
+
+// PeerEntry is a member of the peer set, i.e. a peer that was connected when
+// the peer set was stored.
+type PeerEntry struct {
+	Node     *Node
+	Caps     []string      // Negotiated capabilities, e.g. "eth/68"
+	Duration time.Duration // Time the peer was connected for
+	Stored   time.Time     // Time the peer set was stored
+}
+
+// peerEntryRLP is the database representation of a PeerEntry.
+type peerEntryRLP struct {
+	Record   *enr.Record
+	Caps     []string
+	Duration uint64 // seconds
+	Stored   uint64 // unix time
+}
+
+// peerKey returns the database key for the peer set entry of a node.
+func peerKey(id ID) []byte {
+	return append([]byte(dbPeerPrefix), id[:]...)
+}
+
+// StorePeerSet replaces the stored peer set.
+func (db *DB) StorePeerSet(peers []PeerEntry) error {
+	batch := new(leveldb.Batch)
+	it := db.lvl.NewIterator(util.BytesPrefix([]byte(dbPeerPrefix)), nil)
+	for it.Next() {
+		batch.Delete(it.Key())
+	}
+	it.Release()
+
+	for _, p := range peers {
+		blob, err := rlp.EncodeToBytes(&peerEntryRLP{
+			Record:   &p.Node.r,
+			Caps:     p.Caps,
+			Duration: uint64(p.Duration / time.Second),
+			Stored:   uint64(p.Stored.Unix()),
+		})
+		if err != nil {
+			return err
+		}
+		batch.Put(peerKey(p.Node.ID()), blob)
+	}
+	return db.lvl.Write(batch, nil)
+}
+
+// PeerSet retrieves the stored peer set, ordered by descending connection duration.
+func (db *DB) PeerSet() []PeerEntry {
+	it := db.lvl.NewIterator(util.BytesPrefix([]byte(dbPeerPrefix)), nil)
+	defer it.Release()
+
+	var peers []PeerEntry
+	for it.Next() {
+		id := it.Key()[len(dbPeerPrefix):]
+		if len(id) != len(ID{}) {
+			continue
+		}
+		var enc peerEntryRLP
+		if err := rlp.DecodeBytes(it.Value(), &enc); err != nil {
+			continue
+		}
+		peers = append(peers, PeerEntry{
+			Node:     newNodeWithID(enc.Record, ID(id)),
+			Caps:     enc.Caps,
+			Duration: time.Duration(enc.Duration) * time.Second,
+			Stored:   time.Unix(int64(enc.Stored), 0),
+		})
+	}
+	slices.SortStableFunc(peers, func(a, b PeerEntry) int {
+		return cmp.Compare(b.Duration, a.Duration)
+	})
+	return peers
+}
+
 // localSeq retrieves the local record sequence counter, defaulting to the current
 // timestamp if no previous exists. This ensures that wiping all data associated
 // with a node (apart from its key) will not generate already used sequence nums.
@@ -25,6 +25,8 @@ import (
 	"reflect"
 	"testing"
 	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enr"
 )
 
 var keytestID = HexID("51232b8d7821617d2b29b54b81cdefb9b3e9c37d7fd5f63270bcc9e1a6f6a439")
@@ -470,3 +472,67 @@ func TestDBExpireV5(t *testing.T) {
 	db.UpdateFindFailsV5(ID{}, ip, 4)
 	db.expireNodes()
 }
+
+func TestDBReputation(t *testing.T) {
+	db, _ := OpenDB("")
+	defer db.Close()
+
+	var (
+		fresh = ID{0x01}
+		stale = ID{0x02}
+		now   = time.Now().Truncate(time.Second)
+	)
+	if rep := db.Reputation(fresh); rep != (Reputation{}) {
+		t.Fatalf("unexpected reputation of unknown node: %v", rep)
+	}
+	db.UpdateReputation(fresh, Reputation{Score: -12.5, Updated: now})
+	db.UpdateReputation(stale, Reputation{Score: 40, Updated: now.Add(-dbRepExpiration - time.Hour)})
+
+	if rep := db.Reputation(fresh); rep.Score != -12.5 || !rep.Updated.Equal(now) {
+		t.Fatalf("reputation mismatch: have %v, want score -12.5 at %v", rep, now)
+	}
+	if reps := db.Reputations(); len(reps) != 2 || reps[stale].Score != 40 {
+		t.Fatalf("unexpected reputations: %v", reps)
+	}
+	db.expireReputations()
+	reps := db.Reputations()
+	if _, ok := reps[stale]; ok {
+		t.Fatal("stale reputation not expired")
+	}
+	if _, ok := reps[fresh]; !ok {
+		t.Fatal("fresh reputation expired")
+	}
+}
+
+func TestDBPeerSet(t *testing.T) {
+	db, _ := OpenDB("")
+	defer db.Close()
+
+	var (
+		now   = time.Now().Truncate(time.Second)
+		node1 = SignNull(new(enr.Record), ID{0x01})
+		node2 = SignNull(new(enr.Record), ID{0x02})
+		node3 = SignNull(new(enr.Record), ID{0x03})
+	)
+	db.StorePeerSet([]PeerEntry{
+		{Node: node1, Caps: []string{"eth/68"}, Duration: time.Minute, Stored: now},
+		{Node: node2, Caps: []string{"eth/68", "snap/1"}, Duration: time.Hour, Stored: now},
+	})
+	peers := db.PeerSet()
+	if len(peers) != 2 {
+		t.Fatalf("wrong number of peers: %d", len(peers))
+	}
+	want := PeerEntry{Node: node2, Caps: []string{"eth/68", "snap/1"}, Duration: time.Hour, Stored: now}
+	if p := peers[0]; p.Node.ID() != want.Node.ID() || !reflect.DeepEqual(p.Caps, want.Caps) || p.Duration != want.Duration || !p.Stored.Equal(want.Stored) {
+		t.Fatalf("peer mismatch:\nhave %+v\nwant %+v", p, want)
+	}
+	if peers[1].Node.ID() != node1.ID() {
+		t.Fatalf("wrong order of peers: %v", peers[1].Node.ID())
+	}
+
+	// Storing replaces the previous set.
+	db.StorePeerSet([]PeerEntry{{Node: node3, Stored: now}})
+	if peers := db.PeerSet(); len(peers) != 1 || peers[0].Node.ID() != node3.ID() {
+		t.Fatalf("peer set not replaced: %v", peers)
+	}
+}
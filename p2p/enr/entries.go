@@ -87,6 +87,12 @@ type QUIC6 uint16
 
 func (v QUIC6) ENRKey() string { return "quic6" }
 
+// DevP2PQUIC is the "dquic" key, which holds the UDP port of the devp2p QUIC
+// transport. It is distinct from the "quic" key, which is used by libp2p.
+type DevP2PQUIC uint16
+
+func (v DevP2PQUIC) ENRKey() string { return "dquic" }
+
 // ID is the "id" key, which holds the name of the identity scheme.
 type ID string
 
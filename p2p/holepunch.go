@@ -0,0 +1,377 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/discover"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Hole punching lets nodes dial QUIC endpoints behind NATs which drop unsolicited
+// inbound packets. The dialing node (initiator) asks a discovery v5 node which
+// knows the destination (relay) to notify the destination. In response, the
+// destination sends punch packets from its QUIC socket to the QUIC endpoint of the
+// initiator, creating a mapping for the initiator in its NAT. The initiator then
+// dials the address these packets arrive from, which is also the right address
+// when the destination is behind a symmetric NAT.
+//
+// Only nodes whose QUIC port is not reachable announce the "punch" entry in their
+// record, and hole punching is only tried after direct dials of them time out.
+//
+// All coordination happens through TALKREQ messages of protocol "holepunch".
+const (
+	holePunchProtocol = "holepunch"
+
+	holePunchRelays   = 3                      // relays asked per dial
+	holePunchPackets  = 3                      // punch packets sent per notification
+	holePunchInterval = 50 * time.Millisecond  // time between punch packets
+	holePunchWait     = 500 * time.Millisecond // time to wait for a punch before dialing
+	holePunchThrottle = 10 * time.Second       // minimum time between punches for an initiator
+	holePunchRetry    = time.Minute            // minimum time between dials of a destination
+)
+
+// Hole punching request kinds.
+const (
+	holePunchRelay  = iota // initiator -> relay
+	holePunchNotify        // relay -> destination
+)
+
+// Hole punching response codes.
+const (
+	holePunchOK = iota + 1
+	holePunchUnknownTarget
+	holePunchRejected
+)
+
+// holePunchMagic starts punch packets. The first byte marks them as non-QUIC
+// packets, so the QUIC transport passes them to us.
+var holePunchMagic = []byte("\x00devp2p-punch")
+
+var (
+	errNoQUICEndpoint    = errors.New("node has no QUIC endpoint")
+	errNoHolePunchRelay  = errors.New("no relay for hole punching")
+	errDirectlyReachable = errors.New("node is directly reachable")
+	errHolePunchThrottle = errors.New("hole punching throttled")
+)
+
+// holePunchEntry is the "punch" ENR key. It is set by nodes which accept hole
+// punching notifications because they are not directly reachable, i.e. they
+// have no static external IP and their QUIC port is not mapped.
+type holePunchEntry bool
+
+func (holePunchEntry) ENRKey() string { return "punch" }
+
+// needsHolePunch reports whether a failed direct dial of the node is retried with
+// hole punching. A NAT dropping the packets of the dial makes it time out, while
+// refused or reset connections show that the node is reachable. Nodes are also
+// skipped unless their record says that they are behind a NAT.
+func needsHolePunch(dest *enode.Node, err error) bool {
+	var nerr net.Error
+	if !errors.Is(err, context.DeadlineExceeded) && !(errors.As(err, &nerr) && nerr.Timeout()) {
+		return false
+	}
+	var punch holePunchEntry
+	return dest.Load(&punch) == nil && bool(punch)
+}
+
+// holePunchRequest is the TALKREQ message of the hole punching protocol.
+type holePunchRequest struct {
+	Kind      uint
+	Target    enode.ID    // destination node
+	Initiator *enr.Record `rlp:"optional"` // record of the initiator, in notifications
+}
+
+// holePunchDiscovery is the part of discovery v5 used for hole punching.
+type holePunchDiscovery interface {
+	RegisterTalkHandler(protocol string, handler discover.TalkRequestHandler)
+	TalkRequest(n *enode.Node, protocol string, request []byte) ([]byte, error)
+	GetNode(id enode.ID) *enode.Node
+	AllNodes() []*enode.Node
+}
+
+// holePuncher dials QUIC endpoints using hole punching. It also serves the
+// hole punching protocol as relay and destination. Without a QUIC endpoint, it
+// only relays.
+type holePuncher struct {
+	disc   holePunchDiscovery
+	quic   *quicEndpoint
+	self   enode.ID
+	clock  mclock.Clock
+	log    log.Logger
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	waiting map[enode.ID]chan netip.AddrPort // punches expected by dials
+	relayed expHeap                          // initiator/target pairs of relayed requests
+	punched expHeap                          // initiators of handled notifications
+	dialed  expHeap                          // destinations of recent dials
+}
+
+func newHolePuncher(disc holePunchDiscovery, quic *quicEndpoint, self enode.ID, clock mclock.Clock, log log.Logger) *holePuncher {
+	h := &holePuncher{
+		disc:    disc,
+		quic:    quic,
+		self:    self,
+		clock:   clock,
+		log:     log,
+		waiting: make(map[enode.ID]chan netip.AddrPort),
+	}
+	h.ctx, h.cancel = context.WithCancel(context.Background())
+	disc.RegisterTalkHandler(holePunchProtocol, h.handleRequest)
+	if quic != nil {
+		h.wg.Add(1)
+		go h.readLoop()
+	}
+	return h
+}
+
+// close stops the hole puncher.
+func (h *holePuncher) close() {
+	h.cancel()
+	h.wg.Wait()
+}
+
+// Dial connects to the QUIC endpoint of a node through hole punching. Every
+// destination is dialed at most once per holePunchRetry.
+func (h *holePuncher) Dial(ctx context.Context, dest *enode.Node) (net.Conn, error) {
+	addr, ok := dest.DevP2PQUICEndpoint()
+	if !ok || h.quic == nil {
+		return nil, errNoQUICEndpoint
+	}
+	var entry holePunchEntry
+	if dest.Load(&entry) != nil || !bool(entry) {
+		return nil, errDirectlyReachable
+	}
+	if h.throttle(&h.dialed, dest.ID().String(), holePunchRetry) {
+		return nil, errHolePunchThrottle
+	}
+	holePunchMeter.Mark(1)
+
+	punch := make(chan netip.AddrPort, 1)
+	h.mu.Lock()
+	h.waiting[dest.ID()] = punch
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		delete(h.waiting, dest.ID())
+		h.mu.Unlock()
+	}()
+
+	if err := h.requestRelay(ctx, dest); err != nil {
+		return nil, err
+	}
+	// Open our own NAT for the announced endpoint, then wait for the punch of the
+	// destination. It tells the address of the destination's NAT mapping for us.
+	h.sendPunch(addr)
+	timeout := h.clock.NewTimer(holePunchWait)
+	defer timeout.Stop()
+	select {
+	case addr = <-punch:
+	case <-timeout.C():
+		h.log.Trace("No hole punch received", "id", dest.ID(), "addr", addr)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	conn, err := h.quic.dial(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	holePunchSuccessMeter.Mark(1)
+	return conn, nil
+}
+
+// requestRelay asks the nodes of the local table closest to the destination to
+// notify it, as they are the most likely to know it. It returns when the first
+// relay has accepted the request.
+func (h *holePuncher) requestRelay(ctx context.Context, dest *enode.Node) error {
+	req, err := rlp.EncodeToBytes(&holePunchRequest{Kind: holePunchRelay, Target: dest.ID()})
+	if err != nil {
+		return err
+	}
+	relays := h.disc.AllNodes()
+	slices.SortFunc(relays, func(a, b *enode.Node) int {
+		return enode.DistCmp(dest.ID(), a.ID(), b.ID())
+	})
+	asked := 0
+	for _, n := range relays {
+		if n.ID() == dest.ID() || n.ID() == h.self {
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		resp, err := h.disc.TalkRequest(n, holePunchProtocol, req)
+		if err == nil && bytes.Equal(resp, []byte{holePunchOK}) {
+			h.log.Trace("Hole punch relayed", "id", dest.ID(), "relay", n.ID())
+			return nil
+		}
+		if asked++; asked == holePunchRelays {
+			break
+		}
+	}
+	return errNoHolePunchRelay
+}
+
+// handleRequest serves hole punching TALKREQs.
+func (h *holePuncher) handleRequest(from *enode.Node, addr *net.UDPAddr, msg []byte) []byte {
+	var req holePunchRequest
+	if err := rlp.DecodeBytes(msg, &req); err != nil {
+		return []byte{holePunchRejected}
+	}
+	switch req.Kind {
+	case holePunchRelay:
+		return []byte{h.relay(from, addr, req.Target)}
+	case holePunchNotify:
+		return []byte{h.notified(req)}
+	default:
+		return []byte{holePunchRejected}
+	}
+}
+
+// relay forwards the request of an initiator to the target. The target punches
+// the endpoint in the initiator's record, so the record must point at the host
+// the request was received from. Otherwise, the relay could be used to direct
+// punches to arbitrary hosts.
+func (h *holePuncher) relay(from *enode.Node, addr *net.UDPAddr, target enode.ID) byte {
+	if addr == nil || from.IPAddr().Unmap() != addr.AddrPort().Addr().Unmap() {
+		return holePunchRejected
+	}
+	dest := h.disc.GetNode(target)
+	if dest == nil || target == from.ID() {
+		return holePunchUnknownTarget
+	}
+	if h.throttle(&h.relayed, from.ID().String()+target.String(), holePunchThrottle) {
+		return holePunchRejected
+	}
+	notify, err := rlp.EncodeToBytes(&holePunchRequest{Kind: holePunchNotify, Target: target, Initiator: from.Record()})
+	if err != nil {
+		return holePunchRejected
+	}
+	// The notification can't be sent within the response time of the request.
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		if _, err := h.disc.TalkRequest(dest, holePunchProtocol, notify); err != nil {
+			h.log.Trace("Hole punch notification failed", "id", target, "err", err)
+		}
+	}()
+	return holePunchOK
+}
+
+// notified handles a notification, punching a hole for the initiator.
+func (h *holePuncher) notified(req holePunchRequest) byte {
+	if h.quic == nil || req.Target != h.self || req.Initiator == nil {
+		return holePunchRejected
+	}
+	initiator, err := enode.New(enode.ValidSchemes, req.Initiator)
+	if err != nil {
+		return holePunchRejected
+	}
+	addr, ok := initiator.DevP2PQUICEndpoint()
+	if !ok || h.throttle(&h.punched, initiator.ID().String(), holePunchThrottle) {
+		return holePunchRejected
+	}
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		for i := 0; i < holePunchPackets; i++ {
+			if i > 0 {
+				select {
+				case <-h.clock.After(holePunchInterval):
+				case <-h.ctx.Done():
+					return
+				}
+			}
+			h.sendPunch(addr)
+		}
+	}()
+	return holePunchOK
+}
+
+// throttle reports whether key was seen within the given duration and records it
+// otherwise.
+func (h *holePuncher) throttle(history *expHeap, key string, d time.Duration) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.clock.Now()
+	history.expire(now, nil)
+	if history.contains(key) {
+		return true
+	}
+	history.add(key, now.Add(d))
+	return false
+}
+
+// sendPunch sends a punch packet from the QUIC socket.
+func (h *holePuncher) sendPunch(addr netip.AddrPort) {
+	packet := make([]byte, 0, len(holePunchMagic)+len(h.self))
+	packet = append(packet, holePunchMagic...)
+	packet = append(packet, h.self[:]...)
+	if _, err := h.quic.tr.WriteTo(packet, net.UDPAddrFromAddrPort(addr)); err != nil {
+		h.log.Trace("Failed to send hole punch", "addr", addr, "err", err)
+	}
+}
+
+// readLoop runs in its own goroutine and delivers punches to dials waiting for
+// them.
+func (h *holePuncher) readLoop() {
+	defer h.wg.Done()
+
+	buf := make([]byte, len(holePunchMagic)+len(enode.ID{})+1)
+	for {
+		n, from, err := h.quic.tr.ReadNonQUICPacket(h.ctx, buf)
+		if err != nil {
+			return
+		}
+		packet := buf[:n]
+		if len(packet) != len(buf)-1 || !bytes.HasPrefix(packet, holePunchMagic) {
+			continue
+		}
+		udp, ok := from.(*net.UDPAddr)
+		if !ok {
+			continue
+		}
+		var (
+			id   = enode.ID(packet[len(holePunchMagic):])
+			addr = netip.AddrPortFrom(udp.AddrPort().Addr().Unmap(), udp.AddrPort().Port())
+		)
+		h.mu.Lock()
+		if ch := h.waiting[id]; ch != nil {
+			select {
+			case ch <- addr:
+			default:
+			}
+		}
+		h.mu.Unlock()
+	}
+}
@@ -0,0 +1,484 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/internal/testlog"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/discover"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+)
+
+func TestHolePunchConeNAT(t *testing.T) {
+	testHolePunch(t, false)
+}
+
+func TestHolePunchSymmetricNAT(t *testing.T) {
+	testHolePunch(t, true)
+}
+
+func testHolePunch(t *testing.T, symmetric bool) {
+	var (
+		relay = startHolePunchNode(t, nil, nil)
+		dest  = startHolePunchNode(t, relay, newTestNAT(t, symmetric))
+		init  = startHolePunchNode(t, relay, listenLocalUDP(t))
+	)
+	// The relay must know the destination.
+	if _, err := dest.disc.Ping(relay.disc.Self()); err != nil {
+		t.Fatal("ping failed:", err)
+	}
+	if relay.disc.GetNode(dest.disc.Self().ID()) == nil {
+		t.Fatal("destination not known to relay")
+	}
+	// The initiator picks relays from its table.
+	if _, err := init.disc.Ping(relay.disc.Self()); err != nil {
+		t.Fatal("ping failed:", err)
+	}
+	waitTableNode(t, init, relay.disc.Self().ID())
+	target := dest.disc.Self()
+	addr, ok := target.DevP2PQUICEndpoint()
+	if !ok {
+		t.Fatal("destination has no QUIC endpoint")
+	}
+
+	// Direct dials fail because the NAT drops unsolicited packets.
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	if conn, err := init.quic.dial(ctx, addr); err == nil {
+		conn.Close()
+		t.Fatal("direct dial succeeded")
+	}
+
+	accepted := make(chan net.Addr, 1)
+	go func() {
+		if conn, err := dest.quic.accept(); err == nil {
+			accepted <- conn.RemoteAddr()
+		}
+	}()
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, err := init.hp.Dial(ctx, target)
+	if err != nil {
+		t.Fatal("hole punching dial failed:", err)
+	}
+	defer conn.Close()
+
+	remote := conn.RemoteAddr().(*net.UDPAddr).AddrPort()
+	if symmetric && remote.Port() == addr.Port() {
+		t.Errorf("dialed announced port %d of symmetric NAT", addr.Port())
+	}
+	if !symmetric && remote.Port() != addr.Port() {
+		t.Errorf("dialed port %d, want announced port %d", remote.Port(), addr.Port())
+	}
+	select {
+	case from := <-accepted:
+		if from.(*net.UDPAddr).Port != init.quic.addr().(*net.UDPAddr).Port {
+			t.Errorf("connection accepted from %v", from)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection not accepted")
+	}
+}
+
+// Hole punching fails without a relay which knows the destination.
+func TestHolePunchNoRelay(t *testing.T) {
+	var (
+		relay = startHolePunchNode(t, nil, nil)
+		dest  = startHolePunchNode(t, nil, newTestNAT(t, false))
+		init  = startHolePunchNode(t, relay, listenLocalUDP(t))
+	)
+	if _, err := init.disc.Ping(relay.disc.Self()); err != nil {
+		t.Fatal("ping failed:", err)
+	}
+	waitTableNode(t, init, relay.disc.Self().ID())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := init.hp.Dial(ctx, dest.disc.Self()); err != errNoHolePunchRelay {
+		t.Fatalf("wrong error %v, want %v", err, errNoHolePunchRelay)
+	}
+	// Further dials of the destination are throttled.
+	if _, err := init.hp.Dial(ctx, dest.disc.Self()); err != errHolePunchThrottle {
+		t.Fatalf("wrong error %v, want %v", err, errHolePunchThrottle)
+	}
+}
+
+// Nodes without the punch entry are directly reachable and never punched.
+func TestHolePunchReachable(t *testing.T) {
+	var (
+		init  = startHolePunchNode(t, nil, listenLocalUDP(t))
+		key   = newkey()
+		db, _ = enode.OpenDB("")
+		ln    = enode.NewLocalNode(db, key)
+	)
+	defer db.Close()
+	ln.SetStaticIP(net.IP{127, 0, 0, 1})
+	ln.Set(enr.DevP2PQUIC(30303))
+
+	if _, err := init.hp.Dial(context.Background(), ln.Node()); err != errDirectlyReachable {
+		t.Fatalf("wrong error %v, want %v", err, errDirectlyReachable)
+	}
+}
+
+func TestNeedsHolePunch(t *testing.T) {
+	var (
+		key   = newkey()
+		db, _ = enode.OpenDB("")
+		ln    = enode.NewLocalNode(db, key)
+	)
+	defer db.Close()
+	ln.SetStaticIP(net.IP{127, 0, 0, 1})
+	ln.Set(enr.DevP2PQUIC(30303))
+	reachable := ln.Node()
+	ln.Set(holePunchEntry(true))
+	punch := ln.Node()
+
+	timeout := &net.OpError{Op: "dial", Err: os.ErrDeadlineExceeded}
+	refused := &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}
+	tests := []struct {
+		node *enode.Node
+		err  error
+		want bool
+	}{
+		{punch, timeout, true},
+		{punch, context.DeadlineExceeded, true},
+		{punch, errors.Join(timeout, errNoPort), true},
+		{punch, refused, false},
+		{punch, syscall.ECONNRESET, false},
+		{reachable, timeout, false},
+	}
+	for i, test := range tests {
+		if have := needsHolePunch(test.node, test.err); have != test.want {
+			t.Errorf("test %d: have %t, want %t for error %v", i, have, test.want, test.err)
+		}
+	}
+}
+
+// waitTableNode waits until the node with the given ID is in the table of n.
+func waitTableNode(t *testing.T, n *holePunchNode, id enode.ID) {
+	t.Helper()
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		for _, node := range n.disc.AllNodes() {
+			if node.ID() == id {
+				return
+			}
+		}
+	}
+	t.Fatal("node not added to table")
+}
+
+// Relays reject requests of initiators whose record points at another host.
+func TestHolePunchRelaySpoofedRecord(t *testing.T) {
+	var (
+		relay = startHolePunchNode(t, nil, nil)
+		dest  = startHolePunchNode(t, relay, newTestNAT(t, false))
+	)
+	if _, err := dest.disc.Ping(relay.disc.Self()); err != nil {
+		t.Fatal("ping failed:", err)
+	}
+	var (
+		key   = newkey()
+		db, _ = enode.OpenDB("")
+		ln    = enode.NewLocalNode(db, key)
+	)
+	defer db.Close()
+	ln.SetStaticIP(net.IP{10, 0, 0, 1})
+	ln.Set(enr.DevP2PQUIC(30303))
+
+	observed := &net.UDPAddr{IP: net.IP{127, 0, 0, 1}, Port: 30303}
+	if code := relay.hp.relay(ln.Node(), observed, dest.disc.Self().ID()); code != holePunchRejected {
+		t.Fatalf("spoofed request not rejected, code %d", code)
+	}
+	observed.IP = net.IP{10, 0, 0, 1}
+	if code := relay.hp.relay(ln.Node(), observed, dest.disc.Self().ID()); code != holePunchOK {
+		t.Fatalf("valid request not relayed, code %d", code)
+	}
+}
+
+type holePunchNode struct {
+	disc *discover.UDPv5
+	quic *quicEndpoint
+	hp   *holePuncher
+}
+
+// startHolePunchNode creates a node with discovery v5 and hole punching. The node
+// runs QUIC on the given socket, or only relays if it is nil.
+func startHolePunchNode(t *testing.T, boot *holePunchNode, quicConn net.PacketConn) *holePunchNode {
+	t.Helper()
+	var (
+		key    = newkey()
+		db, _  = enode.OpenDB("")
+		ln     = enode.NewLocalNode(db, key)
+		logger = testlog.Logger(t, log.LvlTrace)
+		n      = new(holePunchNode)
+	)
+	t.Cleanup(db.Close)
+	ln.SetStaticIP(net.IP{127, 0, 0, 1})
+
+	if quicConn != nil {
+		var err error
+		if n.quic, err = newQUICEndpoint(quicConn); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(n.quic.close)
+		ln.Set(enr.DevP2PQUIC(quicConn.LocalAddr().(*net.UDPAddr).Port))
+		ln.Set(holePunchEntry(true))
+	}
+
+	socket := listenLocalUDP(t)
+	ln.SetFallbackUDP(socket.LocalAddr().(*net.UDPAddr).Port)
+	cfg := discover.Config{PrivateKey: key, Log: logger.With("node", ln.ID().TerminalString())}
+	if boot != nil {
+		cfg.Bootnodes = []*enode.Node{boot.disc.Self()}
+	}
+	var err error
+	if n.disc, err = discover.ListenV5(socket, ln, cfg); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(n.disc.Close)
+
+	n.hp = newHolePuncher(n.disc, n.quic, ln.ID(), mclock.System{}, cfg.Log)
+	t.Cleanup(n.hp.close)
+	return n
+}
+
+func listenLocalUDP(t *testing.T) *net.UDPConn {
+	t.Helper()
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IP{127, 0, 0, 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+// testNAT is a simulated NAT in front of a UDP socket. Every mapping of the NAT
+// is a localhost UDP socket, which only accepts packets from the addresses it has
+// sent packets to. A cone NAT uses one mapping for all destinations, a symmetric
+// NAT creates a mapping for each destination.
+//
+// The local address of testNAT is the address of its first mapping. For the
+// symmetric NAT, this is the address seen by some other host, never used to
+// contact peers.
+type testNAT struct {
+	symmetric bool
+	in        chan testNATPacket
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	mu           sync.Mutex
+	public       *testNATMapping
+	mappings     map[netip.AddrPort]*testNATMapping
+	readDeadline time.Time
+	deadlineSet  chan struct{}
+}
+
+type testNATMapping struct {
+	conn    *net.UDPConn
+	allowed map[netip.AddrPort]bool
+}
+
+type testNATPacket struct {
+	data []byte
+	from netip.AddrPort
+}
+
+func newTestNAT(t *testing.T, symmetric bool) *testNAT {
+	nat := &testNAT{
+		symmetric:   symmetric,
+		in:          make(chan testNATPacket, 256),
+		closed:      make(chan struct{}),
+		mappings:    make(map[netip.AddrPort]*testNATMapping),
+		deadlineSet: make(chan struct{}),
+	}
+	m, err := nat.newMapping()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nat.public = m
+	return nat
+}
+
+func (nat *testNAT) newMapping() (*testNATMapping, error) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IP{127, 0, 0, 1}})
+	if err != nil {
+		return nil, err
+	}
+	m := &testNATMapping{conn: conn, allowed: make(map[netip.AddrPort]bool)}
+	go nat.forward(m)
+	return m, nil
+}
+
+// forward delivers the packets of allowed sources received by a mapping.
+func (nat *testNAT) forward(m *testNATMapping) {
+	buf := make([]byte, 2048)
+	for {
+		n, from, err := m.conn.ReadFromUDPAddrPort(buf)
+		if err != nil {
+			return
+		}
+		nat.mu.Lock()
+		allowed := m.allowed[from]
+		nat.mu.Unlock()
+		if !allowed {
+			continue
+		}
+		select {
+		case nat.in <- testNATPacket{append([]byte(nil), buf[:n]...), from}:
+		default:
+		}
+	}
+}
+
+func (nat *testNAT) WriteTo(b []byte, addr net.Addr) (int, error) {
+	dest := addr.(*net.UDPAddr).AddrPort()
+	nat.mu.Lock()
+	m := nat.public
+	if nat.symmetric {
+		if m = nat.mappings[dest]; m == nil {
+			var err error
+			if m, err = nat.newMapping(); err != nil {
+				nat.mu.Unlock()
+				return 0, err
+			}
+			nat.mappings[dest] = m
+		}
+	}
+	m.allowed[dest] = true
+	nat.mu.Unlock()
+	return m.conn.WriteToUDPAddrPort(b, dest)
+}
+
+func (nat *testNAT) ReadFrom(b []byte) (int, net.Addr, error) {
+	for {
+		nat.mu.Lock()
+		deadline, deadlineSet := nat.readDeadline, nat.deadlineSet
+		nat.mu.Unlock()
+
+		var (
+			timer   *time.Timer
+			timeout <-chan time.Time
+		)
+		if !deadline.IsZero() {
+			d := time.Until(deadline)
+			if d <= 0 {
+				return 0, nil, os.ErrDeadlineExceeded
+			}
+			timer = time.NewTimer(d)
+			timeout = timer.C
+		}
+		select {
+		case p := <-nat.in:
+			stopTimer(timer)
+			return copy(b, p.data), net.UDPAddrFromAddrPort(p.from), nil
+		case <-nat.closed:
+			stopTimer(timer)
+			return 0, nil, net.ErrClosed
+		case <-timeout:
+			return 0, nil, os.ErrDeadlineExceeded
+		case <-deadlineSet:
+			stopTimer(timer)
+		}
+	}
+}
+
+func (nat *testNAT) Close() error {
+	nat.closeOnce.Do(func() {
+		close(nat.closed)
+		nat.mu.Lock()
+		defer nat.mu.Unlock()
+		nat.public.conn.Close()
+		for _, m := range nat.mappings {
+			m.conn.Close()
+		}
+	})
+	return nil
+}
+
+func (nat *testNAT) LocalAddr() net.Addr {
+	return nat.public.conn.LocalAddr()
+}
+
+func (nat *testNAT) SetDeadline(t time.Time) error {
+	return nat.SetReadDeadline(t)
+}
+
+func (nat *testNAT) SetReadDeadline(t time.Time) error {
+	nat.mu.Lock()
+	defer nat.mu.Unlock()
+	nat.readDeadline = t
+	close(nat.deadlineSet)
+	nat.deadlineSet = make(chan struct{})
+	return nil
+}
+
+func stopTimer(t *time.Timer) {
+	if t != nil {
+		t.Stop()
+	}
+}
+
+func (nat *testNAT) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+func (nat *testNAT) SetReadBuffer(int) error  { return nil }
+func (nat *testNAT) SetWriteBuffer(int) error { return nil }
+
+func TestServerHolePunching(t *testing.T) {
+	srv := &Server{Config: Config{
+		PrivateKey:   newkey(),
+		MaxPeers:     10,
+		NoDiscovery:  true,
+		HolePunching: true,
+	}}
+	if err := srv.Start(); err == nil {
+		srv.Stop()
+		t.Fatal("server started with hole punching but without discovery v5")
+	}
+
+	srv = &Server{Config: Config{
+		PrivateKey:     newkey(),
+		MaxPeers:       10,
+		ListenAddr:     "127.0.0.1:0",
+		QUICListenAddr: "127.0.0.1:0",
+		DiscoveryV5:    true,
+		HolePunching:   true,
+		Logger:         testlog.Logger(t, log.LvlTrace),
+	}}
+	if err := srv.Start(); err != nil {
+		t.Fatal("could not start server:", err)
+	}
+	defer srv.Stop()
+	if srv.holepunch == nil {
+		t.Fatal("hole punching not enabled")
+	}
+	if srv.dialsched.holePunch == nil {
+		t.Fatal("hole punching not used by dial scheduler")
+	}
+}
@@ -34,6 +34,12 @@ const (
 
 	// egressMeterName is the prefix of the per-packet outbound metrics.
 	egressMeterName = "p2p/egress"
+
+	// ingressThrottledMeterName is the prefix of the inbound bandwidth limiting metrics.
+	ingressThrottledMeterName = "p2p/throttled/ingress"
+
+	// egressThrottledMeterName is the prefix of the outbound bandwidth limiting metrics.
+	egressThrottledMeterName = "p2p/throttled/egress"
 )
 
 var (
@@ -51,6 +57,10 @@ var (
 	dialSuccessMeter    = metrics.NewRegisteredMeter("p2p/dials/success", nil)
 	dialConnectionError = metrics.NewRegisteredMeter("p2p/dials/error/connection", nil) // dial timeout; no route to host; connection refused; network is unreachable
 
+	// hole punching dials, made when a direct dial fails
+	holePunchMeter        = metrics.NewRegisteredMeter("p2p/dials/holepunch", nil)
+	holePunchSuccessMeter = metrics.NewRegisteredMeter("p2p/dials/holepunch/success", nil)
+
 	// count peers that stayed connected for at least 1 min
 	serve1MinSuccessMeter = metrics.NewRegisteredMeter("p2p/serves/success/1min", nil)
 	dial1MinSuccessMeter  = metrics.NewRegisteredMeter("p2p/dials/success/1min", nil)
@@ -154,6 +164,10 @@ func newMeteredConn(conn net.Conn) net.Conn {
 	if !metrics.Enabled() {
 		return conn
 	}
+	// QUIC connections meter their streams directly.
+	if _, ok := conn.(*quicConn); ok {
+		return conn
+	}
 	return &meteredConn{Conn: conn}
 }
 
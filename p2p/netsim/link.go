@@ -0,0 +1,284 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package netsim
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+)
+
+// retransmitMinDelay is the least amount of time it takes to recover lost stream
+// data, modeling a TCP retransmission timeout.
+const retransmitMinDelay = 200 * time.Millisecond
+
+// LinkConfig holds the properties of the link between two nodes.
+type LinkConfig struct {
+	Latency time.Duration // One-way delay of all traffic
+	Loss    float64       // Probability of losing a write or packet, between 0 and 1
+}
+
+// retransmitDelay is the additional delay of lost stream data. Streams are
+// reliable, so loss only makes delivery slower.
+func (cfg LinkConfig) retransmitDelay() time.Duration {
+	return max(retransmitMinDelay, 3*cfg.Latency)
+}
+
+// linkFlow accounts for the data of one direction of a stream connection which
+// is due for delivery but has not been consumed by the receiver yet. Data counts
+// as consumed when the receiver reads again after reading it, i.e. when it has
+// finished handling the previous read.
+type linkFlow struct {
+	net     *Network
+	mu      sync.Mutex
+	pending int64 // bytes due but not consumed
+	closed  bool  // receiver is gone, nothing is counted anymore
+}
+
+func newLinkFlow(network *Network) *linkFlow {
+	return &linkFlow{net: network}
+}
+
+// deliver counts n bytes which became due for delivery.
+func (f *linkFlow) deliver(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.closed {
+		f.pending += int64(n)
+		f.net.pending.Add(int64(n))
+	}
+}
+
+// consume marks n bytes as handled by the receiver.
+func (f *linkFlow) consume(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n64 := min(int64(n), f.pending)
+	f.pending -= n64
+	f.net.pending.Add(-n64)
+}
+
+// close drops the data that will never be consumed.
+func (f *linkFlow) close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = true
+	f.net.pending.Add(-f.pending)
+	f.pending = 0
+}
+
+// linkConn is one end of a stream connection between two simulated nodes. Writes
+// are buffered and passed on to the underlying pipe when the link latency has
+// passed on the simulated clock.
+type linkConn struct {
+	net.Conn // pipe end, used for reading
+	net      *Network
+	from, to *Node
+	in       *linkFlow // data sent to this end
+	out      *linkQueue
+	closed   atomic.Bool
+	unread   int // bytes returned by the last Read, consumed on the next one
+}
+
+func newLinkConn(network *Network, from, to *Node, fd net.Conn, in, out *linkFlow) *linkConn {
+	c := &linkConn{Conn: fd, net: network, from: from, to: to, in: in, out: newLinkQueue(fd, out)}
+	network.addQueue(c.out)
+	go c.out.writeLoop(network)
+	return c
+}
+
+func (c *linkConn) Read(b []byte) (int, error) {
+	// Reading again means the data returned last time has been handled.
+	c.in.consume(c.unread)
+	c.unread = 0
+	if c.closed.Load() {
+		return 0, net.ErrClosed
+	}
+	n, err := c.Conn.Read(b)
+	c.unread = n
+	return n, err
+}
+
+// Write schedules delivery of b. It never blocks.
+func (c *linkConn) Write(b []byte) (int, error) {
+	cfg := c.net.LinkConfig(c.from, c.to)
+	delay := cfg.Latency
+	if c.net.lost(cfg) {
+		delay += cfg.retransmitDelay()
+	}
+	now := c.net.clock.Now()
+	at, err := c.out.push(now.Add(delay), b)
+	if err != nil {
+		return 0, err
+	}
+	if c.net.isClosed() {
+		// The clock does not advance anymore.
+		c.out.flushAll()
+	} else {
+		c.net.clock.AfterFunc(time.Duration(at-now), func() { c.out.flush(c.net.clock.Now()) })
+	}
+	return len(b), nil
+}
+
+// Close stops reading immediately. The pipe is closed after all buffered data
+// has been delivered, so the remote end receives everything written before
+// Close, just like with TCP.
+func (c *linkConn) Close() error {
+	if !c.closed.Swap(true) {
+		c.Conn.SetReadDeadline(time.Unix(1, 0)) // interrupt Read
+		c.in.close()
+		c.out.close()
+	}
+	return nil
+}
+
+func (c *linkConn) LocalAddr() net.Addr {
+	return &net.TCPAddr{IP: c.from.ip.AsSlice(), Port: simPort}
+}
+
+func (c *linkConn) RemoteAddr() net.Addr {
+	return &net.TCPAddr{IP: c.to.ip.AsSlice(), Port: simPort}
+}
+
+func (c *linkConn) SetDeadline(t time.Time) error {
+	return c.SetReadDeadline(t)
+}
+
+func (c *linkConn) SetReadDeadline(t time.Time) error {
+	if c.closed.Load() {
+		return net.ErrClosed
+	}
+	return c.Conn.SetReadDeadline(t)
+}
+
+// SetWriteDeadline does nothing because writes never block.
+func (c *linkConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+// linkQueue holds the data written to a link which has not been delivered yet.
+type linkQueue struct {
+	fd      net.Conn
+	flow    *linkFlow // accounting of the data delivered to the remote end
+	mu      sync.Mutex
+	cond    *sync.Cond
+	pending []linkChunk // scheduled for delivery, ordered by time
+	ready   [][]byte    // due for delivery
+	last    mclock.AbsTime
+	closing bool // no more writes, close pipe when drained
+	done    bool // pipe is closed
+}
+
+type linkChunk struct {
+	at   mclock.AbsTime
+	data []byte
+}
+
+func newLinkQueue(fd net.Conn, flow *linkFlow) *linkQueue {
+	q := &linkQueue{fd: fd, flow: flow}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push schedules delivery of data. Data must be delivered in the order it was
+// written, so the delivery time is never earlier than the last one.
+func (q *linkQueue) push(at mclock.AbsTime, data []byte) (mclock.AbsTime, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closing || q.done {
+		return 0, net.ErrClosed
+	}
+	at = max(at, q.last)
+	q.last = at
+	q.pending = append(q.pending, linkChunk{at, append([]byte(nil), data...)})
+	return at, nil
+}
+
+// flush moves the chunks which are due at the given time to the writer. The
+// data is accounted as pending until the remote end has consumed it.
+func (q *linkQueue) flush(now mclock.AbsTime) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	n := 0
+	for ; n < len(q.pending) && q.pending[n].at <= now; n++ {
+		q.ready = append(q.ready, q.pending[n].data)
+		q.flow.deliver(len(q.pending[n].data))
+	}
+	q.pending = q.pending[n:]
+	if n > 0 {
+		q.cond.Signal()
+	}
+}
+
+// flushAll makes all pending data due immediately. It is used when the network
+// is shut down and the clock no longer advances.
+func (q *linkQueue) flushAll() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, c := range q.pending {
+		q.ready = append(q.ready, c.data)
+	}
+	q.pending = nil
+	q.cond.Signal()
+}
+
+func (q *linkQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closing = true
+	q.cond.Signal()
+}
+
+// writeLoop passes due data on to the pipe.
+func (q *linkQueue) writeLoop(network *Network) {
+	defer q.fd.Close()
+	defer network.removeQueue(q)
+
+	for {
+		q.mu.Lock()
+		for len(q.ready) == 0 && !(q.closing && len(q.pending) == 0) {
+			q.cond.Wait()
+		}
+		if len(q.ready) == 0 {
+			q.done = true
+			q.mu.Unlock()
+			return
+		}
+		data := q.ready[0]
+		q.ready = q.ready[1:]
+		q.mu.Unlock()
+
+		if _, err := q.fd.Write(data); err != nil {
+			q.mu.Lock()
+			q.done = true
+			q.pending, q.ready = nil, nil
+			q.mu.Unlock()
+			q.flow.close()
+			return
+		}
+	}
+}
@@ -0,0 +1,527 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package netsim simulates networks of p2p servers in a single process.
+//
+// Nodes are connected by in-memory links with configurable latency and loss. All
+// traffic is delivered by a simulated clock, which is also used by the servers.
+// Time only advances when the test runs the network, and after every step the
+// network waits for the nodes to finish processing the delivered messages. This
+// makes it possible to write propagation tests that do not depend on the speed
+// of the machine running them.
+//
+// The network keeps track of the stream data and packets which became due for
+// delivery, until the receiving node has read them and returned for more. The
+// subprotocol messages decoded from the streams are tracked by the p2p servers
+// until the protocol handlers are done with them. Work which protocol handlers
+// hand off to other goroutines is not tracked directly. Instead, the network
+// waits until the Go scheduler reports that no other goroutine is running,
+// runnable or in a system call. This wait is bounded, since goroutines of
+// unrelated tests running in parallel may keep the scheduler busy.
+//
+// A typical test creates the nodes, connects them according to a topology and
+// awaits a condition:
+//
+//	network := netsim.New(netsim.Config{Link: netsim.LinkConfig{Latency: 50 * time.Millisecond}})
+//	defer network.Close()
+//	for i := 0; i < 10; i++ {
+//		network.AddNode(netsim.NodeConfig{Protocols: protocols(i)})
+//	}
+//	network.ConnectTopology(netsim.Ring)
+//	if err := network.AwaitConnected(10 * time.Second); err != nil {
+//		t.Fatal(err)
+//	}
+package netsim
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/netip"
+	"runtime"
+	"runtime/metrics"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/ethereum/go-ethereum/p2p/pipes"
+)
+
+const (
+	// simPort is the TCP and UDP port of all simulated nodes.
+	simPort = 30303
+
+	// defaultStep is the default maximum amount of simulated time per step.
+	defaultStep = 10 * time.Millisecond
+
+	// settleTimeout bounds the real time spent waiting for the network to settle
+	// after a step. It only takes effect if a node stops reading delivered data,
+	// e.g. because a protocol handler waits for the simulated clock while holding
+	// a message, which would otherwise block the simulation forever.
+	settleTimeout = 10 * time.Second
+
+	// settleBusyTimeout bounds the real time spent waiting for goroutines which
+	// are not tracked by the network, e.g. those of tests running in parallel.
+	settleBusyTimeout = 100 * time.Millisecond
+)
+
+var (
+	// ErrTimeout is returned by Await when the condition is not met in time.
+	ErrTimeout = errors.New("timeout")
+
+	errUnknownNode      = errors.New("unknown node")
+	errClosed           = errors.New("network closed")
+	errAlreadyListening = errors.New("node is already listening")
+)
+
+// Config holds the settings of a simulated network.
+type Config struct {
+	Clock *mclock.Simulated // Simulated clock of the network, created if nil
+	Link  LinkConfig        // Properties of all links without specific config
+	Seed  int64             // Seed of the loss randomness
+	Step  time.Duration     // Maximum simulated time per step, 10ms if zero
+}
+
+// NodeConfig holds the settings of a simulated node.
+type NodeConfig struct {
+	PrivateKey *ecdsa.PrivateKey // Node key, generated if nil
+	Name       string            // Client name reported in the protocol handshake
+	MaxPeers   int               // Peer limit, 50 if zero
+	Protocols  []p2p.Protocol
+}
+
+// Network is a simulated network of p2p servers.
+type Network struct {
+	cfg        Config
+	clock      *mclock.Simulated
+	start      mclock.AbsTime
+	pending    atomic.Int64 // stream bytes and packets delivered but not handled
+	processing atomic.Int64 // messages being handled by protocols, see p2p.Config
+
+	mu     sync.Mutex
+	closed bool
+	rand   *rand.Rand
+	nodes  []*Node
+	byID   map[enode.ID]*Node
+	byAddr map[netip.AddrPort]*PacketConn
+	config map[[2]enode.ID]LinkConfig
+	links  map[[2]enode.ID]struct{} // connections requested by Connect
+	queues map[*linkQueue]struct{}
+}
+
+// Node is a simulated node.
+type Node struct {
+	Server *p2p.Server
+
+	net   *Network
+	index int
+	id    enode.ID
+	ip    netip.Addr
+	udp   *PacketConn
+}
+
+// New creates a simulated network.
+func New(cfg Config) *Network {
+	if cfg.Clock == nil {
+		cfg.Clock = new(mclock.Simulated)
+	}
+	if cfg.Step == 0 {
+		cfg.Step = defaultStep
+	}
+	return &Network{
+		cfg:    cfg,
+		clock:  cfg.Clock,
+		start:  cfg.Clock.Now(),
+		rand:   rand.New(rand.NewSource(cfg.Seed)),
+		byID:   make(map[enode.ID]*Node),
+		byAddr: make(map[netip.AddrPort]*PacketConn),
+		config: make(map[[2]enode.ID]LinkConfig),
+		links:  make(map[[2]enode.ID]struct{}),
+		queues: make(map[*linkQueue]struct{}),
+	}
+}
+
+// Clock returns the simulated clock of the network.
+func (net *Network) Clock() *mclock.Simulated {
+	return net.clock
+}
+
+// Elapsed returns the simulated time since the network was created.
+func (net *Network) Elapsed() time.Duration {
+	return time.Duration(net.clock.Now() - net.start)
+}
+
+// AddNode creates a node and starts its server. The server does not listen and
+// runs no discovery, it can only dial other nodes of the network.
+func (net *Network) AddNode(cfg NodeConfig) (*Node, error) {
+	key := cfg.PrivateKey
+	if key == nil {
+		var err error
+		if key, err = crypto.GenerateKey(); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.MaxPeers == 0 {
+		cfg.MaxPeers = 50
+	}
+
+	net.mu.Lock()
+	if net.closed {
+		net.mu.Unlock()
+		return nil, errClosed
+	}
+	index := len(net.nodes)
+	n := &Node{
+		net:   net,
+		index: index,
+		id:    enode.PubkeyToIDV4(&key.PublicKey),
+		ip:    netip.AddrFrom4([4]byte{10, byte((index + 1) >> 16), byte((index + 1) >> 8), byte(index + 1)}),
+	}
+	if _, ok := net.byID[n.id]; ok {
+		net.mu.Unlock()
+		return nil, fmt.Errorf("node %v already exists", n.id)
+	}
+	net.nodes = append(net.nodes, n)
+	net.byID[n.id] = n
+	net.mu.Unlock()
+
+	n.Server = &p2p.Server{Config: p2p.Config{
+		PrivateKey:  key,
+		Name:        cfg.Name,
+		MaxPeers:    cfg.MaxPeers,
+		Protocols:   cfg.Protocols,
+		NoDiscovery: true,
+		Dialer:      &dialer{net: net, from: n},
+		Clock:       net.clock,
+		Logger:      log.New("node", index),
+
+		PendingMessages: &net.processing,
+	}}
+	if err := n.Server.Start(); err != nil {
+		return nil, err
+	}
+	ln := n.Server.LocalNode()
+	ln.SetStaticIP(n.ip.AsSlice())
+	ln.Set(enr.TCP(simPort))
+	ln.Set(enr.UDP(simPort))
+	return n, nil
+}
+
+// Nodes returns all nodes in the order they were added.
+func (net *Network) Nodes() []*Node {
+	net.mu.Lock()
+	defer net.mu.Unlock()
+
+	return append([]*Node(nil), net.nodes...)
+}
+
+func (net *Network) node(id enode.ID) *Node {
+	net.mu.Lock()
+	defer net.mu.Unlock()
+
+	return net.byID[id]
+}
+
+// SetLink sets the properties of the link between two nodes, overriding the
+// default link config of the network.
+func (net *Network) SetLink(a, b *Node, cfg LinkConfig) {
+	net.mu.Lock()
+	defer net.mu.Unlock()
+
+	net.config[linkKey(a, b)] = cfg
+}
+
+// LinkConfig returns the properties of the link between two nodes.
+func (net *Network) LinkConfig(a, b *Node) LinkConfig {
+	net.mu.Lock()
+	defer net.mu.Unlock()
+
+	if cfg, ok := net.config[linkKey(a, b)]; ok {
+		return cfg
+	}
+	return net.cfg.Link
+}
+
+func linkKey(a, b *Node) [2]enode.ID {
+	if a.index > b.index {
+		a, b = b, a
+	}
+	return [2]enode.ID{a.id, b.id}
+}
+
+// lost decides whether a write or packet on a link with the given config is lost.
+func (net *Network) lost(cfg LinkConfig) bool {
+	if cfg.Loss <= 0 {
+		return false
+	}
+	net.mu.Lock()
+	defer net.mu.Unlock()
+
+	return net.rand.Float64() < cfg.Loss
+}
+
+// Connect makes node a dial node b. The connection is kept alive like a static
+// peer connection and re-established after it breaks.
+func (net *Network) Connect(a, b *Node) {
+	net.mu.Lock()
+	net.links[linkKey(a, b)] = struct{}{}
+	net.mu.Unlock()
+
+	a.Server.AddPeer(b.Node())
+}
+
+// Disconnect removes the connection between two nodes.
+func (net *Network) Disconnect(a, b *Node) {
+	net.mu.Lock()
+	delete(net.links, linkKey(a, b))
+	net.mu.Unlock()
+
+	a.Server.RemovePeer(b.Node())
+	b.Server.RemovePeer(a.Node())
+}
+
+// ConnectTopology connects all nodes of the network according to t.
+func (net *Network) ConnectTopology(t Topology) {
+	nodes := net.Nodes()
+	for _, l := range t(len(nodes)) {
+		net.Connect(nodes[l[0]], nodes[l[1]])
+	}
+}
+
+// Schedule runs fn when the simulated clock has advanced by d. The function is
+// called on the goroutine which runs the network.
+func (net *Network) Schedule(d time.Duration, fn func()) {
+	net.clock.AfterFunc(d, fn)
+}
+
+// Run advances the simulated clock by d. The clock jumps from one scheduled timer
+// to the next, at most by the configured step, and the network settles after
+// every jump.
+func (net *Network) Run(d time.Duration) {
+	for end := net.clock.Now().Add(d); net.clock.Now() < end; {
+		net.step(end)
+	}
+}
+
+// Await runs the network until cond returns true. It returns ErrTimeout if this
+// does not happen within the given amount of simulated time.
+func (net *Network) Await(timeout time.Duration, cond func() bool) error {
+	net.settle()
+	for end := net.clock.Now().Add(timeout); !cond(); {
+		if net.clock.Now() >= end {
+			return fmt.Errorf("%w after %v", ErrTimeout, timeout)
+		}
+		net.step(end)
+	}
+	return nil
+}
+
+// step advances the clock to the next scheduled timer, but not beyond end and
+// the maximum step, and waits for the network to settle.
+func (net *Network) step(end mclock.AbsTime) {
+	now := net.clock.Now()
+	d := min(time.Duration(end-now), net.cfg.Step)
+	if next, ok := net.clock.NextTimer(); ok && next < now.Add(d) {
+		d = max(time.Duration(next-now), 0)
+	}
+	net.clock.Run(d)
+	net.settle()
+}
+
+// AwaitConnected runs the network until all connections requested by Connect
+// are established.
+func (net *Network) AwaitConnected(timeout time.Duration) error {
+	return net.Await(timeout, func() bool {
+		net.mu.Lock()
+		links := make([][2]*Node, 0, len(net.links))
+		for l := range net.links {
+			links = append(links, [2]*Node{net.byID[l[0]], net.byID[l[1]]})
+		}
+		net.mu.Unlock()
+
+		for _, l := range links {
+			if !l[0].Connected(l[1]) || !l[1].Connected(l[0]) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// settle waits until the nodes have handled all data delivered up to the current
+// simulated time, and until the goroutines woken by the last step have run.
+func (net *Network) settle() {
+	start := time.Now()
+	for {
+		// Sleeping is too coarse on most systems, yield instead.
+		runtime.Gosched()
+
+		// Messages are counted as processing before the stream data they were
+		// decoded from is released, so pending must be checked first.
+		if net.pending.Load() == 0 && net.processing.Load() == 0 {
+			if !schedulerBusy() || time.Since(start) > settleBusyTimeout {
+				return
+			}
+		}
+		if time.Since(start) > settleTimeout {
+			log.Warn("Simulated network did not settle", "pending", net.pending.Load(), "processing", net.processing.Load())
+			return
+		}
+	}
+}
+
+// schedulerBusy reports whether any goroutine other than the calling one is
+// running, waiting to run or inside a system or cgo call (e.g. recovering a
+// signature). It returns false if the Go runtime does not provide the scheduler
+// metrics.
+func schedulerBusy() bool {
+	samples := []metrics.Sample{
+		{Name: "/sched/goroutines/runnable:goroutines"},
+		{Name: "/sched/goroutines/running:goroutines"},
+		{Name: "/sched/goroutines/not-in-go:goroutines"},
+	}
+	metrics.Read(samples)
+	for _, s := range samples {
+		if s.Value.Kind() != metrics.KindUint64 {
+			return false
+		}
+	}
+	return samples[0].Value.Uint64() > 0 || samples[1].Value.Uint64() > 1 || samples[2].Value.Uint64() > 0
+}
+
+// Close stops all nodes.
+func (net *Network) Close() {
+	net.mu.Lock()
+	if net.closed {
+		net.mu.Unlock()
+		return
+	}
+	net.closed = true
+	nodes := net.nodes
+	net.mu.Unlock()
+
+	// The clock does not advance anymore, so data in flight is delivered
+	// immediately to let the nodes shut down.
+	net.flushQueues()
+	var wg sync.WaitGroup
+	for _, n := range nodes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n.Server.Stop()
+			if n.udp != nil {
+				n.udp.Close()
+			}
+		}()
+	}
+	wg.Wait()
+	net.flushQueues()
+}
+
+func (net *Network) isClosed() bool {
+	net.mu.Lock()
+	defer net.mu.Unlock()
+
+	return net.closed
+}
+
+func (net *Network) flushQueues() {
+	net.mu.Lock()
+	defer net.mu.Unlock()
+
+	for q := range net.queues {
+		q.flushAll()
+	}
+}
+
+func (net *Network) addQueue(q *linkQueue) {
+	net.mu.Lock()
+	defer net.mu.Unlock()
+
+	net.queues[q] = struct{}{}
+}
+
+func (net *Network) removeQueue(q *linkQueue) {
+	net.mu.Lock()
+	defer net.mu.Unlock()
+
+	delete(net.queues, q)
+}
+
+// ID returns the node ID.
+func (n *Node) ID() enode.ID {
+	return n.id
+}
+
+// Index returns the position of the node in the network.
+func (n *Node) Index() int {
+	return n.index
+}
+
+// Addr returns the simulated IP address of the node.
+func (n *Node) Addr() netip.Addr {
+	return n.ip
+}
+
+// Node returns the current record of the node.
+func (n *Node) Node() *enode.Node {
+	return n.Server.Self()
+}
+
+// Connected reports whether the node has a peer connection to other.
+func (n *Node) Connected(other *Node) bool {
+	for _, p := range n.Server.Peers() {
+		if p.ID() == other.id {
+			return true
+		}
+	}
+	return false
+}
+
+// dialer creates connections between simulated nodes.
+type dialer struct {
+	net  *Network
+	from *Node
+}
+
+func (d *dialer) Dial(ctx context.Context, dest *enode.Node) (net.Conn, error) {
+	if d.net.isClosed() {
+		return nil, errClosed
+	}
+	to := d.net.node(dest.ID())
+	if to == nil {
+		return nil, errUnknownNode
+	}
+	fd1, fd2, err := pipes.NetPipe()
+	if err != nil {
+		return nil, err
+	}
+	// Data written to c1 is read from c2 and vice versa.
+	toDest, toSource := newLinkFlow(d.net), newLinkFlow(d.net)
+	c1 := newLinkConn(d.net, d.from, to, fd1, toSource, toDest)
+	c2 := newLinkConn(d.net, to, d.from, fd2, toDest, toSource)
+	go to.Server.SetupConn(c2, 0, nil)
+	return c1, nil
+}
@@ -0,0 +1,287 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package netsim
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/discover"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// floodNode runs a protocol which forwards every new message to all peers.
+type floodNode struct {
+	clock *mclock.Simulated
+	mu    sync.Mutex
+	peers map[enode.ID]p2p.MsgReadWriter
+	seen  map[uint64]mclock.AbsTime
+}
+
+func newFloodNode(clock *mclock.Simulated) *floodNode {
+	return &floodNode{
+		clock: clock,
+		peers: make(map[enode.ID]p2p.MsgReadWriter),
+		seen:  make(map[uint64]mclock.AbsTime),
+	}
+}
+
+func (f *floodNode) protocols() []p2p.Protocol {
+	return []p2p.Protocol{{
+		Name:    "flood",
+		Version: 1,
+		Length:  1,
+		Run:     f.run,
+	}}
+}
+
+func (f *floodNode) run(p *p2p.Peer, rw p2p.MsgReadWriter) error {
+	f.mu.Lock()
+	f.peers[p.ID()] = rw
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		delete(f.peers, p.ID())
+		f.mu.Unlock()
+	}()
+
+	for {
+		msg, err := rw.ReadMsg()
+		if err != nil {
+			return err
+		}
+		var id uint64
+		if err := msg.Decode(&id); err != nil {
+			return err
+		}
+		f.publish(id)
+	}
+}
+
+// publish records the message and forwards it if it is new.
+func (f *floodNode) publish(id uint64) {
+	f.mu.Lock()
+	if _, ok := f.seen[id]; ok {
+		f.mu.Unlock()
+		return
+	}
+	f.seen[id] = f.clock.Now()
+	peers := make([]p2p.MsgReadWriter, 0, len(f.peers))
+	for _, rw := range f.peers {
+		peers = append(peers, rw)
+	}
+	f.mu.Unlock()
+
+	for _, rw := range peers {
+		go p2p.Send(rw, 0, id)
+	}
+}
+
+func (f *floodNode) received(id uint64) (mclock.AbsTime, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t, ok := f.seen[id]
+	return t, ok
+}
+
+func newFloodNetwork(t *testing.T, n int, cfg Config) (*Network, []*floodNode) {
+	t.Helper()
+
+	network := New(cfg)
+	t.Cleanup(network.Close)
+	floods := make([]*floodNode, n)
+	for i := range floods {
+		floods[i] = newFloodNode(network.Clock())
+		if _, err := network.AddNode(NodeConfig{Protocols: floods[i].protocols()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return network, floods
+}
+
+func awaitFlood(network *Network, floods []*floodNode, id uint64, timeout time.Duration) error {
+	return network.Await(timeout, func() bool {
+		for _, f := range floods {
+			if _, ok := f.received(id); !ok {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func TestNetworkLatency(t *testing.T) {
+	const latency = 50 * time.Millisecond
+	network, floods := newFloodNetwork(t, 5, Config{Link: LinkConfig{Latency: latency}})
+	network.ConnectTopology(Chain)
+	if err := network.AwaitConnected(10 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	start := network.Clock().Now()
+	floods[0].publish(1)
+	if err := awaitFlood(network, floods, 1, 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	for i, f := range floods {
+		at, _ := f.received(1)
+		var (
+			delay = time.Duration(at - start)
+			min   = time.Duration(i) * latency
+			max   = min + time.Duration(i)*time.Millisecond
+		)
+		if delay < min || delay > max {
+			t.Errorf("node %d received message after %v, want %v..%v", i, delay, min, max)
+		}
+	}
+}
+
+func TestNetworkLinkConfig(t *testing.T) {
+	network, floods := newFloodNetwork(t, 3, Config{Link: LinkConfig{Latency: 10 * time.Millisecond}})
+	nodes := network.Nodes()
+	network.SetLink(nodes[0], nodes[2], LinkConfig{Latency: time.Second})
+	network.ConnectTopology(FullMesh)
+	if err := network.AwaitConnected(10 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	// The message reaches node 2 faster through node 1 than directly.
+	start := network.Clock().Now()
+	floods[0].publish(1)
+	if err := awaitFlood(network, floods, 1, 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	at, _ := floods[2].received(1)
+	if delay := time.Duration(at - start); delay >= time.Second {
+		t.Errorf("message took %v to reach node 2", delay)
+	}
+}
+
+func TestNetworkLoss(t *testing.T) {
+	const latency = 20 * time.Millisecond
+	network, floods := newFloodNetwork(t, 2, Config{Link: LinkConfig{Latency: latency}})
+	network.Connect(network.Nodes()[0], network.Nodes()[1])
+	if err := network.AwaitConnected(10 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	// Stream data is not lost, but delivered late.
+	network.SetLink(network.Nodes()[0], network.Nodes()[1], LinkConfig{Latency: latency, Loss: 1})
+	start := network.Clock().Now()
+	floods[0].publish(1)
+	if err := awaitFlood(network, floods, 1, 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	at, _ := floods[1].received(1)
+	if delay := time.Duration(at - start); delay < latency+retransmitMinDelay {
+		t.Errorf("lost message delivered after %v", delay)
+	}
+}
+
+func TestNetworkDisconnect(t *testing.T) {
+	network, floods := newFloodNetwork(t, 3, Config{Link: LinkConfig{Latency: 10 * time.Millisecond}})
+	network.ConnectTopology(Chain)
+	if err := network.AwaitConnected(10 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	nodes := network.Nodes()
+	network.Disconnect(nodes[1], nodes[2])
+	if err := network.Await(10*time.Second, func() bool { return !nodes[2].Connected(nodes[1]) }); err != nil {
+		t.Fatal(err)
+	}
+
+	// The message does not reach the partitioned node.
+	floods[0].publish(1)
+	network.Run(time.Second)
+	if _, ok := floods[1].received(1); !ok {
+		t.Error("connected node did not receive message")
+	}
+	if _, ok := floods[2].received(1); ok {
+		t.Error("disconnected node received message")
+	}
+	if err := awaitFlood(network, floods, 1, time.Second); !errors.Is(err, ErrTimeout) {
+		t.Errorf("wrong error from Await: %v", err)
+	}
+}
+
+func TestNetworkSchedule(t *testing.T) {
+	network, floods := newFloodNetwork(t, 2, Config{Link: LinkConfig{Latency: 10 * time.Millisecond}})
+	network.ConnectTopology(Chain)
+	if err := network.AwaitConnected(10 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	start := network.Clock().Now()
+	network.Schedule(time.Second, func() { floods[0].publish(1) })
+	if err := awaitFlood(network, floods, 1, 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	at, _ := floods[1].received(1)
+	if delay := time.Duration(at - start); delay < time.Second+10*time.Millisecond {
+		t.Errorf("scheduled message delivered after %v", delay)
+	}
+}
+
+func TestNetworkDiscovery(t *testing.T) {
+	network := New(Config{Link: LinkConfig{Latency: 20 * time.Millisecond}})
+	defer network.Close()
+
+	var disc []*discover.UDPv5
+	for i := 0; i < 8; i++ {
+		n, err := network.AddNode(NodeConfig{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var cfg discover.Config
+		if i > 0 {
+			cfg.Bootnodes = []*enode.Node{network.Nodes()[0].Node()}
+		}
+		d, err := n.ListenV5(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer d.Close()
+		disc = append(disc, d)
+	}
+
+	// After bootstrapping, nodes can find each other through the bootnode.
+	var (
+		target = network.Nodes()[len(disc)-1].ID()
+		result = make(chan []*enode.Node, 1)
+		found  bool
+	)
+	go func() { result <- disc[1].Lookup(target) }()
+	err := network.Await(time.Minute, func() bool {
+		select {
+		case nodes := <-result:
+			found = len(nodes) > 0 && nodes[0].ID() == target
+			if !found {
+				go func() { result <- disc[1].Lookup(target) }()
+			}
+		default:
+		}
+		return found
+	})
+	if err != nil {
+		t.Fatal("lookup did not find target node:", err)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package netsim
+
+import (
+	"net"
+	"net/netip"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/p2p/discover"
+)
+
+// packetQueueSize is the number of packets buffered by a PacketConn. Further
+// packets are dropped.
+const packetQueueSize = 1024
+
+type packet struct {
+	from netip.AddrPort
+	data []byte
+}
+
+// PacketConn is the UDP socket of a simulated node. It implements
+// discover.UDPConn.
+type PacketConn struct {
+	node      *Node
+	addr      netip.AddrPort
+	in        chan packet
+	closed    chan struct{}
+	closeOnce sync.Once
+	unread    bool // whether the last received packet is still being handled
+
+	mu       sync.Mutex // protects in against delivery after close
+	isClosed bool
+}
+
+// ListenUDP creates the UDP socket of the node. Packets sent to other nodes are
+// subject to the latency and loss of the link.
+func (n *Node) ListenUDP() (*PacketConn, error) {
+	network := n.net
+	network.mu.Lock()
+	defer network.mu.Unlock()
+
+	if network.closed {
+		return nil, errClosed
+	}
+	if n.udp != nil {
+		return nil, errAlreadyListening
+	}
+	n.udp = &PacketConn{
+		node:   n,
+		addr:   netip.AddrPortFrom(n.ip, simPort),
+		in:     make(chan packet, packetQueueSize),
+		closed: make(chan struct{}),
+	}
+	network.byAddr[n.udp.addr] = n.udp
+	return n.udp, nil
+}
+
+// ListenV5 runs discovery v5 on the UDP socket of the node. The private key,
+// clock and local node of the config are set to the ones of the node.
+func (n *Node) ListenV5(cfg discover.Config) (*discover.UDPv5, error) {
+	conn, err := n.ListenUDP()
+	if err != nil {
+		return nil, err
+	}
+	cfg.PrivateKey = n.Server.PrivateKey
+	cfg.Clock = n.net.clock
+	disc, err := discover.ListenV5(conn, n.Server.LocalNode(), cfg)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return disc, nil
+}
+
+// ReadFromUDPAddrPort blocks until a packet is received. A received packet counts
+// as pending in the network until the next call.
+func (c *PacketConn) ReadFromUDPAddrPort(b []byte) (int, netip.AddrPort, error) {
+	if c.unread {
+		c.node.net.pending.Add(-1)
+		c.unread = false
+	}
+	select {
+	case p := <-c.in:
+		c.unread = true
+		return copy(b, p.data), p.from, nil
+	case <-c.closed:
+		return 0, netip.AddrPort{}, net.ErrClosed
+	}
+}
+
+// WriteToUDPAddrPort sends a packet. Packets to unknown addresses are dropped.
+func (c *PacketConn) WriteToUDPAddrPort(b []byte, addr netip.AddrPort) (int, error) {
+	select {
+	case <-c.closed:
+		return 0, net.ErrClosed
+	default:
+	}
+	network := c.node.net
+	network.mu.Lock()
+	dest := network.byAddr[addr]
+	network.mu.Unlock()
+	if dest == nil {
+		return len(b), nil
+	}
+	cfg := network.LinkConfig(c.node, dest.node)
+	if network.lost(cfg) {
+		return len(b), nil
+	}
+	p := packet{from: c.addr, data: append([]byte(nil), b...)}
+	network.clock.AfterFunc(cfg.Latency, func() { dest.deliver(p) })
+	return len(b), nil
+}
+
+// deliver queues a packet for reading. It is dropped if the queue is full.
+func (c *PacketConn) deliver(p packet) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.isClosed {
+		return
+	}
+	c.node.net.pending.Add(1)
+	select {
+	case c.in <- p:
+	default:
+		c.node.net.pending.Add(-1)
+	}
+}
+
+// Close closes the socket.
+func (c *PacketConn) Close() error {
+	c.closeOnce.Do(func() {
+		network := c.node.net
+		network.mu.Lock()
+		delete(network.byAddr, c.addr)
+		network.mu.Unlock()
+		close(c.closed)
+
+		// Drop the accounting of the packets which will never be read.
+		c.mu.Lock()
+		c.isClosed = true
+		for drained := false; !drained; {
+			select {
+			case <-c.in:
+				network.pending.Add(-1)
+			default:
+				drained = true
+			}
+		}
+		c.mu.Unlock()
+	})
+	return nil
+}
+
+// LocalAddr returns the simulated address of the socket.
+func (c *PacketConn) LocalAddr() net.Addr {
+	return net.UDPAddrFromAddrPort(c.addr)
+}
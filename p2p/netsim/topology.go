@@ -0,0 +1,95 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package netsim
+
+import (
+	"math/rand"
+)
+
+// Topology returns the connections of a network of n nodes as pairs of node
+// indices. The first node of every pair dials the second one.
+type Topology func(n int) [][2]int
+
+// Chain connects every node to the next one.
+func Chain(n int) [][2]int {
+	var links [][2]int
+	for i := 0; i < n-1; i++ {
+		links = append(links, [2]int{i, i + 1})
+	}
+	return links
+}
+
+// Ring is a chain which also connects the last node to the first one.
+func Ring(n int) [][2]int {
+	links := Chain(n)
+	if n > 2 {
+		links = append(links, [2]int{n - 1, 0})
+	}
+	return links
+}
+
+// Star connects all nodes to the first node.
+func Star(n int) [][2]int {
+	var links [][2]int
+	for i := 1; i < n; i++ {
+		links = append(links, [2]int{i, 0})
+	}
+	return links
+}
+
+// FullMesh connects every node to all other nodes.
+func FullMesh(n int) [][2]int {
+	var links [][2]int
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			links = append(links, [2]int{i, j})
+		}
+	}
+	return links
+}
+
+// Random returns a connected random topology in which every node dials about
+// degree other nodes. The same seed always yields the same topology.
+func Random(degree int, seed int64) Topology {
+	return func(n int) [][2]int {
+		var (
+			rng   = rand.New(rand.NewSource(seed))
+			seen  = make(map[[2]int]bool)
+			links [][2]int
+		)
+		add := func(a, b int) bool {
+			if a == b || seen[[2]int{a, b}] || seen[[2]int{b, a}] {
+				return false
+			}
+			seen[[2]int{a, b}] = true
+			links = append(links, [2]int{a, b})
+			return true
+		}
+		// A random spanning tree keeps the network connected.
+		for i := 1; i < n; i++ {
+			add(i, rng.Intn(i))
+		}
+		for i := 0; i < n && n > degree; i++ {
+			for d, tries := 1, 0; d < degree && tries < 4*n; tries++ {
+				if add(i, rng.Intn(n)) {
+					d++
+				}
+			}
+		}
+		return links
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package netsim
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTopologies(t *testing.T) {
+	tests := []struct {
+		name string
+		topo Topology
+		want [][2]int
+	}{
+		{"chain", Chain, [][2]int{{0, 1}, {1, 2}, {2, 3}}},
+		{"ring", Ring, [][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 0}}},
+		{"star", Star, [][2]int{{1, 0}, {2, 0}, {3, 0}}},
+		{"mesh", FullMesh, [][2]int{{0, 1}, {0, 2}, {0, 3}, {1, 2}, {1, 3}, {2, 3}}},
+	}
+	for _, test := range tests {
+		if links := test.topo(4); !reflect.DeepEqual(links, test.want) {
+			t.Errorf("%s: wrong links %v, want %v", test.name, links, test.want)
+		}
+	}
+}
+
+func TestRandomTopology(t *testing.T) {
+	const n = 50
+	links := Random(4, 1)(n)
+	if !reflect.DeepEqual(links, Random(4, 1)(n)) {
+		t.Fatal("same seed yields different topologies")
+	}
+
+	// All nodes are reachable from the first one.
+	adj := make(map[int][]int)
+	for _, l := range links {
+		if l[0] == l[1] {
+			t.Fatalf("self link %v", l)
+		}
+		adj[l[0]] = append(adj[l[0]], l[1])
+		adj[l[1]] = append(adj[l[1]], l[0])
+	}
+	seen := map[int]bool{0: true}
+	for queue := []int{0}; len(queue) > 0; queue = queue[1:] {
+		for _, next := range adj[queue[0]] {
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	if len(seen) != n {
+		t.Fatalf("only %d of %d nodes reachable", len(seen), n)
+	}
+	if len(links) < n*3 || len(links) > n*4 {
+		t.Errorf("wrong number of links %d", len(links))
+	}
+}
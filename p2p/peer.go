@@ -23,6 +23,7 @@ import (
 	"net"
 	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common/mclock"
@@ -118,6 +119,15 @@ type Peer struct {
 	// events receives message send / receive events if set
 	events   *event.Feed
 	testPipe *MsgPipeRW // for testing
+
+	// rep maintains the reputation of the peer if set
+	rep *reputationStore
+
+	// bw enforces the bandwidth limits of the peer if set
+	bw *peerBandwidth
+
+	// pending counts the messages being processed by protocol handlers if set
+	pending *atomic.Int64
 }
 
 // NewPeer returns a peer for testing purposes.
@@ -248,6 +258,33 @@ func (p *Peer) Lifetime() mclock.AbsTime {
 	return mclock.Now() - p.created
 }
 
+// Reputation returns the current reputation score of the peer. Peers start out
+// with a neutral score of zero, which is adjusted by reported events and decays
+// back towards zero over time.
+func (p *Peer) Reputation() float64 {
+	if p.rep == nil {
+		return 0
+	}
+	return p.rep.score(p.ID())
+}
+
+// Report records a behaviour of the peer, adjusting its reputation.
+func (p *Peer) Report(ev ReputationEvent) {
+	if p.rep != nil {
+		p.rep.report(p.ID(), ev)
+	}
+}
+
+// BandwidthExhausted reports whether the outbound bandwidth budget available to the
+// given subprotocol of the peer is currently used up. Protocols may use this to
+// reduce the size of their responses.
+func (p *Peer) BandwidthExhausted(protocol string) bool {
+	if p.bw == nil {
+		return false
+	}
+	return p.bw.exhausted(protocol)
+}
+
 func newPeer(log log.Logger, conn *conn, protocols []Protocol) *Peer {
 	protomap := matchProtocols(protocols, conn.caps, conn)
 	p := &Peer{
@@ -322,6 +359,12 @@ loop:
 	close(p.closed)
 	p.rw.close(reason)
 	p.wg.Wait()
+
+	if !remoteRequested {
+		if ev, ok := disconnectReputation(reason); ok {
+			p.Report(ev)
+		}
+	}
 	return remoteRequested, err
 }
 
@@ -391,10 +434,16 @@ func (p *Peer) handle(msg Msg) error {
 			metrics.GetOrRegisterMeter(m, nil).Mark(int64(msg.meterSize))
 			metrics.GetOrRegisterMeter(m+"/packets", nil).Mark(1)
 		}
+		if p.pending != nil {
+			p.pending.Add(1)
+		}
 		select {
 		case proto.in <- msg:
 			return nil
 		case <-p.closed:
+			if p.pending != nil {
+				p.pending.Add(-1)
+			}
 			return io.EOF
 		}
 	}
@@ -465,6 +514,8 @@ func (p *Peer) startProtocols(writeStart <-chan struct{}, writeErr chan<- error)
 		proto.closed = p.closed
 		proto.wstart = writeStart
 		proto.werr = writeErr
+		proto.bw = p.bw
+		proto.pending = p.pending
 		var rw MsgReadWriter = proto
 		if p.events != nil {
 			rw = newMsgEventer(rw, p.events, p.ID(), proto.Name, p.Info().Network.RemoteAddress, p.Info().Network.LocalAddress)
@@ -473,6 +524,7 @@ func (p *Peer) startProtocols(writeStart <-chan struct{}, writeErr chan<- error)
 		go func() {
 			defer p.wg.Done()
 			err := proto.Run(p, rw)
+			proto.release()
 			if err == nil {
 				p.log.Trace(fmt.Sprintf("Protocol %s/%d returned", proto.Name, proto.Version))
 				err = errProtocolReturned
@@ -503,6 +555,10 @@ type protoRW struct {
 	werr   chan<- error    // for write results
 	offset uint64
 	w      MsgWriter
+	bw     *peerBandwidth // bandwidth limits, nil if unlimited
+
+	pending *atomic.Int64 // counter of messages being processed, nil if untracked
+	held    uint32        // set (atomically) while the handler holds a counted message
 }
 
 func (rw *protoRW) WriteMsg(msg Msg) (err error) {
@@ -514,6 +570,11 @@ func (rw *protoRW) WriteMsg(msg Msg) (err error) {
 
 	msg.Code += rw.offset
 
+	// Wait for the bandwidth budget before taking the write slot, so other
+	// protocols of the peer aren't blocked by this one.
+	if rw.bw != nil && !rw.bw.wait(rw.Name, true, msg.Size, rw.closed) {
+		return ErrShuttingDown
+	}
 	select {
 	case <-rw.wstart:
 		err = rw.w.WriteMsg(msg)
@@ -529,8 +590,24 @@ func (rw *protoRW) WriteMsg(msg Msg) (err error) {
 }
 
 func (rw *protoRW) ReadMsg() (Msg, error) {
+	// Reading the next message means the handler is done with the last one.
+	rw.release()
 	select {
 	case msg := <-rw.in:
+		if rw.pending != nil {
+			atomic.StoreUint32(&rw.held, 1)
+		}
+		// Wait for the ingress budget on behalf of the protocol instead of in
+		// the read loop, so the read loop can deliver messages of other protocols
+		// while this one is delayed. This only covers a single message: the read
+		// loop hands messages over without buffering, so the next message of the
+		// throttled protocol blocks it until the handler reads again, holding up
+		// all protocols of the peer behind it.
+		if rw.bw != nil && !rw.bw.wait(rw.Name, false, msg.Size, rw.closed) {
+			rw.release()
+			msg.Discard()
+			return Msg{}, io.EOF
+		}
 		msg.Code -= rw.offset
 		return msg, nil
 	case <-rw.closed:
@@ -538,6 +615,13 @@ func (rw *protoRW) ReadMsg() (Msg, error) {
 	}
 }
 
+// release marks the message held by the protocol handler as processed.
+func (rw *protoRW) release() {
+	if atomic.SwapUint32(&rw.held, 0) == 1 {
+		rw.pending.Add(-1)
+	}
+}
+
 // PeerInfo represents a short summary of the information known about a connected
 // peer. Sub-protocol independent fields are contained and initialized here, with
 // protocol specifics delegated to all connected sub-protocols.
@@ -554,7 +638,8 @@ type PeerInfo struct {
 		Trusted       bool   `json:"trusted"`
 		Static        bool   `json:"static"`
 	} `json:"network"`
-	Protocols map[string]interface{} `json:"protocols"` // Sub-protocol specific metadata fields
+	Reputation float64                `json:"reputation"` // Reputation score of the peer
+	Protocols  map[string]interface{} `json:"protocols"`  // Sub-protocol specific metadata fields
 }
 
 // Info gathers and returns a collection of metadata known about a peer.
@@ -566,11 +651,12 @@ func (p *Peer) Info() *PeerInfo {
 	}
 	// Assemble the generic peer metadata
 	info := &PeerInfo{
-		Enode:     p.Node().URLv4(),
-		ID:        p.ID().String(),
-		Name:      p.Fullname(),
-		Caps:      caps,
-		Protocols: make(map[string]interface{}, len(p.running)),
+		Enode:      p.Node().URLv4(),
+		ID:         p.ID().String(),
+		Name:       p.Fullname(),
+		Caps:       caps,
+		Reputation: p.Reputation(),
+		Protocols:  make(map[string]interface{}, len(p.running)),
 	}
 	if p.Node().Seq() > 0 {
 		info.ENR = p.Node().String()
@@ -86,6 +86,10 @@ func newNode(id enode.ID, addr string) *enode.Node {
 }
 
 func testPeer(protos []Protocol) (func(), *conn, *Peer, <-chan error) {
+	return testPeerWithBandwidth(protos, nil)
+}
+
+func testPeerWithBandwidth(protos []Protocol, bw *peerBandwidth) (func(), *conn, *Peer, <-chan error) {
 	var (
 		fd1, fd2   = net.Pipe()
 		key1, key2 = newkey(), newkey()
@@ -101,6 +105,7 @@ func testPeer(protos []Protocol) (func(), *conn, *Peer, <-chan error) {
 	}
 
 	peer := newPeer(log.Root(), c1, protos)
+	peer.bw = bw
 	errc := make(chan error, 1)
 	go func() {
 		_, err := peer.run()
@@ -0,0 +1,94 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+const (
+	// Dialed peers connected for at least this long are stored in the peer set
+	// on shutdown.
+	peerSetMinDuration = 5 * time.Minute
+
+	// Peer sets older than this are not used after a restart.
+	peerSetMaxAge = 24 * time.Hour
+)
+
+// savePeerSet stores the long-lived dialed peers in the node database. They are
+// dialed first when the server is started again.
+//
+// Inbound peers are not stored because their listening endpoint is unknown, and
+// static peers are dialed on startup anyway.
+func (srv *Server) savePeerSet(peers map[enode.ID]*Peer) {
+	var (
+		now     = mclock.Now()
+		entries []enode.PeerEntry
+	)
+	for _, p := range peers {
+		duration := time.Duration(now - p.created)
+		if !p.rw.is(dynDialedConn) || duration < peerSetMinDuration {
+			continue
+		}
+		caps := make([]string, len(p.Caps()))
+		for i, c := range p.Caps() {
+			caps[i] = c.String()
+		}
+		entries = append(entries, enode.PeerEntry{
+			Node:     p.Node(),
+			Caps:     caps,
+			Duration: duration,
+			Stored:   time.Now(),
+		})
+	}
+	if err := srv.nodedb.StorePeerSet(entries); err != nil {
+		srv.log.Warn("Failed to store peer set", "err", err)
+		return
+	}
+	srv.log.Debug("Stored peer set", "peers", len(entries))
+}
+
+// loadPeerSet returns the nodes of the stored peer set which still support any
+// of our protocols, longest connected first.
+func (srv *Server) loadPeerSet() []*enode.Node {
+	var (
+		ours  = make([]string, len(srv.ourHandshake.Caps))
+		nodes []*enode.Node
+	)
+	for i, c := range srv.ourHandshake.Caps {
+		ours[i] = c.String()
+	}
+	for _, p := range srv.nodedb.PeerSet() {
+		if time.Since(p.Stored) > peerSetMaxAge {
+			continue
+		}
+		if !slices.ContainsFunc(p.Caps, func(c string) bool { return slices.Contains(ours, c) }) {
+			continue
+		}
+		// Discovery may have found a newer record of the node in the meantime.
+		node := p.Node
+		if n := srv.nodedb.Resolve(node); n != nil {
+			node = n
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
@@ -0,0 +1,74 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+func TestPeerSet(t *testing.T) {
+	db, _ := enode.OpenDB("")
+	defer db.Close()
+	srv := &Server{
+		nodedb:       db,
+		log:          log.Root(),
+		ourHandshake: &protoHandshake{Caps: []Cap{{"eth", 68}, {"snap", 1}}},
+	}
+
+	newTestPeer := func(id enode.ID, flags connFlag, duration time.Duration, caps ...Cap) *Peer {
+		p := NewPeer(id, "test", caps)
+		p.rw.flags = flags
+		p.created = mclock.Now() - mclock.AbsTime(duration)
+		return p
+	}
+	var (
+		longLived = newTestPeer(enode.ID{1}, dynDialedConn, time.Hour, Cap{"eth", 68})
+		longest   = newTestPeer(enode.ID{2}, dynDialedConn, 2*time.Hour, Cap{"eth", 68}, Cap{"snap", 1})
+		peers     = map[enode.ID]*Peer{
+			longLived.ID(): longLived,
+			longest.ID():   longest,
+			{3}:            newTestPeer(enode.ID{3}, dynDialedConn, time.Minute, Cap{"eth", 68}),  // too short
+			{4}:            newTestPeer(enode.ID{4}, inboundConn, time.Hour, Cap{"eth", 68}),      // inbound
+			{5}:            newTestPeer(enode.ID{5}, staticDialedConn, time.Hour, Cap{"eth", 68}), // static
+			{6}:            newTestPeer(enode.ID{6}, dynDialedConn, time.Hour, Cap{"other", 1}),   // no shared protocol
+		}
+	)
+	srv.savePeerSet(peers)
+
+	nodes := srv.loadPeerSet()
+	if len(nodes) != 2 {
+		t.Fatalf("wrong number of nodes loaded: %d", len(nodes))
+	}
+	if nodes[0].ID() != longest.ID() || nodes[1].ID() != longLived.ID() {
+		t.Fatalf("wrong nodes loaded: %v, %v", nodes[0].ID(), nodes[1].ID())
+	}
+
+	// Outdated peer sets are ignored.
+	db.StorePeerSet([]enode.PeerEntry{{
+		Node:   longest.Node(),
+		Caps:   []string{"eth/68"},
+		Stored: time.Now().Add(-peerSetMaxAge - time.Minute),
+	}})
+	if nodes := srv.loadPeerSet(); len(nodes) != 0 {
+		t.Fatalf("outdated peer set loaded: %v", nodes)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pipes
+
+import (
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// packetQueueSize is the number of packets buffered by each end of a PacketPipe.
+// Further packets are dropped, just like a real UDP socket would.
+const packetQueueSize = 1024
+
+// PacketPipe creates an in process datagram pipe. Packets written to one end are
+// delivered to the other end regardless of the destination address. The ends have
+// distinct localhost UDP addresses.
+func PacketPipe() (net.PacketConn, net.PacketConn) {
+	a := newPacketConn(&net.UDPAddr{IP: net.IP{127, 0, 0, 1}, Port: 1})
+	b := newPacketConn(&net.UDPAddr{IP: net.IP{127, 0, 0, 1}, Port: 2})
+	a.peer, b.peer = b, a
+	return a, b
+}
+
+type packetConn struct {
+	addr      *net.UDPAddr
+	peer      *packetConn
+	in        chan []byte
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	mu           sync.Mutex
+	readDeadline time.Time
+	deadlineSet  chan struct{} // closed when the read deadline changes
+}
+
+func newPacketConn(addr *net.UDPAddr) *packetConn {
+	return &packetConn{
+		addr:        addr,
+		in:          make(chan []byte, packetQueueSize),
+		closed:      make(chan struct{}),
+		deadlineSet: make(chan struct{}),
+	}
+}
+
+func (c *packetConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	for {
+		c.mu.Lock()
+		deadline, deadlineSet := c.readDeadline, c.deadlineSet
+		c.mu.Unlock()
+
+		var (
+			timer   *time.Timer
+			timeout <-chan time.Time
+		)
+		if !deadline.IsZero() {
+			d := time.Until(deadline)
+			if d <= 0 {
+				return 0, nil, os.ErrDeadlineExceeded
+			}
+			timer = time.NewTimer(d)
+			timeout = timer.C
+		}
+		select {
+		case p := <-c.in:
+			stopTimer(timer)
+			return copy(b, p), c.peer.addr, nil
+		case <-c.closed:
+			stopTimer(timer)
+			return 0, nil, net.ErrClosed
+		case <-timeout:
+			return 0, nil, os.ErrDeadlineExceeded
+		case <-deadlineSet:
+			stopTimer(timer)
+		}
+	}
+}
+
+func stopTimer(t *time.Timer) {
+	if t != nil {
+		t.Stop()
+	}
+}
+
+func (c *packetConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	select {
+	case <-c.closed:
+		return 0, net.ErrClosed
+	default:
+	}
+	select {
+	case c.peer.in <- append([]byte(nil), b...):
+	default:
+	}
+	return len(b), nil
+}
+
+func (c *packetConn) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	return nil
+}
+
+func (c *packetConn) LocalAddr() net.Addr {
+	return c.addr
+}
+
+func (c *packetConn) SetDeadline(t time.Time) error {
+	return c.SetReadDeadline(t)
+}
+
+func (c *packetConn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.readDeadline = t
+	close(c.deadlineSet)
+	c.deadlineSet = make(chan struct{})
+	return nil
+}
+
+// SetWriteDeadline does nothing because writes never block.
+func (c *packetConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+// SetReadBuffer and SetWriteBuffer exist to keep QUIC implementations from
+// complaining about the buffer size.
+func (c *packetConn) SetReadBuffer(int) error  { return nil }
+func (c *packetConn) SetWriteBuffer(int) error { return nil }
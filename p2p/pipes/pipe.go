@@ -18,6 +18,12 @@ package pipes
 
 import "net"
 
+// NetPipe wraps net.Pipe in a signature returning an error.
+func NetPipe() (net.Conn, net.Conn, error) {
+	p1, p2 := net.Pipe()
+	return p1, p2, nil
+}
+
 // TCPPipe creates an in process full duplex pipe based on a localhost TCP socket.
 func TCPPipe() (net.Conn, net.Conn, error) {
 	l, err := net.Listen("tcp", "127.0.0.1:0")
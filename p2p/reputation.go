@@ -0,0 +1,255 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+const (
+	// Reputation scores are clamped into this range.
+	minReputation = -100
+	maxReputation = 100
+
+	// Time after which a reputation score decays to half its value, allowing
+	// nodes to recover from past misbehaviour and stale merits to fade.
+	reputationHalfLife = 24 * time.Hour
+
+	// Nodes with a reputation below this threshold are not dialed.
+	dialReputationThreshold = -50
+
+	// Nodes with a reputation above this threshold are dialed before any
+	// other discovered nodes.
+	preferredReputationThreshold = 20
+)
+
+// ReputationEvent is a behaviour of a remote peer affecting its reputation.
+type ReputationEvent int
+
+const (
+	ReputationResponse          ReputationEvent = iota // Request served in time
+	ReputationTimeout                                  // Request not served in time
+	ReputationValidAnnouncement                        // Announced useful data, e.g. valid transactions
+	ReputationInvalidData                              // Delivered invalid or useless data
+	ReputationProtocolError                            // Disconnected due to a protocol violation
+	ReputationNetworkError                             // Disconnected due to a network failure
+)
+
+// reputationWeights are the score adjustments of the reputation events.
+var reputationWeights = [...]float64{
+	ReputationResponse:          0.5,
+	ReputationTimeout:           -5,
+	ReputationValidAnnouncement: 0.2,
+	ReputationInvalidData:       -25,
+	ReputationProtocolError:     -20,
+	ReputationNetworkError:      -2,
+}
+
+// String implements fmt.Stringer.
+func (ev ReputationEvent) String() string {
+	switch ev {
+	case ReputationResponse:
+		return "response"
+	case ReputationTimeout:
+		return "timeout"
+	case ReputationValidAnnouncement:
+		return "valid announcement"
+	case ReputationInvalidData:
+		return "invalid data"
+	case ReputationProtocolError:
+		return "protocol error"
+	case ReputationNetworkError:
+		return "network error"
+	default:
+		return "unknown"
+	}
+}
+
+// reputation is the in-memory reputation of a connected peer.
+type reputation struct {
+	score   float64
+	updated mclock.AbsTime
+}
+
+// decayReputation decays the score by the time elapsed since its last update.
+func decayReputation(score float64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return score
+	}
+	return score * math.Exp2(-float64(elapsed)/float64(reputationHalfLife))
+}
+
+// reputationStore maintains the reputation scores of remote nodes. The scores
+// of connected peers are kept in memory and written to the node database when
+// the peer disconnects, everything else is accessed in the database directly.
+//
+// All scores are decayed using the configured clock. The database holds wall
+// clock timestamps, which are derived from the clock relative to the time the
+// store was created.
+type reputationStore struct {
+	db        *enode.DB
+	clock     mclock.Clock
+	wallStart time.Time      // wall clock time at creation
+	clockBase mclock.AbsTime // clock reading at creation
+
+	lock   sync.Mutex
+	active map[enode.ID]*reputation
+}
+
+func newReputationStore(db *enode.DB, clock mclock.Clock) *reputationStore {
+	return &reputationStore{
+		db:        db,
+		clock:     clock,
+		wallStart: time.Now(),
+		clockBase: clock.Now(),
+		active:    make(map[enode.ID]*reputation),
+	}
+}
+
+// now returns the wall clock time according to the store's clock.
+func (s *reputationStore) now() time.Time {
+	return s.wallStart.Add(time.Duration(s.clock.Now() - s.clockBase))
+}
+
+// load retrieves the decayed reputation score of a node from the database.
+func (s *reputationStore) load(id enode.ID) float64 {
+	rep := s.db.Reputation(id)
+	if rep.Updated.IsZero() {
+		return 0
+	}
+	return decayReputation(rep.Score, s.now().Sub(rep.Updated))
+}
+
+// store writes the reputation score of a node into the database.
+func (s *reputationStore) store(id enode.ID, score float64) {
+	s.db.UpdateReputation(id, enode.Reputation{Score: score, Updated: s.now()})
+}
+
+// score returns the current reputation score of a node.
+func (s *reputationStore) score(id enode.ID) float64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if rep := s.active[id]; rep != nil {
+		return decayReputation(rep.score, time.Duration(s.clock.Now()-rep.updated))
+	}
+	return s.load(id)
+}
+
+// report adjusts the reputation score of a node with the weight of the event.
+func (s *reputationStore) report(id enode.ID, ev ReputationEvent) {
+	if int(ev) >= len(reputationWeights) {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	rep := s.active[id]
+	if rep == nil {
+		// The peer is not connected (anymore), update the database directly
+		s.store(id, clampReputation(s.load(id)+reputationWeights[ev]))
+		return
+	}
+	now := s.clock.Now()
+	rep.score = clampReputation(decayReputation(rep.score, time.Duration(now-rep.updated)) + reputationWeights[ev])
+	rep.updated = now
+}
+
+// connected loads the reputation of a newly connected peer into memory.
+func (s *reputationStore) connected(id enode.ID) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.active[id] = &reputation{score: s.load(id), updated: s.clock.Now()}
+}
+
+// disconnected writes the reputation of a disconnected peer into the database.
+// The node record is also retained for dialed peers, allowing them to be
+// preferred when dialing later.
+func (s *reputationStore) disconnected(c *conn) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	id := c.node.ID()
+	rep := s.active[id]
+	if rep == nil {
+		return
+	}
+	delete(s.active, id)
+
+	score := decayReputation(rep.score, time.Duration(s.clock.Now()-rep.updated))
+	s.store(id, score)
+	if c.is(dynDialedConn) && score > 0 {
+		s.db.UpdateNode(c.node)
+	}
+}
+
+// preferred returns at most n known nodes with the highest reputation scores
+// above preferredReputationThreshold.
+func (s *reputationStore) preferred(n int) []*enode.Node {
+	type candidate struct {
+		id    enode.ID
+		score float64
+	}
+	var (
+		now        = s.now()
+		candidates []candidate
+	)
+	for id, rep := range s.db.Reputations() {
+		score := decayReputation(rep.Score, now.Sub(rep.Updated))
+		if score >= preferredReputationThreshold {
+			candidates = append(candidates, candidate{id, score})
+		}
+	}
+	slices.SortFunc(candidates, func(a, b candidate) int {
+		return cmp.Compare(b.score, a.score)
+	})
+	var nodes []*enode.Node
+	for _, c := range candidates {
+		if len(nodes) >= n {
+			break
+		}
+		if node := s.db.Node(c.id); node != nil {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+func clampReputation(score float64) float64 {
+	return max(minReputation, min(maxReputation, score))
+}
+
+// disconnectReputation returns the reputation event corresponding to the
+// reason of a locally initiated disconnect, if any.
+func disconnectReputation(reason DiscReason) (ReputationEvent, bool) {
+	switch reason {
+	case DiscProtocolError, DiscSubprotocolError:
+		return ReputationProtocolError, true
+	case DiscNetworkError:
+		return ReputationNetworkError, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+func TestReputationStore(t *testing.T) {
+	db, _ := enode.OpenDB("")
+	defer db.Close()
+
+	var (
+		clock = new(mclock.Simulated)
+		store = newReputationStore(db, clock)
+		good  = &conn{flags: dynDialedConn, node: newNode(uintID(0x01), "127.0.0.1:30303")}
+		bad   = &conn{flags: inboundConn, node: newNode(uintID(0x02), "127.0.0.2:30303")}
+	)
+	store.connected(good.node.ID())
+	store.connected(bad.node.ID())
+
+	for i := 0; i < 60; i++ {
+		store.report(good.node.ID(), ReputationResponse)
+	}
+	store.report(bad.node.ID(), ReputationInvalidData)
+	store.report(bad.node.ID(), ReputationInvalidData)
+
+	if score := store.score(good.node.ID()); score != 30 {
+		t.Fatalf("good peer score mismatch: have %v, want 30", score)
+	}
+	if score := store.score(bad.node.ID()); score != -50 {
+		t.Fatalf("bad peer score mismatch: have %v, want -50", score)
+	}
+	// Scores decay towards neutral over time.
+	clock.Run(reputationHalfLife)
+	if score := store.score(bad.node.ID()); math.Abs(score+25) > 1e-9 {
+		t.Fatalf("decayed score mismatch: have %v, want -25", score)
+	}
+	// Scores are clamped into the allowed range.
+	for i := 0; i < 10; i++ {
+		store.report(bad.node.ID(), ReputationInvalidData)
+	}
+	if score := store.score(bad.node.ID()); score != minReputation {
+		t.Fatalf("clamped score mismatch: have %v, want %v", score, minReputation)
+	}
+	// Scores are persisted on disconnect and reloaded on reconnect.
+	store.disconnected(good)
+	store.disconnected(bad)
+	if len(store.active) != 0 {
+		t.Fatalf("disconnected peers still tracked: %d", len(store.active))
+	}
+	if score := store.score(bad.node.ID()); math.Abs(score-minReputation) > 1e-3 {
+		t.Fatalf("persisted score mismatch: have %v, want %v", score, minReputation)
+	}
+	store.connected(good.node.ID())
+	if score := store.score(good.node.ID()); math.Abs(score-15) > 1e-3 {
+		t.Fatalf("reloaded score mismatch: have %v, want 15", score)
+	}
+	// Events reported after disconnect update the database directly.
+	store.disconnected(good)
+	store.report(good.node.ID(), ReputationTimeout)
+	if score := store.score(good.node.ID()); math.Abs(score-10) > 1e-3 {
+		t.Fatalf("late event score mismatch: have %v, want 10", score)
+	}
+	// Persisted scores decay with the same clock as active ones.
+	clock.Run(reputationHalfLife)
+	if score := store.score(good.node.ID()); math.Abs(score-5) > 1e-3 {
+		t.Fatalf("decayed persisted score mismatch: have %v, want 5", score)
+	}
+}
+
+func TestReputationPreferred(t *testing.T) {
+	db, _ := enode.OpenDB("")
+	defer db.Close()
+
+	var (
+		store   = newReputationStore(db, new(mclock.Simulated))
+		now     = time.Now()
+		dialed  = newNode(uintID(0x01), "127.0.0.1:30303")
+		better  = newNode(uintID(0x02), "127.0.0.2:30303")
+		low     = newNode(uintID(0x03), "127.0.0.3:30303")
+		decayed = newNode(uintID(0x04), "127.0.0.4:30303")
+		norec   = newNode(uintID(0x05), "127.0.0.5:30303")
+	)
+	for _, n := range []*enode.Node{dialed, better, low, decayed} {
+		db.UpdateNode(n)
+	}
+	db.UpdateReputation(dialed.ID(), enode.Reputation{Score: 40, Updated: now})
+	db.UpdateReputation(better.ID(), enode.Reputation{Score: 80, Updated: now})
+	db.UpdateReputation(low.ID(), enode.Reputation{Score: preferredReputationThreshold - 1, Updated: now})
+	db.UpdateReputation(decayed.ID(), enode.Reputation{Score: 30, Updated: now.Add(-reputationHalfLife)})
+	db.UpdateReputation(norec.ID(), enode.Reputation{Score: 90, Updated: now})
+
+	nodes := store.preferred(10)
+	if len(nodes) != 2 || nodes[0].ID() != better.ID() || nodes[1].ID() != dialed.ID() {
+		t.Fatalf("preferred nodes mismatch: %v", nodes)
+	}
+	if nodes := store.preferred(1); len(nodes) != 1 || nodes[0].ID() != better.ID() {
+		t.Fatalf("limited preferred nodes mismatch: %v", nodes)
+	}
+}
+
+func TestDisconnectReputation(t *testing.T) {
+	tests := []struct {
+		reason DiscReason
+		ev     ReputationEvent
+		ok     bool
+	}{
+		{DiscProtocolError, ReputationProtocolError, true},
+		{DiscSubprotocolError, ReputationProtocolError, true},
+		{DiscNetworkError, ReputationNetworkError, true},
+		{DiscUselessPeer, 0, false},
+		{DiscQuitting, 0, false},
+		{DiscTooManyPeers, 0, false},
+	}
+	for _, test := range tests {
+		ev, ok := disconnectReputation(test.reason)
+		if ev != test.ev || ok != test.ok {
+			t.Errorf("%v: have (%v, %v), want (%v, %v)", test.reason, ev, ok, test.ev, test.ok)
+		}
+	}
+}
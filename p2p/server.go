@@ -39,6 +39,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p/discover"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/ethereum/go-ethereum/p2p/nat"
 	"github.com/ethereum/go-ethereum/p2p/netutil"
 )
 
@@ -85,17 +86,21 @@ type Server struct {
 	running bool
 
 	listener     net.Listener
+	quic         *quicEndpoint
+	holepunch    *holePuncher
 	ourHandshake *protoHandshake
 	loopWG       sync.WaitGroup // loop, listenLoop
 	peerFeed     event.Feed
 	log          log.Logger
 
-	nodedb    *enode.DB
-	localnode *enode.LocalNode
-	discv4    *discover.UDPv4
-	discv5    *discover.UDPv5
-	discmix   *enode.FairMix
-	dialsched *dialScheduler
+	nodedb     *enode.DB
+	reputation *reputationStore
+	bandwidth  *bandwidthLimits
+	localnode  *enode.LocalNode
+	discv4     *discover.UDPv4
+	discv5     *discover.UDPv5
+	discmix    *enode.FairMix
+	dialsched  *dialScheduler
 
 	// This is read by the NAT port mapping loop.
 	portMappingRegister chan *portMapping
@@ -325,9 +330,18 @@ func (srv *Server) Stop() {
 		// this unblocks listener Accept
 		srv.listener.Close()
 	}
+	if srv.quic != nil {
+		srv.quic.closeListener()
+	}
 	close(srv.quit)
 	srv.lock.Unlock()
 	srv.loopWG.Wait()
+	if srv.holepunch != nil {
+		srv.holepunch.close()
+	}
+	if srv.quic != nil {
+		srv.quic.close()
+	}
 }
 
 // sharedUDPConn implements a shared connection. Write sends messages to the underlying connection while read returns
@@ -369,8 +383,8 @@ func (srv *Server) Start() (err error) {
 	if srv.log == nil {
 		srv.log = log.Root()
 	}
-	if srv.clock == nil {
-		srv.clock = mclock.System{}
+	if srv.Clock == nil {
+		srv.Clock = mclock.System{}
 	}
 	if srv.NoDial && srv.ListenAddr == "" {
 		srv.log.Warn("P2P server will be useless, neither dialing nor listening")
@@ -381,7 +395,7 @@ func (srv *Server) Start() (err error) {
 		return errors.New("Server.PrivateKey must be set to a non-nil key")
 	}
 	if srv.newTransport == nil {
-		srv.newTransport = newRLPX
+		srv.newTransport = newTransport
 	}
 	if srv.listenFunc == nil {
 		srv.listenFunc = net.Listen
@@ -394,6 +408,7 @@ func (srv *Server) Start() (err error) {
 	srv.removetrusted = make(chan *enode.Node)
 	srv.peerOp = make(chan peerOpFunc)
 	srv.peerOpDone = make(chan struct{})
+	srv.bandwidth = newBandwidthLimits(&srv.Config)
 
 	if err := srv.setupLocalNode(); err != nil {
 		return err
@@ -405,9 +420,19 @@ func (srv *Server) Start() (err error) {
 			return err
 		}
 	}
+	if srv.QUICListenAddr != "" {
+		if err := srv.setupQUICListening(); err != nil {
+			return err
+		}
+	}
 	if err := srv.setupDiscovery(); err != nil {
 		return err
 	}
+	if srv.HolePunching {
+		if err := srv.setupHolePunching(); err != nil {
+			return err
+		}
+	}
 	srv.setupDialScheduler()
 
 	srv.loopWG.Add(1)
@@ -430,6 +455,7 @@ func (srv *Server) setupLocalNode() error {
 		return err
 	}
 	srv.nodedb = db
+	srv.reputation = newReputationStore(db, srv.Clock)
 	srv.localnode = enode.NewLocalNode(db, srv.PrivateKey)
 	srv.localnode.SetFallbackIP(net.IP{127, 0, 0, 1})
 	// TODO: check conflicts
@@ -523,6 +549,14 @@ func (srv *Server) setupDiscovery() error {
 	return nil
 }
 
+func (srv *Server) setupHolePunching() error {
+	if srv.discv5 == nil {
+		return errors.New("hole punching requires discovery v5")
+	}
+	srv.holepunch = newHolePuncher(srv.discv5, srv.quic, srv.localnode.ID(), srv.Clock, srv.log)
+	return nil
+}
+
 func (srv *Server) setupDialScheduler() {
 	config := dialConfig{
 		self:           srv.localnode.ID(),
@@ -531,7 +565,10 @@ func (srv *Server) setupDialScheduler() {
 		log:            srv.Logger,
 		netRestrict:    srv.NetRestrict,
 		dialer:         srv.Dialer,
-		clock:          srv.clock,
+		clock:          srv.Clock,
+		reputation:     srv.reputation.score,
+		preferred:      srv.reputation.preferred(srv.MaxDialedConns()),
+		previous:       srv.loadPeerSet(),
 	}
 	if srv.discv4 != nil {
 		config.resolver = srv.discv4
@@ -539,6 +576,12 @@ func (srv *Server) setupDialScheduler() {
 	if config.dialer == nil {
 		config.dialer = tcpDialer{&net.Dialer{Timeout: defaultDialTimeout}}
 	}
+	if srv.quic != nil {
+		config.dialer = quicDialer{quic: srv.quic, fallback: config.dialer}
+		if srv.holepunch != nil {
+			config.holePunch = srv.holepunch
+		}
+	}
 	srv.dialsched = newDialScheduler(config, srv.discmix, srv.SetupConn)
 	for _, n := range srv.StaticNodes {
 		srv.dialsched.addStatic(n)
@@ -580,7 +623,7 @@ func (srv *Server) setupListening() error {
 		if !tcp.IP.IsLoopback() && !tcp.IP.IsPrivate() {
 			srv.portMappingRegister <- &portMapping{
 				protocol: "TCP",
-				name:     "ethereum p2p",
+				name:     tcpMappingName,
 				port:     tcp.Port,
 			}
 		}
@@ -591,6 +634,41 @@ func (srv *Server) setupListening() error {
 	return nil
 }
 
+func (srv *Server) setupQUICListening() error {
+	addr, err := net.ResolveUDPAddr("udp", srv.QUICListenAddr)
+	if err != nil {
+		return err
+	}
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return err
+	}
+	srv.quic, err = newQUICEndpoint(conn)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	// Announce the QUIC port and map it if NAT is configured. Without a static
+	// external IP, the node asks for hole punching until the port is mapped.
+	laddr := conn.LocalAddr().(*net.UDPAddr)
+	srv.localnode.Set(enr.DevP2PQUIC(laddr.Port))
+	if _, static := srv.NAT.(nat.ExtIP); srv.HolePunching && !static {
+		srv.localnode.Set(holePunchEntry(true))
+	}
+	if !laddr.IP.IsLoopback() && !laddr.IP.IsPrivate() {
+		srv.portMappingRegister <- &portMapping{
+			protocol: "UDP",
+			name:     quicMappingName,
+			port:     laddr.Port,
+		}
+	}
+
+	srv.loopWG.Add(1)
+	go srv.quicListenLoop()
+	return nil
+}
+
 func (srv *Server) setupUDPListening() (*net.UDPConn, error) {
 	listenAddr := srv.ListenAddr
 
@@ -613,7 +691,7 @@ func (srv *Server) setupUDPListening() (*net.UDPConn, error) {
 	if !laddr.IP.IsLoopback() && !laddr.IP.IsPrivate() {
 		srv.portMappingRegister <- &portMapping{
 			protocol: "UDP",
-			name:     "ethereum peer discovery",
+			name:     discMappingName,
 			port:     laddr.Port,
 		}
 	}
@@ -718,7 +796,8 @@ running:
 			// A peer disconnected.
 			d := common.PrettyDuration(mclock.Now() - pd.created)
 			delete(peers, pd.ID())
-			srv.log.Debug("Removing p2p peer", "peercount", len(peers), "id", pd.ID(), "duration", d, "req", pd.requested, "err", pd.err)
+			srv.log.Debug("Removing p2p peer", "peercount", len(peers), "id", pd.ID(), "duration", d, "req", pd.requested, "err", pd.err, "reputation", pd.Reputation())
+			srv.reputation.disconnected(pd.rw)
 			srv.dialsched.peerRemoved(pd.rw)
 			if pd.Inbound() {
 				inboundCount--
@@ -731,6 +810,7 @@ running:
 	}
 
 	srv.log.Trace("P2P networking is spinning down")
+	srv.savePeerSet(peers)
 
 	// Terminate discovery. If there is a running lookup it will terminate soon.
 	if srv.discv4 != nil {
@@ -750,6 +830,7 @@ running:
 		p := <-srv.delpeer
 		p.log.Trace("<-delpeer (spindown)")
 		delete(peers, p.ID())
+		srv.reputation.disconnected(p.rw)
 	}
 }
 
@@ -849,6 +930,55 @@ func (srv *Server) listenLoop() {
 	}
 }
 
+// quicListenLoop runs in its own goroutine and accepts inbound QUIC connections.
+func (srv *Server) quicListenLoop() {
+	srv.log.Debug("QUIC listener up", "addr", srv.quic.addr())
+
+	// The slots channel limits accepts of new connections.
+	tokens := defaultMaxPendingPeers
+	if srv.MaxPendingPeers > 0 {
+		tokens = srv.MaxPendingPeers
+	}
+	slots := make(chan struct{}, tokens)
+	for i := 0; i < tokens; i++ {
+		slots <- struct{}{}
+	}
+
+	// Wait for slots to be returned on exit.
+	defer srv.loopWG.Done()
+	defer func() {
+		for i := 0; i < cap(slots); i++ {
+			<-slots
+		}
+	}()
+
+	for {
+		// Wait for a free slot before accepting.
+		<-slots
+
+		qconn, err := srv.quic.accept()
+		if err != nil {
+			slots <- struct{}{}
+			return
+		}
+		remoteIP := netutil.AddrAddr(qconn.RemoteAddr())
+		if err := srv.checkInboundConn(remoteIP); err != nil {
+			srv.log.Debug("Rejected inbound QUIC connection", "addr", qconn.RemoteAddr(), "err", err)
+			qconn.CloseWithError(quicNoReason, "")
+			slots <- struct{}{}
+			continue
+		}
+		serveMeter.Mark(1)
+		srv.log.Trace("Accepted QUIC connection", "addr", qconn.RemoteAddr())
+		go func() {
+			if fd, err := srv.quic.acceptControl(qconn); err == nil {
+				srv.SetupConn(fd, inboundConn, nil)
+			}
+			slots <- struct{}{}
+		}()
+	}
+}
+
 func (srv *Server) checkInboundConn(remoteIP netip.Addr) error {
 	if !remoteIP.IsValid() {
 		// This case happens for internal test connections without remote address.
@@ -859,7 +989,7 @@ func (srv *Server) checkInboundConn(remoteIP netip.Addr) error {
 		return errors.New("not in netrestrict list")
 	}
 	// Reject Internet peers that try too often.
-	now := srv.clock.Now()
+	now := srv.Clock.Now()
 	srv.inboundHistory.expire(now, nil)
 	if !netutil.AddrIsLAN(remoteIP) && srv.inboundHistory.contains(remoteIP.String()) {
 		return errors.New("too many attempts")
@@ -956,9 +1086,13 @@ func (srv *Server) setupConn(c *conn, dialDest *enode.Node) error {
 func nodeFromConn(pubkey *ecdsa.PublicKey, conn net.Conn) *enode.Node {
 	var ip net.IP
 	var port int
-	if tcp, ok := conn.RemoteAddr().(*net.TCPAddr); ok {
-		ip = tcp.IP
-		port = tcp.Port
+	switch addr := conn.RemoteAddr().(type) {
+	case *net.TCPAddr:
+		ip = addr.IP
+		port = addr.Port
+	case *net.UDPAddr:
+		// QUIC connections don't tell the TCP port.
+		ip = addr.IP
 	}
 	return enode.NewV4(pubkey, ip, port, port)
 }
@@ -976,6 +1110,12 @@ func (srv *Server) checkpoint(c *conn, stage chan<- *conn) error {
 
 func (srv *Server) launchPeer(c *conn) *Peer {
 	p := newPeer(srv.log, c, srv.Protocols)
+	p.rep = srv.reputation
+	srv.reputation.connected(c.node.ID())
+	if srv.bandwidth != nil {
+		p.bw = srv.bandwidth.newPeer()
+	}
+	p.pending = srv.PendingMessages
 	if srv.EnableMsgEvents {
 		// If message events are enabled, pass the peerFeed
 		// to the peer.
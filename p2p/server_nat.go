@@ -34,6 +34,13 @@ const (
 	maxRetries             = 5 // max number of failed attempts to refresh the mapping
 )
 
+// Names of the port mappings, which also identify them in portMappingLoop.
+const (
+	tcpMappingName  = "ethereum p2p"
+	quicMappingName = "ethereum p2p quic"
+	discMappingName = "ethereum peer discovery"
+)
+
 type portMapping struct {
 	protocol string
 	name     string
@@ -48,11 +55,11 @@ type portMapping struct {
 // setupPortMapping starts the port mapping loop if necessary.
 // Note: this needs to be called after the LocalNode instance has been set on the server.
 func (srv *Server) setupPortMapping() {
-	// portMappingRegister will receive up to two values: one for the TCP port if
-	// listening is enabled, and one more for enabling UDP port mapping if discovery is
-	// enabled. We make it buffered to avoid blocking setup while a mapping request is in
-	// progress.
-	srv.portMappingRegister = make(chan *portMapping, 2)
+	// portMappingRegister will receive up to three values: one for the TCP port if
+	// listening is enabled, one for the UDP port of QUIC if it is enabled, and one more
+	// for enabling UDP port mapping if discovery is enabled. We make it buffered to avoid
+	// blocking setup while a mapping request is in progress.
+	srv.portMappingRegister = make(chan *portMapping, 3)
 
 	switch srv.NAT.(type) {
 	case nil:
@@ -84,7 +91,8 @@ func (srv *Server) consumePortMappingRequests() {
 	}
 }
 
-// portMappingLoop manages port mappings for UDP and TCP.
+// portMappingLoop manages port mappings for UDP and TCP. Mappings are identified
+// by their name, since both discovery and QUIC map a UDP port.
 func (srv *Server) portMappingLoop() {
 	defer srv.loopWG.Done()
 
@@ -93,12 +101,12 @@ func (srv *Server) portMappingLoop() {
 	}
 
 	var (
-		mappings  = make(map[string]*portMapping, 2)
-		refresh   = mclock.NewAlarm(srv.clock)
-		extip     = mclock.NewAlarm(srv.clock)
+		mappings  = make(map[string]*portMapping, 3)
+		refresh   = mclock.NewAlarm(srv.Clock)
+		extip     = mclock.NewAlarm(srv.Clock)
 		lastExtIP net.IP
 	)
-	extip.Schedule(srv.clock.Now())
+	extip.Schedule(srv.Clock.Now())
 	defer func() {
 		refresh.Stop()
 		extip.Stop()
@@ -122,7 +130,7 @@ func (srv *Server) portMappingLoop() {
 			return
 
 		case <-extip.C():
-			extip.Schedule(srv.clock.Now().Add(extipRetryInterval))
+			extip.Schedule(srv.Clock.Now().Add(extipRetryInterval))
 			ip, err := srv.NAT.ExternalIP()
 			if err != nil {
 				log.Debug("Couldn't get external IP", "err", err, "interface", srv.NAT)
@@ -136,19 +144,19 @@ func (srv *Server) portMappingLoop() {
 			srv.localnode.SetStaticIP(ip)
 			// Ensure port mappings are refreshed in case we have moved to a new network.
 			for _, m := range mappings {
-				m.nextTime = srv.clock.Now()
+				m.nextTime = srv.Clock.Now()
 			}
 
 		case m := <-srv.portMappingRegister:
 			if m.protocol != "TCP" && m.protocol != "UDP" {
 				panic("unknown NAT protocol name: " + m.protocol)
 			}
-			mappings[m.protocol] = m
-			m.nextTime = srv.clock.Now()
+			mappings[m.name] = m
+			m.nextTime = srv.Clock.Now()
 
 		case <-refresh.C():
 			for _, m := range mappings {
-				if srv.clock.Now() < m.nextTime {
+				if srv.Clock.Now() < m.nextTime {
 					continue
 				}
 
@@ -172,9 +180,13 @@ func (srv *Server) portMappingLoop() {
 							err := srv.NAT.DeleteMapping(m.protocol, m.extPort, m.port)
 							log.Debug("Couldn't refresh port mapping, trying to delete it:", "err", err)
 							m.extPort = 0
+							if m.name == quicMappingName && srv.HolePunching {
+								// The QUIC port is unreachable again without the mapping.
+								srv.localnode.Set(holePunchEntry(true))
+							}
 						}
 					}
-					m.nextTime = srv.clock.Now().Add(portMapRetryInterval)
+					m.nextTime = srv.Clock.Now().Add(portMapRetryInterval)
 					// Note ENR is not updated here, i.e. we keep the last port.
 					continue
 				}
@@ -191,14 +203,17 @@ func (srv *Server) portMappingLoop() {
 					}
 
 					// Update port in local ENR.
-					switch m.protocol {
-					case "TCP":
+					switch {
+					case m.protocol == "TCP":
 						srv.localnode.Set(enr.TCP(m.extPort))
-					case "UDP":
+					case m.name == quicMappingName:
+						srv.localnode.Set(enr.DevP2PQUIC(m.extPort))
+						srv.localnode.Delete(holePunchEntry(false))
+					default:
 						srv.localnode.SetFallbackUDP(m.extPort)
 					}
 				}
-				m.nextTime = srv.clock.Now().Add(portMapRefreshInterval)
+				m.nextTime = srv.Clock.Now().Add(portMapRefreshInterval)
 			}
 		}
 	}
@@ -19,6 +19,7 @@ package p2p
 import (
 	"net"
 	"net/netip"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -39,7 +40,7 @@ func TestServerPortMapping(t *testing.T) {
 			DiscAddr:   ":0",
 			NAT:        mockNAT,
 			Logger:     testlog.Logger(t, log.LvlTrace),
-			clock:      clock,
+			Clock:      clock,
 		},
 	}
 	err := srv.Start()
@@ -77,8 +78,54 @@ func TestServerPortMapping(t *testing.T) {
 	}
 }
 
+// This test checks that the UDP ports of discovery and QUIC are mapped separately.
+func TestServerPortMappingQUIC(t *testing.T) {
+	clock := new(mclock.Simulated)
+	mockNAT := &mockNAT{portOffset: 1000}
+	srv := Server{
+		Config: Config{
+			PrivateKey:     newkey(),
+			NoDial:         true,
+			DiscAddr:       ":0",
+			QUICListenAddr: ":0",
+			NAT:            mockNAT,
+			Logger:         testlog.Logger(t, log.LvlTrace),
+			Clock:          clock,
+		},
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	deadline := clock.Now().Add(portMapRefreshInterval)
+	for clock.Now() < deadline && mockNAT.mapRequests.Load() < 2 {
+		time.Sleep(10 * time.Millisecond)
+		clock.Run(1 * time.Second)
+	}
+	if reqCount := mockNAT.mapRequests.Load(); reqCount != 2 {
+		t.Error("wrong request count:", reqCount)
+	}
+	var (
+		n             = srv.LocalNode().Node()
+		discPort, _   = mockNAT.ports.Load(discMappingName)
+		quicPort, _   = mockNAT.ports.Load(quicMappingName)
+		quic, hasQUIC = n.DevP2PQUICEndpoint()
+	)
+	if discPort == nil || n.UDP() != discPort.(int)+1000 {
+		t.Errorf("wrong UDP port in ENR: %d", n.UDP())
+	}
+	if quicPort == nil || !hasQUIC || int(quic.Port()) != quicPort.(int)+1000 {
+		t.Errorf("wrong QUIC port in ENR: %v", quic)
+	}
+	srv.Stop()
+	if unmapCount := mockNAT.unmapRequests.Load(); unmapCount != 2 {
+		t.Error("wrong unmap request count:", unmapCount)
+	}
+}
+
 type mockNAT struct {
 	mappedPort    uint16
+	portOffset    uint16   // if set, ports are mapped to the internal port plus offset
+	ports         sync.Map // mapping name -> internal port
 	mapRequests   atomic.Int32
 	unmapRequests atomic.Int32
 	ipRequests    atomic.Int32
@@ -86,6 +133,10 @@ type mockNAT struct {
 
 func (m *mockNAT) AddMapping(protocol string, extport, intport int, name string, lifetime time.Duration) (uint16, error) {
 	m.mapRequests.Add(1)
+	m.ports.Store(name, intport)
+	if m.portOffset != 0 {
+		return uint16(intport) + m.portOffset, nil
+	}
 	return m.mappedPort, nil
 }
 
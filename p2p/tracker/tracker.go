@@ -66,13 +66,20 @@ type Response struct {
 	Size    int    // number/size of items in response
 }
 
+// Reporter is notified about the outcome of the tracked requests, typically to
+// maintain the reputation of the remote peer.
+type Reporter interface {
+	Report(ev p2p.ReputationEvent)
+}
+
 // Tracker is a pending network request tracker to measure how much time it takes
 // a remote peer to respond.
 type Tracker struct {
 	cap p2p.Cap // Protocol capability identifier for the metrics
 
-	peer    string        // Peer ID
-	timeout time.Duration // Global timeout after which to drop a tracked packet
+	peer     string        // Peer ID
+	timeout  time.Duration // Global timeout after which to drop a tracked packet
+	reporter Reporter      // Optional reporter of served and lost requests
 
 	pending map[uint64]*Request // Currently pending requests
 	expire  *list.List          // Linked list tracking the expiration order
@@ -93,6 +100,14 @@ func New(cap p2p.Cap, peerID string, timeout time.Duration) *Tracker {
 	}
 }
 
+// SetReporter sets the reporter to notify about served and timed out requests.
+func (t *Tracker) SetReporter(r Reporter) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.reporter = r
+}
+
 // Track adds a network request to the tracker to wait for a response to arrive
 // or until the request it cancelled or times out.
 func (t *Tracker) Track(req Request) error {
@@ -162,6 +177,9 @@ func (t *Tracker) clean() {
 			t.trackedGauge(req.ReqCode).Dec(1)
 			t.lostMeter(req.ReqCode).Mark(1)
 		}
+		if t.reporter != nil {
+			t.reporter.Report(p2p.ReputationTimeout)
+		}
 	}
 	t.schedule()
 }
@@ -241,6 +259,9 @@ func (t *Tracker) Fulfil(resp Response) error {
 		t.trackedGauge(req.ReqCode).Dec(1)
 		t.waitHistogram(req.ReqCode).Update(time.Since(req.time).Microseconds())
 	}
+	if t.reporter != nil {
+		t.reporter.Report(p2p.ReputationResponse)
+	}
 	return nil
 }
 
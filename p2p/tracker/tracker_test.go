@@ -17,6 +17,8 @@
 package tracker
 
 import (
+	"reflect"
+	"sync"
 	"testing"
 	"time"
 
@@ -81,3 +83,40 @@ func TestMetricsOnStop(t *testing.T) {
 		t.Fatalf("gauge2 value after stop: got %d, want 0", gauge2.Snapshot().Value())
 	}
 }
+
+type testReporter struct {
+	lock   sync.Mutex
+	events []p2p.ReputationEvent
+}
+
+func (r *testReporter) Report(ev p2p.ReputationEvent) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.events = append(r.events, ev)
+}
+
+// This checks that served and timed out requests are reported.
+func TestReporter(t *testing.T) {
+	var (
+		cap      = p2p.Cap{Name: "test", Version: 1}
+		timeout  = 50 * time.Millisecond
+		tr       = New(cap, "peer1", timeout)
+		reporter = new(testReporter)
+	)
+	defer tr.Stop()
+	tr.SetReporter(reporter)
+
+	tr.Track(Request{ID: 1, ReqCode: 0x01, RespCode: 0x02, Size: 1})
+	tr.Track(Request{ID: 2, ReqCode: 0x01, RespCode: 0x02, Size: 1})
+	if err := tr.Fulfil(Response{ID: 1, MsgCode: 0x02, Size: 1}); err != nil {
+		t.Fatalf("failed to fulfil request: %v", err)
+	}
+	time.Sleep(timeout + 50*time.Millisecond)
+
+	reporter.lock.Lock()
+	defer reporter.lock.Unlock()
+	want := []p2p.ReputationEvent{p2p.ReputationResponse, p2p.ReputationTimeout}
+	if !reflect.DeepEqual(reporter.events, want) {
+		t.Fatalf("reported events mismatch: have %v, want %v", reporter.events, want)
+	}
+}
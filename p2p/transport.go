@@ -52,6 +52,15 @@ type rlpxTransport struct {
 	conn     *rlpx.Conn
 }
 
+// newTransport creates the transport of a connection. QUIC connections use the QUIC
+// transport, all other connections use RLPx.
+func newTransport(conn net.Conn, dialDest *ecdsa.PublicKey) transport {
+	if qconn, ok := conn.(*quicConn); ok {
+		return newQUICTransport(qconn, dialDest)
+	}
+	return newRLPX(conn, dialDest)
+}
+
 func newRLPX(conn net.Conn, dialDest *ecdsa.PublicKey) transport {
 	return &rlpxTransport{conn: rlpx.NewConn(conn, dialDest)}
 }
@@ -0,0 +1,528 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/netip"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/golang/snappy"
+	"github.com/quic-go/quic-go"
+)
+
+const (
+	quicALPN        = "devp2p"
+	quicAuthLabel   = "devp2p quic auth"
+	quicAuthVersion = 1
+	quicAuthMsg     = 0
+
+	// quicMaxStreams is the number of subprotocol streams a peer may open.
+	quicMaxStreams = 32
+
+	// quicMaxMessageSize is the size limit of messages, same as in RLPx.
+	quicMaxMessageSize = 1<<24 - 1
+
+	// quicNoReason is the error code used when closing a connection for reasons
+	// other than a DiscReason.
+	quicNoReason = 0x100
+
+	quicKeepAlivePeriod = 15 * time.Second
+)
+
+var (
+	errQUICMessageTooLarge = errors.New("message length >= 16MB")
+	errQUICWrongIdentity   = errors.New("remote identity mismatch")
+)
+
+// quicEndpoint runs devp2p over QUIC on a UDP socket. It accepts inbound
+// connections and dials outbound connections from the same socket.
+//
+// Peers use ephemeral self-signed TLS certificates, the node identities are
+// authenticated by the handshake on the control stream of a connection.
+type quicEndpoint struct {
+	tr        *quic.Transport
+	ln        *quic.Listener
+	tlsConfig *tls.Config
+}
+
+func newQUICEndpoint(conn net.PacketConn) (*quicEndpoint, error) {
+	cert, err := quicCertificate()
+	if err != nil {
+		return nil, err
+	}
+	e := &quicEndpoint{
+		tr: &quic.Transport{Conn: conn},
+		tlsConfig: &tls.Config{
+			Certificates:       []tls.Certificate{cert},
+			NextProtos:         []string{quicALPN},
+			MinVersion:         tls.VersionTLS13,
+			InsecureSkipVerify: true,
+		},
+	}
+	if e.ln, err = e.tr.Listen(e.tlsConfig, newQUICConfig()); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func newQUICConfig() *quic.Config {
+	return &quic.Config{
+		HandshakeIdleTimeout:  handshakeTimeout,
+		MaxIdleTimeout:        frameReadTimeout,
+		KeepAlivePeriod:       quicKeepAlivePeriod,
+		MaxIncomingStreams:    1, // the control stream
+		MaxIncomingUniStreams: quicMaxStreams,
+	}
+}
+
+// quicCertificate creates a self-signed TLS certificate.
+func quicCertificate() (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(100 * 365 * 24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
+
+// addr returns the local UDP address.
+func (e *quicEndpoint) addr() net.Addr {
+	return e.tr.Conn.LocalAddr()
+}
+
+// accept waits for an inbound connection.
+func (e *quicEndpoint) accept() (*quic.Conn, error) {
+	return e.ln.Accept(context.Background())
+}
+
+// acceptControl waits for the control stream of an inbound connection.
+func (e *quicEndpoint) acceptControl(conn *quic.Conn) (*quicConn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), handshakeTimeout)
+	defer cancel()
+	stream, err := conn.AcceptStream(ctx)
+	if err != nil {
+		conn.CloseWithError(quicNoReason, "")
+		return nil, err
+	}
+	return &quicConn{Stream: stream, conn: conn}, nil
+}
+
+// dial creates an outbound connection.
+func (e *quicEndpoint) dial(ctx context.Context, addr netip.AddrPort) (*quicConn, error) {
+	conn, err := e.tr.Dial(ctx, net.UDPAddrFromAddrPort(addr), e.tlsConfig, newQUICConfig())
+	if err != nil {
+		return nil, err
+	}
+	stream, err := conn.OpenStreamSync(ctx)
+	if err != nil {
+		conn.CloseWithError(quicNoReason, "")
+		return nil, err
+	}
+	return &quicConn{Stream: stream, conn: conn, initiator: true}, nil
+}
+
+// closeListener stops accepting inbound connections.
+func (e *quicEndpoint) closeListener() {
+	e.ln.Close()
+}
+
+// close shuts down all connections and the socket.
+func (e *quicEndpoint) close() {
+	e.ln.Close()
+	e.tr.Close()
+	e.tr.Conn.Close()
+}
+
+// quicDialer dials nodes over QUIC if they announce support for it, and falls
+// back to the given dialer otherwise. If both dials fail, the errors of both are
+// returned.
+type quicDialer struct {
+	quic     *quicEndpoint
+	fallback NodeDialer
+}
+
+func (d quicDialer) Dial(ctx context.Context, dest *enode.Node) (net.Conn, error) {
+	addr, ok := dest.DevP2PQUICEndpoint()
+	if !ok {
+		return d.fallback.Dial(ctx, dest)
+	}
+	conn, qerr := d.quic.dial(ctx, addr)
+	if qerr == nil {
+		return conn, nil
+	}
+	if ctx.Err() != nil {
+		return nil, qerr
+	}
+	fconn, err := d.fallback.Dial(ctx, dest)
+	if err != nil {
+		return nil, errors.Join(qerr, err)
+	}
+	return fconn, nil
+}
+
+// quicConn is a QUIC connection along with its control stream. It implements
+// net.Conn to pass through Server.SetupConn, reads and writes operate on the
+// control stream.
+type quicConn struct {
+	*quic.Stream
+	conn      *quic.Conn
+	initiator bool
+}
+
+func (c *quicConn) LocalAddr() net.Addr  { return c.conn.LocalAddr() }
+func (c *quicConn) RemoteAddr() net.Addr { return c.conn.RemoteAddr() }
+
+func (c *quicConn) Close() error {
+	return c.conn.CloseWithError(quicNoReason, "")
+}
+
+// quicTransport is the transport of QUIC connections. Base protocol messages are
+// sent on the control stream, every subprotocol uses a unidirectional stream of
+// its own. Since the streams are independent, a lost packet of a large response
+// doesn't hold up messages of other protocols.
+type quicTransport struct {
+	conn     *quicConn
+	dialDest *ecdsa.PublicKey
+	ctrl     *quicSendStream
+	ctrlIn   *bufio.Reader
+	snappy   bool // set by doProtoHandshake
+
+	smu     sync.Mutex
+	streams map[string]*quicSendStream // outgoing subprotocol streams
+
+	in       chan quicFrame
+	failOnce sync.Once
+	failed   chan struct{}
+	err      error
+}
+
+// quicSendStream is a stream with a lock for concurrent writes.
+type quicSendStream struct {
+	mu sync.Mutex
+	s  interface {
+		io.Writer
+		SetWriteDeadline(time.Time) error
+	}
+}
+
+type quicFrame struct {
+	code uint64
+	data []byte
+}
+
+// quicAuth is sent on the control stream to prove ownership of the node key.
+type quicAuth struct {
+	Version   uint
+	Signature []byte
+
+	// Ignore additional fields (forward-compatibility).
+	Rest []rlp.RawValue `rlp:"tail"`
+}
+
+func newQUICTransport(conn *quicConn, dialDest *ecdsa.PublicKey) transport {
+	return &quicTransport{
+		conn:     conn,
+		dialDest: dialDest,
+		ctrl:     &quicSendStream{s: conn.Stream},
+		ctrlIn:   bufio.NewReader(conn.Stream),
+		streams:  make(map[string]*quicSendStream),
+		in:       make(chan quicFrame),
+		failed:   make(chan struct{}),
+	}
+}
+
+func (t *quicTransport) doEncHandshake(prv *ecdsa.PrivateKey) (*ecdsa.PublicKey, error) {
+	t.conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	defer t.conn.SetDeadline(time.Time{})
+
+	// Both ends sign keying material of the TLS session, binding their node
+	// keys to this connection.
+	ourHash, err := t.authHash(t.conn.initiator)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := crypto.Sign(ourHash, prv)
+	if err != nil {
+		return nil, err
+	}
+	auth, _ := rlp.EncodeToBytes(&quicAuth{Version: quicAuthVersion, Signature: sig})
+	werr := make(chan error, 1)
+	go func() { werr <- t.ctrl.writeFrame(quicAuthMsg, auth) }()
+
+	f, err := readQUICFrame(t.ctrlIn)
+	if err != nil {
+		<-werr
+		return nil, err
+	}
+	if err := <-werr; err != nil {
+		return nil, fmt.Errorf("write error: %v", err)
+	}
+	var their quicAuth
+	if f.code != quicAuthMsg {
+		return nil, fmt.Errorf("expected auth, got %x", f.code)
+	}
+	if err := rlp.DecodeBytes(f.data, &their); err != nil {
+		return nil, err
+	}
+	theirHash, _ := t.authHash(!t.conn.initiator)
+	pubkey, err := crypto.SigToPub(theirHash, their.Signature)
+	if err != nil {
+		return nil, err
+	}
+	if t.dialDest != nil && !pubkey.Equal(t.dialDest) {
+		return nil, errQUICWrongIdentity
+	}
+	go t.readLoop(t.ctrlIn)
+	return pubkey, nil
+}
+
+// authHash returns the hash signed by the initiator or recipient of the connection.
+func (t *quicTransport) authHash(initiator bool) ([]byte, error) {
+	tlsState := t.conn.conn.ConnectionState().TLS
+	km, err := tlsState.ExportKeyingMaterial(quicAuthLabel, nil, 32)
+	if err != nil {
+		return nil, err
+	}
+	role := []byte{0}
+	if initiator {
+		role[0] = 1
+	}
+	return crypto.Keccak256(km, role), nil
+}
+
+func (t *quicTransport) doProtoHandshake(our *protoHandshake) (their *protoHandshake, err error) {
+	werr := make(chan error, 1)
+	go func() { werr <- Send(t, handshakeMsg, our) }()
+	if their, err = readProtocolHandshake(t); err != nil {
+		<-werr // make sure the write terminates too
+		return nil, err
+	}
+	if err := <-werr; err != nil {
+		return nil, fmt.Errorf("write error: %v", err)
+	}
+	t.snappy = their.Version >= snappyProtocolVersion
+
+	// Subprotocol messages are accepted only after the handshake, to ensure
+	// the handshake is the first message returned by ReadMsg.
+	go t.acceptLoop()
+	return their, nil
+}
+
+// acceptLoop reads the subprotocol streams opened by the remote end.
+func (t *quicTransport) acceptLoop() {
+	for {
+		s, err := t.conn.conn.AcceptUniStream(context.Background())
+		if err != nil {
+			t.fail(err)
+			return
+		}
+		go t.readLoop(bufio.NewReader(s))
+	}
+}
+
+// readLoop reads the frames of a stream.
+func (t *quicTransport) readLoop(r *bufio.Reader) {
+	for {
+		f, err := readQUICFrame(r)
+		if err != nil {
+			t.fail(err)
+			return
+		}
+		select {
+		case t.in <- f:
+		case <-t.failed:
+			return
+		}
+	}
+}
+
+// fail records the first error of the connection.
+func (t *quicTransport) fail(err error) {
+	t.failOnce.Do(func() {
+		// Connections closed by the remote end with a DiscReason code are
+		// reported like a disconnect message.
+		var aerr *quic.ApplicationError
+		if errors.As(err, &aerr) && aerr.Remote && aerr.ErrorCode < quicNoReason {
+			err = DiscReason(aerr.ErrorCode)
+		}
+		t.err = err
+		close(t.failed)
+	})
+}
+
+func (t *quicTransport) ReadMsg() (Msg, error) {
+	timeout := time.NewTimer(frameReadTimeout)
+	defer timeout.Stop()
+
+	var f quicFrame
+	select {
+	case f = <-t.in:
+	case <-t.failed:
+		return Msg{}, t.err
+	case <-timeout.C:
+		return Msg{}, os.ErrDeadlineExceeded
+	}
+	data := f.data
+	if t.snappy {
+		size, err := snappy.DecodedLen(data)
+		if err != nil {
+			return Msg{}, err
+		}
+		if size > quicMaxMessageSize {
+			return Msg{}, errQUICMessageTooLarge
+		}
+		if data, err = snappy.Decode(nil, data); err != nil {
+			return Msg{}, err
+		}
+	}
+	return Msg{
+		ReceivedAt: time.Now(),
+		Code:       f.code,
+		Size:       uint32(len(data)),
+		meterSize:  uint32(len(f.data)),
+		Payload:    bytes.NewReader(data),
+	}, nil
+}
+
+func (t *quicTransport) WriteMsg(msg Msg) error {
+	data := make([]byte, msg.Size)
+	if _, err := io.ReadFull(msg.Payload, data); err != nil {
+		return err
+	}
+	if len(data) > quicMaxMessageSize {
+		return errQUICMessageTooLarge
+	}
+	if t.snappy {
+		data = snappy.Encode(nil, data)
+	}
+	stream, err := t.stream(msg.meterCap.Name)
+	if err != nil {
+		return err
+	}
+	if err := stream.writeFrame(msg.Code, data); err != nil {
+		return err
+	}
+
+	// Set metrics.
+	msg.meterSize = uint32(len(data))
+	if metrics.Enabled() && msg.meterCap.Name != "" { // don't meter non-subprotocol messages
+		m := fmt.Sprintf("%s/%s/%d/%#02x", egressMeterName, msg.meterCap.Name, msg.meterCap.Version, msg.meterCode)
+		metrics.GetOrRegisterMeter(m, nil).Mark(int64(msg.meterSize))
+		metrics.GetOrRegisterMeter(m+"/packets", nil).Mark(1)
+	}
+	return nil
+}
+
+// stream returns the stream of the given subprotocol, opening it if necessary.
+// Base protocol messages use the control stream.
+func (t *quicTransport) stream(protocol string) (*quicSendStream, error) {
+	if protocol == "" {
+		return t.ctrl, nil
+	}
+	t.smu.Lock()
+	defer t.smu.Unlock()
+
+	if s := t.streams[protocol]; s != nil {
+		return s, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), frameWriteTimeout)
+	defer cancel()
+	s, err := t.conn.conn.OpenUniStreamSync(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t.streams[protocol] = &quicSendStream{s: s}
+	return t.streams[protocol], nil
+}
+
+func (t *quicTransport) close(err error) {
+	code, reason := quic.ApplicationErrorCode(quicNoReason), ""
+	if r, ok := err.(DiscReason); ok {
+		code, reason = quic.ApplicationErrorCode(r), r.String()
+	}
+	t.conn.conn.CloseWithError(code, reason)
+	t.fail(net.ErrClosed)
+}
+
+// writeFrame writes a message to the stream.
+func (s *quicSendStream) writeFrame(code uint64, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buf := make([]byte, 0, 2*binary.MaxVarintLen64+len(data))
+	buf = binary.AppendUvarint(buf, code)
+	buf = binary.AppendUvarint(buf, uint64(len(data)))
+	buf = append(buf, data...)
+	s.s.SetWriteDeadline(time.Now().Add(frameWriteTimeout))
+	n, err := s.s.Write(buf)
+	egressTrafficMeter.Mark(int64(n))
+	return err
+}
+
+// readQUICFrame reads a message from a stream.
+func readQUICFrame(r *bufio.Reader) (quicFrame, error) {
+	code, err := binary.ReadUvarint(r)
+	if err != nil {
+		return quicFrame{}, err
+	}
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return quicFrame{}, err
+	}
+	if size > quicMaxMessageSize {
+		return quicFrame{}, errQUICMessageTooLarge
+	}
+	// The size is chosen by the remote end, so the buffer is grown as the data
+	// arrives instead of being allocated upfront.
+	var data bytes.Buffer
+	if _, err := io.CopyN(&data, r, int64(size)); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return quicFrame{}, err
+	}
+	ingressTrafficMeter.Mark(int64(size))
+	return quicFrame{code: code, data: data.Bytes()}, nil
+}
@@ -0,0 +1,245 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/netip"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/ethereum/go-ethereum/p2p/pipes"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// quicHandshake runs both handshakes on a connection pair.
+func quicHandshake(t *testing.T, dialKey, acceptKey *ecdsa.PrivateKey, dialDest *ecdsa.PublicKey) (dialed, accepted transport, err error) {
+	var (
+		fd1, fd2 *quicConn
+		errc     = make(chan error, 1)
+	)
+	c1, c2 := pipes.PacketPipe()
+	e1, err := newQUICEndpoint(c1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(e1.close)
+	e2, err := newQUICEndpoint(c2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(e2.close)
+
+	go func() {
+		conn, err := e2.accept()
+		if err == nil {
+			if fd2, err = e2.acceptControl(conn); err == nil {
+				accepted = newQUICTransport(fd2, nil)
+				_, err = accepted.doEncHandshake(acceptKey)
+			}
+		}
+		errc <- err
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if fd1, err = e1.dial(ctx, netip.MustParseAddrPort(c2.LocalAddr().String())); err != nil {
+		t.Fatal("dial failed:", err)
+	}
+	dialed = newQUICTransport(fd1, dialDest)
+	rpubkey, err := dialed.doEncHandshake(dialKey)
+	if err != nil {
+		fd1.Close()
+		<-errc
+		return nil, nil, err
+	}
+	if !rpubkey.Equal(&acceptKey.PublicKey) {
+		t.Fatal("dial side remote pubkey mismatch")
+	}
+	if err := <-errc; err != nil {
+		t.Fatal("accept side enc handshake failed:", err)
+	}
+	return dialed, accepted, nil
+}
+
+func TestQUICTransport(t *testing.T) {
+	var (
+		key1, _ = crypto.GenerateKey()
+		key2, _ = crypto.GenerateKey()
+		hs1     = &protoHandshake{Version: baseProtocolVersion, ID: crypto.FromECDSAPub(&key1.PublicKey)[1:]}
+		hs2     = &protoHandshake{Version: baseProtocolVersion, ID: crypto.FromECDSAPub(&key2.PublicKey)[1:]}
+	)
+	dialed, accepted, err := quicHandshake(t, key1, key2, &key2.PublicKey)
+	if err != nil {
+		t.Fatal("enc handshake failed:", err)
+	}
+	errc := make(chan error, 1)
+	go func() {
+		_, err := accepted.doProtoHandshake(hs2)
+		errc <- err
+	}()
+	if _, err := dialed.doProtoHandshake(hs1); err != nil {
+		t.Fatal("dial side proto handshake failed:", err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal("accept side proto handshake failed:", err)
+	}
+
+	// Messages of base protocol and subprotocols arrive on their own streams.
+	msgs := []Msg{
+		{Code: pingMsg},
+		{Code: baseProtocolLength, meterCap: Cap{"a", 1}},
+		{Code: baseProtocolLength + 1, meterCap: Cap{"b", 1}},
+		{Code: baseProtocolLength + 2, meterCap: Cap{"a", 1}},
+	}
+	for i, msg := range msgs {
+		size, r, _ := rlp.EncodeToReader(uint(i))
+		msg.Size, msg.Payload = uint32(size), r
+		if err := dialed.WriteMsg(msg); err != nil {
+			t.Fatalf("write %d failed: %v", i, err)
+		}
+	}
+	seen := make(map[uint64]uint)
+	for range msgs {
+		msg, err := accepted.ReadMsg()
+		if err != nil {
+			t.Fatal("read failed:", err)
+		}
+		var i uint
+		if err := msg.Decode(&i); err != nil {
+			t.Fatal("decode failed:", err)
+		}
+		if msgs[i].Code != msg.Code {
+			t.Errorf("message %d has code %d, want %d", i, msg.Code, msgs[i].Code)
+		}
+		seen[msg.Code] = i
+	}
+	if len(seen) != len(msgs) {
+		t.Fatalf("received %d distinct messages, want %d", len(seen), len(msgs))
+	}
+
+	// Closing with a reason is reported as a disconnect.
+	dialed.close(DiscTooManyPeers)
+	if _, err := accepted.ReadMsg(); err != DiscTooManyPeers {
+		t.Fatalf("wrong error after close: %v", err)
+	}
+}
+
+func TestQUICTransportWrongIdentity(t *testing.T) {
+	var (
+		key1, _  = crypto.GenerateKey()
+		key2, _  = crypto.GenerateKey()
+		other, _ = crypto.GenerateKey()
+	)
+	if _, _, err := quicHandshake(t, key1, key2, &other.PublicKey); !errors.Is(err, errQUICWrongIdentity) {
+		t.Fatalf("wrong error for unexpected identity: %v", err)
+	}
+}
+
+// This test checks that the buffer of a frame is not allocated upfront, since the
+// announced size is chosen by the remote end.
+func TestReadQUICFrameSize(t *testing.T) {
+	frame := binary.AppendUvarint(nil, 0x10)
+	frame = binary.AppendUvarint(frame, quicMaxMessageSize)
+	frame = append(frame, make([]byte, 100)...)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err := readQUICFrame(bufio.NewReader(bytes.NewReader(frame)))
+	runtime.ReadMemStats(&after)
+	if err != io.ErrUnexpectedEOF {
+		t.Fatalf("wrong error for truncated frame: %v", err)
+	}
+	if alloc := after.TotalAlloc - before.TotalAlloc; alloc > 1<<20 {
+		t.Fatalf("reading truncated frame allocated %d bytes", alloc)
+	}
+
+	// Complete frames are read as usual.
+	frame = binary.AppendUvarint(nil, 0x10)
+	frame = binary.AppendUvarint(frame, 3)
+	frame = append(frame, 1, 2, 3)
+	f, err := readQUICFrame(bufio.NewReader(bytes.NewReader(frame)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.code != 0x10 || !bytes.Equal(f.data, []byte{1, 2, 3}) {
+		t.Fatalf("wrong frame: code %d, data %x", f.code, f.data)
+	}
+}
+
+func TestServerQUIC(t *testing.T) {
+	var (
+		connected = make(chan *Peer, 2)
+		servers   []*Server
+	)
+	for i := 0; i < 2; i++ {
+		srv := &Server{
+			Config: Config{
+				PrivateKey:     newkey(),
+				MaxPeers:       10,
+				NoDiscovery:    true,
+				ListenAddr:     "127.0.0.1:0",
+				QUICListenAddr: "127.0.0.1:0",
+				Protocols: []Protocol{{
+					Name:    "test",
+					Version: 1,
+					Length:  1,
+					Run: func(p *Peer, rw MsgReadWriter) error {
+						connected <- p
+						<-p.closed
+						return nil
+					},
+				}},
+			},
+		}
+		if err := srv.Start(); err != nil {
+			t.Fatal("could not start server:", err)
+		}
+		defer srv.Stop()
+		servers = append(servers, srv)
+	}
+	self := servers[1].Self()
+	var port enr.DevP2PQUIC
+	if err := self.Load(&port); err != nil {
+		t.Fatal("QUIC port not announced:", err)
+	}
+	servers[0].AddPeer(self)
+
+	timeout := time.After(10 * time.Second)
+	for i := 0; i < 2; i++ {
+		select {
+		case p := <-connected:
+			if _, ok := p.RemoteAddr().(*net.UDPAddr); !ok {
+				t.Errorf("peer connected over %v, want QUIC", p.RemoteAddr())
+			}
+		case <-timeout:
+			t.Fatal("peers not connected")
+		}
+	}
+	if id := servers[0].Peers()[0].ID(); id != self.ID() {
+		t.Fatalf("connected to wrong peer %v", id)
+	}
+}
@@ -425,6 +425,17 @@ var NetworkNames = map[string]string{
 	HoodiChainConfig.ChainID.String():   "hoodi",
 }
 
+// ChainConfigByID returns the chain configuration of the well-known network with
+// the given chain ID, or nil if the network is not known.
+func ChainConfigByID(chainID uint64) *ChainConfig {
+	for _, config := range []*ChainConfig{MainnetChainConfig, SepoliaChainConfig, HoodiChainConfig} {
+		if config.ChainID.Uint64() == chainID {
+			return config
+		}
+	}
+	return nil
+}
+
 // ChainConfig is the core config which determines the blockchain settings.
 //
 // ChainConfig is stored in the database on a per block basis. This means
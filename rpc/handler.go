@@ -636,7 +636,12 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 	n := &Notifier{h: h, namespace: namespace}
 	cp.notifiers = append(cp.notifiers, n)
 	ctx := context.WithValue(cp.ctx, notifierKey{}, n)
-	return h.runMethod(ctx, msg, callb, args)
+	answer := h.runMethod(ctx, msg, callb, args)
+	if answer.Error != nil {
+		// The subscription was rejected, make sure it is never registered.
+		n.discard()
+	}
+	return answer
 }
 
 // rpcInfoFromMessage builds the RPCInfo for a SERVER/INTERNAL RPC span from a
@@ -108,6 +108,7 @@ type Notifier struct {
 	buffer       []any
 	callReturned bool
 	activated    bool
+	discarded    bool
 }
 
 // CreateSubscription returns a new subscription that is coupled to the
@@ -133,6 +134,9 @@ func (n *Notifier) Notify(id ID, data any) error {
 	n.mu.Lock()
 	defer n.mu.Unlock()
 
+	if n.discarded {
+		return ErrSubscriptionNotFound
+	}
 	if n.sub == nil {
 		panic("can't Notify before subscription is created")
 	} else if n.sub.ID != id {
@@ -154,6 +158,22 @@ func (n *Notifier) takeSubscription() *Subscription {
 	return n.sub
 }
 
+// discard is called when the subscribe call failed after creating a subscription.
+// The subscription is closed, so that server callbacks waiting on it terminate,
+// and any buffered notifications are dropped.
+func (n *Notifier) discard() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.sub != nil {
+		close(n.sub.err)
+		n.sub = nil
+	}
+	n.buffer = nil
+	n.callReturned = true
+	n.discarded = true
+}
+
 // activate is called after the subscription ID was sent to client. Notifications are
 // buffered before activation. This prevents notifications being sent to the client before
 // the subscription ID is sent to the client.
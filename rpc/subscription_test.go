@@ -184,6 +184,49 @@ func TestServerUnsubscribe(t *testing.T) {
 	}
 }
 
+// This test checks that a subscription created by a failing subscribe call is
+// discarded without delivering notifications.
+func TestServerSubscribeFailure(t *testing.T) {
+	t.Parallel()
+
+	p1, p2 := net.Pipe()
+	defer p2.Close()
+
+	// Start the server.
+	server := newTestServer()
+	service := &notificationTestService{unsubscribed: make(chan string, 1)}
+	server.RegisterName("nftest2", service)
+	go server.ServeCodec(NewCodec(p1), 0)
+
+	p2.SetDeadline(time.Now().Add(10 * time.Second))
+	p2.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"nftest2_subscribe","params":["failingSubscription",10]}`))
+
+	// The subscribe call must fail.
+	var (
+		resps         = make(chan subConfirmation)
+		notifications = make(chan subscriptionResult)
+		errors        = make(chan error, 1)
+	)
+	go waitForMessages(json.NewDecoder(p2), resps, notifications, errors)
+
+	select {
+	case sub := <-resps:
+		t.Fatalf("unexpected subscription %v", sub.subid)
+	case n := <-notifications:
+		t.Fatalf("unexpected notification %v", n)
+	case err := <-errors:
+		if !strings.Contains(err.Error(), "subscription rejected") {
+			t.Fatalf("wrong error: %v", err)
+		}
+	}
+	// The server side subscription must be closed.
+	select {
+	case <-service.unsubscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not closed")
+	}
+}
+
 type subConfirmation struct {
 	reqid int
 	subid ID
@@ -349,6 +349,19 @@ func (db *Database) Journal(root common.Hash) error {
 	return pdb.Journal(root)
 }
 
+// Checkpoint creates a consistent copy of the database on disk, using the given
+// function to checkpoint the key-value store. In path-based scheme, the state
+// histories are copied into ancientDir and the in-memory layers up to the given
+// state are journaled into journalDir. In hash-based scheme, only the persisted
+// trie nodes are included in the copy, the dirty ones are not.
+func (db *Database) Checkpoint(root common.Hash, ancientDir, journalDir string, checkpoint func() error) error {
+	pdb, ok := db.backend.(*pathdb.Database)
+	if !ok {
+		return checkpoint()
+	}
+	return pdb.Checkpoint(root, ancientDir, journalDir, checkpoint)
+}
+
 // VerifyState traverses the flat states specified by the given state root and
 // ensures they are matched with each other.
 func (db *Database) VerifyState(root common.Hash) error {
@@ -18,6 +18,7 @@ package triedb
 
 import (
 	"errors"
+	"io"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/triedb/pathdb"
@@ -70,3 +71,133 @@ func (db *Database) HistoryRange() (uint64, uint64, error) {
 	}
 	return pdb.HistoryRange()
 }
+
+// TrienodeHistoryRange returns the block numbers associated with earliest and
+// latest trienode history in the local store.
+//
+// This function is only supported by path mode database.
+func (db *Database) TrienodeHistoryRange() (uint64, uint64, error) {
+	pdb, ok := db.backend.(*pathdb.Database)
+	if !ok {
+		return 0, 0, errors.New("not supported")
+	}
+	return pdb.TrienodeHistoryRange()
+}
+
+// AccountChanges returns the mutations of the given account made by the blocks
+// within the range [first, last], on the chain leading to the specified state.
+// At most limit changes are returned, in ascending block order.
+//
+// This function is only supported by path mode database.
+func (db *Database) AccountChanges(root common.Hash, address common.Address, first, last uint64, limit int) ([]pathdb.StateChange, error) {
+	pdb, ok := db.backend.(*pathdb.Database)
+	if !ok {
+		return nil, errors.New("not supported")
+	}
+	return pdb.AccountChanges(root, address, first, last, limit)
+}
+
+// StorageChanges returns the mutations of the given storage slot made by the
+// blocks within the range [first, last], on the chain leading to the specified
+// state. At most limit changes are returned, in ascending block order.
+//
+// Note, slot refers to the raw slot key.
+//
+// This function is only supported by path mode database.
+func (db *Database) StorageChanges(root common.Hash, address common.Address, slot common.Hash, first, last uint64, limit int) ([]pathdb.StateChange, error) {
+	pdb, ok := db.backend.(*pathdb.Database)
+	if !ok {
+		return nil, errors.New("not supported")
+	}
+	return pdb.StorageChanges(root, address, slot, first, last, limit)
+}
+
+// ExportStateHistory writes the state histories of the blocks within the range
+// [first, last] into the given writer, along with the trienode histories if
+// they cover the whole range. It returns the number of exported histories.
+//
+// This function is only supported by path mode database.
+func (db *Database) ExportStateHistory(w io.Writer, first, last uint64) (uint64, error) {
+	pdb, ok := db.backend.(*pathdb.Database)
+	if !ok {
+		return 0, errors.New("not supported")
+	}
+	return pdb.ExportStateHistory(w, first, last)
+}
+
+// ImportStateHistory imports the exported state histories preceding the local
+// ones, extending the historical state window backwards. The database must be
+// reopened afterwards.
+//
+// This function is only supported by path mode database.
+func (db *Database) ImportStateHistory(r io.Reader) (uint64, error) {
+	pdb, ok := db.backend.(*pathdb.Database)
+	if !ok {
+		return 0, errors.New("not supported")
+	}
+	return pdb.ImportStateHistory(r)
+}
+
+// SetStateHistoryLimit adjusts the number of recent blocks for which the state
+// histories are retained, truncating the excess ones in the background.
+//
+// This function is only supported by path mode database.
+func (db *Database) SetStateHistoryLimit(limit uint64) error {
+	pdb, ok := db.backend.(*pathdb.Database)
+	if !ok {
+		return errors.New("not supported")
+	}
+	return pdb.SetStateHistoryLimit(limit)
+}
+
+// SetTrienodeHistoryLimit adjusts the number of recent blocks for which the
+// trienode histories are retained, truncating the excess ones in the background.
+//
+// This function is only supported by path mode database.
+func (db *Database) SetTrienodeHistoryLimit(limit uint64) error {
+	pdb, ok := db.backend.(*pathdb.Database)
+	if !ok {
+		return errors.New("not supported")
+	}
+	return pdb.SetTrienodeHistoryLimit(limit)
+}
+
+// HistoryLimits returns the number of recent blocks for which the state and
+// trienode histories are retained, negative means the trienode history is
+// disabled.
+//
+// This function is only supported by path mode database.
+func (db *Database) HistoryLimits() (uint64, int64, error) {
+	pdb, ok := db.backend.(*pathdb.Database)
+	if !ok {
+		return 0, 0, errors.New("not supported")
+	}
+	state, trienode := pdb.HistoryLimits()
+	return state, trienode, nil
+}
+
+// HistoryPruneProgress reports the retention window and the truncation progress
+// of the state and trienode histories. The latter is nil if the trienode
+// history is not enabled.
+//
+// This function is only supported by path mode database.
+func (db *Database) HistoryPruneProgress() (*pathdb.HistoryPruneProgress, *pathdb.HistoryPruneProgress, error) {
+	pdb, ok := db.backend.(*pathdb.Database)
+	if !ok {
+		return nil, nil, errors.New("not supported")
+	}
+	return pdb.HistoryPruneProgress()
+}
+
+// StateDiff returns the states mutated by the blocks within the range [first,
+// last] on the chain leading to the specified state, along with their values
+// before the first block.
+//
+// This function is only supported by path mode database.
+func (db *Database) StateDiff(root common.Hash, first, last uint64, limit int) (*pathdb.StateDiff, error) {
+	pdb, ok := db.backend.(*pathdb.Database)
+	if !ok {
+		return nil, errors.New("not supported")
+	}
+	return pdb.StateDiff(root, first, last, limit)
+}
@@ -0,0 +1,102 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pathdb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Checkpoint creates a consistent copy of the state database while the state
+// transitions are blocked. The given function is expected to checkpoint the
+// key-value store, after which the state histories are copied into ancientDir
+// and the in-memory layers up to the specified state are journaled into
+// journalDir, making the copy openable at that state. If the specified state
+// is not available, only the disk layer is journaled.
+//
+// The database stays fully functional afterwards, unlike with Journal.
+func (db *Database) Checkpoint(root common.Hash, ancientDir, journalDir string, checkpoint func() error) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	start := time.Now()
+	l := db.tree.get(root)
+	if l == nil {
+		log.Warn("State to checkpoint is not available, falling back to disk layer", "root", root)
+		l = db.tree.bottom()
+	}
+	// Block until the background flushing is finished, ensuring the persistent
+	// state in the key-value store matches the disk layer. No new flush can be
+	// scheduled while the lock is held.
+	if err := db.tree.bottom().waitFlush(); err != nil {
+		return err
+	}
+	if err := syncHistory(db.stateFreezer, db.trienodeFreezer); err != nil {
+		return err
+	}
+	if err := checkpoint(); err != nil {
+		return err
+	}
+	// Copy the state histories after the key-value store. Any extra histories
+	// are truncated once the copy is opened.
+	for _, freezer := range []ethdb.ResettableAncientStore{db.stateFreezer, db.trienodeFreezer} {
+		if freezer == nil {
+			continue
+		}
+		dir, err := freezer.AncientDatadir()
+		if err != nil {
+			return err
+		}
+		if dir == "" {
+			continue // in-memory freezer
+		}
+		cp, ok := freezer.(ethdb.Checkpointer)
+		if !ok {
+			return ethdb.ErrCheckpointUnsupported
+		}
+		if err := cp.Checkpoint(filepath.Join(ancientDir, filepath.Base(dir))); err != nil {
+			return err
+		}
+	}
+	if journalDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(journalDir, 0755); err != nil {
+		return err
+	}
+	path := filepath.Join(journalDir, db.journalName())
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create journal file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	if err := db.writeJournal(file, l); err != nil {
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		return err
+	}
+	log.Info("Checkpointed state database", "root", l.rootHash(), "id", l.stateID(), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pathdb
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+)
+
+func TestCheckpoint(t *testing.T) {
+	// Redefine the diff layer depth allowance for faster testing.
+	maxDiffLayers = 4
+	defer func() {
+		maxDiffLayers = 128
+	}()
+
+	tester := newTester(t, &testerConfig{layers: 12})
+	defer tester.release()
+
+	var (
+		dir        = t.TempDir()
+		ancientDir = filepath.Join(dir, "ancient")
+		journalDir = filepath.Join(dir, "triedb")
+		kvdb       = memorydb.New()
+	)
+	err := tester.db.Checkpoint(tester.lastHash(), ancientDir, journalDir, func() error {
+		it := tester.db.diskdb.NewIterator(nil, nil)
+		defer it.Release()
+
+		for it.Next() {
+			if err := kvdb.Put(it.Key(), it.Value()); err != nil {
+				return err
+			}
+		}
+		return it.Error()
+	})
+	if err != nil {
+		t.Fatalf("Failed to checkpoint, err: %v", err)
+	}
+	// Open the copy and verify all the states available at the checkpoint
+	disk, err := rawdb.Open(kvdb, rawdb.OpenOptions{Ancient: ancientDir})
+	if err != nil {
+		t.Fatalf("Failed to open checkpoint, err: %v", err)
+	}
+	config := *tester.db.config
+	config.JournalDirectory = journalDir
+	db := New(disk, &config, false)
+	defer func() {
+		db.Close()
+		disk.Close()
+	}()
+
+	origin := tester.db
+	tester.db = db
+	for i := tester.bottomIndex(); i < len(tester.roots); i++ {
+		if err := tester.verifyState(tester.roots[i]); err != nil {
+			t.Fatalf("Invalid state, err: %v", err)
+		}
+	}
+	if err := tester.verifyHistory(); err != nil {
+		t.Fatalf("State history is invalid, err: %v", err)
+	}
+	tester.db = origin
+
+	// The original database must remain writable after the checkpoint
+	root, nodes, states := tester.generate(tester.lastHash(), true)
+	if err := tester.db.Update(root, tester.lastHash(), uint64(len(tester.roots)), nodes, states); err != nil {
+		t.Fatalf("Failed to update state changes, err: %v", err)
+	}
+}
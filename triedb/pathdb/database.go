@@ -130,11 +130,13 @@ type Database struct {
 	diskdb ethdb.Database // Persistent storage for matured trie nodes
 	tree   *layerTree     // The group for all known layers
 
-	stateFreezer ethdb.ResettableAncientStore // Freezer for storing state histories, nil possible in tests
-	stateIndexer *historyIndexer              // History indexer historical state data, nil possible
+	stateFreezer    ethdb.ResettableAncientStore // Freezer for storing state histories, nil possible in tests
+	stateIndexer    *historyIndexer              // History indexer historical state data, nil possible
+	stateTruncation historyTruncation            // Background truncation of the excess state histories
 
-	trienodeFreezer ethdb.ResettableAncientStore // Freezer for storing trienode histories, nil possible in tests
-	trienodeIndexer *historyIndexer              // History indexer for historical trienode data
+	trienodeFreezer    ethdb.ResettableAncientStore // Freezer for storing trienode histories, nil possible in tests
+	trienodeIndexer    *historyIndexer              // History indexer for historical trienode data
+	trienodeTruncation historyTruncation            // Background truncation of the excess trienode histories
 
 	lock sync.RWMutex // Lock to prevent mutations from happening at the same time
 }
@@ -164,6 +166,11 @@ func New(diskdb ethdb.Database, config *Config, isUBT bool) *Database {
 		db.diskdb = rawdb.NewTable(diskdb, string(rawdb.VerklePrefix))
 		db.hasher = binaryNodeHasher
 	}
+	// Finish the state history import interrupted by an unclean shutdown, as
+	// it affects both the layer journal and the histories.
+	if err := db.resumeHistoryImport(); err != nil {
+		log.Crit("Failed to resume state history import", "err", err)
+	}
 	// Construct the layer tree by resolving the in-disk singleton state
 	// and in-memory layer journal.
 	db.tree = newLayerTree(db.loadLayers())
@@ -624,13 +631,15 @@ func (db *Database) journalPath() string {
 	if db.config.JournalDirectory == "" {
 		return ""
 	}
-	var fname string
+	return filepath.Join(db.config.JournalDirectory, db.journalName())
+}
+
+// journalName returns the file name of the journal for persisting state data.
+func (db *Database) journalName() string {
 	if db.isUBT {
-		fname = fmt.Sprintf("verkle.journal")
-	} else {
-		fname = fmt.Sprintf("merkle.journal")
+		return "verkle.journal"
 	}
-	return filepath.Join(db.config.JournalDirectory, fname)
+	return "merkle.journal"
 }
 
 // AccountHistory inspects the account history within the specified range.
@@ -663,6 +672,15 @@ func (db *Database) HistoryRange() (uint64, uint64, error) {
 	return historyRange(db.stateFreezer)
 }
 
+// TrienodeHistoryRange returns the block numbers associated with earliest and
+// latest trienode history in the local store.
+func (db *Database) TrienodeHistoryRange() (uint64, uint64, error) {
+	if db.trienodeFreezer == nil {
+		return 0, 0, errors.New("trienode history is not enabled")
+	}
+	return trienodeHistoryRange(db.trienodeFreezer)
+}
+
 // IndexProgress returns the indexing progress made so far. It provides the
 // number of states that remain unindexed.
 func (db *Database) IndexProgress() (uint64, uint64, error) {
@@ -411,7 +411,7 @@ func (dl *diskLayer) writeHistory(typ historyType, diff *diffLayer) (bool, error
 	// Notify the index pruner about the new tail so that stale index
 	// blocks referencing the pruned histories can be cleaned up.
 	if indexer != nil && pruned > 0 {
-		indexer.prune(newFirst)
+		indexer.prune(newFirst, false)
 	}
 	log.Debug("Pruned history", "type", typ, "items", pruned, "tailid", newFirst)
 	return false, nil
@@ -0,0 +1,227 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pathdb
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// StateChange represents a single mutation of an account or storage slot.
+// The values are in the slim account and trimmed storage encodings used by
+// the state histories, nil means the state was not present.
+type StateChange struct {
+	Block uint64 // Number of the block that made the change
+	Prev  []byte // Value before the block
+	Post  []byte // Value after the block
+}
+
+// AccountChanges returns the mutations of the given account made by the blocks
+// within the range [first, last], on the chain leading to the specified state.
+// At most limit changes are returned, in ascending block order.
+//
+// Changes older than the disk layer are resolved via the state history index,
+// the rest from the diff layers in memory.
+func (db *Database) AccountChanges(root common.Hash, address common.Address, first, last uint64, limit int) ([]StateChange, error) {
+	hash := crypto.Keccak256Hash(address.Bytes())
+	return db.stateChanges(root, newAccountIdentQuery(address, hash), first, last, limit)
+}
+
+// StorageChanges returns the mutations of the given storage slot made by the
+// blocks within the range [first, last], on the chain leading to the specified
+// state. At most limit changes are returned, in ascending block order.
+//
+// Note, slot refers to the raw slot key.
+func (db *Database) StorageChanges(root common.Hash, address common.Address, slot common.Hash, first, last uint64, limit int) ([]StateChange, error) {
+	var (
+		addrHash = crypto.Keccak256Hash(address.Bytes())
+		slotHash = crypto.Keccak256Hash(slot.Bytes())
+	)
+	return db.stateChanges(root, newStorageIdentQuery(address, addrHash, slot, slotHash), first, last, limit)
+}
+
+// stateChanges implements AccountChanges and StorageChanges.
+func (db *Database) stateChanges(root common.Hash, state stateIdentQuery, first, last uint64, limit int) ([]StateChange, error) {
+	if first > last {
+		return nil, fmt.Errorf("range is invalid, first: %d, last: %d", first, last)
+	}
+	if limit <= 0 {
+		return nil, nil
+	}
+	// Collect the diff layers on top of the disk layer, in ascending order.
+	l := db.tree.get(root)
+	if l == nil {
+		return nil, fmt.Errorf("state %#x is not available", root)
+	}
+	var diffs []*diffLayer
+	for {
+		diff, ok := l.(*diffLayer)
+		if !ok {
+			break
+		}
+		diffs = append(diffs, diff)
+		l = diff.parentLayer()
+	}
+	slices.Reverse(diffs)
+	disk := l.(*diskLayer)
+
+	// Resolve the changes persisted in the state histories, unless the
+	// requested range is entirely covered by the diff layers or there are
+	// no histories at all.
+	var changes []StateChange
+	if disk.stateID() > 0 && (len(diffs) == 0 || first < diffs[0].block) {
+		var (
+			pending *StateChange
+			err     error
+		)
+		changes, pending, err = db.historicalChanges(disk.stateID(), state, first, last, limit)
+		if err != nil {
+			return nil, err
+		}
+		if pending != nil {
+			// The value after the last persisted change is the one
+			// held by the disk layer.
+			if state.typ == typeAccount {
+				pending.Post, err = disk.account(state.addressHash, 0)
+			} else {
+				pending.Post, err = disk.storage(state.addressHash, state.storageHash, 0)
+			}
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, *pending)
+		}
+	}
+	// Resolve the changes held by the diff layers.
+	for _, diff := range diffs {
+		if len(changes) >= limit || diff.block > last {
+			break
+		}
+		if diff.block < first {
+			continue
+		}
+		var (
+			prev, post []byte
+			exists     bool
+		)
+		if state.typ == typeAccount {
+			prev, exists = diff.states.accountOrigin[state.address]
+			post = diff.states.accountData[state.addressHash]
+		} else {
+			key := state.storageHash
+			if diff.states.rawStorageKey {
+				key = state.storageKey
+			}
+			prev, exists = diff.states.storageOrigin[state.address][key]
+			post = diff.states.storageData[state.addressHash][state.storageHash]
+		}
+		if exists {
+			changes = append(changes, StateChange{Block: diff.block, Prev: prev, Post: post})
+		}
+	}
+	return changes, nil
+}
+
+// historicalChanges resolves the state changes within the block range from the
+// state histories up to and including the given one. The last change is returned
+// separately if the value after it is not covered by the histories.
+func (db *Database) historicalChanges(lastID uint64, state stateIdentQuery, first, last uint64, limit int) ([]StateChange, *StateChange, error) {
+	if db.stateIndexer == nil || db.stateFreezer == nil {
+		return nil, nil, errors.New("state history index is not available")
+	}
+	if !db.stateIndexer.inited() {
+		return nil, nil, errors.New("state histories haven't been fully indexed yet")
+	}
+	tail, err := db.stateFreezer.Tail(rawdb.DefaultHistoryGroup)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Ensure the histories of the requested blocks haven't been pruned.
+	if tail > 0 {
+		if lastID <= tail {
+			return nil, nil, errors.New("historical state has been pruned")
+		}
+		m, err := readStateHistoryMeta(db.stateFreezer, tail+1)
+		if err != nil {
+			return nil, nil, err
+		}
+		if first < m.block {
+			return nil, nil, fmt.Errorf("historical state has been pruned, first available block: %d", m.block)
+		}
+	}
+	// Locate the first history within the block range.
+	startID, err := searchHistory(db.stateFreezer, tail, lastID, first)
+	if err != nil {
+		return nil, nil, err
+	}
+	if startID > lastID {
+		return nil, nil, nil
+	}
+	if _, err := checkStateAvail(state.stateIdent, typeStateHistory, db.stateFreezer, startID-1, lastID, db.diskdb); err != nil {
+		return nil, nil, err
+	}
+	ir, err := newIndexReader(db.diskdb, state.stateIdent, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		changes []StateChange
+		pending *StateChange
+		reader  = newStateHistoryReader(db.diskdb, db.stateFreezer)
+		it      = ir.newIterator(nil)
+	)
+	for found := it.SeekGT(startID - 1); found && it.ID() <= lastID; found = it.Next() {
+		var (
+			id   = it.ID()
+			prev []byte
+		)
+		if state.typ == typeAccount {
+			prev, err = reader.readAccount(state.address, id)
+		} else {
+			prev, err = reader.readStorage(state.address, state.storageKey, state.storageHash, id)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		// The value before this change is the value after the previous one.
+		if pending != nil {
+			pending.Post = prev
+			changes = append(changes, *pending)
+			pending = nil
+			if len(changes) == limit {
+				return changes, nil, nil
+			}
+		}
+		m, err := readStateHistoryMeta(db.stateFreezer, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		if m.block > last {
+			return changes, nil, nil
+		}
+		pending = &StateChange{Block: m.block, Prev: prev}
+	}
+	if err := it.Error(); err != nil {
+		return nil, nil, err
+	}
+	return changes, pending, nil
+}
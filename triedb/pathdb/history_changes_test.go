@@ -0,0 +1,139 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pathdb
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestStateChanges(t *testing.T) {
+	maxDiffLayers = 4
+	defer func() {
+		maxDiffLayers = 128
+	}()
+
+	env := newTester(t, &testerConfig{layers: 32, enableIndex: true})
+	defer env.release()
+	waitIndexing(env.db)
+
+	// Pick the account and the slot modified most often.
+	var (
+		accounts = make(map[common.Address]int)
+		slots    = make(map[common.Address]map[common.Hash]int)
+		account  common.Address
+		owner    common.Address
+		slot     common.Hash
+	)
+	for _, states := range env.states {
+		for addr := range states.accountOrigin {
+			accounts[addr]++
+			if accounts[addr] > accounts[account] {
+				account = addr
+			}
+		}
+		for addr, origin := range states.storageOrigin {
+			if slots[addr] == nil {
+				slots[addr] = make(map[common.Hash]int)
+			}
+			for key := range origin {
+				if !states.rawStorageKey {
+					key = env.hashPreimage(key)
+				}
+				slots[addr][key]++
+				if slots[addr][key] > slots[owner][slot] {
+					owner, slot = addr, key
+				}
+			}
+		}
+	}
+	var (
+		root     = env.roots[len(env.roots)-1]
+		ownerKey = crypto.Keccak256Hash(owner.Bytes())
+		slotHash = crypto.Keccak256Hash(slot.Bytes())
+		accChanges,
+		slotChanges []StateChange
+	)
+	for i, states := range env.states {
+		if prev, ok := states.accountOrigin[account]; ok {
+			accChanges = append(accChanges, StateChange{Block: uint64(i), Prev: prev, Post: states.accountData[crypto.Keccak256Hash(account.Bytes())]})
+		}
+		key := slotHash
+		if states.rawStorageKey {
+			key = slot
+		}
+		if prev, ok := states.storageOrigin[owner][key]; ok {
+			slotChanges = append(slotChanges, StateChange{Block: uint64(i), Prev: prev, Post: states.storageData[ownerKey][slotHash]})
+		}
+	}
+	check := func(name string, query func(first, last uint64, limit int) ([]StateChange, error), want []StateChange) {
+		t.Helper()
+
+		// Retrieve all changes at once
+		have, err := query(0, uint64(len(env.roots)), len(want)+1)
+		if err != nil {
+			t.Fatalf("%s: failed to retrieve changes: %v", name, err)
+		}
+		compareChanges(t, name, have, want)
+
+		// Walk through the changes one by one
+		have = have[:0]
+		for first := uint64(0); ; {
+			page, err := query(first, uint64(len(env.roots)), 1)
+			if err != nil {
+				t.Fatalf("%s: failed to retrieve changes: %v", name, err)
+			}
+			if len(page) == 0 {
+				break
+			}
+			have = append(have, page...)
+			first = page[0].Block + 1
+		}
+		compareChanges(t, name, have, want)
+
+		// Retrieve a sub range
+		if len(want) > 2 {
+			have, err := query(want[1].Block, want[len(want)-2].Block, len(want))
+			if err != nil {
+				t.Fatalf("%s: failed to retrieve changes: %v", name, err)
+			}
+			compareChanges(t, name, have, want[1:len(want)-1])
+		}
+	}
+	check("account", func(first, last uint64, limit int) ([]StateChange, error) {
+		return env.db.AccountChanges(root, account, first, last, limit)
+	}, accChanges)
+	check("storage", func(first, last uint64, limit int) ([]StateChange, error) {
+		return env.db.StorageChanges(root, owner, slot, first, last, limit)
+	}, slotChanges)
+}
+
+func compareChanges(t *testing.T, name string, have, want []StateChange) {
+	t.Helper()
+
+	if len(have) != len(want) {
+		t.Fatalf("%s: change count mismatch, have %d, want %d", name, len(have), len(want))
+	}
+	for i := range want {
+		if have[i].Block != want[i].Block || !bytes.Equal(have[i].Prev, want[i].Prev) || !bytes.Equal(have[i].Post, want[i].Post) {
+			t.Fatalf("%s: change %d mismatch, have %v, want %v", name, i, have[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pathdb
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+)
+
+// errStateDiffTooLarge is returned if the aggregated state diff exceeds the
+// given size limit.
+var errStateDiffTooLarge = errors.New("state diff is too large")
+
+// StateDiff is the aggregated set of states mutated by a range of blocks,
+// along with their values before the first block of the range. The values
+// are in the slim account and trimmed storage encodings used by the state
+// histories, nil means the state was not present.
+type StateDiff struct {
+	Accounts map[common.Address][]byte                 // Account values keyed by address
+	Storages map[common.Address]map[common.Hash][]byte // Storage values keyed by address and raw slot key
+}
+
+// size returns the number of states in the diff.
+func (d *StateDiff) size() int {
+	n := len(d.Accounts)
+	for _, slots := range d.Storages {
+		n += len(slots)
+	}
+	return n
+}
+
+// merge adds the given pre-values into the diff, unless the states are already
+// tracked with an older value.
+func (d *StateDiff) merge(accounts map[common.Address][]byte, storages map[common.Address]map[common.Hash][]byte) {
+	for addr, blob := range accounts {
+		if _, ok := d.Accounts[addr]; !ok {
+			d.Accounts[addr] = blob
+		}
+	}
+	for addr, slots := range storages {
+		subset, ok := d.Storages[addr]
+		if !ok {
+			subset = make(map[common.Hash][]byte, len(slots))
+			d.Storages[addr] = subset
+		}
+		for key, blob := range slots {
+			if _, ok := subset[key]; !ok {
+				subset[key] = blob
+			}
+		}
+	}
+}
+
+// StateDiff returns the states mutated by the blocks within the range [first,
+// last] on the chain leading to the specified state, along with their values
+// before the first block. The diff is assembled by merging the state histories
+// and the diff layers in memory, the trie is not traversed. An error is returned
+// if the diff contains more than limit states.
+//
+// The values after the last block are not included; they can be resolved from
+// the state of the last block.
+func (db *Database) StateDiff(root common.Hash, first, last uint64, limit int) (*StateDiff, error) {
+	if first > last {
+		return nil, fmt.Errorf("range is invalid, first: %d, last: %d", first, last)
+	}
+	// Collect the diff layers on top of the disk layer, in ascending order.
+	l := db.tree.get(root)
+	if l == nil {
+		return nil, fmt.Errorf("state %#x is not available", root)
+	}
+	var diffs []*diffLayer
+	for {
+		diff, ok := l.(*diffLayer)
+		if !ok {
+			break
+		}
+		diffs = append(diffs, diff)
+		l = diff.parentLayer()
+	}
+	slices.Reverse(diffs)
+	disk := l.(*diskLayer)
+
+	diff := &StateDiff{
+		Accounts: make(map[common.Address][]byte),
+		Storages: make(map[common.Address]map[common.Hash][]byte),
+	}
+	// Merge the persisted state histories, unless the requested range is
+	// entirely covered by the diff layers or there are no histories at all.
+	if disk.stateID() > 0 && (len(diffs) == 0 || first < diffs[0].block) {
+		if err := db.mergeHistories(diff, disk.stateID(), first, last, limit); err != nil {
+			return nil, err
+		}
+	}
+	// Merge the state transitions held by the diff layers.
+	for _, layer := range diffs {
+		if layer.block > last {
+			break
+		}
+		if layer.block < first {
+			continue
+		}
+		if !layer.states.rawStorageKey && len(layer.states.storageOrigin) > 0 {
+			return nil, errors.New("state diff with hashed storage keys is not supported")
+		}
+		diff.merge(layer.states.accountOrigin, layer.states.storageOrigin)
+		if diff.size() > limit {
+			return nil, errStateDiffTooLarge
+		}
+	}
+	return diff, nil
+}
+
+// mergeHistories merges the state histories up to and including the given one,
+// which belong to the blocks within the range, into the diff.
+func (db *Database) mergeHistories(diff *StateDiff, lastID uint64, first, last uint64, limit int) error {
+	if db.stateFreezer == nil {
+		return errors.New("state histories are not available")
+	}
+	tail, err := db.stateFreezer.Tail(rawdb.DefaultHistoryGroup)
+	if err != nil {
+		return err
+	}
+	// Ensure the histories of the requested blocks haven't been pruned.
+	if tail > 0 {
+		if lastID <= tail {
+			return errors.New("historical state has been pruned")
+		}
+		m, err := readStateHistoryMeta(db.stateFreezer, tail+1)
+		if err != nil {
+			return err
+		}
+		if first < m.block {
+			return fmt.Errorf("historical state has been pruned, first available block: %d", m.block)
+		}
+	}
+	startID, err := searchHistory(db.stateFreezer, tail, lastID, first)
+	if err != nil {
+		return err
+	}
+	for id := startID; id <= lastID; id++ {
+		h, err := readStateHistory(db.stateFreezer, id)
+		if err != nil {
+			return err
+		}
+		if h.meta.block > last {
+			break
+		}
+		if h.meta.version == stateHistoryV0 && len(h.storages) > 0 {
+			return fmt.Errorf("state history %d has no raw storage keys", id)
+		}
+		diff.merge(h.accounts, h.storages)
+		if diff.size() > limit {
+			return errStateDiffTooLarge
+		}
+	}
+	return nil
+}
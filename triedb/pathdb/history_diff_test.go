@@ -0,0 +1,88 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pathdb
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestStateDiff(t *testing.T) {
+	maxDiffLayers = 4
+	defer func() {
+		maxDiffLayers = 128
+	}()
+
+	env := newTester(t, &testerConfig{layers: 32})
+	defer env.release()
+
+	// The first few layers use the hashed storage keys, skip them.
+	root := env.roots[len(env.roots)-1]
+	for _, r := range [][2]uint64{
+		{8, 12},  // persisted histories only
+		{8, 30},  // histories and diff layers
+		{29, 31}, // diff layers only
+		{10, 10}, // single block
+	} {
+		want := &StateDiff{
+			Accounts: make(map[common.Address][]byte),
+			Storages: make(map[common.Address]map[common.Hash][]byte),
+		}
+		for _, states := range env.states[r[0] : r[1]+1] {
+			want.merge(states.accountOrigin, states.storageOrigin)
+		}
+		have, err := env.db.StateDiff(root, r[0], r[1], want.size())
+		if err != nil {
+			t.Fatalf("Failed to retrieve state diff of %v: %v", r, err)
+		}
+		compareStateDiff(t, have, want)
+		if _, err := env.db.StateDiff(root, r[0], r[1], want.size()-1); !errors.Is(err, errStateDiffTooLarge) {
+			t.Fatalf("Oversized state diff of %v is not rejected: %v", r, err)
+		}
+	}
+	if _, err := env.db.StateDiff(root, 12, 8, 1000); err == nil {
+		t.Fatal("Invalid range is accepted")
+	}
+}
+
+// compareStateDiff checks the equality of the two state diffs, the absent
+// states are either nil or empty in the decoded state histories.
+func compareStateDiff(t *testing.T, have, want *StateDiff) {
+	t.Helper()
+
+	if len(have.Accounts) != len(want.Accounts) || len(have.Storages) != len(want.Storages) {
+		t.Fatalf("Unexpected state diff size, want %d accounts, %d storages, got %d, %d", len(want.Accounts), len(want.Storages), len(have.Accounts), len(have.Storages))
+	}
+	for addr, blob := range want.Accounts {
+		if !bytes.Equal(have.Accounts[addr], blob) {
+			t.Fatalf("Unexpected account %x, want %x, got %x", addr, blob, have.Accounts[addr])
+		}
+	}
+	for addr, slots := range want.Storages {
+		if len(have.Storages[addr]) != len(slots) {
+			t.Fatalf("Unexpected storage size of %x, want %d, got %d", addr, len(slots), len(have.Storages[addr]))
+		}
+		for key, blob := range slots {
+			if !bytes.Equal(have.Storages[addr][key], blob) {
+				t.Fatalf("Unexpected slot %x:%x, want %x, got %x", addr, key, blob, have.Storages[addr][key])
+			}
+		}
+	}
+}
@@ -0,0 +1,744 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pathdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	historyExportMagic   = "gethhistory" // Magic tag of the state history export
+	historyExportVersion = 0             // Version of the state history export format
+
+	// historyImportDir is the directory within the ancient store in which
+	// the histories are staged during an import.
+	historyImportDir = "history.import"
+
+	// historyImportJournal is the file within the staging directory holding
+	// the renumbered layer journal.
+	historyImportJournal = "journal"
+)
+
+// historyExportHeader is the leading element of a state history export,
+// describing the content of the stream.
+type historyExportHeader struct {
+	Magic     string
+	Version   uint64
+	UBT       bool   // Whether the histories belong to the binary trie
+	Trienodes bool   // Whether the trienode histories are included
+	First     uint64 // Block number of the first exported history
+	Last      uint64 // Block number of the last exported history
+	Count     uint64 // Number of exported histories
+}
+
+// historyExportEntry is the raw content of a state history, along with the
+// trienode history of the same state transition if it's exported.
+type historyExportEntry struct {
+	Meta         []byte
+	AccountIndex []byte
+	StorageIndex []byte
+	AccountData  []byte
+	StorageData  []byte
+
+	TrienodeHeader []byte
+	TrienodeKeys   []byte
+	TrienodeValues []byte
+}
+
+// historyExportTrailer terminates the export, carrying the keccak256 checksum
+// of the encoded header and all the encoded entries.
+type historyExportTrailer struct {
+	Checksum common.Hash
+}
+
+// ExportStateHistory writes the state histories of the blocks within the range
+// [first, last] into the given writer, clamped to the histories available
+// locally. The trienode histories are included if they cover the whole range.
+// It returns the number of exported histories.
+func (db *Database) ExportStateHistory(w io.Writer, first, last uint64) (uint64, error) {
+	if first > last {
+		return 0, fmt.Errorf("range is invalid, first: %d, last: %d", first, last)
+	}
+	if db.stateFreezer == nil {
+		return 0, errors.New("state history is not available")
+	}
+	tail, err := db.stateFreezer.Tail(rawdb.DefaultHistoryGroup)
+	if err != nil {
+		return 0, err
+	}
+	head, err := db.stateFreezer.Ancients()
+	if err != nil {
+		return 0, err
+	}
+	// Resolve the ids of the histories within the block range
+	start, err := searchHistory(db.stateFreezer, tail, head, first)
+	if err != nil {
+		return 0, err
+	}
+	end := head
+	if last != math.MaxUint64 {
+		next, err := searchHistory(db.stateFreezer, tail, head, last+1)
+		if err != nil {
+			return 0, err
+		}
+		end = next - 1
+	}
+	if start > end {
+		return 0, fmt.Errorf("no state history within block range [%d, %d]", first, last)
+	}
+	var trienodes bool
+	if db.trienodeFreezer != nil {
+		ttail, err := db.trienodeFreezer.Tail(rawdb.DefaultHistoryGroup)
+		if err != nil {
+			return 0, err
+		}
+		thead, err := db.trienodeFreezer.Ancients()
+		if err != nil {
+			return 0, err
+		}
+		trienodes = ttail < start && thead >= end
+	}
+	fm, err := readStateHistoryMeta(db.stateFreezer, start)
+	if err != nil {
+		return 0, err
+	}
+	lm, err := readStateHistoryMeta(db.stateFreezer, end)
+	if err != nil {
+		return 0, err
+	}
+	header := historyExportHeader{
+		Magic:     historyExportMagic,
+		Version:   historyExportVersion,
+		UBT:       db.isUBT,
+		Trienodes: trienodes,
+		First:     fm.block,
+		Last:      lm.block,
+		Count:     end - start + 1,
+	}
+	var (
+		hasher = crypto.NewKeccakState()
+		out    = io.MultiWriter(w, hasher)
+		begin  = time.Now()
+		logged = time.Now()
+	)
+	if err := rlp.Encode(out, &header); err != nil {
+		return 0, err
+	}
+	for id := start; id <= end; id++ {
+		var entry historyExportEntry
+		entry.Meta, entry.AccountIndex, entry.StorageIndex, entry.AccountData, entry.StorageData, err = rawdb.ReadStateHistory(db.stateFreezer, id)
+		if err != nil {
+			return 0, err
+		}
+		if trienodes {
+			entry.TrienodeHeader, entry.TrienodeKeys, entry.TrienodeValues, err = rawdb.ReadTrienodeHistory(db.trienodeFreezer, id)
+			if err != nil {
+				return 0, err
+			}
+		}
+		if err := rlp.Encode(out, &entry); err != nil {
+			return 0, err
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Exporting state histories", "exported", id-start+1, "total", header.Count, "elapsed", common.PrettyDuration(time.Since(begin)))
+			logged = time.Now()
+		}
+	}
+	var trailer historyExportTrailer
+	hasher.Read(trailer.Checksum[:])
+	if err := rlp.Encode(w, &trailer); err != nil {
+		return 0, err
+	}
+	log.Info("Exported state histories", "first", header.First, "last", header.Last, "count", header.Count, "trienodes", trienodes, "elapsed", common.PrettyDuration(time.Since(begin)))
+	return header.Count, nil
+}
+
+// ImportStateHistory imports the state histories from the given export, which
+// must end at the state right before the earliest local history (or at the disk
+// state if there is none). It's meant to extend the historical state window of
+// the node backwards, e.g. after a snap sync. The trienode histories are only
+// imported if the local ones start at the same position.
+//
+// The history ids are node local, so all the local histories are renumbered
+// and the history indexes are rebuilt from scratch. The import is meant to be
+// performed offline. Once all the histories are staged, the import is committed
+// by a persisted marker and finished at startup if it gets interrupted. The
+// database is switched to read-only mode afterwards and must be reopened to be
+// used.
+func (db *Database) ImportStateHistory(r io.Reader) (uint64, error) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if err := db.modifyAllowed(); err != nil {
+		return 0, err
+	}
+	if db.stateFreezer == nil {
+		return 0, errors.New("state history is not available")
+	}
+	var (
+		stream = rlp.NewStream(r, 0)
+		hasher = crypto.NewKeccakState()
+		header historyExportHeader
+	)
+	blob, err := stream.Raw()
+	if err != nil {
+		return 0, fmt.Errorf("could not read header: %v", err)
+	}
+	hasher.Write(blob)
+	if err := rlp.DecodeBytes(blob, &header); err != nil {
+		return 0, fmt.Errorf("could not decode header: %v", err)
+	}
+	if header.Magic != historyExportMagic {
+		return 0, errors.New("incompatible data, wrong magic")
+	}
+	if header.Version != historyExportVersion {
+		return 0, fmt.Errorf("incompatible version %d, (support only %d)", header.Version, historyExportVersion)
+	}
+	if header.UBT != db.isUBT {
+		return 0, fmt.Errorf("incompatible trie type, binary trie: %t", header.UBT)
+	}
+	if header.Count == 0 {
+		return 0, errors.New("no state history to import")
+	}
+	// Resolve the state which the imported histories must lead to.
+	tail, err := db.stateFreezer.Tail(rawdb.DefaultHistoryGroup)
+	if err != nil {
+		return 0, err
+	}
+	head, err := db.stateFreezer.Ancients()
+	if err != nil {
+		return 0, err
+	}
+	anchor := db.tree.bottom().rootHash()
+	if head > tail {
+		m, err := readStateHistoryMeta(db.stateFreezer, tail+1)
+		if err != nil {
+			return 0, err
+		}
+		anchor = m.parent
+	}
+	ancient, err := db.diskdb.AncientDatadir()
+	if err != nil {
+		return 0, err
+	}
+	// The trienode histories must be renumbered along with the state histories
+	// even if they are not enabled, otherwise they are no longer aligned.
+	freezer := db.trienodeFreezer
+	if freezer == nil && ancient != "" && common.FileExist(trienodeFreezerDir(ancient, db.isUBT)) {
+		freezer, err = rawdb.NewTrienodeFreezer(ancient, db.isUBT, false)
+		if err != nil {
+			return 0, err
+		}
+		defer freezer.Close()
+	}
+	// Local trienode histories older than the first state history are
+	// superseded by the imported ones and dropped.
+	var ttail, thead uint64
+	if freezer != nil {
+		if ttail, err = freezer.Tail(rawdb.DefaultHistoryGroup); err != nil {
+			return 0, err
+		}
+		if thead, err = freezer.Ancients(); err != nil {
+			return 0, err
+		}
+	}
+	importTrienodes := header.Trienodes && freezer != nil && ttail <= tail
+	if header.Trienodes && !importTrienodes {
+		log.Warn("Skipping trienode histories, not continuous with local ones")
+	}
+	ttail = max(ttail, tail)
+
+	// Stage the imported histories followed by the local ones in temporary
+	// freezers, with the local ones renumbered.
+	var (
+		dir       string
+		committed bool
+	)
+	if ancient != "" {
+		dir = filepath.Join(ancient, historyImportDir)
+		if err := os.RemoveAll(dir); err != nil {
+			return 0, err
+		}
+		// The staged histories are kept once the import is committed, until
+		// it's finished.
+		defer func() {
+			if !committed {
+				os.RemoveAll(dir)
+			}
+		}()
+	}
+	states, err := rawdb.NewStateFreezer(dir, db.isUBT, false)
+	if err != nil {
+		return 0, err
+	}
+	defer states.Close()
+
+	var trienodes ethdb.ResettableAncientStore
+	if freezer != nil {
+		trienodes, err = rawdb.NewTrienodeFreezer(dir, db.isUBT, false)
+		if err != nil {
+			return 0, err
+		}
+		defer trienodes.Close()
+	}
+	if err := stageHistories(stream, hasher, &header, states, trienodes, importTrienodes, anchor); err != nil {
+		return 0, err
+	}
+	marker := &historyImport{Tail: tail, Count: header.Count}
+	shift := marker.shift
+	if err := copyHistories(db.stateFreezer, states, typeStateHistory, tail+1, head, shift); err != nil {
+		return 0, err
+	}
+	if trienodes != nil {
+		if !importTrienodes {
+			if _, err := trienodes.TruncateTail(rawdb.DefaultHistoryGroup, shift(ttail)); err != nil {
+				return 0, err
+			}
+		}
+		if err := copyHistories(freezer, trienodes, typeTrienodeHistory, ttail+1, thead, shift); err != nil {
+			return 0, err
+		}
+	}
+	// All the histories are staged, the persistent state must not be accessed
+	// until the database is reopened.
+	disk := db.tree.bottom()
+	if err := disk.terminate(); err != nil {
+		return 0, err
+	}
+	db.readOnly = true
+
+	if db.stateIndexer != nil {
+		db.stateIndexer.close()
+	}
+	if db.trienodeIndexer != nil {
+		db.trienodeIndexer.close()
+	}
+	marker.StateID = rawdb.ReadPersistentStateID(db.diskdb)
+
+	// Stage the journal of the loaded layers renumbered as well, then commit the
+	// import by persisting the marker. From now on the import is finished at
+	// startup if it's interrupted.
+	journal, err := db.renumberJournal(disk, shift)
+	if err != nil {
+		return 0, err
+	}
+	if err := syncHistory(states, trienodes); err != nil {
+		return 0, err
+	}
+	if dir != "" {
+		if journal != nil {
+			if err := writeFileSync(filepath.Join(dir, historyImportJournal), journal); err != nil {
+				return 0, err
+			}
+		}
+		blob, err := rlp.EncodeToBytes(marker)
+		if err != nil {
+			return 0, err
+		}
+		rawdb.WriteStateHistoryImport(db.diskdb, blob)
+		if err := db.diskdb.SyncKeyValue(); err != nil {
+			return 0, err
+		}
+		committed = true
+	}
+	if err := db.finishHistoryImport(marker, db.stateFreezer, freezer, states, trienodes, journal); err != nil {
+		return 0, err
+	}
+	committed = false
+	log.Info("Imported state histories", "first", header.First, "last", header.Last, "count", header.Count, "trienodes", importTrienodes)
+	return header.Count, nil
+}
+
+// stageHistories reads the exported histories from the stream and writes them
+// into the given stores with ids starting from one. The histories are verified
+// to be intact, continuous and leading to the specified state. The hasher must
+// already contain the encoded header.
+func stageHistories(stream *rlp.Stream, hasher crypto.KeccakState, header *historyExportHeader, states, trienodes ethdb.AncientWriter, importTrienodes bool, anchor common.Hash) error {
+	var (
+		prev   *meta
+		begin  = time.Now()
+		logged = time.Now()
+	)
+	for id := uint64(1); id <= header.Count; id++ {
+		blob, err := stream.Raw()
+		if err != nil {
+			return fmt.Errorf("could not read history %d: %v", id, err)
+		}
+		hasher.Write(blob)
+
+		var entry historyExportEntry
+		if err := rlp.DecodeBytes(blob, &entry); err != nil {
+			return fmt.Errorf("could not decode history %d: %v", id, err)
+		}
+		m, err := verifyExportEntry(&entry, header.Trienodes)
+		if err != nil {
+			return fmt.Errorf("invalid history %d: %v", id, err)
+		}
+		switch {
+		case prev == nil && m.block != header.First:
+			return fmt.Errorf("unexpected first block, want %d, got %d", header.First, m.block)
+		case prev != nil && m.parent != prev.root:
+			return fmt.Errorf("history %d is not continuous, parent: %#x, want: %#x", id, m.parent, prev.root)
+		case prev != nil && m.block <= prev.block:
+			return fmt.Errorf("history %d is out of order, block: %d, previous: %d", id, m.block, prev.block)
+		}
+		prev = m
+
+		if err := rawdb.WriteStateHistory(states, id, entry.Meta, entry.AccountIndex, entry.StorageIndex, entry.AccountData, entry.StorageData); err != nil {
+			return err
+		}
+		if importTrienodes {
+			if err := rawdb.WriteTrienodeHistory(trienodes, id, entry.TrienodeHeader, entry.TrienodeKeys, entry.TrienodeValues); err != nil {
+				return err
+			}
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Importing state histories", "imported", id, "total", header.Count, "block", m.block, "elapsed", common.PrettyDuration(time.Since(begin)))
+			logged = time.Now()
+		}
+	}
+	if prev.block != header.Last {
+		return fmt.Errorf("unexpected last block, want %d, got %d", header.Last, prev.block)
+	}
+	var trailer historyExportTrailer
+	if err := stream.Decode(&trailer); err != nil {
+		return fmt.Errorf("could not decode trailer: %v", err)
+	}
+	var checksum common.Hash
+	hasher.Read(checksum[:])
+	if checksum != trailer.Checksum {
+		return fmt.Errorf("checksum mismatch, want: %#x, got: %#x", trailer.Checksum, checksum)
+	}
+	if prev.root != anchor {
+		return fmt.Errorf("histories lead to state %#x, local histories start from %#x", prev.root, anchor)
+	}
+	return nil
+}
+
+// verifyExportEntry checks the integrity of an exported history, returning the
+// metadata of the state transition.
+func verifyExportEntry(entry *historyExportEntry, trienodes bool) (*meta, error) {
+	var m meta
+	if err := m.decode(entry.Meta); err != nil {
+		return nil, err
+	}
+	h := stateHistory{meta: &m}
+	if err := h.decode(entry.AccountData, entry.StorageData, entry.AccountIndex, entry.StorageIndex); err != nil {
+		return nil, err
+	}
+	if trienodes {
+		var th trienodeHistory
+		if err := th.decode(entry.TrienodeHeader, entry.TrienodeKeys, entry.TrienodeValues); err != nil {
+			return nil, err
+		}
+		if th.meta.parent != m.parent || th.meta.root != m.root || th.meta.block != m.block {
+			return nil, errors.New("trienode history is not aligned with state history")
+		}
+	}
+	return &m, nil
+}
+
+// copyHistories copies the histories within the range [start, end] from one
+// store to another, renumbering them with the given function.
+func copyHistories(src ethdb.AncientReader, dst ethdb.AncientWriter, typ historyType, start, end uint64, shift func(uint64) uint64) error {
+	var (
+		begin  = time.Now()
+		logged = time.Now()
+	)
+	for id := start; id <= end; id++ {
+		if typ == typeStateHistory {
+			meta, accountIndex, storageIndex, accounts, storages, err := rawdb.ReadStateHistory(src, id)
+			if err != nil {
+				return err
+			}
+			if err := rawdb.WriteStateHistory(dst, shift(id), meta, accountIndex, storageIndex, accounts, storages); err != nil {
+				return err
+			}
+		} else {
+			header, keys, values, err := rawdb.ReadTrienodeHistory(src, id)
+			if err != nil {
+				return err
+			}
+			if err := rawdb.WriteTrienodeHistory(dst, shift(id), header, keys, values); err != nil {
+				return err
+			}
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Copying histories", "type", typ, "id", id, "last", end, "elapsed", common.PrettyDuration(time.Since(begin)))
+			logged = time.Now()
+		}
+	}
+	return nil
+}
+
+// restoreHistories moves all the histories from the staging store into the
+// given empty store, keeping their ids.
+func restoreHistories(src ethdb.AncientReader, dst ethdb.AncientStore, typ historyType) error {
+	tail, err := src.Tail(rawdb.DefaultHistoryGroup)
+	if err != nil {
+		return err
+	}
+	head, err := src.Ancients()
+	if err != nil {
+		return err
+	}
+	if tail > 0 {
+		if _, err := dst.TruncateTail(rawdb.DefaultHistoryGroup, tail); err != nil {
+			return err
+		}
+	}
+	return copyHistories(src, dst, typ, tail+1, head, func(id uint64) uint64 { return id })
+}
+
+// renumberJournal returns the layer journal with the disk layer renumbered by
+// the given function. Nil is returned if the journal isn't the one the given
+// disk layer was loaded from, as it's discarded at startup anyway.
+func (db *Database) renumberJournal(disk *diskLayer, shift func(uint64) uint64) ([]byte, error) {
+	var (
+		blob []byte
+		err  error
+		path = db.journalPath()
+	)
+	if path != "" && common.FileExist(path) {
+		if blob, err = os.ReadFile(path); err != nil {
+			return nil, err
+		}
+	} else {
+		blob = rawdb.ReadTrieJournal(db.diskdb)
+	}
+	// The journal starts with the version and the disk root, followed by the
+	// root and the state id of the disk layer.
+	version, rest, err := rlp.SplitUint64(blob)
+	if err != nil || version != journalVersion {
+		return nil, nil
+	}
+	if _, _, rest, err = rlp.Split(rest); err != nil {
+		return nil, nil
+	}
+	kind, root, rest, err := rlp.Split(rest)
+	if err != nil || kind != rlp.String || !bytes.Equal(root, disk.rootHash().Bytes()) {
+		return nil, nil
+	}
+	prefix := blob[:len(blob)-len(rest)]
+	id, rest, err := rlp.SplitUint64(rest)
+	if err != nil || id != disk.stateID() {
+		return nil, nil
+	}
+	return slices.Concat(prefix, rlp.AppendUint64(nil, shift(id)), rest), nil
+}
+
+// historyImport is the persisted marker of a committed state history import.
+// All the histories are staged at this point, the marker is removed once they
+// have replaced the local ones.
+type historyImport struct {
+	Tail    uint64 // Tail of the local state histories before the import
+	Count   uint64 // Number of imported state histories
+	StateID uint64 // Persistent state id before the import
+}
+
+// shift returns the id of a local history after the import.
+func (m *historyImport) shift(id uint64) uint64 {
+	return id - m.Tail + m.Count
+}
+
+// finishHistoryImport replaces the local histories with the staged ones, then
+// renumbers the persistent state and installs the staged journal. It can be
+// run repeatedly until the marker is removed at the end.
+func (db *Database) finishHistoryImport(marker *historyImport, states, trienodes ethdb.ResettableAncientStore, stagedStates, stagedTrienodes ethdb.AncientReader, journal []byte) error {
+	purgeHistory(states, db.diskdb, typeStateHistory)
+	purgeHistory(trienodes, db.diskdb, typeTrienodeHistory)
+
+	if err := restoreHistories(stagedStates, states, typeStateHistory); err != nil {
+		return err
+	}
+	if trienodes != nil {
+		if err := restoreHistories(stagedTrienodes, trienodes, typeTrienodeHistory); err != nil {
+			return err
+		}
+	}
+	if err := syncHistory(states, trienodes); err != nil {
+		return err
+	}
+	head, err := states.Ancients()
+	if err != nil {
+		return err
+	}
+	// Renumber the persistent state along with the root->id mappings.
+	var (
+		id    uint64
+		batch = db.diskdb.NewBatch()
+	)
+	err = checkStateHistories(states, 1, head, func(m *meta) error {
+		if id == 0 {
+			rawdb.WriteStateID(batch, m.parent, 0)
+		}
+		id++
+		rawdb.WriteStateID(batch, m.root, id)
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	rawdb.WritePersistentStateID(batch, marker.shift(marker.StateID))
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	if err := db.installJournal(journal); err != nil {
+		return err
+	}
+	if err := db.diskdb.SyncKeyValue(); err != nil {
+		return err
+	}
+	rawdb.DeleteStateHistoryImport(db.diskdb)
+	return db.diskdb.SyncKeyValue()
+}
+
+// resumeHistoryImport finishes the state history import interrupted by an
+// unclean shutdown, or drops the staged histories if it wasn't committed yet.
+// It must be called before the layers are loaded.
+func (db *Database) resumeHistoryImport() error {
+	ancient, err := db.diskdb.AncientDatadir()
+	if err != nil || ancient == "" {
+		return nil
+	}
+	dir := filepath.Join(ancient, historyImportDir)
+	blob := rawdb.ReadStateHistoryImport(db.diskdb)
+	if len(blob) == 0 {
+		if db.readOnly || !common.FileExist(dir) {
+			return nil
+		}
+		log.Warn("Dropping uncommitted state history import")
+		return os.RemoveAll(dir)
+	}
+	var marker historyImport
+	if err := rlp.DecodeBytes(blob, &marker); err != nil {
+		return err
+	}
+	if db.readOnly {
+		return errors.New("state history import is not finished, database is read-only")
+	}
+	log.Info("Resuming state history import", "count", marker.Count)
+
+	states, err := rawdb.NewStateFreezer(ancient, db.isUBT, false)
+	if err != nil {
+		return err
+	}
+	defer states.Close()
+	stagedStates, err := rawdb.NewStateFreezer(dir, db.isUBT, false)
+	if err != nil {
+		return err
+	}
+	defer stagedStates.Close()
+
+	var trienodes, stagedTrienodes ethdb.ResettableAncientStore
+	if common.FileExist(trienodeFreezerDir(ancient, db.isUBT)) {
+		if trienodes, err = rawdb.NewTrienodeFreezer(ancient, db.isUBT, false); err != nil {
+			return err
+		}
+		defer trienodes.Close()
+		if stagedTrienodes, err = rawdb.NewTrienodeFreezer(dir, db.isUBT, false); err != nil {
+			return err
+		}
+		defer stagedTrienodes.Close()
+	}
+	journal, err := os.ReadFile(filepath.Join(dir, historyImportJournal))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := db.finishHistoryImport(&marker, states, trienodes, stagedStates, stagedTrienodes, journal); err != nil {
+		return err
+	}
+	log.Info("Finished state history import", "count", marker.Count)
+	return os.RemoveAll(dir)
+}
+
+// installJournal replaces the layer journal with the given one, or removes it
+// if nil.
+func (db *Database) installJournal(journal []byte) error {
+	path := db.journalPath()
+	if journal == nil {
+		if path != "" {
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+		rawdb.DeleteTrieJournal(db.diskdb)
+		return nil
+	}
+	if path == "" {
+		rawdb.WriteTrieJournal(db.diskdb, journal)
+		return nil
+	}
+	if err := os.MkdirAll(db.config.JournalDirectory, 0755); err != nil {
+		return err
+	}
+	tmp := path + tempJournalSuffix
+	if err := writeFileSync(tmp, journal); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	return syncDir(db.config.JournalDirectory)
+}
+
+// writeFileSync writes the data into the named file and flushes it to disk.
+func writeFileSync(name string, data []byte) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// trienodeFreezerDir returns the directory of the trienode history freezer
+// within the given ancient store.
+func trienodeFreezerDir(ancient string, isUBT bool) string {
+	if isUBT {
+		return filepath.Join(ancient, rawdb.VerkleTrienodeFreezerName)
+	}
+	return filepath.Join(ancient, rawdb.MerkleTrienodeFreezerName)
+}
@@ -0,0 +1,175 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pathdb
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+)
+
+func TestStateHistoryExportImport(t *testing.T) {
+	testStateHistoryExportImport(t, false)
+}
+
+func TestStateHistoryImportResume(t *testing.T) {
+	testStateHistoryExportImport(t, true)
+}
+
+func testStateHistoryExportImport(t *testing.T, interrupt bool) {
+	maxDiffLayers = 4
+	defer func() {
+		maxDiffLayers = 128
+	}()
+
+	env := newTester(t, &testerConfig{layers: 32, journalDir: t.TempDir()})
+	defer func() { env.release() }()
+
+	// Histories are numbered from one, the history with id n belongs to the
+	// block n-1. Export the histories of blocks [4, 13].
+	var buf bytes.Buffer
+	n, err := env.db.ExportStateHistory(&buf, 4, 13)
+	if err != nil {
+		t.Fatalf("Failed to export histories: %v", err)
+	}
+	if n != 10 {
+		t.Fatalf("Unexpected number of exported histories, want 10, got %d", n)
+	}
+	export := buf.Bytes()
+
+	readAll := func(first, last uint64) (states, trienodes [][][]byte) {
+		for id := first; id <= last; id++ {
+			m, ai, si, ad, sd, err := rawdb.ReadStateHistory(env.db.stateFreezer, id)
+			if err != nil {
+				t.Fatalf("Failed to read state history %d: %v", id, err)
+			}
+			states = append(states, [][]byte{m, ai, si, ad, sd})
+
+			h, k, v, err := rawdb.ReadTrienodeHistory(env.db.trienodeFreezer, id)
+			if err != nil {
+				t.Fatalf("Failed to read trienode history %d: %v", id, err)
+			}
+			trienodes = append(trienodes, [][]byte{h, k, v})
+		}
+		return states, trienodes
+	}
+	wantStates, wantTrienodes := readAll(5, env.db.tree.bottom().stateID())
+
+	// Simulate a node with the histories before block 14 pruned, reopen it
+	// from the journal.
+	if _, err := truncateFromTail(env.db.stateFreezer, typeStateHistory, 14); err != nil {
+		t.Fatalf("Failed to truncate state histories: %v", err)
+	}
+	if _, err := truncateFromTail(env.db.trienodeFreezer, typeTrienodeHistory, 14); err != nil {
+		t.Fatalf("Failed to truncate trienode histories: %v", err)
+	}
+	reopen := func() {
+		if err := env.db.Journal(env.roots[len(env.roots)-1]); err != nil && err != errDatabaseReadOnly {
+			t.Fatalf("Failed to journal the layers: %v", err)
+		}
+		env.db.Close()
+		env.db = New(env.db.diskdb, env.db.config, false)
+	}
+	reopen()
+
+	// Corrupted or non-continuous exports must be rejected.
+	corrupted := bytes.Clone(export)
+	corrupted[len(corrupted)/2]++
+	if _, err := env.db.ImportStateHistory(bytes.NewReader(corrupted)); err == nil {
+		t.Fatal("Corrupted export is accepted")
+	}
+	// The header is covered by the checksum too, clear the trienode flag.
+	corrupted = bytes.Clone(export)
+	if pos := bytes.Index(corrupted, []byte(historyExportMagic)) + len(historyExportMagic) + 2; corrupted[pos] != 0x01 {
+		t.Fatalf("Unexpected trienode flag %#x", corrupted[pos])
+	} else {
+		corrupted[pos] = 0x80
+	}
+	if _, err := env.db.ImportStateHistory(bytes.NewReader(corrupted)); err == nil {
+		t.Fatal("Export with corrupted header is accepted")
+	}
+	var partial bytes.Buffer
+	if _, err := env.db.ExportStateHistory(&partial, 14, 15); err != nil {
+		t.Fatalf("Failed to export histories: %v", err)
+	}
+	if _, err := env.db.ImportStateHistory(&partial); err == nil {
+		t.Fatal("Non-continuous export is accepted")
+	}
+	// Import the histories, all the histories are renumbered by four.
+	if interrupt {
+		// Fail the import right before the journal is replaced, after all
+		// the histories have been replaced.
+		tmp := env.db.journalPath() + tempJournalSuffix
+		if err := os.Mkdir(tmp, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := env.db.ImportStateHistory(bytes.NewReader(export)); err == nil {
+			t.Fatal("Interrupted import succeeded")
+		}
+		if blob := rawdb.ReadStateHistoryImport(env.db.diskdb); len(blob) == 0 {
+			t.Fatal("Import marker is not persisted")
+		}
+		if err := os.Remove(tmp); err != nil {
+			t.Fatal(err)
+		}
+	} else {
+		n, err = env.db.ImportStateHistory(bytes.NewReader(export))
+		if err != nil {
+			t.Fatalf("Failed to import histories: %v", err)
+		}
+		if n != 10 {
+			t.Fatalf("Unexpected number of imported histories, want 10, got %d", n)
+		}
+	}
+	reopen()
+
+	if blob := rawdb.ReadStateHistoryImport(env.db.diskdb); len(blob) != 0 {
+		t.Fatal("Import marker is not removed")
+	}
+	if id := env.db.tree.bottom().stateID(); id != 24 {
+		t.Fatalf("Unexpected disk layer id, want 24, got %d", id)
+	}
+	if env.db.tree.len() != 5 {
+		t.Fatalf("Unexpected number of layers, want 5, got %d", env.db.tree.len())
+	}
+	first, last, err := env.db.HistoryRange()
+	if err != nil {
+		t.Fatalf("Failed to retrieve history range: %v", err)
+	}
+	if first != 4 || last != 27 {
+		t.Fatalf("Unexpected history range, want [4, 27], got [%d, %d]", first, last)
+	}
+	haveStates, haveTrienodes := readAll(1, 24)
+	if !reflect.DeepEqual(haveStates, wantStates) {
+		t.Fatal("State histories are not matched")
+	}
+	if !reflect.DeepEqual(haveTrienodes, wantTrienodes) {
+		t.Fatal("Trienode histories are not matched")
+	}
+	// Revert the database to the state before the earliest imported history.
+	for i := env.bottomIndex(); i >= 4; i-- {
+		if err := env.db.Recover(env.roots[i-1]); err != nil {
+			t.Fatalf("Failed to revert db, err: %v", err)
+		}
+		if err := env.verifyState(env.roots[i-1]); err != nil {
+			t.Fatalf("Failed to verify state, err: %v", err)
+		}
+	}
+}
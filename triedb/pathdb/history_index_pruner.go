@@ -55,6 +55,8 @@ type indexPruner struct {
 	typ     historyType
 	tail    atomic.Uint64 // Tail below which index entries can be pruned
 	lastRun uint64        // The tail in the last pruning run
+	force   atomic.Bool   // Whether to prune regardless of the accumulated amount
+	running atomic.Bool   // Whether a pruning run is in progress
 	trigger chan struct{} // Non-blocking signal that tail has advanced
 	closed  chan struct{}
 	wg      sync.WaitGroup
@@ -81,18 +83,27 @@ func newIndexPruner(disk ethdb.KeyValueStore, typ historyType) *indexPruner {
 }
 
 // prune signals the pruner that the history tail has advanced to the given ID.
-// All index entries referencing history IDs below newTail can be removed.
-func (p *indexPruner) prune(newTail uint64) {
-	// Only update if the tail is actually advancing
+// All index entries referencing history IDs below newTail can be removed. If
+// force is set, the pruning is scheduled even if only a few histories have been
+// removed since the last run.
+func (p *indexPruner) prune(newTail uint64, force bool) {
+	// Only update if the tail is actually advancing. A forced signal is still
+	// delivered, as the previously advanced tail may not have been served yet.
 	for {
 		old := p.tail.Load()
 		if newTail <= old {
-			return
+			if !force {
+				return
+			}
+			break
 		}
 		if p.tail.CompareAndSwap(old, newTail) {
 			break
 		}
 	}
+	if force {
+		p.force.Store(true)
+	}
 	// Non-blocking signal
 	select {
 	case p.trigger <- struct{}{}:
@@ -141,14 +152,16 @@ func (p *indexPruner) run() {
 		select {
 		case <-p.trigger:
 			tail := p.tail.Load()
-			if tail < p.lastRun || tail-p.lastRun < indexPruningThreshold {
+			if tail < p.lastRun || (!p.force.Swap(false) && tail-p.lastRun < indexPruningThreshold) {
 				continue
 			}
+			p.running.Store(true)
 			if err := p.process(tail); err != nil {
 				p.log.Error("Failed to prune index", "tail", tail, "err", err)
 			} else {
 				p.lastRun = tail
 			}
+			p.running.Store(false)
 
 		case ack := <-p.pauseReq:
 			// Pruner is idle, acknowledge immediately and wait for resume.
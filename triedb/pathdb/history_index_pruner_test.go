@@ -19,6 +19,7 @@ package pathdb
 import (
 	"math"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/rawdb"
@@ -353,3 +354,34 @@ func TestPrunePauseResume(t *testing.T) {
 		t.Fatal("First block of early account should have been pruned")
 	}
 }
+
+// TestPruneForce verifies that a forced pruning signal is served even if the
+// tail hasn't advanced far enough since the last run.
+func TestPruneForce(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	ident := newAccountIdent(common.Hash{0xf})
+	descList := writeMultiBlockIndex(t, db, ident, 0, 1)
+	firstBlockMax := descList[0].max
+	origLen := len(readStateIndex(ident, db))
+
+	pruner := newIndexPruner(db, typeStateHistory)
+	defer pruner.close()
+
+	// The tail advancement is below the threshold, the signal is ignored.
+	pruner.prune(firstBlockMax+1, false)
+	time.Sleep(50 * time.Millisecond)
+	if n := len(readStateIndex(ident, db)); n != origLen {
+		t.Fatalf("Unexpected pruning, original len %d, got %d", origLen, n)
+	}
+	// The forced signal must be served regardless of the threshold.
+	pruner.prune(firstBlockMax+1, true)
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		if len(readStateIndex(ident, db)) < origLen {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Index is not pruned")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -833,18 +833,25 @@ func (i *historyIndexer) shorten(historyID uint64) error {
 }
 
 // prune signals the pruner that the history tail has advanced to the given ID,
-// so that stale index blocks referencing pruned histories can be removed.
-func (i *historyIndexer) prune(newTail uint64) {
+// so that stale index blocks referencing pruned histories can be removed. If
+// force is set, the pruning is performed right away rather than deferred until
+// enough histories have been removed.
+func (i *historyIndexer) prune(newTail uint64, force bool) {
 	select {
 	case <-i.initer.closed:
 		log.Debug("Ignored the pruning signal", "reason", "closed")
 	case <-i.initer.done:
-		i.pruner.prune(newTail)
+		i.pruner.prune(newTail, force)
 	default:
 		log.Debug("Ignored the pruning signal", "reason", "busy")
 	}
 }
 
+// pruning reports whether the stale index data is being pruned.
+func (i *historyIndexer) pruning() bool {
+	return i.pruner.running.Load()
+}
+
 // progress returns the indexing progress made so far. It provides the number
 // of states that remain unindexed.
 func (i *historyIndexer) progress() (uint64, error) {
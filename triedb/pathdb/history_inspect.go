@@ -17,7 +17,9 @@
 package pathdb
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -156,3 +158,46 @@ func historyRange(freezer ethdb.AncientReader) (uint64, uint64, error) {
 	}
 	return fh.meta.block, lh.meta.block, nil
 }
+
+// trienodeHistoryRange returns the block number range of local trienode histories.
+func trienodeHistoryRange(freezer ethdb.AncientReader) (uint64, uint64, error) {
+	tail, err := freezer.Tail(rawdb.DefaultHistoryGroup)
+	if err != nil {
+		return 0, 0, err
+	}
+	head, err := freezer.Ancients()
+	if err != nil {
+		return 0, 0, err
+	}
+	if head == tail {
+		return 0, 0, errors.New("no trienode history available")
+	}
+	fm, err := readTrienodeMetadata(freezer, tail+1)
+	if err != nil {
+		return 0, 0, err
+	}
+	lm, err := readTrienodeMetadata(freezer, head)
+	if err != nil {
+		return 0, 0, err
+	}
+	return fm.block, lm.block, nil
+}
+
+// searchHistory returns the id of the first state history within the range
+// (tail, head] whose block number is not lower than the given one, or head+1
+// if there is no such history.
+func searchHistory(freezer ethdb.AncientReader, tail, head uint64, block uint64) (uint64, error) {
+	var searchErr error
+	id := tail + 1 + uint64(sort.Search(int(head-tail), func(i int) bool {
+		m, err := readStateHistoryMeta(freezer, tail+1+uint64(i))
+		if err != nil {
+			searchErr = err
+			return true
+		}
+		return m.block >= block
+	}))
+	if searchErr != nil {
+		return 0, searchErr
+	}
+	return id, nil
+}
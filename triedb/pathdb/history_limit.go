@@ -0,0 +1,237 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pathdb
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// historyTruncateBatch is the maximum number of histories truncated at once
+// when shrinking the history window, the database lock is released in between.
+const historyTruncateBatch = 10000
+
+// historyTruncation tracks the background truncation of the histories falling
+// out of the retention window after it has been narrowed.
+type historyTruncation struct {
+	running   atomic.Bool   // Whether the truncation is in progress
+	remaining atomic.Uint64 // Number of histories waiting to be truncated
+}
+
+// HistoryPruneProgress describes the retention window of a history type along
+// with the progress of removing the histories falling out of it.
+type HistoryPruneProgress struct {
+	Limit        uint64 // Number of recent blocks to retain histories for, zero means the entire chain
+	Histories    uint64 // Number of histories currently retained
+	Remaining    uint64 // Number of histories waiting to be truncated
+	Truncating   bool   // Whether the excess histories are being truncated
+	IndexPruning bool   // Whether the index data of the truncated histories is being pruned
+}
+
+// SetStateHistoryLimit adjusts the number of recent blocks for which the state
+// histories are retained, zero means the entire chain. If the window is
+// narrowed, the excess histories are truncated in the background right away,
+// instead of waiting for the next block.
+func (db *Database) SetStateHistoryLimit(limit uint64) error {
+	return db.setHistoryLimit(typeStateHistory, limit)
+}
+
+// SetTrienodeHistoryLimit adjusts the number of recent blocks for which the
+// trienode histories are retained, zero means the entire chain. The trienode
+// history can't be enabled or disabled at runtime.
+func (db *Database) SetTrienodeHistoryLimit(limit uint64) error {
+	return db.setHistoryLimit(typeTrienodeHistory, limit)
+}
+
+// HistoryLimits returns the number of recent blocks for which the state
+// histories and the trienode histories are retained. Zero means the entire
+// chain, negative means the trienode history is disabled.
+func (db *Database) HistoryLimits() (uint64, int64) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	return db.config.StateHistory, db.config.TrienodeHistory
+}
+
+// HistoryPruneProgress reports the retention window and the truncation progress
+// of the state histories and the trienode histories respectively. The latter
+// is nil if the trienode history is not enabled.
+func (db *Database) HistoryPruneProgress() (*HistoryPruneProgress, *HistoryPruneProgress, error) {
+	state, err := db.historyPruneProgress(typeStateHistory)
+	if err != nil {
+		return nil, nil, err
+	}
+	trienode, err := db.historyPruneProgress(typeTrienodeHistory)
+	if err != nil {
+		return nil, nil, err
+	}
+	return state, trienode, nil
+}
+
+// historyPruneProgress implements HistoryPruneProgress for the given history type.
+func (db *Database) historyPruneProgress(typ historyType) (*HistoryPruneProgress, error) {
+	db.lock.RLock()
+	freezer, indexer, truncation, limit := db.historyRetention(typ)
+	db.lock.RUnlock()
+
+	if freezer == nil {
+		return nil, nil
+	}
+	tail, err := freezer.Tail(rawdb.DefaultHistoryGroup)
+	if err != nil {
+		return nil, err
+	}
+	head, err := freezer.Ancients()
+	if err != nil {
+		return nil, err
+	}
+	progress := &HistoryPruneProgress{
+		Limit:      limit,
+		Histories:  head - tail,
+		Remaining:  truncation.remaining.Load(),
+		Truncating: truncation.running.Load(),
+	}
+	if indexer != nil {
+		progress.IndexPruning = indexer.pruning()
+	}
+	return progress, nil
+}
+
+// historyRetention returns the freezer, indexer, truncation tracker and the
+// retention limit of the given history type. The freezer is nil if the history
+// is not enabled. This function assumes the db.lock is already held.
+func (db *Database) historyRetention(typ historyType) (ethdb.AncientStore, *historyIndexer, *historyTruncation, uint64) {
+	if typ == typeStateHistory {
+		if db.stateFreezer == nil {
+			return nil, nil, nil, 0
+		}
+		return db.stateFreezer, db.stateIndexer, &db.stateTruncation, db.config.StateHistory
+	}
+	if db.trienodeFreezer == nil || db.config.TrienodeHistory < 0 {
+		return nil, nil, nil, 0
+	}
+	return db.trienodeFreezer, db.trienodeIndexer, &db.trienodeTruncation, uint64(db.config.TrienodeHistory)
+}
+
+// setHistoryLimit implements SetStateHistoryLimit and SetTrienodeHistoryLimit.
+func (db *Database) setHistoryLimit(typ historyType, limit uint64) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if err := db.modifyAllowed(); err != nil {
+		return err
+	}
+	freezer, _, truncation, _ := db.historyRetention(typ)
+	if freezer == nil {
+		return fmt.Errorf("%s history is not enabled", typ)
+	}
+	if typ == typeStateHistory {
+		db.config.StateHistory = limit
+	} else {
+		db.config.TrienodeHistory = int64(limit)
+	}
+	log.Info("Updated history limit", "type", typ, "limit", limit)
+
+	// Schedule the truncation of the excess histories if it's not running
+	// yet, otherwise the new limit is picked up by the running one.
+	if limit != 0 && truncation.running.CompareAndSwap(false, true) {
+		go db.truncateHistory(typ, truncation)
+	}
+	return nil
+}
+
+// truncateHistory removes the histories falling out of the retention window
+// from the tail in batches, releasing the database lock in between to not
+// block the state transitions.
+func (db *Database) truncateHistory(typ historyType, truncation *historyTruncation) {
+	var (
+		start  = time.Now()
+		logged = time.Now()
+	)
+	for db.truncateHistoryBatch(typ, truncation) {
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Truncating excess histories", "type", typ, "remaining", truncation.remaining.Load(), "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+}
+
+// truncateHistoryBatch truncates a batch of the histories falling out of the
+// retention window, returning whether there are more to truncate. Once done,
+// the truncation is marked as finished while still holding the lock, so that
+// a concurrent limit change can't be missed.
+func (db *Database) truncateHistoryBatch(typ historyType, truncation *historyTruncation) bool {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	stop := func() bool {
+		truncation.remaining.Store(0)
+		truncation.running.Store(false)
+		return false
+	}
+	// Terminate if the database is closed or deactivated in the meantime.
+	if db.modifyAllowed() != nil {
+		return stop()
+	}
+	freezer, indexer, _, limit := db.historyRetention(typ)
+	if freezer == nil || limit == 0 {
+		return stop()
+	}
+	tail, err := freezer.Tail(rawdb.DefaultHistoryGroup)
+	if err != nil {
+		log.Error("Failed to retrieve history tail", "type", typ, "err", err)
+		return stop()
+	}
+	head, err := freezer.Ancients()
+	if err != nil {
+		log.Error("Failed to retrieve history head", "type", typ, "err", err)
+		return stop()
+	}
+	if head-tail <= limit {
+		return stop()
+	}
+	// The histories of the persistent state must be retained, the rest are
+	// truncated once the buffered layers are flushed.
+	target := head - limit
+	if persistentID := rawdb.ReadPersistentStateID(db.diskdb); target >= persistentID {
+		if persistentID <= tail+1 {
+			return stop()
+		}
+		target = persistentID - 1
+	}
+	next := min(target, tail+historyTruncateBatch)
+	if _, err := truncateFromTail(freezer, typ, next); err != nil {
+		log.Error("Failed to truncate histories", "type", typ, "err", err)
+		return stop()
+	}
+	truncation.remaining.Store(target - next)
+	if next < target {
+		return true
+	}
+	// Prune the index data of the truncated histories right away.
+	if indexer != nil {
+		indexer.prune(next+1, true)
+	}
+	log.Info("Truncated excess histories", "type", typ, "items", next-tail, "tailid", next+1)
+	return stop()
+}
@@ -0,0 +1,103 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pathdb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+func TestSetHistoryLimit(t *testing.T) {
+	maxDiffLayers = 4
+	defer func() {
+		maxDiffLayers = 128
+	}()
+
+	// Flush every state transition to disk, so that all the histories beyond
+	// the limit can be truncated.
+	writeBuffer := 0
+	env := newTester(t, &testerConfig{layers: 32, writeBuffer: &writeBuffer})
+	defer env.release()
+
+	waitTruncation := func() (*HistoryPruneProgress, *HistoryPruneProgress) {
+		for {
+			state, trienode, err := env.db.HistoryPruneProgress()
+			if err != nil {
+				t.Fatalf("Failed to retrieve prune progress: %v", err)
+			}
+			if !state.Truncating && (trienode == nil || !trienode.Truncating) {
+				return state, trienode
+			}
+			time.Sleep(time.Millisecond * 10)
+		}
+	}
+	checkTail := func(freezer ethdb.AncientStore, want uint64) {
+		t.Helper()
+		tail, err := freezer.Tail(rawdb.DefaultHistoryGroup)
+		if err != nil {
+			t.Fatalf("Failed to retrieve tail: %v", err)
+		}
+		if tail != want {
+			t.Fatalf("Unexpected history tail, want %d, got %d", want, tail)
+		}
+	}
+	head := env.db.tree.bottom().stateID()
+
+	// Narrow the state history window, the excess histories must be
+	// truncated without further state transitions.
+	if err := env.db.SetStateHistoryLimit(10); err != nil {
+		t.Fatalf("Failed to set state history limit: %v", err)
+	}
+	state, trienode := waitTruncation()
+	if state.Limit != 10 || state.Histories != 10 || state.Remaining != 0 {
+		t.Fatalf("Unexpected state progress: %+v", state)
+	}
+	checkTail(env.db.stateFreezer, head-10)
+	if trienode == nil || trienode.Histories != head {
+		t.Fatalf("Unexpected trienode progress: %+v", trienode)
+	}
+	checkTail(env.db.trienodeFreezer, 0)
+
+	// Widening the window leaves the retained histories untouched.
+	if err := env.db.SetStateHistoryLimit(20); err != nil {
+		t.Fatalf("Failed to set state history limit: %v", err)
+	}
+	if state, _ = waitTruncation(); state.Limit != 20 || state.Histories != 10 {
+		t.Fatalf("Unexpected state progress: %+v", state)
+	}
+	if limit, _ := env.db.HistoryLimits(); limit != 20 {
+		t.Fatalf("Unexpected state history limit, want 20, got %d", limit)
+	}
+	// Narrow the trienode history window.
+	if err := env.db.SetTrienodeHistoryLimit(5); err != nil {
+		t.Fatalf("Failed to set trienode history limit: %v", err)
+	}
+	if _, trienode = waitTruncation(); trienode.Limit != 5 || trienode.Histories != 5 {
+		t.Fatalf("Unexpected trienode progress: %+v", trienode)
+	}
+	checkTail(env.db.trienodeFreezer, head-5)
+
+	// Narrowing the window again truncates the histories once more.
+	if err := env.db.SetStateHistoryLimit(3); err != nil {
+		t.Fatalf("Failed to set state history limit: %v", err)
+	}
+	waitTruncation()
+	checkTail(env.db.stateFreezer, head-3)
+}
@@ -312,6 +312,26 @@ func (dl *diffLayer) journal(w io.Writer) error {
 	return nil
 }
 
+// writeJournal writes the journal of the given layer, along with all the layers
+// below it, into the writer. This function assumes the db.lock is already held.
+func (db *Database) writeJournal(w io.Writer, l layer) error {
+	// Firstly write out the metadata of journal
+	if err := rlp.Encode(w, journalVersion); err != nil {
+		return err
+	}
+	// Secondly write out the state root in disk, ensure all layers
+	// on top are continuous with disk.
+	diskRoot, err := db.hasher(rawdb.ReadAccountTrieNode(db.diskdb, nil))
+	if err != nil {
+		return err
+	}
+	if err := rlp.Encode(w, diskRoot); err != nil {
+		return err
+	}
+	// Finally write out the journal of each layer in reverse order.
+	return l.journal(w)
+}
+
 // Journal commits an entire diff hierarchy to disk into a single journal entry.
 // This is meant to be used during shutdown to persist the layer without
 // flattening everything down (bad for reorgs). And this function will mark the
@@ -382,21 +402,7 @@ func (db *Database) Journal(root common.Hash) error {
 		journal = new(bytes.Buffer)
 	}
 
-	// Firstly write out the metadata of journal
-	if err := rlp.Encode(journal, journalVersion); err != nil {
-		return err
-	}
-	// Secondly write out the state root in disk, ensure all layers
-	// on top are continuous with disk.
-	diskRoot, err := db.hasher(rawdb.ReadAccountTrieNode(db.diskdb, nil))
-	if err != nil {
-		return err
-	}
-	if err := rlp.Encode(journal, diskRoot); err != nil {
-		return err
-	}
-	// Finally write out the journal of each layer in reverse order.
-	if err := l.journal(journal); err != nil {
+	if err := db.writeJournal(journal, l); err != nil {
 		return err
 	}
 